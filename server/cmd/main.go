@@ -1,11 +1,23 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	_ "time/tzdata" // user timezones must resolve even without system zoneinfo
 
 	"github.com/NetlutZ/subscout/internal/database"
 	"github.com/NetlutZ/subscout/internal/handler"
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/NetlutZ/subscout/internal/queue"
+	"github.com/NetlutZ/subscout/internal/realtime"
+	"github.com/NetlutZ/subscout/internal/receipt"
 	"github.com/NetlutZ/subscout/internal/repository"
 	"github.com/NetlutZ/subscout/internal/service"
 	"github.com/gofiber/fiber/v2"
@@ -19,6 +31,17 @@ func main() {
 		log.Println("Error Loading .env File : ", err)
 	}
 
+	// The first argument picks what this process runs: "api", "worker" for
+	// the background jobs, or "all" of it, the default
+	mode := "all"
+	if len(os.Args) > 1 {
+		mode = os.Args[1]
+	}
+	if mode != "all" && mode != "api" && mode != "worker" {
+		log.Fatalf("Unknown mode %q, expected api, worker or all", mode)
+	}
+	runAPI, runWorkers := mode != "worker", mode != "api"
+
 	// Connect to Database
 	db, err := database.DatabaseConnect()
 	if err != nil {
@@ -34,24 +57,276 @@ func main() {
 		log.Fatal("Error while creating/migrating database: ", err)
 	}
 
+	// Real-time events stay inside this process unless several replicas
+	// need to share them
+	var broker realtime.Broker = realtime.NewHub(realtime.DefaultBufferSize)
+	if os.Getenv("REALTIME_BROKER") == "postgres" {
+		pgBroker, err := realtime.NewPostgresBroker(db, database.DSN(), realtime.DefaultBufferSize)
+		if err != nil {
+			log.Fatal("Failed to listen for real-time events: ", err)
+		}
+		defer pgBroker.Close()
+		broker = pgBroker
+	}
+
 	subscriptionRepositoryDB := repository.NewSubscriptionRepositoryDB(db)
-	subscriptionService := service.NewSubscriptionService(subscriptionRepositoryDB)
+	customFieldRepo := repository.NewCustomFieldRepositoryDB(db)
+	userRepo := repository.NewUserRepositoryDB(db)
+	webhookRepo := repository.NewWebhookRepositoryDB(db)
+	webhookService := service.NewWebhookService(webhookRepo, subscriptionRepositoryDB, userRepo)
+	subscriptionService := service.NewSubscriptionService(subscriptionRepositoryDB, customFieldRepo)
 
 	app := fiber.New()
 	app.Use(cors.New(cors.Config{
 		AllowOrigins: "*",
 		AllowMethods: "GET,POST,PUT,DELETE",
-		AllowHeaders: "Content-Type, Authorization",
+		AllowHeaders: "Content-Type, Authorization, Last-Event-ID",
 	}))
 	handler.RegisterSubscriptionRoutes(app, subscriptionService)
+	handler.RegisterWebhookRoutes(app, webhookService)
+	handler.RegisterEventRoutes(app, broker)
 
-	userRepo := repository.NewUserRepositoryDB(db)
-	authService := service.NewAuthService(userRepo)
+	customFieldService := service.NewCustomFieldService(customFieldRepo)
+	handler.RegisterCustomFieldRoutes(app, customFieldService)
+
+	workspaceRepo := repository.NewWorkspaceRepositoryDB(db)
+	importService := service.NewImportService(subscriptionRepositoryDB, workspaceRepo)
+	handler.RegisterImportRoutes(app, importService)
+
+	chargeRepo := repository.NewChargeRepositoryDB(db)
+	exportService := service.NewExportService(subscriptionRepositoryDB, chargeRepo, customFieldRepo)
+	handler.RegisterExportRoutes(app, exportService)
+
+	suggestionRepo := repository.NewSuggestionRepositoryDB(db)
+	statementService := service.NewStatementService(subscriptionRepositoryDB, suggestionRepo)
+	handler.RegisterStatementRoutes(app, statementService)
+
+	suggestionService := service.NewSuggestionService(suggestionRepo)
+	handler.RegisterSuggestionRoutes(app, suggestionService)
+
+	currencyRepo := repository.NewCurrencyRepositoryDB(db)
+	authService := service.NewAuthService(userRepo, currencyRepo)
 	handler.RegisterAuthRoutes(app, authService)
 
-	port := os.Getenv("PORT")
-	if port == "" {
-		port = "8080"
+	renewalService := service.NewRenewalService(subscriptionRepositoryDB, userRepo, currencyRepo)
+	handler.RegisterRenewalRoutes(app, renewalService)
+
+	categoryRepo := repository.NewCategoryRepositoryDB(db)
+	categoryService := service.NewCategoryService(categoryRepo, subscriptionRepositoryDB, userRepo, currencyRepo)
+	handler.RegisterCategoryRoutes(app, categoryService)
+
+	tagRepo := repository.NewTagRepositoryDB(db)
+	tagService := service.NewTagService(tagRepo)
+	handler.RegisterTagRoutes(app, tagService)
+
+	summaryService := service.NewSummaryService(subscriptionRepositoryDB, userRepo, currencyRepo)
+	handler.RegisterSummaryRoutes(app, summaryService)
+
+	budgetRepo := repository.NewBudgetRepositoryDB(db)
+	budgetService := service.NewBudgetService(budgetRepo, categoryRepo, subscriptionRepositoryDB, chargeRepo, userRepo, currencyRepo)
+	handler.RegisterBudgetRoutes(app, budgetService)
+
+	workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo)
+	handler.RegisterWorkspaceRoutes(app, workspaceService)
+
+	shareRepo := repository.NewShareRepositoryDB(db)
+	shareService := service.NewShareService(shareRepo, subscriptionRepositoryDB, userRepo, currencyRepo)
+	handler.RegisterShareRoutes(app, shareService)
+
+	paymentMethodRepo := repository.NewPaymentMethodRepositoryDB(db)
+	paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo)
+	handler.RegisterPaymentMethodRoutes(app, paymentMethodService)
+
+	channelRepo := repository.NewNotificationChannelRepositoryDB(db)
+	notifiers := notifiersFromEnv()
+	channelService := service.NewNotificationChannelService(channelRepo, notifiers, broker)
+	outboxService := service.NewOutboxService(
+		repository.NewOutboxRepositoryDB(db),
+		workspaceRepo,
+		service.Publishers{webhookService, broker},
+		channelService,
+	)
+	handler.RegisterNotificationChannelRoutes(app, channelService)
+
+	prefRepo := repository.NewNotificationPreferenceRepositoryDB(db)
+	prefService := service.NewNotificationPreferenceService(prefRepo, channelRepo)
+	handler.RegisterNotificationPreferenceRoutes(app, prefService)
+
+	reminderRepo := repository.NewReminderRepositoryDB(db)
+	reminderService := service.NewReminderService(reminderRepo, prefRepo, subscriptionRepositoryDB, userRepo)
+	handler.RegisterReminderRoutes(app, reminderService)
+
+	digestRepo := repository.NewDigestRepositoryDB(db)
+	digestService := service.NewDigestService(digestRepo, userRepo, chargeRepo, renewalService, budgetService, notifiers[service.ChannelEmail])
+	handler.RegisterDigestRoutes(app, digestService)
+
+	calendarService := service.NewCalendarService(subscriptionRepositoryDB, userRepo)
+	handler.RegisterCalendarRoutes(app, calendarService)
+
+	receiptService := service.NewReceiptService(
+		receipt.NewDefaultParser(), subscriptionRepositoryDB, userRepo, suggestionRepo, os.Getenv("RECEIPT_INBOX"),
+	)
+	handler.RegisterReceiptRoutes(app, receiptService)
+
+	auditRepo := repository.NewAuditRepositoryDB(db)
+	auditService := service.NewAuditService(auditRepo)
+	handler.RegisterAuditRoutes(app, auditService)
+
+	retentionDays, err := strconv.Atoi(os.Getenv("TRASH_RETENTION_DAYS"))
+	if err != nil {
+		retentionDays = service.DefaultTrashRetentionDays
+	}
+	trashService := service.NewTrashService(subscriptionRepositoryDB, retentionDays)
+	handler.RegisterTrashRoutes(app, trashService)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var workers sync.WaitGroup
+	if runWorkers {
+		if mode == "worker" && os.Getenv("REALTIME_BROKER") != "postgres" {
+			log.Println("REALTIME_BROKER is not postgres, so events published by this worker do not reach API clients")
+		}
+
+		// Optional mailbox that users forward receipts to
+		if maildir := os.Getenv("RECEIPT_MAILDIR"); maildir != "" {
+			if os.Getenv("RECEIPT_INBOX") == "" {
+				log.Println("RECEIPT_INBOX is not set, so no receipt from", maildir, "can be matched to a user")
+			}
+			interval, err := time.ParseDuration(os.Getenv("RECEIPT_POLL_INTERVAL"))
+			if err != nil || interval <= 0 {
+				interval = 5 * time.Minute
+			}
+			poller := receipt.NewMaildirPoller(maildir, interval, receiptService.IngestMail)
+			go poller.Run(ctx)
+			log.Println("Polling receipts from", maildir)
+		}
+
+		jobs := queue.New(queue.NewPostgresStore(db))
+		// handlers get the job's lease as their deadline and stop once it
+		// has passed, as by then another worker may run the job again
+		schedule := func(kind string, env string, fallback string, fn func(context.Context) error) {
+			spec := scheduleFromEnv(env, fallback)
+			jobs.Handle(kind, func(ctx context.Context, _ queue.Job) error {
+				return fn(ctx)
+			})
+			if err := jobs.Schedule(kind, spec, kind, nil); err != nil {
+				log.Fatalf("Invalid schedule for %s: %v", kind, err)
+			}
+		}
+
+		// Budget alerts are also raised whenever a user opens their budget status
+		schedule("budgets.evaluate", "BUDGET_CHECK_INTERVAL", "@every 1h", budgetService.EvaluateAll)
+		schedule("payment_methods.check_expiry", "PAYMENT_METHOD_CHECK_INTERVAL", "@every 24h", paymentMethodService.CheckExpiring)
+
+		// Pending webhook deliveries are the retry queue; failed sends come
+		// back here once their backoff has passed
+		schedule("webhooks.deliver", "WEBHOOK_DELIVERY_INTERVAL", "@every 15s", webhookService.DeliverDue)
+		schedule("webhooks.queue_upcoming", "WEBHOOK_UPCOMING_INTERVAL", "@every 1h", webhookService.QueueUpcoming)
+
+		// Domain events are stored with the change that raised them and
+		// published from here, so a crash between the two only delays them.
+		// The relay polls on its own rather than as a job: an empty outbox
+		// is only read, while a job every second would write all the time.
+		relayInterval, err := time.ParseDuration(os.Getenv("OUTBOX_RELAY_INTERVAL"))
+		if err != nil || relayInterval <= 0 {
+			relayInterval = time.Second
+		}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			outboxService.Run(ctx, relayInterval)
+		}()
+
+		schedule("notifications.dispatch", "NOTIFICATION_DISPATCH_INTERVAL", "@every 30s", channelService.Dispatch)
+		schedule("reminders.send", "REMINDER_CHECK_INTERVAL", "@every 1h", reminderService.SendReminders)
+
+		// Digests need SMTP; each period is claimed once, so checking often
+		// only affects how soon after 08:00 local time they arrive
+		schedule("digests.send", "DIGEST_CHECK_INTERVAL", "@every 15m", digestService.SendDue)
+
+		// Deleted subscriptions wait in the trash for TRASH_RETENTION_DAYS
+		schedule("trash.purge", "TRASH_PURGE_INTERVAL", "@every 6h", trashService.PurgeExpired)
+
+		concurrency, err := strconv.Atoi(os.Getenv("WORKER_CONCURRENCY"))
+		if err != nil || concurrency <= 0 {
+			concurrency = 4
+		}
+		workers.Add(1)
+		go func() {
+			defer workers.Done()
+			if err := jobs.Run(ctx, concurrency); err != nil {
+				log.Fatal("Failed to start the job queue: ", err)
+			}
+		}()
+		log.Printf("Running background jobs on %d workers", concurrency)
+	}
+
+	if runAPI {
+		go func() {
+			<-ctx.Done()
+			app.Shutdown()
+		}()
+
+		port := os.Getenv("PORT")
+		if port == "" {
+			port = "8080"
+		}
+		if err := app.Listen("0.0.0.0:" + port); err != nil {
+			log.Fatal(err)
+		}
+		// the workers of this process stop along with the server
+		stop()
+	}
+
+	workers.Wait()
+}
+
+// notifiersFromEnv sets up the notification channels the server has
+// credentials for. Chat webhooks need none and are always available.
+func notifiersFromEnv() map[string]notifier.Notifier {
+	notifiers := map[string]notifier.Notifier{
+		service.ChannelWebhook: notifier.ChatWebhook{},
+	}
+
+	if host := os.Getenv("SMTP_HOST"); host != "" {
+		port, err := strconv.Atoi(os.Getenv("SMTP_PORT"))
+		if err != nil || port <= 0 {
+			port = 587
+		}
+		notifiers[service.ChannelEmail] = notifier.Email{
+			Host:     host,
+			Port:     port,
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		}
+	}
+	if token := os.Getenv("TELEGRAM_BOT_TOKEN"); token != "" {
+		notifiers[service.ChannelTelegram] = notifier.Telegram{
+			BaseURL: os.Getenv("TELEGRAM_API_URL"),
+			Token:   token,
+		}
+	}
+	if token := os.Getenv("LINE_CHANNEL_TOKEN"); token != "" {
+		notifiers[service.ChannelLINE] = notifier.LINE{
+			BaseURL: os.Getenv("LINE_API_URL"),
+			Token:   token,
+		}
+	}
+
+	return notifiers
+}
+
+// scheduleFromEnv reads when a job runs from env: a duration such as 15m,
+// or a spec as queue.ParseSpec reads it. Anything else falls back.
+func scheduleFromEnv(env string, fallback string) string {
+	value := strings.TrimSpace(os.Getenv(env))
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return "@every " + d.String()
+	}
+	if _, err := queue.ParseSpec(value); err == nil {
+		return value
 	}
-	log.Fatal(app.Listen("0.0.0.0:" + port))
+	return fallback
 }
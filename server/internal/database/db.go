@@ -12,13 +12,10 @@ import (
 
 // var DB *sql.DB
 
-func DatabaseConnect() (*sql.DB, error) {
-	err := godotenv.Load()
-	if err != nil {
-		log.Println("Error loading .env file")
-	}
-
-	dsn := fmt.Sprintf(
+// DSN is the connection string built from the DB_* environment variables,
+// also used by connections opened outside the pool such as LISTEN.
+func DSN() string {
+	return fmt.Sprintf(
 		"host=%s port=%s user=%s password=%s dbname=%s sslmode=require",
 		// "host=%s port=%s user=%s password=%s dbname=%s sslmode=disable",
 		os.Getenv("DB_HOST"),
@@ -27,8 +24,15 @@ func DatabaseConnect() (*sql.DB, error) {
 		os.Getenv("DB_PASSWORD"),
 		os.Getenv("DB_NAME"),
 	)
+}
+
+func DatabaseConnect() (*sql.DB, error) {
+	err := godotenv.Load()
+	if err != nil {
+		log.Println("Error loading .env file")
+	}
 
-	db, err := sql.Open("postgres", dsn)
+	db, err := sql.Open("postgres", DSN())
 	if err != nil {
 		return nil, err
 	}
@@ -12,6 +12,25 @@ func Migrate() string {
 	
 	ALTER TABLE users
 	ADD COLUMN IF NOT EXISTS name TEXT NOT NULL DEFAULT '';
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS base_currency VARCHAR(10) NOT NULL DEFAULT 'THB';
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS reminder_days INTEGER NOT NULL DEFAULT 3;
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS calendar_token_hash VARCHAR(64) UNIQUE;
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS receipt_token_hash VARCHAR(64) UNIQUE;
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS timezone TEXT NOT NULL DEFAULT 'UTC';
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS digest_frequency VARCHAR(10) NOT NULL DEFAULT 'off'
+		CHECK (digest_frequency IN ('off', 'weekly', 'monthly'));
 	
 	CREATE TABLE IF NOT EXISTS subscriptions (
 		id SERIAL PRIMARY KEY,
@@ -32,16 +51,13 @@ func Migrate() string {
 		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
 	
-	DO $$
-	BEGIN
-		IF NOT EXISTS (
-			SELECT 1 FROM pg_constraint WHERE conname = 'unique_user_subscription'
-		) THEN
-			ALTER TABLE subscriptions
-			ADD CONSTRAINT unique_user_subscription
-			UNIQUE (user_id, name);
-		END IF;
-	END$$;
+	CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_name_trgm
+	ON subscriptions USING GIN (name gin_trgm_ops);
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_category_trgm
+	ON subscriptions USING GIN (category gin_trgm_ops);
 		
 	CREATE TABLE IF NOT EXISTS notifications (
 		id SERIAL PRIMARY KEY,
@@ -55,6 +71,676 @@ func Migrate() string {
 
 		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
 	);
+
+	CREATE TABLE IF NOT EXISTS exchange_rates (
+		currency VARCHAR(10) PRIMARY KEY,
+		rate NUMERIC(18,8) NOT NULL,		-- value of 1 unit in THB
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS subscription_charges (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		subscription_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL,
+
+		charged_on DATE NOT NULL,
+		amount DECIMAL(10,2) NOT NULL,
+		currency VARCHAR(10) DEFAULT 'THB',
+		source VARCHAR(20) NOT NULL DEFAULT 'manual',	-- manual, statement, renewal
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_subscription_charges_user
+	ON subscription_charges (user_id, charged_on);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_subscription_charge
+	ON subscription_charges (subscription_id, charged_on, amount);
+
+	CREATE TABLE IF NOT EXISTS subscription_price_history (
+		id SERIAL PRIMARY KEY,
+		subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+
+		old_amount DECIMAL(10,2) NOT NULL,
+		new_amount DECIMAL(10,2) NOT NULL,
+		old_currency VARCHAR(10),
+		new_currency VARCHAR(10),
+
+		changed_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE OR REPLACE FUNCTION record_subscription_price_change() RETURNS trigger AS $$
+	BEGIN
+		IF NEW.amount IS DISTINCT FROM OLD.amount
+		   OR NEW.currency IS DISTINCT FROM OLD.currency THEN
+			INSERT INTO subscription_price_history
+			(subscription_id, old_amount, new_amount, old_currency, new_currency)
+			VALUES (NEW.id, OLD.amount, NEW.amount, OLD.currency, NEW.currency);
+		END IF;
+		RETURN NEW;
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS subscriptions_price_history ON subscriptions;
+	CREATE TRIGGER subscriptions_price_history
+	AFTER UPDATE ON subscriptions
+	FOR EACH ROW EXECUTE FUNCTION record_subscription_price_change();
+
+	CREATE TABLE IF NOT EXISTS subscription_suggestions (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		source VARCHAR(20) NOT NULL,		-- statement, email
+		merchant_key VARCHAR(100) NOT NULL,
+		name VARCHAR(100) NOT NULL,
+		amount DECIMAL(10,2) NOT NULL,
+		currency VARCHAR(10) DEFAULT 'THB',
+		billing_cycle VARCHAR(20),
+		billing_date DATE,
+
+		confidence NUMERIC(4,2) NOT NULL DEFAULT 0,
+		amount_variation NUMERIC(8,4) NOT NULL DEFAULT 0,
+		matched_subscription_id INTEGER REFERENCES subscriptions(id) ON DELETE SET NULL,
+		evidence JSONB NOT NULL DEFAULT '[]',
+
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',	-- pending, accepted, rejected
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_pending_suggestion
+	ON subscription_suggestions (user_id, source, merchant_key)
+	WHERE status = 'pending';
+
+	CREATE TABLE IF NOT EXISTS categories (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		name VARCHAR(50) NOT NULL,
+		color VARCHAR(7),		-- #RRGGBB
+		icon VARCHAR(50),
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_user_category
+	ON categories (user_id, (lower(name)));
+
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS category_id INTEGER REFERENCES categories(id) ON DELETE SET NULL;
+
+	-- backfill categories from the free-text column; the first spelling seen wins
+	INSERT INTO categories (user_id, name)
+	SELECT DISTINCT ON (user_id, lower(trim(category))) user_id, trim(category)
+	FROM subscriptions
+	WHERE category_id IS NULL AND trim(COALESCE(category, '')) <> ''
+	ORDER BY user_id, lower(trim(category)), id
+	ON CONFLICT (user_id, (lower(name))) DO NOTHING;
+
+	UPDATE subscriptions s
+	SET category_id = c.id, category = c.name
+	FROM categories c
+	WHERE s.category_id IS NULL
+	  AND c.user_id = s.user_id
+	  AND lower(c.name) = lower(trim(s.category));
+
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS notes TEXT;		-- markdown
+
+	CREATE TABLE IF NOT EXISTS tags (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		name VARCHAR(50) NOT NULL,		-- work, shared, tax-deductible
+		color VARCHAR(7),			-- #RRGGBB
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_user_tag
+	ON tags (user_id, (lower(name)));
+
+	CREATE TABLE IF NOT EXISTS subscription_tags (
+		subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+		tag_id INTEGER NOT NULL REFERENCES tags(id) ON DELETE CASCADE,
+		PRIMARY KEY (subscription_id, tag_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_subscription_tags_tag
+	ON subscription_tags (tag_id);
+
+	CREATE TABLE IF NOT EXISTS custom_fields (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		key VARCHAR(50) NOT NULL,		-- cost_center, account_email
+		label VARCHAR(100) NOT NULL,
+		type VARCHAR(20) NOT NULL,		-- text, number, date, select, url
+		options TEXT[] NOT NULL DEFAULT '{}',	-- choices of a select field
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (user_id, key)
+	);
+
+	-- values keyed by custom_fields.key
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS custom_fields JSONB NOT NULL DEFAULT '{}';
+
+	CREATE TABLE IF NOT EXISTS subscription_shares (
+		subscription_id INTEGER PRIMARY KEY REFERENCES subscriptions(id) ON DELETE CASCADE,
+
+		split_method VARCHAR(20) NOT NULL DEFAULT 'equal',	-- equal, percentage, fixed
+		started_on DATE NOT NULL DEFAULT CURRENT_DATE,		-- first billing period that is split
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	-- people the owner splits the cost with; the owner pays the rest
+	CREATE TABLE IF NOT EXISTS subscription_members (
+		id SERIAL PRIMARY KEY,
+		subscription_id INTEGER NOT NULL REFERENCES subscription_shares(subscription_id) ON DELETE CASCADE,
+		user_id INTEGER REFERENCES users(id) ON DELETE SET NULL,	-- NULL for someone without an account
+
+		name VARCHAR(100) NOT NULL,
+		value DECIMAL(10,2),		-- percentage or fixed amount per period, unused for equal
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_subscription_member_user
+	ON subscription_members (subscription_id, user_id)
+	WHERE user_id IS NOT NULL;
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_subscription_member_name
+	ON subscription_members (subscription_id, lower(name))
+	WHERE user_id IS NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_subscription_members_user
+	ON subscription_members (user_id);
+
+	-- payments from a member to the owner, in the subscription's currency
+	CREATE TABLE IF NOT EXISTS share_settlements (
+		id SERIAL PRIMARY KEY,
+		member_id INTEGER NOT NULL REFERENCES subscription_members(id) ON DELETE CASCADE,
+
+		amount DECIMAL(10,2) NOT NULL,
+		paid_on DATE NOT NULL DEFAULT CURRENT_DATE,
+		note VARCHAR(200),
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE TABLE IF NOT EXISTS budgets (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		category_id INTEGER REFERENCES categories(id) ON DELETE CASCADE,	-- NULL = total budget
+
+		amount DECIMAL(10,2) NOT NULL,		-- per month, in the user's base currency
+		thresholds INTEGER[] NOT NULL DEFAULT '{80,100}',	-- percentages that trigger an alert
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_user_budget
+	ON budgets (user_id, (COALESCE(category_id, 0)));
+
+	-- category budgets used to live on the categories table
+	DO $$
+	BEGIN
+		IF EXISTS (
+			SELECT 1 FROM information_schema.columns
+			WHERE table_name = 'categories' AND column_name = 'monthly_budget'
+		) THEN
+			INSERT INTO budgets (user_id, category_id, amount)
+			SELECT user_id, id, monthly_budget
+			FROM categories
+			WHERE monthly_budget IS NOT NULL
+			ON CONFLICT (user_id, (COALESCE(category_id, 0))) DO NOTHING;
+
+			ALTER TABLE categories DROP COLUMN monthly_budget;
+		END IF;
+	END$$;
+
+	-- one row per threshold crossed, so every alert is sent once per period
+	CREATE TABLE IF NOT EXISTS budget_alerts (
+		budget_id INTEGER NOT NULL REFERENCES budgets(id) ON DELETE CASCADE,
+		period DATE NOT NULL,			-- first day of the month
+		basis VARCHAR(20) NOT NULL,		-- projected, actual
+		threshold INTEGER NOT NULL,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (budget_id, period, basis, threshold)
+	);
+
+	-- subscriptions belong to a workspace; subscriptions.user_id only
+	-- records who created them
+	CREATE TABLE IF NOT EXISTS workspaces (
+		id SERIAL PRIMARY KEY,
+		name VARCHAR(100) NOT NULL,
+		personal BOOLEAN NOT NULL DEFAULT FALSE,
+		created_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_personal_workspace
+	ON workspaces (created_by)
+	WHERE personal;
+
+	CREATE TABLE IF NOT EXISTS workspace_members (
+		workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		role VARCHAR(20) NOT NULL,		-- owner, admin, editor, viewer
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (workspace_id, user_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_workspace_members_user
+	ON workspace_members (user_id);
+
+	CREATE TABLE IF NOT EXISTS workspace_invitations (
+		id SERIAL PRIMARY KEY,
+		workspace_id INTEGER NOT NULL REFERENCES workspaces(id) ON DELETE CASCADE,
+		email VARCHAR(255) NOT NULL,
+		role VARCHAR(20) NOT NULL,
+		token_hash VARCHAR(64) NOT NULL UNIQUE,
+		invited_by INTEGER REFERENCES users(id) ON DELETE SET NULL,
+
+		expires_at TIMESTAMP NOT NULL,
+		accepted_at TIMESTAMP,
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS workspace_id INTEGER REFERENCES workspaces(id);
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_workspace
+	ON subscriptions (workspace_id);
+
+	-- move subscriptions created before workspaces into their creator's
+	-- personal workspace
+	INSERT INTO workspaces (name, personal, created_by)
+	SELECT DISTINCT 'Personal', TRUE, s.user_id
+	FROM subscriptions s
+	WHERE s.workspace_id IS NULL
+	ON CONFLICT (created_by) WHERE personal DO NOTHING;
+
+	INSERT INTO workspace_members (workspace_id, user_id, role)
+	SELECT w.id, w.created_by, 'owner'
+	FROM workspaces w
+	WHERE w.personal AND w.created_by IS NOT NULL
+	ON CONFLICT DO NOTHING;
+
+	UPDATE subscriptions s
+	SET workspace_id = w.id
+	FROM workspaces w
+	WHERE s.workspace_id IS NULL AND w.personal AND w.created_by = s.user_id;
+
+	-- how subscriptions are paid; card numbers are never stored, only the
+	-- last four digits
+	CREATE TABLE IF NOT EXISTS payment_methods (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		label VARCHAR(50) NOT NULL,
+		type VARCHAR(20) NOT NULL,		-- card, bank_account, paypal, wallet, other
+		last4 CHAR(4),
+		expiry_month SMALLINT CHECK (expiry_month BETWEEN 1 AND 12),
+		expiry_year SMALLINT,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_payment_methods_user
+	ON payment_methods (user_id);
+
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS payment_method_id INTEGER REFERENCES payment_methods(id) ON DELETE SET NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_payment_method
+	ON subscriptions (payment_method_id);
+
+	-- one row per expiry warning sent; a new expiry date starts over
+	CREATE TABLE IF NOT EXISTS payment_method_alerts (
+		payment_method_id INTEGER NOT NULL REFERENCES payment_methods(id) ON DELETE CASCADE,
+		expires_on DATE NOT NULL,		-- last day of the expiry month
+		days_before INTEGER NOT NULL,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (payment_method_id, expires_on, days_before)
+	);
+
+	-- the last day to cancel is the next renewal minus the notice period
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS auto_renew BOOLEAN NOT NULL DEFAULT TRUE;
+
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS notice_period_days INTEGER NOT NULL DEFAULT 0 CHECK (notice_period_days >= 0);
+
+	-- endpoints users register to receive subscription events; an empty
+	-- events list subscribes to every event
+	CREATE TABLE IF NOT EXISTS webhooks (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		url TEXT NOT NULL,
+		secret VARCHAR(100) NOT NULL,		-- HMAC-SHA256 signing key
+		events TEXT[] NOT NULL DEFAULT '{}',
+		active BOOLEAN NOT NULL DEFAULT TRUE,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_webhooks_user
+	ON webhooks (user_id);
+
+	-- every event sent to a webhook; pending rows are the retry queue
+	CREATE TABLE IF NOT EXISTS webhook_deliveries (
+		id SERIAL PRIMARY KEY,
+		webhook_id INTEGER NOT NULL REFERENCES webhooks(id) ON DELETE CASCADE,
+
+		event VARCHAR(50) NOT NULL,
+		dedupe_key VARCHAR(200),		-- scheduled events are queued once per key
+		payload TEXT NOT NULL,
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',		-- pending, delivered, failed
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		response_status INTEGER,
+		last_error TEXT,
+		delivered_at TIMESTAMP,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_webhook_deliveries_dedupe
+	ON webhook_deliveries (webhook_id, dedupe_key) WHERE dedupe_key IS NOT NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_due
+	ON webhook_deliveries (next_attempt_at) WHERE status = 'pending';
+
+	CREATE INDEX IF NOT EXISTS idx_webhook_deliveries_webhook
+	ON webhook_deliveries (webhook_id, id DESC);
+
+	-- where a user's notifications are sent besides the app: an email
+	-- address, a chat webhook URL, a Telegram chat or a LINE user
+	CREATE TABLE IF NOT EXISTS notification_channels (
+		id SERIAL PRIMARY KEY,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+
+		type VARCHAR(20) NOT NULL,		-- email, webhook, telegram, line
+		target TEXT NOT NULL,
+		label VARCHAR(50),
+		enabled BOOLEAN NOT NULL DEFAULT TRUE,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_channels_user
+	ON notification_channels (user_id);
+
+	-- notifications that existed before channels are marked dispatched so
+	-- they are not sent out now; new rows start undispatched
+	ALTER TABLE notifications
+	ADD COLUMN IF NOT EXISTS dispatched_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP;
+
+	ALTER TABLE notifications
+	ALTER COLUMN dispatched_at DROP DEFAULT;
+
+	CREATE INDEX IF NOT EXISTS idx_notifications_undispatched
+	ON notifications (id) WHERE dispatched_at IS NULL;
+
+	-- one row per notification and channel; pending rows are retried
+	CREATE TABLE IF NOT EXISTS notification_deliveries (
+		id SERIAL PRIMARY KEY,
+		notification_id INTEGER NOT NULL REFERENCES notifications(id) ON DELETE CASCADE,
+		channel_id INTEGER NOT NULL REFERENCES notification_channels(id) ON DELETE CASCADE,
+
+		status VARCHAR(20) NOT NULL DEFAULT 'pending',		-- pending, sent, failed
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		last_error TEXT,
+		sent_at TIMESTAMP,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (notification_id, channel_id)
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_notification_deliveries_due
+	ON notification_deliveries (next_attempt_at) WHERE status = 'pending';
+
+	-- ids of real-time events shared between replicas over LISTEN/NOTIFY
+	CREATE SEQUENCE IF NOT EXISTS realtime_event_id;
+
+	-- one row per digest period, claimed before sending so a restart
+	-- never mails the same digest twice
+	CREATE TABLE IF NOT EXISTS digest_sends (
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		frequency VARCHAR(10) NOT NULL,
+		period_start DATE NOT NULL,
+		sent_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, frequency, period_start)
+	);
+
+	-- notifications are held back during quiet hours, in the user's
+	-- timezone; a window may wrap past midnight
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS quiet_hours_start VARCHAR(5);
+
+	ALTER TABLE users
+	ADD COLUMN IF NOT EXISTS quiet_hours_end VARCHAR(5);
+
+	-- which channels a notification type goes to; types without a row go
+	-- to every enabled channel and an empty list keeps them in the app
+	CREATE TABLE IF NOT EXISTS notification_preferences (
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		type VARCHAR(50) NOT NULL,
+		channel_ids INTEGER[] NOT NULL DEFAULT '{}',
+		PRIMARY KEY (user_id, type)
+	);
+
+	-- a user's reminders for a subscription stay silent until this day,
+	-- without affecting other members of its workspace
+	CREATE TABLE IF NOT EXISTS subscription_snoozes (
+		subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		until DATE NOT NULL,
+		PRIMARY KEY (subscription_id, user_id)
+	);
+
+	-- one row per reminder raised, so a renewal is only announced once
+	CREATE TABLE IF NOT EXISTS subscription_reminders (
+		user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+		subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+		kind VARCHAR(50) NOT NULL,		-- renewal_reminder, trial_ending, cancellation_deadline
+		due_on DATE NOT NULL,			-- the date being announced
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		PRIMARY KEY (user_id, subscription_id, kind, due_on)
+	);
+
+	-- when to remind about a subscription: days before its renewal, the
+	-- end of its trial or its last day to cancel. Subscriptions without
+	-- rules use each user's reminder_days before the renewal.
+	CREATE TABLE IF NOT EXISTS reminder_rules (
+		id SERIAL PRIMARY KEY,
+		subscription_id INTEGER NOT NULL REFERENCES subscriptions(id) ON DELETE CASCADE,
+		anchor VARCHAR(30) NOT NULL
+			CHECK (anchor IN ('renewal', 'trial_end', 'cancellation_deadline')),
+		days_before INTEGER NOT NULL CHECK (days_before BETWEEN 0 AND 365),
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		UNIQUE (subscription_id, anchor, days_before)
+	);
+
+	-- with several rules per date, a reminder is raised once per offset
+	ALTER TABLE subscription_reminders
+	ADD COLUMN IF NOT EXISTS days_before INTEGER NOT NULL DEFAULT 0;
+
+	ALTER TABLE subscription_reminders
+	DROP CONSTRAINT IF EXISTS subscription_reminders_pkey;
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_subscription_reminders_once
+	ON subscription_reminders (user_id, subscription_id, kind, due_on, days_before);
+
+	-- domain events, written in the transaction of the change they describe
+	-- and relayed to webhooks and the real-time stream afterwards. No
+	-- foreign keys: events outlive the rows they are about.
+	CREATE TABLE IF NOT EXISTS outbox (
+		id BIGSERIAL PRIMARY KEY,
+		aggregate_type VARCHAR(50) NOT NULL,		-- subscription, share, snooze
+		aggregate_id INTEGER NOT NULL,
+		event VARCHAR(50) NOT NULL,
+		user_id INTEGER NOT NULL,		-- who made the change
+		payload TEXT NOT NULL,		-- the aggregate as stored, as JSON
+
+		attempts INTEGER NOT NULL DEFAULT 0,
+		next_attempt_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+		last_error TEXT,
+		published_at TIMESTAMP,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_outbox_pending
+	ON outbox (aggregate_type, aggregate_id, id) WHERE published_at IS NULL;
+
+	-- background jobs, claimed by workers with FOR UPDATE SKIP LOCKED.
+	-- Jobs are deleted once they succeed; dead ones ran out of attempts
+	-- and stay for inspection, and are retried by setting them back to
+	-- pending with attempts = 0.
+	CREATE TABLE IF NOT EXISTS jobs (
+		id BIGSERIAL PRIMARY KEY,
+		kind VARCHAR(100) NOT NULL,
+		payload TEXT NOT NULL DEFAULT 'null',		-- JSON
+		unique_key VARCHAR(200),		-- at most one pending job per key
+		status VARCHAR(20) NOT NULL DEFAULT 'pending'
+			CHECK (status IN ('pending', 'dead')),
+		attempts INTEGER NOT NULL DEFAULT 0,
+		max_attempts INTEGER NOT NULL DEFAULT 5,
+		run_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,		-- also the lease of a running job
+		last_error TEXT,
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP,
+		updated_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_jobs_unique_key
+	ON jobs (unique_key) WHERE status = 'pending';
+
+	CREATE INDEX IF NOT EXISTS idx_jobs_due
+	ON jobs (run_at, id) WHERE status = 'pending';
+
+	-- recurring jobs; spec is "@every <duration>" or a cron expression
+	CREATE TABLE IF NOT EXISTS job_schedules (
+		name VARCHAR(100) PRIMARY KEY,
+		spec VARCHAR(100) NOT NULL,
+		kind VARCHAR(100) NOT NULL,
+		payload TEXT NOT NULL DEFAULT 'null',
+		next_run_at TIMESTAMP NOT NULL,
+		last_run_at TIMESTAMP
+	);
+
+	-- who changed what, written in the transaction of the change. Rows
+	-- outlive the users and entities they mention, so there are no foreign
+	-- keys, and they are never updated or deleted.
+	CREATE TABLE IF NOT EXISTS audit_log (
+		id BIGSERIAL PRIMARY KEY,
+		actor_id INTEGER,
+		ip VARCHAR(45),
+		user_agent TEXT,
+
+		action VARCHAR(50) NOT NULL,		-- subscription.deleted, user.settings_updated
+		entity_type VARCHAR(50) NOT NULL,		-- subscription, share, user
+		entity_id INTEGER NOT NULL,
+		workspace_id INTEGER,		-- lets workspace admins see changes to its subscriptions
+		changes JSONB NOT NULL DEFAULT '{}',		-- {"field": {"before": ..., "after": ...}}
+
+		created_at TIMESTAMP DEFAULT CURRENT_TIMESTAMP
+	);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_actor
+	ON audit_log (actor_id, id DESC);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_entity
+	ON audit_log (entity_type, entity_id, id DESC);
+
+	CREATE INDEX IF NOT EXISTS idx_audit_log_workspace
+	ON audit_log (workspace_id, id DESC) WHERE workspace_id IS NOT NULL;
+
+	CREATE OR REPLACE FUNCTION reject_audit_log_change() RETURNS trigger AS $$
+	BEGIN
+		RAISE EXCEPTION 'audit_log is append-only';
+	END;
+	$$ LANGUAGE plpgsql;
+
+	DROP TRIGGER IF EXISTS audit_log_append_only ON audit_log;
+	CREATE TRIGGER audit_log_append_only
+	BEFORE UPDATE OR DELETE ON audit_log
+	FOR EACH ROW EXECUTE FUNCTION reject_audit_log_change();
+
+	-- deleted subscriptions stay in the trash until they are purged, so
+	-- names only have to be unique among the rest
+	ALTER TABLE subscriptions
+	ADD COLUMN IF NOT EXISTS deleted_at TIMESTAMP;
+
+	ALTER TABLE subscriptions
+	DROP CONSTRAINT IF EXISTS unique_user_subscription;
+
+	-- names are unique within a workspace, so the same name can be used
+	-- in a personal and in a shared workspace
+	DROP INDEX IF EXISTS unique_user_subscription;
+
+	CREATE UNIQUE INDEX IF NOT EXISTS unique_workspace_subscription
+	ON subscriptions (workspace_id, name) WHERE deleted_at IS NULL;
+
+	CREATE INDEX IF NOT EXISTS idx_subscriptions_trash
+	ON subscriptions (deleted_at) WHERE deleted_at IS NOT NULL;
+
+	-- outbox events that ran out of attempts stay for inspection and are
+	-- retried by clearing dead_at and setting attempts back to 0
+	ALTER TABLE outbox
+	ADD COLUMN IF NOT EXISTS dead_at TIMESTAMP;
+
+	-- notifications created for an outbox event carry its key, so a
+	-- relayed event does not notify anyone twice
+	ALTER TABLE notifications
+	ADD COLUMN IF NOT EXISTS event_key VARCHAR(100);
+
+	CREATE UNIQUE INDEX IF NOT EXISTS idx_notifications_event_key
+	ON notifications (user_id, event_key) WHERE event_key IS NOT NULL;
+
+	-- changes made by background jobs, e.g. purging the trash, are
+	-- recorded with the system as their actor
+	ALTER TABLE audit_log
+	ADD COLUMN IF NOT EXISTS actor_type VARCHAR(20) NOT NULL DEFAULT 'user'
+		CHECK (actor_type IN ('user', 'system'));
+
+	-- a workspace can only be deleted once its subscriptions are gone, so
+	-- deleting one never takes its members' subscriptions with it
+	ALTER TABLE subscriptions
+	DROP CONSTRAINT IF EXISTS subscriptions_workspace_id_fkey;
+
+	ALTER TABLE subscriptions
+	ADD CONSTRAINT subscriptions_workspace_id_fkey
+	FOREIGN KEY (workspace_id) REFERENCES workspaces(id);
+
+	-- starting rates only; existing rows are never overwritten
+	INSERT INTO exchange_rates (currency, rate) VALUES
+		('THB', 1),
+		('USD', 36.5),
+		('EUR', 39.5),
+		('GBP', 46.0),
+		('JPY', 0.24),
+		('SGD', 27.0)
+	ON CONFLICT (currency) DO NOTHING;
 	`
 
 	return query
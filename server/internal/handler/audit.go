@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type auditHandler struct {
+	auditService service.AuditService
+}
+
+func NewAuditHandler(auditService service.AuditService) auditHandler {
+	return auditHandler{auditService: auditService}
+}
+
+func RegisterAuditRoutes(app *fiber.App, auditService service.AuditService) {
+	h := NewAuditHandler(auditService)
+
+	api := app.Group("/api")
+	api.Get("/audit", Protected(), h.GetAudit)
+}
+
+// getActor is the signed-in user making a change, as the audit log records
+// it.
+func getActor(c *fiber.Ctx) (repository.Actor, error) {
+	userID, err := getUserID(c)
+	if err != nil {
+		return repository.Actor{}, err
+	}
+	return requestActor(c, userID), nil
+}
+
+// requestActor is userID making a change through this request; zero for a
+// visitor who is not signed in.
+func requestActor(c *fiber.Ctx, userID int) repository.Actor {
+	return repository.Actor{
+		UserID:    userID,
+		IP:        c.IP(),
+		UserAgent: c.Get(fiber.HeaderUserAgent),
+	}
+}
+
+// GET /audit?entity_type=subscription&entity_id=3&action=subscription.updated
+// &actor_id=10&from=2026-01-01&to=2026-01-31&before=120&limit=50
+func (h auditHandler) GetAudit(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	filter := service.AuditFilter{
+		EntityType: c.Query("entity_type"),
+		Action:     c.Query("action"),
+		From:       c.Query("from"),
+		To:         c.Query("to"),
+	}
+
+	ints := []struct {
+		name string
+		set  func(int)
+	}{
+		{"entity_id", func(v int) { filter.EntityID = &v }},
+		{"actor_id", func(v int) { filter.ActorID = &v }},
+		{"before", func(v int) { filter.Before = v }},
+		{"limit", func(v int) { filter.Limit = v }},
+	}
+	for _, p := range ints {
+		value := c.Query(p.name)
+		if value == "" {
+			continue
+		}
+		v, err := strconv.Atoi(value)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid " + p.name,
+			})
+		}
+		p.set(v)
+	}
+
+	entries, err := h.auditService.GetAudit(userID, filter)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidAuditFilter) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(entries)
+}
@@ -1,6 +1,7 @@
 package handler
 
 import (
+	"errors"
 	"log"
 	"os"
 	"strings"
@@ -36,7 +37,7 @@ func (h AuthHandler) Register(c *fiber.Ctx) error {
 		})
 	}
 
-	user, err := h.authService.Register(body.Name, body.Email, body.Password)
+	user, err := h.authService.Register(body.Name, body.Email, body.Password, requestActor(c, 0))
 	if err != nil {
 		return c.Status(409).JSON(fiber.Map{"error": "email already exists"})
 	}
@@ -71,6 +72,50 @@ func (h AuthHandler) Logout(c *fiber.Ctx) error {
 	})
 }
 
+func (h AuthHandler) Me(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	user, err := h.authService.GetProfile(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user)
+}
+
+func (h AuthHandler) UpdateSettings(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.UpdateSettingsRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid body"})
+	}
+
+	user, err := h.authService.UpdateSettings(actor, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.Status(404).JSON(fiber.Map{"error": err.Error()})
+		case errors.Is(err, service.ErrInvalidCurrency), errors.Is(err, service.ErrUnknownCurrency),
+			errors.Is(err, service.ErrInvalidReminder), errors.Is(err, service.ErrInvalidTimezone),
+			errors.Is(err, service.ErrInvalidDigest):
+			return c.Status(400).JSON(fiber.Map{"error": err.Error()})
+		}
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	return c.JSON(user)
+}
+
 func Protected() fiber.Handler {
 	err := godotenv.Load(".env")
 	if err != nil {
@@ -115,4 +160,6 @@ func RegisterAuthRoutes(app *fiber.App, authService service.AuthService) {
 	auth.Post("/register", h.Register)
 	auth.Post("/login", h.Login)
 	auth.Post("/logout", Protected(), h.Logout)
+	auth.Get("/me", Protected(), h.Me)
+	auth.Put("/me", Protected(), h.UpdateSettings)
 }
@@ -0,0 +1,158 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type budgetHandler struct {
+	budgetService service.BudgetService
+}
+
+func NewBudgetHandler(budgetService service.BudgetService) budgetHandler {
+	return budgetHandler{budgetService: budgetService}
+}
+
+func RegisterBudgetRoutes(app *fiber.App, budgetService service.BudgetService) {
+	h := NewBudgetHandler(budgetService)
+
+	api := app.Group("/api")
+	budgets := api.Group("/budgets", Protected())
+
+	budgets.Get("/", h.GetBudgets)
+	budgets.Get("/status", h.GetBudgetStatus)
+	budgets.Post("/", h.CreateBudget)
+	budgets.Put("/:id", h.UpdateBudget)
+	budgets.Delete("/:id", h.DeleteBudget)
+}
+
+// GET /budgets
+func (h budgetHandler) GetBudgets(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	budgets, err := h.budgetService.GetBudgets(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(budgets)
+}
+
+// GET /budgets/status
+func (h budgetHandler) GetBudgetStatus(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	status, err := h.budgetService.GetBudgetStatus(userID)
+	if err != nil {
+		return budgetError(c, err)
+	}
+
+	return c.JSON(status)
+}
+
+// POST /budgets
+func (h budgetHandler) CreateBudget(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.BudgetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	budget, err := h.budgetService.CreateBudget(req, userID)
+	if err != nil {
+		return budgetError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(budget)
+}
+
+// PUT /budgets/:id
+func (h budgetHandler) UpdateBudget(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid budget id",
+		})
+	}
+
+	var req service.BudgetRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	budget, err := h.budgetService.UpdateBudget(id, req, userID)
+	if err != nil {
+		return budgetError(c, err)
+	}
+
+	return c.JSON(budget)
+}
+
+// DELETE /budgets/:id
+func (h budgetHandler) DeleteBudget(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid budget id",
+		})
+	}
+
+	err = h.budgetService.DeleteBudget(id, userID)
+	if err != nil {
+		return budgetError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "budget deleted"})
+}
+
+func budgetError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrBudgetNotFound),
+		errors.Is(err, service.ErrCategoryNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrDuplicateBudget):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidBudget):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,63 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type calendarHandler struct {
+	calendarService service.CalendarService
+}
+
+func NewCalendarHandler(calendarService service.CalendarService) calendarHandler {
+	return calendarHandler{calendarService: calendarService}
+}
+
+func RegisterCalendarRoutes(app *fiber.App, calendarService service.CalendarService) {
+	h := NewCalendarHandler(calendarService)
+
+	// calendar apps cannot send an Authorization header, the token in the
+	// URL is the credential
+	app.Get("/calendar/:token.ics", h.GetFeed)
+
+	api := app.Group("/api")
+	api.Post("/calendar/token", Protected(), h.RotateToken)
+}
+
+// GET /calendar/:token.ics
+func (h calendarHandler) GetFeed(c *fiber.Ctx) error {
+	feed, err := h.calendarService.GetFeed(c.Params("token"))
+	if err != nil {
+		if errors.Is(err, service.ErrCalendarNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, "text/calendar; charset=utf-8")
+	c.Set(fiber.HeaderCacheControl, "private, max-age=900")
+	return c.Send(feed)
+}
+
+// POST /calendar/token
+func (h calendarHandler) RotateToken(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.calendarService.RotateToken(actor)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(res)
+}
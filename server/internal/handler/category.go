@@ -0,0 +1,163 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type categoryHandler struct {
+	categoryService service.CategoryService
+}
+
+func NewCategoryHandler(categoryService service.CategoryService) categoryHandler {
+	return categoryHandler{categoryService: categoryService}
+}
+
+func RegisterCategoryRoutes(app *fiber.App, categoryService service.CategoryService) {
+	h := NewCategoryHandler(categoryService)
+
+	api := app.Group("/api")
+	categories := api.Group("/categories", Protected())
+
+	categories.Get("/", h.GetCategories)
+	categories.Get("/spend", h.GetCategorySpend)
+	categories.Post("/", h.CreateCategory)
+	categories.Put("/:id", h.UpdateCategory)
+	categories.Delete("/:id", h.DeleteCategory)
+}
+
+// GET /categories
+func (h categoryHandler) GetCategories(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	categories, err := h.categoryService.GetCategories(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(categories)
+}
+
+// GET /categories/spend
+func (h categoryHandler) GetCategorySpend(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	spend, err := h.categoryService.GetCategorySpend(userID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(spend)
+}
+
+// POST /categories
+func (h categoryHandler) CreateCategory(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	category, err := h.categoryService.CreateCategory(req, userID)
+	if err != nil {
+		return categoryError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(category)
+}
+
+// PUT /categories/:id
+func (h categoryHandler) UpdateCategory(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid category id",
+		})
+	}
+
+	var req service.CategoryRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	category, err := h.categoryService.UpdateCategory(id, req, actor)
+	if err != nil {
+		return categoryError(c, err)
+	}
+
+	return c.JSON(category)
+}
+
+// DELETE /categories/:id
+func (h categoryHandler) DeleteCategory(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid category id",
+		})
+	}
+
+	err = h.categoryService.DeleteCategory(id, actor)
+	if err != nil {
+		return categoryError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "category deleted"})
+}
+
+func categoryError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrCategoryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrDuplicateCategory):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidCategory):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type customFieldHandler struct {
+	customFieldService service.CustomFieldService
+}
+
+func NewCustomFieldHandler(customFieldService service.CustomFieldService) customFieldHandler {
+	return customFieldHandler{customFieldService: customFieldService}
+}
+
+func RegisterCustomFieldRoutes(app *fiber.App, customFieldService service.CustomFieldService) {
+	h := NewCustomFieldHandler(customFieldService)
+
+	api := app.Group("/api")
+	fields := api.Group("/custom-fields", Protected())
+
+	fields.Get("/", h.GetCustomFields)
+	fields.Post("/", h.CreateCustomField)
+	fields.Put("/:id", h.UpdateCustomField)
+	fields.Delete("/:id", h.DeleteCustomField)
+}
+
+// GET /custom-fields
+func (h customFieldHandler) GetCustomFields(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	fields, err := h.customFieldService.GetCustomFields(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fields)
+}
+
+// POST /custom-fields
+func (h customFieldHandler) CreateCustomField(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.CustomFieldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	field, err := h.customFieldService.CreateCustomField(req, userID)
+	if err != nil {
+		return customFieldError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(field)
+}
+
+// PUT /custom-fields/:id
+func (h customFieldHandler) UpdateCustomField(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid custom field id",
+		})
+	}
+
+	var req service.CustomFieldRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	field, err := h.customFieldService.UpdateCustomField(id, req, userID)
+	if err != nil {
+		return customFieldError(c, err)
+	}
+
+	return c.JSON(field)
+}
+
+// DELETE /custom-fields/:id
+func (h customFieldHandler) DeleteCustomField(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid custom field id",
+		})
+	}
+
+	err = h.customFieldService.DeleteCustomField(id, actor)
+	if err != nil {
+		return customFieldError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "custom field deleted"})
+}
+
+func customFieldError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrCustomFieldNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrDuplicateCustomField):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidCustomField):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type digestHandler struct {
+	digestService service.DigestService
+}
+
+func NewDigestHandler(digestService service.DigestService) digestHandler {
+	return digestHandler{digestService: digestService}
+}
+
+func RegisterDigestRoutes(app *fiber.App, digestService service.DigestService) {
+	h := NewDigestHandler(digestService)
+
+	api := app.Group("/api")
+	digest := api.Group("/digest", Protected())
+
+	digest.Get("/preview", h.PreviewDigest)
+}
+
+// GET /digest/preview?frequency=weekly&format=json|html|text
+func (h digestHandler) PreviewDigest(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	preview, err := h.digestService.PreviewDigest(userID, c.Query("frequency"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidDigest):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	switch c.Query("format", "json") {
+	case "html":
+		c.Set(fiber.HeaderContentType, fiber.MIMETextHTMLCharsetUTF8)
+		return c.SendString(preview.HTML)
+	case "text":
+		c.Set(fiber.HeaderContentType, fiber.MIMETextPlainCharsetUTF8)
+		return c.SendString(preview.Text)
+	case "json":
+		return c.JSON(preview)
+	}
+
+	return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+		"error": "format must be json, html or text",
+	})
+}
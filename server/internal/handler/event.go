@@ -0,0 +1,170 @@
+package handler
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/realtime"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	eventHeartbeat   = 25 * time.Second
+	eventRetryMillis = 3000
+	// eventResync tells a resuming client that events were lost and it
+	// should reload its data.
+	eventResync = "resync"
+)
+
+type eventHandler struct {
+	broker realtime.Broker
+}
+
+func NewEventHandler(broker realtime.Broker) eventHandler {
+	return eventHandler{broker: broker}
+}
+
+func RegisterEventRoutes(app *fiber.App, broker realtime.Broker) {
+	h := NewEventHandler(broker)
+
+	api := app.Group("/api")
+	events := api.Group("/events", Protected())
+
+	events.Get("/", h.Stream)
+	events.Get("/ws", requireUpgrade, websocket.New(h.Socket))
+}
+
+func requireUpgrade(c *fiber.Ctx) error {
+	if !websocket.IsWebSocketUpgrade(c) {
+		return fiber.ErrUpgradeRequired
+	}
+	return c.Next()
+}
+
+// lastEventID reads where a reconnecting client left off, from the header
+// EventSource sends or from ?last_event_id=.
+func lastEventID(value string) int64 {
+	id, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || id < 0 {
+		return 0
+	}
+	return id
+}
+
+// GET /events
+func (h eventHandler) Stream(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	resume := c.Get("Last-Event-ID")
+	if resume == "" {
+		resume = c.Query("last_event_id")
+	}
+	sub := h.broker.Subscribe(userID, lastEventID(resume))
+
+	c.Set(fiber.HeaderContentType, "text/event-stream")
+	c.Set(fiber.HeaderCacheControl, "no-cache")
+	c.Set(fiber.HeaderConnection, "keep-alive")
+	c.Set("X-Accel-Buffering", "no")
+
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		defer sub.Close()
+
+		fmt.Fprintf(w, "retry: %d\n\n", eventRetryMillis)
+		if sub.Missed {
+			fmt.Fprintf(w, "event: %s\ndata: {}\n\n", eventResync)
+		}
+		for _, e := range sub.Replay {
+			writeServerSentEvent(w, e)
+		}
+		if err := w.Flush(); err != nil {
+			return
+		}
+
+		heartbeat := time.NewTicker(eventHeartbeat)
+		defer heartbeat.Stop()
+
+		for {
+			select {
+			case e, ok := <-sub.Events:
+				if !ok {
+					return
+				}
+				writeServerSentEvent(w, e)
+			case <-heartbeat.C:
+				fmt.Fprint(w, ": keep-alive\n\n")
+			}
+			// a failed flush means the client has gone
+			if err := w.Flush(); err != nil {
+				return
+			}
+		}
+	})
+
+	return nil
+}
+
+func writeServerSentEvent(w *bufio.Writer, e realtime.Event) {
+	fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, e.Data)
+}
+
+// GET /events/ws
+func (h eventHandler) Socket(conn *websocket.Conn) {
+	userID, ok := conn.Locals("user_id").(int)
+	if !ok {
+		return
+	}
+
+	sub := h.broker.Subscribe(userID, lastEventID(conn.Query("last_event_id")))
+	defer sub.Close()
+
+	// clients only ever send close frames; reading is how we notice them
+	closed := make(chan struct{})
+	go func() {
+		defer close(closed)
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}()
+
+	if sub.Missed {
+		if err := conn.WriteJSON(fiber.Map{"type": eventResync, "data": json.RawMessage("{}")}); err != nil {
+			return
+		}
+	}
+	for _, e := range sub.Replay {
+		if err := conn.WriteJSON(e); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(eventHeartbeat)
+	defer heartbeat.Stop()
+
+	for {
+		select {
+		case e, ok := <-sub.Events:
+			if !ok {
+				conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseTryAgainLater, "too far behind"))
+				return
+			}
+			if err := conn.WriteJSON(e); err != nil {
+				return
+			}
+		case <-heartbeat.C:
+			if err := conn.WriteControl(websocket.PingMessage, nil, time.Now().Add(eventHeartbeat)); err != nil {
+				return
+			}
+		case <-closed:
+			return
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package handler
+
+import (
+	"bufio"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type exportHandler struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) exportHandler {
+	return exportHandler{exportService: exportService}
+}
+
+func RegisterExportRoutes(app *fiber.App, exportService service.ExportService) {
+	h := NewExportHandler(exportService)
+
+	api := app.Group("/api")
+	api.Get("/export", Protected(), h.Export)
+}
+
+// GET /export?format=csv|json|xlsx&dataset=
+func (h exportHandler) Export(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	file, err := h.exportService.PrepareExport(c.Query("format", service.ExportFormatCSV), c.Query("dataset"))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidExport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	c.Set(fiber.HeaderContentType, file.ContentType)
+	c.Set(fiber.HeaderContentDisposition, fmt.Sprintf(`attachment; filename="%s"`, file.Filename))
+
+	// the body is produced while it is being sent, so an error halfway
+	// through can only be logged, the status line is already out
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		if err := h.exportService.Export(userID, *file, w); err != nil {
+			log.Println("Error while exporting: ", err)
+		}
+		if err := w.Flush(); err != nil {
+			log.Println("Error while exporting: ", err)
+		}
+	})
+
+	return nil
+}
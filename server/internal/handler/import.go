@@ -0,0 +1,126 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type importHandler struct {
+	importService service.ImportService
+}
+
+func NewImportHandler(importService service.ImportService) importHandler {
+	return importHandler{importService: importService}
+}
+
+func RegisterImportRoutes(app *fiber.App, importService service.ImportService) {
+	h := NewImportHandler(importService)
+
+	api := app.Group("/api")
+	api.Post("/subscriptions/import", Protected(), h.ImportSubscriptions)
+}
+
+// POST /subscriptions/import?format=csv|json&mapping={...}&commit=true&workspace_id=2
+//
+// The file is either the raw request body or a multipart "file" field.
+// Without commit=true the response is a dry-run report.
+func (h importHandler) ImportSubscriptions(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	req := service.ImportRequest{
+		Format: c.Query("format"),
+		Commit: c.QueryBool("commit"),
+	}
+	mapping := c.Query("mapping")
+	if workspace := c.Query("workspace_id"); workspace != "" {
+		workspaceID, err := strconv.Atoi(workspace)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid workspace id",
+			})
+		}
+		req.WorkspaceID = &workspaceID
+	}
+
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+		defer f.Close()
+
+		if req.Data, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+		if req.Format == "" {
+			req.Format = strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+		}
+		if v := c.FormValue("mapping"); v != "" {
+			mapping = v
+		}
+		if c.FormValue("commit") == "true" {
+			req.Commit = true
+		}
+	} else {
+		req.Data = c.Body()
+		if req.Format == "" {
+			req.Format = formatFromContentType(c.Get(fiber.HeaderContentType))
+		}
+	}
+
+	if mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &req.Mapping); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "mapping must be a JSON object of field to column",
+			})
+		}
+	}
+
+	report, err := h.importService.ImportSubscriptions(req, actor)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidImport) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrWorkspaceNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		if errors.Is(err, service.ErrWorkspaceForbidden) {
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(report)
+}
+
+func formatFromContentType(contentType string) string {
+	switch {
+	case strings.HasPrefix(contentType, "text/csv"):
+		return service.ImportFormatCSV
+	case strings.HasPrefix(contentType, fiber.MIMEApplicationJSON):
+		return service.ImportFormatJSON
+	}
+	return ""
+}
@@ -0,0 +1,160 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type notificationChannelHandler struct {
+	channelService service.NotificationChannelService
+}
+
+func NewNotificationChannelHandler(channelService service.NotificationChannelService) notificationChannelHandler {
+	return notificationChannelHandler{channelService: channelService}
+}
+
+func RegisterNotificationChannelRoutes(app *fiber.App, channelService service.NotificationChannelService) {
+	h := NewNotificationChannelHandler(channelService)
+
+	api := app.Group("/api")
+	channels := api.Group("/notification-channels", Protected())
+
+	channels.Get("/", h.GetChannels)
+	channels.Post("/", h.CreateChannel)
+	channels.Put("/:id", h.UpdateChannel)
+	channels.Delete("/:id", h.DeleteChannel)
+	channels.Post("/:id/test", h.TestChannel)
+}
+
+// GET /notification-channels
+func (h notificationChannelHandler) GetChannels(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	channels, err := h.channelService.GetChannels(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(channels)
+}
+
+// POST /notification-channels
+func (h notificationChannelHandler) CreateChannel(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.NotificationChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	channel, err := h.channelService.CreateChannel(req, userID)
+	if err != nil {
+		return notificationChannelError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(channel)
+}
+
+// PUT /notification-channels/:id
+func (h notificationChannelHandler) UpdateChannel(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid channel id",
+		})
+	}
+
+	var req service.NotificationChannelRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	channel, err := h.channelService.UpdateChannel(id, req, userID)
+	if err != nil {
+		return notificationChannelError(c, err)
+	}
+
+	return c.JSON(channel)
+}
+
+// DELETE /notification-channels/:id
+func (h notificationChannelHandler) DeleteChannel(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid channel id",
+		})
+	}
+
+	if err := h.channelService.DeleteChannel(id, userID); err != nil {
+		return notificationChannelError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "notification channel deleted"})
+}
+
+// POST /notification-channels/:id/test
+func (h notificationChannelHandler) TestChannel(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid channel id",
+		})
+	}
+
+	if err := h.channelService.TestChannel(id, userID); err != nil {
+		return notificationChannelError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "test message sent"})
+}
+
+func notificationChannelError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrNotificationChannelNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidNotificationChannel):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrChannelTestFailed):
+		return c.Status(fiber.StatusBadGateway).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type notificationPreferenceHandler struct {
+	prefService service.NotificationPreferenceService
+}
+
+func NewNotificationPreferenceHandler(prefService service.NotificationPreferenceService) notificationPreferenceHandler {
+	return notificationPreferenceHandler{prefService: prefService}
+}
+
+func RegisterNotificationPreferenceRoutes(app *fiber.App, prefService service.NotificationPreferenceService) {
+	h := NewNotificationPreferenceHandler(prefService)
+
+	api := app.Group("/api")
+	prefs := api.Group("/notification-preferences", Protected())
+
+	prefs.Get("/", h.GetPreferences)
+	prefs.Put("/", h.UpdatePreferences)
+
+	api.Put("/subscriptions/:id/snooze", Protected(), h.Snooze)
+	api.Delete("/subscriptions/:id/snooze", Protected(), h.Unsnooze)
+}
+
+// GET /notification-preferences
+func (h notificationPreferenceHandler) GetPreferences(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	prefs, err := h.prefService.GetPreferences(userID)
+	if err != nil {
+		return notificationPreferenceError(c, err)
+	}
+
+	return c.JSON(prefs)
+}
+
+// PUT /notification-preferences
+func (h notificationPreferenceHandler) UpdatePreferences(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.NotificationPreferencesRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	prefs, err := h.prefService.UpdatePreferences(req, userID)
+	if err != nil {
+		return notificationPreferenceError(c, err)
+	}
+
+	return c.JSON(prefs)
+}
+
+// PUT /subscriptions/:id/snooze
+func (h notificationPreferenceHandler) Snooze(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.SnoozeRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	snooze, err := h.prefService.Snooze(id, req, actor)
+	if err != nil {
+		return notificationPreferenceError(c, err)
+	}
+
+	return c.JSON(snooze)
+}
+
+// DELETE /subscriptions/:id/snooze
+func (h notificationPreferenceHandler) Unsnooze(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	if err := h.prefService.Unsnooze(id, actor); err != nil {
+		return notificationPreferenceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "snooze removed"})
+}
+
+func notificationPreferenceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrUserNotFound),
+		errors.Is(err, service.ErrSubscriptionNotFound),
+		errors.Is(err, service.ErrSnoozeNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidNotificationPreferences),
+		errors.Is(err, service.ErrInvalidSnooze):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
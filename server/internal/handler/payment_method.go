@@ -0,0 +1,188 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type paymentMethodHandler struct {
+	paymentMethodService service.PaymentMethodService
+}
+
+func NewPaymentMethodHandler(paymentMethodService service.PaymentMethodService) paymentMethodHandler {
+	return paymentMethodHandler{paymentMethodService: paymentMethodService}
+}
+
+func RegisterPaymentMethodRoutes(app *fiber.App, paymentMethodService service.PaymentMethodService) {
+	h := NewPaymentMethodHandler(paymentMethodService)
+
+	api := app.Group("/api")
+	methods := api.Group("/payment-methods", Protected())
+
+	methods.Get("/", h.GetPaymentMethods)
+	methods.Post("/", h.CreatePaymentMethod)
+	methods.Put("/:id", h.UpdatePaymentMethod)
+	methods.Delete("/:id", h.DeletePaymentMethod)
+	methods.Get("/:id/subscriptions", h.GetAffectedSubscriptions)
+
+	api.Put("/subscriptions/:id/payment-method", Protected(), h.LinkSubscription)
+}
+
+// GET /payment-methods
+func (h paymentMethodHandler) GetPaymentMethods(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	methods, err := h.paymentMethodService.GetPaymentMethods(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(methods)
+}
+
+// POST /payment-methods
+func (h paymentMethodHandler) CreatePaymentMethod(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.PaymentMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	method, err := h.paymentMethodService.CreatePaymentMethod(req, userID)
+	if err != nil {
+		return paymentMethodError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(method)
+}
+
+// PUT /payment-methods/:id
+func (h paymentMethodHandler) UpdatePaymentMethod(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payment method id",
+		})
+	}
+
+	var req service.PaymentMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	method, err := h.paymentMethodService.UpdatePaymentMethod(id, req, userID)
+	if err != nil {
+		return paymentMethodError(c, err)
+	}
+
+	return c.JSON(method)
+}
+
+// DELETE /payment-methods/:id
+func (h paymentMethodHandler) DeletePaymentMethod(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payment method id",
+		})
+	}
+
+	if err := h.paymentMethodService.DeletePaymentMethod(id, userID); err != nil {
+		return paymentMethodError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "payment method deleted"})
+}
+
+// GET /payment-methods/:id/subscriptions
+func (h paymentMethodHandler) GetAffectedSubscriptions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid payment method id",
+		})
+	}
+
+	subs, err := h.paymentMethodService.GetAffectedSubscriptions(id, userID)
+	if err != nil {
+		return paymentMethodError(c, err)
+	}
+
+	return c.JSON(subs)
+}
+
+// PUT /subscriptions/:id/payment-method
+func (h paymentMethodHandler) LinkSubscription(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.LinkPaymentMethodRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	if err := h.paymentMethodService.LinkSubscription(id, req, actor); err != nil {
+		return paymentMethodError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "payment method updated"})
+}
+
+func paymentMethodError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrPaymentMethodNotFound),
+		errors.Is(err, service.ErrSubscriptionNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidPaymentMethod):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,98 @@
+package handler
+
+import (
+	"errors"
+	"io"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type receiptHandler struct {
+	receiptService service.ReceiptService
+}
+
+func NewReceiptHandler(receiptService service.ReceiptService) receiptHandler {
+	return receiptHandler{receiptService: receiptService}
+}
+
+func RegisterReceiptRoutes(app *fiber.App, receiptService service.ReceiptService) {
+	h := NewReceiptHandler(receiptService)
+
+	api := app.Group("/api")
+	api.Post("/receipts", Protected(), h.IngestReceipt)
+	api.Post("/receipts/address", Protected(), h.RotateAddress)
+}
+
+// POST /receipts
+//
+// The .eml message is either the raw request body (message/rfc822) or a
+// multipart "file" field.
+func (h receiptHandler) IngestReceipt(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	raw := c.Body()
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+		defer f.Close()
+
+		if raw, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+	}
+
+	suggestion, err := h.receiptService.IngestReceipt(raw, userID)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidReceipt):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrNoReceiptData):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrSuggestionNotPending):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(suggestion)
+}
+
+// POST /receipts/address
+func (h receiptHandler) RotateAddress(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.receiptService.RotateAddress(actor)
+	if err != nil {
+		if errors.Is(err, service.ErrReceiptInboxDisabled) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(res)
+}
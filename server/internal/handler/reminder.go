@@ -0,0 +1,166 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type reminderHandler struct {
+	reminderService service.ReminderService
+}
+
+func NewReminderHandler(reminderService service.ReminderService) reminderHandler {
+	return reminderHandler{reminderService: reminderService}
+}
+
+func RegisterReminderRoutes(app *fiber.App, reminderService service.ReminderService) {
+	h := NewReminderHandler(reminderService)
+
+	api := app.Group("/api")
+	reminders := api.Group("/subscriptions/:id/reminders", Protected())
+
+	reminders.Get("/", h.GetRules)
+	reminders.Post("/", h.CreateRule)
+	reminders.Put("/:ruleId", h.UpdateRule)
+	reminders.Delete("/:ruleId", h.DeleteRule)
+}
+
+// GET /subscriptions/:id/reminders
+func (h reminderHandler) GetRules(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	rules, err := h.reminderService.GetRules(id, userID)
+	if err != nil {
+		return reminderError(c, err)
+	}
+
+	return c.JSON(rules)
+}
+
+// POST /subscriptions/:id/reminders
+func (h reminderHandler) CreateRule(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.ReminderRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := h.reminderService.CreateRule(id, req, userID)
+	if err != nil {
+		return reminderError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(rule)
+}
+
+// PUT /subscriptions/:id/reminders/:ruleId
+func (h reminderHandler) UpdateRule(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("ruleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid reminder rule id",
+		})
+	}
+
+	var req service.ReminderRuleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	rule, err := h.reminderService.UpdateRule(id, ruleID, req, userID)
+	if err != nil {
+		return reminderError(c, err)
+	}
+
+	return c.JSON(rule)
+}
+
+// DELETE /subscriptions/:id/reminders/:ruleId
+func (h reminderHandler) DeleteRule(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	ruleID, err := strconv.Atoi(c.Params("ruleId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid reminder rule id",
+		})
+	}
+
+	if err := h.reminderService.DeleteRule(id, ruleID, userID); err != nil {
+		return reminderError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "reminder rule deleted"})
+}
+
+func reminderError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrSubscriptionNotFound),
+		errors.Is(err, service.ErrReminderRuleNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrDuplicateReminderRule):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidReminderRule):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
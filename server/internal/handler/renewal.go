@@ -0,0 +1,80 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type renewalHandler struct {
+	renewalService service.RenewalService
+}
+
+func NewRenewalHandler(renewalService service.RenewalService) renewalHandler {
+	return renewalHandler{renewalService: renewalService}
+}
+
+func RegisterRenewalRoutes(app *fiber.App, renewalService service.RenewalService) {
+	h := NewRenewalHandler(renewalService)
+
+	api := app.Group("/api")
+	api.Get("/renewals", Protected(), h.GetRenewals)
+	api.Get("/renewals/cancellation-windows", Protected(), h.GetCancellationWindows)
+}
+
+// GET /renewals?from=&to=
+func (h renewalHandler) GetRenewals(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	res, err := h.renewalService.GetRenewals(userID, c.Query("from"), c.Query("to"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidDate), errors.Is(err, service.ErrInvalidDateRange):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(res)
+}
+
+// GET /renewals/cancellation-windows?days=30
+func (h renewalHandler) GetCancellationWindows(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	days := 30
+	if v := c.Query("days"); v != "" {
+		days, err = strconv.Atoi(v)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid days",
+			})
+		}
+	}
+
+	windows, err := h.renewalService.GetCancellationWindows(userID, days)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidDateRange) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(windows)
+}
@@ -0,0 +1,183 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type shareHandler struct {
+	shareService service.ShareService
+}
+
+func NewShareHandler(shareService service.ShareService) shareHandler {
+	return shareHandler{shareService: shareService}
+}
+
+func RegisterShareRoutes(app *fiber.App, shareService service.ShareService) {
+	h := NewShareHandler(shareService)
+
+	api := app.Group("/api")
+	shares := api.Group("/shares", Protected())
+
+	shares.Get("/", h.GetShares)
+	shares.Get("/balances", h.GetBalances)
+
+	api.Get("/subscriptions/:id/share", Protected(), h.GetShare)
+	api.Put("/subscriptions/:id/share", Protected(), h.SetShare)
+	api.Delete("/subscriptions/:id/share", Protected(), h.DeleteShare)
+	api.Post("/subscriptions/:id/share/settlements", Protected(), h.RecordSettlement)
+}
+
+// GET /shares
+func (h shareHandler) GetShares(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	shares, err := h.shareService.GetShares(userID)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return c.JSON(shares)
+}
+
+// GET /shares/balances
+func (h shareHandler) GetBalances(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	balances, err := h.shareService.GetBalances(userID)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return c.JSON(balances)
+}
+
+// GET /subscriptions/:id/share
+func (h shareHandler) GetShare(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	share, err := h.shareService.GetShare(id, userID)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return c.JSON(share)
+}
+
+// PUT /subscriptions/:id/share
+func (h shareHandler) SetShare(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.ShareRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	share, err := h.shareService.SetShare(id, req, actor)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return c.JSON(share)
+}
+
+// DELETE /subscriptions/:id/share
+func (h shareHandler) DeleteShare(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	if err := h.shareService.DeleteShare(id, actor); err != nil {
+		return shareError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "share deleted"})
+}
+
+// POST /subscriptions/:id/share/settlements
+func (h shareHandler) RecordSettlement(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.SettlementRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	settlement, err := h.shareService.RecordSettlement(id, req, actor)
+	if err != nil {
+		return shareError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(settlement)
+}
+
+func shareError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrSubscriptionNotFound),
+		errors.Is(err, service.ErrShareNotFound),
+		errors.Is(err, service.ErrShareMemberNotFound),
+		errors.Is(err, service.ErrUserNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidShare),
+		errors.Is(err, service.ErrInvalidSettlement):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,90 @@
+package handler
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"path/filepath"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type statementHandler struct {
+	statementService service.StatementService
+}
+
+func NewStatementHandler(statementService service.StatementService) statementHandler {
+	return statementHandler{statementService: statementService}
+}
+
+func RegisterStatementRoutes(app *fiber.App, statementService service.StatementService) {
+	h := NewStatementHandler(statementService)
+
+	api := app.Group("/api")
+	api.Post("/statements/import", Protected(), h.ImportStatement)
+}
+
+// POST /statements/import?format=csv|ofx|qfx|camt053&mapping={...}
+//
+// The statement is either the raw request body or a multipart "file"
+// field. The format is detected from the content when not given.
+func (h statementHandler) ImportStatement(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	req := service.StatementImportRequest{Format: c.Query("format")}
+	mapping := c.Query("mapping")
+
+	if file, err := c.FormFile("file"); err == nil {
+		f, err := file.Open()
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+		defer f.Close()
+
+		if req.Data, err = io.ReadAll(f); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid file",
+			})
+		}
+		if req.Format == "" {
+			ext := strings.TrimPrefix(strings.ToLower(filepath.Ext(file.Filename)), ".")
+			if ext == "csv" || ext == "ofx" || ext == "qfx" {
+				req.Format = ext
+			}
+		}
+		if v := c.FormValue("mapping"); v != "" {
+			mapping = v
+		}
+	} else {
+		req.Data = c.Body()
+	}
+
+	if mapping != "" {
+		if err := json.Unmarshal([]byte(mapping), &req.Mapping); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "mapping must be a JSON object of field to column",
+			})
+		}
+	}
+
+	res, err := h.statementService.ImportStatement(req, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatement) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(res)
+}
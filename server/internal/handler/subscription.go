@@ -1,8 +1,11 @@
 package handler
 
 import (
+	"errors"
 	"strconv"
+	"strings"
 
+	"github.com/NetlutZ/subscout/internal/repository"
 	"github.com/NetlutZ/subscout/internal/service"
 	"github.com/gofiber/fiber/v2"
 )
@@ -22,8 +25,11 @@ func RegisterSubscriptionRoutes(app *fiber.App, subService service.SubscriptionS
 	subscriptions := api.Group("/subscriptions", Protected())
 
 	subscriptions.Get("/", h.GetSubscriptions)
+	subscriptions.Get("/search", h.SearchSubscriptions)
 	subscriptions.Get("/:id", h.GetSubscription)
 	subscriptions.Post("/", h.CreateSubscription)
+	subscriptions.Put("/:id", h.UpdateSubscription)
+	subscriptions.Put("/:id/custom-fields", h.SetCustomFields)
 	subscriptions.Delete("/:id", h.DeleteSubscription)
 }
 
@@ -35,15 +41,42 @@ func getUserID(c *fiber.Ctx) (int, error) {
 	return userID, nil
 }
 
-// GET /subscriptions
+// GET /subscriptions?workspace_id=2&tags=work,shared&cf.cost_center=IT
 func (h subscriptionHandler) GetSubscriptions(c *fiber.Ctx) error {
 	userID, err := getUserID(c)
 	if err != nil {
 		return err
 	}
 
-	subs, err := h.subService.GetSubscriptions(userID)
+	var filter service.SubscriptionFilter
+	if workspace := c.Query("workspace_id"); workspace != "" {
+		workspaceID, err := strconv.Atoi(workspace)
+		if err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid workspace id",
+			})
+		}
+		filter.WorkspaceID = &workspaceID
+	}
+	if tags := c.Query("tags"); tags != "" {
+		filter.Tags = strings.Split(tags, ",")
+	}
+	for key, value := range c.Queries() {
+		if field, ok := strings.CutPrefix(key, "cf."); ok {
+			if filter.CustomFields == nil {
+				filter.CustomFields = map[string]string{}
+			}
+			filter.CustomFields[field] = value
+		}
+	}
+
+	subs, err := h.subService.GetSubscriptions(userID, filter)
 	if err != nil {
+		if errors.Is(err, service.ErrUnknownCustomField) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -84,7 +117,7 @@ func (h subscriptionHandler) GetSubscription(c *fiber.Ctx) error {
 
 // POST /subscriptions
 func (h subscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
-	userID, err := getUserID(c)
+	actor, err := getActor(c)
 	if err != nil {
 		return err
 	}
@@ -96,8 +129,18 @@ func (h subscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	sub, err := h.subService.CreateSubscription(req, userID)
+	sub, err := h.subService.CreateSubscription(req, actor)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrWorkspaceForbidden):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidSubscription):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -106,9 +149,103 @@ func (h subscriptionHandler) CreateSubscription(c *fiber.Ctx) error {
 	return c.Status(fiber.StatusCreated).JSON(sub)
 }
 
+// PUT /subscriptions/:id
+func (h subscriptionHandler) UpdateSubscription(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var req service.UpdateSubscriptionRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	sub, err := h.subService.UpdateSubscription(id, req, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSubscriptionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrWorkspaceForbidden):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrDuplicateSubscription):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidSubscription):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(sub)
+}
+
+// PUT /subscriptions/:id/custom-fields
+func (h subscriptionHandler) SetCustomFields(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	var values map[string]any
+	if err := c.BodyParser(&values); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	sub, err := h.subService.SetCustomFields(id, values, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSubscriptionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrWorkspaceForbidden):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidSubscription):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(sub)
+}
+
 // DELETE /subscriptions/:id
 func (h subscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
-	userID, err := getUserID(c)
+	actor, err := getActor(c)
 	if err != nil {
 		return err
 	}
@@ -120,8 +257,18 @@ func (h subscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 		})
 	}
 
-	err = h.subService.DeleteSubscription(id, userID)
+	err = h.subService.DeleteSubscription(id, actor)
 	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSubscriptionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrWorkspaceForbidden):
+			return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
 		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
 			"error": err.Error(),
 		})
@@ -129,3 +276,25 @@ func (h subscriptionHandler) DeleteSubscription(c *fiber.Ctx) error {
 
 	return c.JSON("message : delete success")
 }
+
+// GET /subscriptions/search?q=
+func (h subscriptionHandler) SearchSubscriptions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	results, err := h.subService.SearchSubscriptions(c.Query("q"), userID)
+	if err != nil {
+		if errors.Is(err, service.ErrEmptySearchQuery) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(results)
+}
@@ -9,6 +9,7 @@ import (
 	"testing"
 
 	"github.com/NetlutZ/subscout/internal/handler"
+	"github.com/NetlutZ/subscout/internal/repository"
 	"github.com/NetlutZ/subscout/internal/service"
 	"github.com/gofiber/fiber/v2"
 	"github.com/stretchr/testify/assert"
@@ -22,6 +23,14 @@ func mockAuth() fiber.Handler {
 	}
 }
 
+// signedInActor matches the actor of a request made by the user mockAuth
+// signs in with the given User-Agent.
+func signedInActor(userAgent string) any {
+	return mock.MatchedBy(func(actor repository.Actor) bool {
+		return actor.UserID == 10 && actor.UserAgent == userAgent
+	})
+}
+
 func setupApp(mockSvc *service.SubscriptionServiceMock) *fiber.App {
 	app := fiber.New()
 
@@ -31,8 +40,10 @@ func setupApp(mockSvc *service.SubscriptionServiceMock) *fiber.App {
 	subscriptions := api.Group("/subscriptions", mockAuth())
 
 	subscriptions.Get("/", h.GetSubscriptions)
+	subscriptions.Get("/search", h.SearchSubscriptions)
 	subscriptions.Get("/:id", h.GetSubscription)
 	subscriptions.Post("/", h.CreateSubscription)
+	subscriptions.Put("/:id", h.UpdateSubscription)
 	subscriptions.Delete("/:id", h.DeleteSubscription)
 
 	return app
@@ -41,6 +52,8 @@ func setupApp(mockSvc *service.SubscriptionServiceMock) *fiber.App {
 func TestGetSubscriptions(t *testing.T) {
 	tests := []struct {
 		name       string
+		url        string
+		filter     service.SubscriptionFilter
 		mockReturn []service.SubscriptionResponse
 		mockErr    error
 		status     int
@@ -52,6 +65,15 @@ func TestGetSubscriptions(t *testing.T) {
 			},
 			status: fiber.StatusOK,
 		},
+		{
+			name:   "filter by tags",
+			url:    "/api/subscriptions?tags=work,shared",
+			filter: service.SubscriptionFilter{Tags: []string{"work", "shared"}},
+			mockReturn: []service.SubscriptionResponse{
+				{SubscriptionID: 1, Name: "Netflix", Tags: []string{"shared", "work"}},
+			},
+			status: fiber.StatusOK,
+		},
 		{
 			name:    "service error",
 			mockErr: errors.New("db error"),
@@ -63,12 +85,16 @@ func TestGetSubscriptions(t *testing.T) {
 		t.Run(tt.name, func(t *testing.T) {
 			svc := service.NewSubscriptionServiceMock()
 
-			svc.On("GetSubscriptions", 10).
+			svc.On("GetSubscriptions", 10, tt.filter).
 				Return(tt.mockReturn, tt.mockErr)
 
 			app := setupApp(svc)
 
-			req := httptest.NewRequest(http.MethodGet, "/api/subscriptions", nil)
+			url := tt.url
+			if url == "" {
+				url = "/api/subscriptions"
+			}
+			req := httptest.NewRequest(http.MethodGet, url, nil)
 			resp, _ := app.Test(req)
 
 			assert.Equal(t, tt.status, resp.StatusCode)
@@ -166,7 +192,7 @@ func TestCreateSubscription(t *testing.T) {
 			svc := service.NewSubscriptionServiceMock()
 
 			if tt.body == nil || string(tt.body) == string(jsonBody) {
-				svc.On("CreateSubscription", mock.Anything, 10).
+				svc.On("CreateSubscription", mock.Anything, signedInActor("subscout-test")).
 					Return(&service.SubscriptionResponse{SubscriptionID: 1}, tt.mockErr)
 			}
 
@@ -179,6 +205,7 @@ func TestCreateSubscription(t *testing.T) {
 
 			req := httptest.NewRequest(http.MethodPost, "/api/subscriptions", bytes.NewReader(reqBody))
 			req.Header.Set("Content-Type", "application/json")
+			req.Header.Set("User-Agent", "subscout-test")
 
 			resp, _ := app.Test(req)
 
@@ -188,6 +215,62 @@ func TestCreateSubscription(t *testing.T) {
 	}
 }
 
+func TestUpdateSubscription(t *testing.T) {
+	jsonBody, _ := json.Marshal(service.UpdateSubscriptionRequest{Name: "Netflix", Tags: []string{"work"}})
+
+	tests := []struct {
+		name    string
+		id      string
+		mockErr error
+		status  int
+	}{
+		{
+			name:   "success",
+			id:     "1",
+			status: fiber.StatusOK,
+		},
+		{
+			name:    "name taken",
+			id:      "1",
+			mockErr: repository.ErrDuplicateSubscription,
+			status:  fiber.StatusConflict,
+		},
+		{
+			name:    "not found",
+			id:      "1",
+			mockErr: service.ErrSubscriptionNotFound,
+			status:  fiber.StatusNotFound,
+		},
+		{
+			name:   "invalid id",
+			id:     "abc",
+			status: fiber.StatusBadRequest,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewSubscriptionServiceMock()
+
+			if tt.id == "1" {
+				svc.On("UpdateSubscription", 1,
+					service.UpdateSubscriptionRequest{Name: "Netflix", Tags: []string{"work"}},
+					signedInActor(""),
+				).Return(&service.SubscriptionResponse{SubscriptionID: 1}, tt.mockErr)
+			}
+
+			app := setupApp(svc)
+
+			req := httptest.NewRequest(http.MethodPut, "/api/subscriptions/"+tt.id, bytes.NewReader(jsonBody))
+			req.Header.Set("Content-Type", "application/json")
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			svc.AssertExpectations(t)
+		})
+	}
+}
+
 func TestDeleteSubscription(t *testing.T) {
 	tests := []struct {
 		name    string
@@ -218,7 +301,7 @@ func TestDeleteSubscription(t *testing.T) {
 			svc := service.NewSubscriptionServiceMock()
 
 			if tt.id == "1" {
-				svc.On("DeleteSubscription", 1, 10).
+				svc.On("DeleteSubscription", 1, signedInActor("")).
 					Return(tt.mockErr)
 			}
 
@@ -232,3 +315,51 @@ func TestDeleteSubscription(t *testing.T) {
 		})
 	}
 }
+
+func TestSearchSubscriptions(t *testing.T) {
+	tests := []struct {
+		name       string
+		query      string
+		mockReturn []service.SubscriptionSearchResponse
+		mockErr    error
+		status     int
+	}{
+		{
+			name:  "success",
+			query: "spot",
+			mockReturn: []service.SubscriptionSearchResponse{
+				{SubscriptionResponse: service.SubscriptionResponse{SubscriptionID: 1, Name: "Spotify"}},
+			},
+			status: fiber.StatusOK,
+		},
+		{
+			name:    "empty query",
+			query:   "",
+			mockErr: service.ErrEmptySearchQuery,
+			status:  fiber.StatusBadRequest,
+		},
+		{
+			name:    "service error",
+			query:   "spot",
+			mockErr: errors.New("db error"),
+			status:  fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			svc := service.NewSubscriptionServiceMock()
+
+			svc.On("SearchSubscriptions", tt.query, 10).
+				Return(tt.mockReturn, tt.mockErr)
+
+			app := setupApp(svc)
+
+			req := httptest.NewRequest(http.MethodGet, "/api/subscriptions/search?q="+tt.query, nil)
+			resp, _ := app.Test(req)
+
+			assert.Equal(t, tt.status, resp.StatusCode)
+			svc.AssertExpectations(t)
+		})
+	}
+}
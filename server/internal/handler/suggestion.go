@@ -0,0 +1,128 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type suggestionHandler struct {
+	suggestionService service.SuggestionService
+}
+
+func NewSuggestionHandler(suggestionService service.SuggestionService) suggestionHandler {
+	return suggestionHandler{suggestionService: suggestionService}
+}
+
+func RegisterSuggestionRoutes(app *fiber.App, suggestionService service.SuggestionService) {
+	h := NewSuggestionHandler(suggestionService)
+
+	api := app.Group("/api")
+	suggestions := api.Group("/suggestions", Protected())
+
+	suggestions.Get("/", h.GetSuggestions)
+	suggestions.Post("/:id/accept", h.AcceptSuggestion)
+	suggestions.Post("/:id/reject", h.RejectSuggestion)
+}
+
+// GET /suggestions?status=pending
+func (h suggestionHandler) GetSuggestions(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	suggestions, err := h.suggestionService.GetSuggestions(userID, c.Query("status", service.SuggestionStatusPending))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidStatus) {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(suggestions)
+}
+
+// POST /suggestions/:id/accept
+func (h suggestionHandler) AcceptSuggestion(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid suggestion id",
+		})
+	}
+
+	var req service.AcceptSuggestionRequest
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(&req); err != nil {
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": "invalid request body",
+			})
+		}
+	}
+
+	sub, err := h.suggestionService.AcceptSuggestion(id, actor, req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSuggestionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrSuggestionNotPending), errors.Is(err, service.ErrSuggestionMatched),
+			errors.Is(err, repository.ErrDuplicateSubscription):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrInvalidSubscription):
+			return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(sub)
+}
+
+// POST /suggestions/:id/reject
+func (h suggestionHandler) RejectSuggestion(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid suggestion id",
+		})
+	}
+
+	err = h.suggestionService.RejectSuggestion(id, userID)
+	if err != nil {
+		if errors.Is(err, service.ErrSuggestionNotFound) {
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(fiber.Map{"message": "suggestion rejected"})
+}
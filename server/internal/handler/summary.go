@@ -0,0 +1,50 @@
+package handler
+
+import (
+	"errors"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type summaryHandler struct {
+	summaryService service.SummaryService
+}
+
+func NewSummaryHandler(summaryService service.SummaryService) summaryHandler {
+	return summaryHandler{summaryService: summaryService}
+}
+
+func RegisterSummaryRoutes(app *fiber.App, summaryService service.SummaryService) {
+	h := NewSummaryHandler(summaryService)
+
+	api := app.Group("/api")
+	api.Get("/summary", Protected(), h.GetSummary)
+}
+
+// GET /summary?group_by=category|tag
+func (h summaryHandler) GetSummary(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	summary, err := h.summaryService.GetSummary(userID, c.Query("group_by"))
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidGroupBy):
+			return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, service.ErrUserNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(summary)
+}
@@ -0,0 +1,140 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type tagHandler struct {
+	tagService service.TagService
+}
+
+func NewTagHandler(tagService service.TagService) tagHandler {
+	return tagHandler{tagService: tagService}
+}
+
+func RegisterTagRoutes(app *fiber.App, tagService service.TagService) {
+	h := NewTagHandler(tagService)
+
+	api := app.Group("/api")
+	tags := api.Group("/tags", Protected())
+
+	tags.Get("/", h.GetTags)
+	tags.Post("/", h.CreateTag)
+	tags.Put("/:id", h.UpdateTag)
+	tags.Delete("/:id", h.DeleteTag)
+}
+
+// GET /tags
+func (h tagHandler) GetTags(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	tags, err := h.tagService.GetTags(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(tags)
+}
+
+// POST /tags
+func (h tagHandler) CreateTag(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.TagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	tag, err := h.tagService.CreateTag(req, userID)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(tag)
+}
+
+// PUT /tags/:id
+func (h tagHandler) UpdateTag(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid tag id",
+		})
+	}
+
+	var req service.TagRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	tag, err := h.tagService.UpdateTag(id, req, userID)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(tag)
+}
+
+// DELETE /tags/:id
+func (h tagHandler) DeleteTag(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid tag id",
+		})
+	}
+
+	err = h.tagService.DeleteTag(id, userID)
+	if err != nil {
+		return tagError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "tag deleted"})
+}
+
+func tagError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrTagNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, repository.ErrDuplicateTag):
+		return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidTag):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
@@ -0,0 +1,79 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type trashHandler struct {
+	trashService service.TrashService
+}
+
+func NewTrashHandler(trashService service.TrashService) trashHandler {
+	return trashHandler{trashService: trashService}
+}
+
+func RegisterTrashRoutes(app *fiber.App, trashService service.TrashService) {
+	h := NewTrashHandler(trashService)
+
+	api := app.Group("/api")
+	trash := api.Group("/trash", Protected())
+
+	trash.Get("/", h.GetTrash)
+	trash.Post("/:id/restore", h.RestoreSubscription)
+}
+
+// GET /trash
+func (h trashHandler) GetTrash(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	trash, err := h.trashService.GetTrash(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(trash)
+}
+
+// POST /trash/:id/restore
+func (h trashHandler) RestoreSubscription(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid subscription id",
+		})
+	}
+
+	sub, err := h.trashService.RestoreSubscription(id, actor)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrSubscriptionNotFound):
+			return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		case errors.Is(err, repository.ErrDuplicateSubscription):
+			return c.Status(fiber.StatusConflict).JSON(fiber.Map{
+				"error": err.Error(),
+			})
+		}
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(sub)
+}
@@ -0,0 +1,211 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type webhookHandler struct {
+	webhookService service.WebhookService
+}
+
+func NewWebhookHandler(webhookService service.WebhookService) webhookHandler {
+	return webhookHandler{webhookService: webhookService}
+}
+
+func RegisterWebhookRoutes(app *fiber.App, webhookService service.WebhookService) {
+	h := NewWebhookHandler(webhookService)
+
+	api := app.Group("/api")
+	webhooks := api.Group("/webhooks", Protected())
+
+	webhooks.Get("/", h.GetWebhooks)
+	webhooks.Post("/", h.CreateWebhook)
+	webhooks.Put("/:id", h.UpdateWebhook)
+	webhooks.Delete("/:id", h.DeleteWebhook)
+	webhooks.Post("/:id/ping", h.PingWebhook)
+	webhooks.Get("/:id/deliveries", h.GetDeliveries)
+	webhooks.Post("/:id/deliveries/:deliveryId/redeliver", h.Redeliver)
+}
+
+// GET /webhooks
+func (h webhookHandler) GetWebhooks(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	webhooks, err := h.webhookService.GetWebhooks(userID)
+	if err != nil {
+		return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+
+	return c.JSON(webhooks)
+}
+
+// POST /webhooks
+func (h webhookHandler) CreateWebhook(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	webhook, err := h.webhookService.CreateWebhook(req, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(webhook)
+}
+
+// PUT /webhooks/:id
+func (h webhookHandler) UpdateWebhook(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	var req service.WebhookRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	webhook, err := h.webhookService.UpdateWebhook(id, req, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(webhook)
+}
+
+// DELETE /webhooks/:id
+func (h webhookHandler) DeleteWebhook(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	if err := h.webhookService.DeleteWebhook(id, userID); err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "webhook deleted"})
+}
+
+// POST /webhooks/:id/ping
+func (h webhookHandler) PingWebhook(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	delivery, err := h.webhookService.PingWebhook(id, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+// GET /webhooks/:id/deliveries
+func (h webhookHandler) GetDeliveries(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	deliveries, err := h.webhookService.GetDeliveries(id, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.JSON(deliveries)
+}
+
+// POST /webhooks/:id/deliveries/:deliveryId/redeliver
+func (h webhookHandler) Redeliver(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid webhook id",
+		})
+	}
+
+	deliveryID, err := strconv.Atoi(c.Params("deliveryId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid delivery id",
+		})
+	}
+
+	delivery, err := h.webhookService.Redeliver(id, deliveryID, userID)
+	if err != nil {
+		return webhookError(c, err)
+	}
+
+	return c.Status(fiber.StatusAccepted).JSON(delivery)
+}
+
+func webhookError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrWebhookNotFound),
+		errors.Is(err, service.ErrWebhookDeliveryNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidWebhook):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
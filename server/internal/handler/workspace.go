@@ -0,0 +1,303 @@
+package handler
+
+import (
+	"errors"
+	"strconv"
+
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/gofiber/fiber/v2"
+)
+
+type workspaceHandler struct {
+	workspaceService service.WorkspaceService
+}
+
+func NewWorkspaceHandler(workspaceService service.WorkspaceService) workspaceHandler {
+	return workspaceHandler{workspaceService: workspaceService}
+}
+
+func RegisterWorkspaceRoutes(app *fiber.App, workspaceService service.WorkspaceService) {
+	h := NewWorkspaceHandler(workspaceService)
+
+	api := app.Group("/api")
+	workspaces := api.Group("/workspaces", Protected())
+
+	workspaces.Get("/", h.GetWorkspaces)
+	workspaces.Post("/", h.CreateWorkspace)
+	workspaces.Post("/invitations/accept", h.AcceptInvitation)
+	workspaces.Get("/:id", h.GetWorkspace)
+	workspaces.Put("/:id", h.RenameWorkspace)
+	workspaces.Delete("/:id", h.DeleteWorkspace)
+	workspaces.Get("/:id/members", h.GetMembers)
+	workspaces.Put("/:id/members/:userId", h.SetMemberRole)
+	workspaces.Delete("/:id/members/:userId", h.RemoveMember)
+	workspaces.Post("/:id/invitations", h.Invite)
+}
+
+// GET /workspaces
+func (h workspaceHandler) GetWorkspaces(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	workspaces, err := h.workspaceService.GetWorkspaces(userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(workspaces)
+}
+
+// GET /workspaces/:id
+func (h workspaceHandler) GetWorkspace(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	workspace, err := h.workspaceService.GetWorkspace(id, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(workspace)
+}
+
+// POST /workspaces
+func (h workspaceHandler) CreateWorkspace(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.WorkspaceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	workspace, err := h.workspaceService.CreateWorkspace(req, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(workspace)
+}
+
+// PUT /workspaces/:id
+func (h workspaceHandler) RenameWorkspace(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	var req service.WorkspaceRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	workspace, err := h.workspaceService.RenameWorkspace(id, req, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(workspace)
+}
+
+// DELETE /workspaces/:id
+func (h workspaceHandler) DeleteWorkspace(c *fiber.Ctx) error {
+	actor, err := getActor(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	if err := h.workspaceService.DeleteWorkspace(id, actor); err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "workspace deleted"})
+}
+
+// GET /workspaces/:id/members
+func (h workspaceHandler) GetMembers(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	members, err := h.workspaceService.GetMembers(id, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(members)
+}
+
+// PUT /workspaces/:id/members/:userId
+func (h workspaceHandler) SetMemberRole(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	memberID, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid user id",
+		})
+	}
+
+	var req service.WorkspaceRoleRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	members, err := h.workspaceService.SetMemberRole(id, memberID, req, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(members)
+}
+
+// DELETE /workspaces/:id/members/:userId
+func (h workspaceHandler) RemoveMember(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	memberID, err := strconv.Atoi(c.Params("userId"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid user id",
+		})
+	}
+
+	if err := h.workspaceService.RemoveMember(id, memberID, userID); err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(fiber.Map{"message": "member removed"})
+}
+
+// POST /workspaces/:id/invitations
+func (h workspaceHandler) Invite(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	id, err := strconv.Atoi(c.Params("id"))
+	if err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid workspace id",
+		})
+	}
+
+	var req service.InvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	invitation, err := h.workspaceService.Invite(id, req, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(invitation)
+}
+
+// POST /workspaces/invitations/accept
+func (h workspaceHandler) AcceptInvitation(c *fiber.Ctx) error {
+	userID, err := getUserID(c)
+	if err != nil {
+		return err
+	}
+
+	var req service.AcceptInvitationRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(fiber.StatusBadRequest).JSON(fiber.Map{
+			"error": "invalid request body",
+		})
+	}
+
+	workspace, err := h.workspaceService.AcceptInvitation(req.Token, userID)
+	if err != nil {
+		return workspaceError(c, err)
+	}
+
+	return c.JSON(workspace)
+}
+
+func workspaceError(c *fiber.Ctx, err error) error {
+	switch {
+	case errors.Is(err, service.ErrWorkspaceNotFound),
+		errors.Is(err, service.ErrWorkspaceMemberNotFound),
+		errors.Is(err, service.ErrInvitationNotFound):
+		return c.Status(fiber.StatusNotFound).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrWorkspaceForbidden):
+		return c.Status(fiber.StatusForbidden).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	case errors.Is(err, service.ErrInvalidWorkspace):
+		return c.Status(fiber.StatusUnprocessableEntity).JSON(fiber.Map{
+			"error": err.Error(),
+		})
+	}
+	return c.Status(fiber.StatusInternalServerError).JSON(fiber.Map{
+		"error": err.Error(),
+	})
+}
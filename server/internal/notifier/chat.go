@@ -0,0 +1,21 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+)
+
+// ChatWebhook posts to Slack or Discord compatible incoming webhooks; the
+// target is the webhook URL. Slack reads "text" and Discord "content", so
+// both are sent.
+type ChatWebhook struct {
+	Client *http.Client
+}
+
+func (n ChatWebhook) Send(ctx context.Context, target string, msg Message) error {
+	text := msg.Text()
+	return postJSON(ctx, n.Client, target, map[string]string{
+		"text":    text,
+		"content": text,
+	}, nil)
+}
@@ -0,0 +1,32 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const DefaultLINEURL = "https://api.line.me"
+
+// LINE pushes through the LINE Messaging API; the target is the user,
+// group or room id the bot talks to. BaseURL defaults to the public API
+// and can point at a local stand-in.
+type LINE struct {
+	BaseURL string
+	Token   string // channel access token
+	Client  *http.Client
+}
+
+func (n LINE) Send(ctx context.Context, target string, msg Message) error {
+	base := n.BaseURL
+	if base == "" {
+		base = DefaultLINEURL
+	}
+
+	return postJSON(ctx, n.Client, strings.TrimRight(base, "/")+"/v2/bot/message/push", map[string]any{
+		"to": target,
+		"messages": []map[string]string{
+			{"type": "text", "text": msg.Text()},
+		},
+	}, http.Header{"Authorization": {"Bearer " + n.Token}})
+}
@@ -0,0 +1,72 @@
+// Package notifier delivers notifications to the outside world: email,
+// chat webhooks and messaging bots.
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 10 * time.Second
+
+// Message is a notification rendered for a channel. HTML is an optional
+// alternative to Body for channels that can show it.
+type Message struct {
+	Title string
+	Body  string
+	HTML  string
+}
+
+// Text joins the title and body for channels without a separate subject.
+func (m Message) Text() string {
+	if m.Body == "" {
+		return m.Title
+	}
+	return m.Title + "\n\n" + m.Body
+}
+
+// Notifier sends a message to one recipient. What target means depends on
+// the channel: an email address, a webhook URL, a chat or user id.
+type Notifier interface {
+	Send(ctx context.Context, target string, msg Message) error
+}
+
+// postJSON sends body to url and treats any status outside 2xx as an
+// error, quoting the start of the response.
+func postJSON(ctx context.Context, client *http.Client, url string, body any, header http.Header) error {
+	raw, err := json.Marshal(body)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(raw))
+	if err != nil {
+		return err
+	}
+	for key, values := range header {
+		req.Header[key] = values
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	if client == nil {
+		client = &http.Client{Timeout: defaultTimeout}
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		snippet, _ := io.ReadAll(io.LimitReader(resp.Body, 200))
+		return fmt.Errorf("%s responded %s: %s", url, resp.Status, strings.TrimSpace(string(snippet)))
+	}
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+	return nil
+}
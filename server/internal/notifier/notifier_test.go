@@ -0,0 +1,88 @@
+package notifier_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/stretchr/testify/assert"
+)
+
+// standIn records the last request a notifier made.
+type standIn struct {
+	path   string
+	header http.Header
+	body   map[string]any
+	status int
+}
+
+func (s *standIn) serve() *httptest.Server {
+	return httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		s.path = r.URL.Path
+		s.header = r.Header
+		json.NewDecoder(r.Body).Decode(&s.body)
+		if s.status != 0 {
+			w.WriteHeader(s.status)
+			w.Write([]byte(`{"ok":false,"description":"chat not found"}`))
+		}
+	}))
+}
+
+var message = notifier.Message{Title: "Netflix renews tomorrow", Body: "419.00 THB"}
+
+func TestTelegram(t *testing.T) {
+	stand := &standIn{}
+	server := stand.serve()
+	defer server.Close()
+
+	n := notifier.Telegram{BaseURL: server.URL + "/", Token: "123:abc"}
+	err := n.Send(context.Background(), "42", message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/bot123:abc/sendMessage", stand.path)
+	assert.Equal(t, "42", stand.body["chat_id"])
+	assert.Equal(t, "Netflix renews tomorrow\n\n419.00 THB", stand.body["text"])
+}
+
+func TestLINE(t *testing.T) {
+	stand := &standIn{}
+	server := stand.serve()
+	defer server.Close()
+
+	n := notifier.LINE{BaseURL: server.URL, Token: "line-token"}
+	err := n.Send(context.Background(), "U123", message)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "/v2/bot/message/push", stand.path)
+	assert.Equal(t, "Bearer line-token", stand.header.Get("Authorization"))
+	assert.Equal(t, "U123", stand.body["to"])
+	assert.Len(t, stand.body["messages"], 1)
+}
+
+func TestChatWebhook(t *testing.T) {
+	t.Run("Slack And Discord Fields", func(t *testing.T) {
+		stand := &standIn{}
+		server := stand.serve()
+		defer server.Close()
+
+		err := notifier.ChatWebhook{}.Send(context.Background(), server.URL+"/hooks/T1", message)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "/hooks/T1", stand.path)
+		assert.Equal(t, stand.body["text"], stand.body["content"])
+	})
+
+	t.Run("Error Status", func(t *testing.T) {
+		stand := &standIn{status: http.StatusNotFound}
+		server := stand.serve()
+		defer server.Close()
+
+		err := notifier.ChatWebhook{}.Send(context.Background(), server.URL, message)
+
+		assert.ErrorContains(t, err, "404 Not Found")
+		assert.ErrorContains(t, err, "chat not found")
+	})
+}
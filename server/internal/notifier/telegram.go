@@ -0,0 +1,31 @@
+package notifier
+
+import (
+	"context"
+	"net/http"
+	"strings"
+)
+
+const DefaultTelegramURL = "https://api.telegram.org"
+
+// Telegram sends through a Telegram bot; the target is a chat id or an
+// @channel username the bot can post to. BaseURL defaults to the public
+// Bot API and can point at a local stand-in.
+type Telegram struct {
+	BaseURL string
+	Token   string
+	Client  *http.Client
+}
+
+func (n Telegram) Send(ctx context.Context, target string, msg Message) error {
+	base := n.BaseURL
+	if base == "" {
+		base = DefaultTelegramURL
+	}
+
+	return postJSON(ctx, n.Client, strings.TrimRight(base, "/")+"/bot"+n.Token+"/sendMessage", map[string]any{
+		"chat_id":                  target,
+		"text":                     msg.Text(),
+		"disable_web_page_preview": true,
+	}, nil)
+}
@@ -0,0 +1,192 @@
+package queue
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+// PostgresStore keeps jobs in the jobs table and schedules in
+// job_schedules. Workers claim jobs with FOR UPDATE SKIP LOCKED, so any
+// number of them can share the table.
+type PostgresStore struct {
+	db *sql.DB
+}
+
+func NewPostgresStore(db *sql.DB) *PostgresStore {
+	return &PostgresStore{db: db}
+}
+
+func (s *PostgresStore) Enqueue(kind string, payload []byte, key string, maxAttempts int, delay time.Duration) (bool, error) {
+	result, err := s.db.Exec(`
+		INSERT INTO jobs (kind, payload, unique_key, max_attempts, run_at)
+		VALUES ($1, $2, NULLIF($3, ''), $4, CURRENT_TIMESTAMP + make_interval(secs => $5))
+		ON CONFLICT (unique_key) WHERE status = 'pending' DO NOTHING
+	`, kind, string(payload), key, maxAttempts, delay.Seconds())
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rows > 0, nil
+}
+
+func (s *PostgresStore) Claim(lease time.Duration) (*Job, error) {
+	var job Job
+	var payload string
+	var lastError sql.NullString
+
+	err := s.db.QueryRow(`
+		UPDATE jobs j
+		SET attempts = j.attempts + 1,
+		    run_at = CURRENT_TIMESTAMP + make_interval(secs => $1),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE j.id = (
+			SELECT due.id
+			FROM jobs due
+			WHERE due.status = 'pending' AND due.run_at <= CURRENT_TIMESTAMP
+			ORDER BY due.run_at, due.id
+			LIMIT 1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING j.id, j.kind, j.payload, j.attempts, j.max_attempts, j.last_error
+	`, lease.Seconds()).Scan(&job.ID, &job.Kind, &payload, &job.Attempts, &job.MaxAttempts, &lastError)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	job.Payload = []byte(payload)
+	job.LastError = lastError.String
+	return &job, nil
+}
+
+func (s *PostgresStore) Complete(id int64) error {
+	_, err := s.db.Exec(`DELETE FROM jobs WHERE id = $1`, id)
+	return err
+}
+
+func (s *PostgresStore) Retry(id int64, lastError string, delay time.Duration) error {
+	_, err := s.db.Exec(`
+		UPDATE jobs
+		SET run_at = CURRENT_TIMESTAMP + make_interval(secs => $1), last_error = $2,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3
+	`, delay.Seconds(), lastError, id)
+	return err
+}
+
+func (s *PostgresStore) Bury(id int64, lastError string) error {
+	_, err := s.db.Exec(`
+		UPDATE jobs
+		SET status = 'dead', last_error = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, lastError, id)
+	return err
+}
+
+func (s *PostgresStore) SaveSchedules(schedules []Schedule) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	names := []string{}
+	for _, sc := range schedules {
+		spec, err := ParseSpec(sc.Spec)
+		if err != nil {
+			return err
+		}
+		now := time.Now()
+
+		_, err = tx.Exec(`
+			INSERT INTO job_schedules (name, spec, kind, payload, next_run_at)
+			VALUES ($1, $2, $3, $4, CURRENT_TIMESTAMP + make_interval(secs => $5))
+			ON CONFLICT (name) DO UPDATE
+			SET kind = EXCLUDED.kind, payload = EXCLUDED.payload, spec = EXCLUDED.spec,
+			    next_run_at = CASE
+			        WHEN job_schedules.spec = EXCLUDED.spec THEN job_schedules.next_run_at
+			        ELSE EXCLUDED.next_run_at
+			    END
+		`, sc.Name, sc.Spec, sc.Kind, string(sc.Payload), spec.Next(now).Sub(now).Seconds())
+		if err != nil {
+			return err
+		}
+		names = append(names, sc.Name)
+	}
+
+	_, err = tx.Exec(`DELETE FROM job_schedules WHERE NOT (name = ANY($1))`, pq.Array(names))
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (s *PostgresStore) FireSchedules(next func(spec string) (time.Duration, error)) error {
+	tx, err := s.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.Query(`
+		SELECT name, spec, kind, payload
+		FROM job_schedules
+		WHERE next_run_at <= CURRENT_TIMESTAMP
+		FOR UPDATE SKIP LOCKED
+	`)
+	if err != nil {
+		return err
+	}
+
+	var due []Schedule
+	for rows.Next() {
+		var sc Schedule
+		var payload string
+		if err := rows.Scan(&sc.Name, &sc.Spec, &sc.Kind, &payload); err != nil {
+			rows.Close()
+			return err
+		}
+		sc.Payload = []byte(payload)
+		due = append(due, sc)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return err
+	}
+
+	for _, sc := range due {
+		delay, err := next(sc.Spec)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			INSERT INTO jobs (kind, payload, unique_key)
+			VALUES ($1, $2, $3)
+			ON CONFLICT (unique_key) WHERE status = 'pending' DO NOTHING
+		`, sc.Kind, string(sc.Payload), "schedule:"+sc.Name)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Exec(`
+			UPDATE job_schedules
+			SET next_run_at = CURRENT_TIMESTAMP + make_interval(secs => $1), last_run_at = CURRENT_TIMESTAMP
+			WHERE name = $2
+		`, delay.Seconds(), sc.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,281 @@
+// Package queue runs background jobs stored in Postgres on a pool of
+// workers, which can live in any number of processes. Jobs are retried
+// with backoff and dead-lettered once they run out of attempts.
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultMaxAttempts = 5
+	// DefaultLease is how long a worker may take for one attempt before the
+	// job is handed to another worker.
+	DefaultLease = 5 * time.Minute
+	pollInterval = time.Second
+	retryBase    = 10 * time.Second
+	retryMax     = time.Hour
+)
+
+// Job is one piece of background work. Payload is the JSON the handler
+// for Kind decodes.
+type Job struct {
+	ID          int64
+	Kind        string
+	Payload     json.RawMessage
+	Attempts    int // including the one running
+	MaxAttempts int
+	LastError   string
+}
+
+// Handler runs a job. A returned error fails the attempt, and the job is
+// retried later unless it was the last attempt or the error is Permanent.
+type Handler func(ctx context.Context, job Job) error
+
+type permanentError struct {
+	err error
+}
+
+func (e permanentError) Error() string { return e.err.Error() }
+func (e permanentError) Unwrap() error { return e.err }
+
+// Permanent marks err as one that retrying will not fix, so the job is
+// dead-lettered straight away.
+func Permanent(err error) error {
+	return permanentError{err: err}
+}
+
+// Store keeps the jobs and schedules. Delays count from the store's clock.
+type Store interface {
+	// Enqueue adds a job due after delay. A non-empty key is unique among
+	// the jobs that are not dead yet; a duplicate is dropped and reported
+	// as false.
+	Enqueue(kind string, payload []byte, key string, maxAttempts int, delay time.Duration) (bool, error)
+	// Claim picks the job that is due the longest, counts the attempt and
+	// hides the job for lease, or returns nil when none is due.
+	Claim(lease time.Duration) (*Job, error)
+	// Complete removes a job that succeeded.
+	Complete(id int64) error
+	// Retry makes a failed job due again after delay.
+	Retry(id int64, lastError string, delay time.Duration) error
+	// Bury dead-letters a job; it stays in the store for inspection.
+	Bury(id int64, lastError string) error
+
+	// SaveSchedules stores the recurring jobs and removes the ones no
+	// longer scheduled. A schedule keeps its next run unless its spec
+	// changed.
+	SaveSchedules(schedules []Schedule) error
+	// FireSchedules enqueues a job for every schedule that is due and
+	// moves it to its next run, which next gives as a delay. A schedule
+	// fires in one process only.
+	FireSchedules(next func(spec string) (time.Duration, error)) error
+}
+
+// Schedule enqueues a job of Kind whenever Spec comes round. The job is
+// keyed by Name, so a run is skipped while the previous one is still
+// running or waiting for a retry.
+type Schedule struct {
+	Name    string
+	Spec    string
+	Kind    string
+	Payload []byte
+}
+
+// Queue enqueues jobs and runs them with the handlers registered for their
+// kinds.
+type Queue struct {
+	store     Store
+	lease     time.Duration
+	mu        sync.RWMutex
+	handlers  map[string]Handler
+	schedules []Schedule
+}
+
+func New(store Store) *Queue {
+	return &Queue{store: store, lease: DefaultLease, handlers: map[string]Handler{}}
+}
+
+// Handle registers the handler for jobs of kind.
+func (q *Queue) Handle(kind string, h Handler) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.handlers[kind] = h
+}
+
+// HandleFunc registers fn for jobs of kind, decoding their payload into T.
+// A payload that does not decode is dead-lettered.
+func HandleFunc[T any](q *Queue, kind string, fn func(ctx context.Context, payload T) error) {
+	q.Handle(kind, func(ctx context.Context, job Job) error {
+		var payload T
+		if len(job.Payload) > 0 {
+			if err := json.Unmarshal(job.Payload, &payload); err != nil {
+				return Permanent(fmt.Errorf("decoding %s payload: %w", kind, err))
+			}
+		}
+		return fn(ctx, payload)
+	})
+}
+
+// EnqueueOptions tune one job. The zero value runs the job as soon as a
+// worker is free, with DefaultMaxAttempts and no key.
+type EnqueueOptions struct {
+	Delay       time.Duration
+	MaxAttempts int
+	Key         string
+}
+
+// Enqueue adds a job of kind with payload encoded as JSON. It reports false
+// when a job with the same key is already waiting.
+func (q *Queue) Enqueue(kind string, payload any, opts EnqueueOptions) (bool, error) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return false, err
+	}
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = DefaultMaxAttempts
+	}
+
+	return q.store.Enqueue(kind, data, opts.Key, opts.MaxAttempts, opts.Delay)
+}
+
+// Schedule runs a job of kind with payload on spec, see ParseSpec. Schedules
+// are stored when Run starts.
+func (q *Queue) Schedule(name string, spec string, kind string, payload any) error {
+	if _, err := ParseSpec(spec); err != nil {
+		return err
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	q.schedules = append(q.schedules, Schedule{Name: name, Spec: spec, Kind: kind, Payload: data})
+	return nil
+}
+
+// Run stores the schedules, then works off jobs with the given number of
+// workers and fires the schedules until ctx is done. It returns once the
+// running jobs have finished.
+func (q *Queue) Run(ctx context.Context, workers int) error {
+	q.mu.RLock()
+	schedules := q.schedules
+	q.mu.RUnlock()
+	if err := q.store.SaveSchedules(schedules); err != nil {
+		return err
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		q.poll(ctx, q.fireSchedules)
+	}()
+
+	for range max(workers, 1) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			q.poll(ctx, func() bool { return q.work(ctx) })
+		}()
+	}
+
+	wg.Wait()
+	return nil
+}
+
+// poll calls fn until ctx is done, pausing whenever it had nothing to do.
+func (q *Queue) poll(ctx context.Context, fn func() bool) {
+	for ctx.Err() == nil {
+		if fn() {
+			continue
+		}
+		select {
+		case <-ctx.Done():
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+func (q *Queue) fireSchedules() bool {
+	err := q.store.FireSchedules(func(spec string) (time.Duration, error) {
+		s, err := ParseSpec(spec)
+		if err != nil {
+			return 0, err
+		}
+		now := time.Now()
+		return s.Next(now).Sub(now), nil
+	})
+	if err != nil {
+		log.Printf("firing job schedules failed: %v", err)
+	}
+	return false
+}
+
+// work runs one due job and reports whether there was one.
+func (q *Queue) work(ctx context.Context) bool {
+	job, err := q.store.Claim(q.lease)
+	if err != nil {
+		log.Printf("claiming a job failed: %v", err)
+		return false
+	}
+	if job == nil {
+		return false
+	}
+
+	err = q.run(ctx, *job)
+	switch {
+	case err == nil:
+		err = q.store.Complete(job.ID)
+	case errors.As(err, new(permanentError)) || job.Attempts >= job.MaxAttempts:
+		log.Printf("job %d (%s) failed for good: %v", job.ID, job.Kind, err)
+		err = q.store.Bury(job.ID, err.Error())
+	default:
+		log.Printf("job %d (%s) failed, retrying: %v", job.ID, job.Kind, err)
+		err = q.store.Retry(job.ID, err.Error(), backoff(job.Attempts))
+	}
+	if err != nil {
+		log.Printf("recording job %d failed: %v", job.ID, err)
+	}
+
+	return true
+}
+
+// run calls the job's handler with a deadline of the lease, turning a
+// panic into a failed attempt. Stopping the queue lets the handler finish.
+func (q *Queue) run(ctx context.Context, job Job) (err error) {
+	q.mu.RLock()
+	h, ok := q.handlers[job.Kind]
+	q.mu.RUnlock()
+	if !ok {
+		// another process may know the kind, e.g. during a deploy
+		return fmt.Errorf("no handler for job kind %q", job.Kind)
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("panic: %v", r)
+		}
+	}()
+
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), q.lease)
+	defer cancel()
+	return h(ctx, job)
+}
+
+// backoff is the wait after the attempts-th failure: retryBase, doubling
+// with every further failure up to retryMax.
+func backoff(attempts int) time.Duration {
+	wait := retryBase
+	for i := 1; i < attempts && wait < retryMax; i++ {
+		wait *= 2
+	}
+	return min(wait, retryMax)
+}
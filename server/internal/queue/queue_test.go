@@ -0,0 +1,232 @@
+package queue_test
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/queue"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseSpec(t *testing.T) {
+	at := func(s string) time.Time {
+		tm, err := time.Parse("2006-01-02 15:04", s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return tm
+	}
+
+	t.Run("Next Runs", func(t *testing.T) {
+		for _, c := range []struct {
+			spec, from, next string
+		}{
+			{"@every 15m", "2026-03-02 10:07", "2026-03-02 10:22"},
+			{"*/15 * * * *", "2026-03-02 10:07", "2026-03-02 10:15"},
+			{"0 8 * * *", "2026-03-02 08:00", "2026-03-03 08:00"},
+			{"30 8-18/2 * * 1-5", "2026-03-06 18:30", "2026-03-09 08:30"}, // Friday evening to Monday
+			{"0 0 1 * *", "2026-12-15 00:00", "2027-01-01 00:00"},
+			{"0 0 29 2 *", "2026-03-01 00:00", "2028-02-29 00:00"},
+			{"0 9 1 * 0", "2026-03-02 10:00", "2026-03-08 09:00"}, // the 1st or a Sunday
+		} {
+			spec, err := queue.ParseSpec(c.spec)
+			assert.NoError(t, err, c.spec)
+			assert.Equal(t, at(c.next), spec.Next(at(c.from)), c.spec)
+		}
+	})
+
+	t.Run("Invalid Specs", func(t *testing.T) {
+		for _, spec := range []string{"", "@every", "@every -1m", "* * * *", "60 * * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+			_, err := queue.ParseSpec(spec)
+			assert.Error(t, err, spec)
+		}
+	})
+}
+
+// memoryStore keeps jobs in memory and makes them due right away.
+type memoryStore struct {
+	mu     sync.Mutex
+	nextID int64
+	jobs   map[int64]*queue.Job
+	done   []int64
+	dead   map[int64]string
+	delays []time.Duration
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{jobs: map[int64]*queue.Job{}, dead: map[int64]string{}}
+}
+
+func (s *memoryStore) Enqueue(kind string, payload []byte, key string, maxAttempts int, delay time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.nextID++
+	s.jobs[s.nextID] = &queue.Job{ID: s.nextID, Kind: kind, Payload: payload, MaxAttempts: maxAttempts}
+	return true, nil
+}
+
+func (s *memoryStore) Claim(lease time.Duration) (*queue.Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for id := int64(1); id <= s.nextID; id++ {
+		if job, ok := s.jobs[id]; ok {
+			delete(s.jobs, id)
+			job.Attempts++
+			claimed := *job
+			return &claimed, nil
+		}
+	}
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(id int64) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.done = append(s.done, id)
+	return nil
+}
+
+func (s *memoryStore) Retry(id int64, lastError string, delay time.Duration) error {
+	return errors.New("use retryStore")
+}
+
+func (s *memoryStore) Bury(id int64, lastError string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.dead[id] = lastError
+	return nil
+}
+
+func (s *memoryStore) SaveSchedules(schedules []queue.Schedule) error { return nil }
+
+func (s *memoryStore) FireSchedules(next func(spec string) (time.Duration, error)) error { return nil }
+
+// retryStore puts failed jobs straight back.
+type retryStore struct {
+	*memoryStore
+	attempts map[int64]int
+}
+
+func (s retryStore) Claim(lease time.Duration) (*queue.Job, error) {
+	job, err := s.memoryStore.Claim(lease)
+	if job != nil {
+		s.mu.Lock()
+		s.attempts[job.ID] = job.Attempts
+		s.mu.Unlock()
+	}
+	return job, err
+}
+
+func (s retryStore) Retry(id int64, lastError string, delay time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.delays = append(s.delays, delay)
+	s.jobs[id] = &queue.Job{ID: id, Kind: "flaky", Attempts: s.attempts[id], MaxAttempts: 3, LastError: lastError}
+	return nil
+}
+
+// runUntil runs q until cond holds or a second has passed.
+func runUntil(t *testing.T, q *queue.Queue, cond func() bool) {
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	go func() {
+		for ctx.Err() == nil && !cond() {
+			time.Sleep(5 * time.Millisecond)
+		}
+		cancel()
+	}()
+	assert.NoError(t, q.Run(ctx, 2))
+	assert.True(t, cond())
+}
+
+func TestQueue(t *testing.T) {
+	type reminder struct {
+		UserID int `json:"user_id"`
+	}
+
+	t.Run("Typed Handler Gets Payload", func(t *testing.T) {
+		store := newMemoryStore()
+		q := queue.New(store)
+		var got []int
+		var mu sync.Mutex
+		queue.HandleFunc(q, "remind", func(ctx context.Context, r reminder) error {
+			mu.Lock()
+			defer mu.Unlock()
+			got = append(got, r.UserID)
+			return nil
+		})
+
+		_, err := q.Enqueue("remind", reminder{UserID: 7}, queue.EnqueueOptions{})
+		assert.NoError(t, err)
+
+		runUntil(t, q, func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			return len(store.done) == 1
+		})
+		assert.Equal(t, []int{7}, got)
+	})
+
+	t.Run("Failures Are Retried Then Dead-Lettered", func(t *testing.T) {
+		store := retryStore{memoryStore: newMemoryStore(), attempts: map[int64]int{}}
+		q := queue.New(store)
+		q.Handle("flaky", func(ctx context.Context, job queue.Job) error {
+			return errors.New("upstream is down")
+		})
+
+		_, err := q.Enqueue("flaky", nil, queue.EnqueueOptions{MaxAttempts: 3})
+		assert.NoError(t, err)
+
+		runUntil(t, q, func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			return len(store.dead) == 1
+		})
+		assert.Equal(t, "upstream is down", store.dead[1])
+		assert.Equal(t, []time.Duration{10 * time.Second, 20 * time.Second}, store.delays)
+	})
+
+	t.Run("Permanent Errors And Bad Payloads Are Dead-Lettered", func(t *testing.T) {
+		store := newMemoryStore()
+		q := queue.New(store)
+		q.Handle("broken", func(ctx context.Context, job queue.Job) error {
+			return queue.Permanent(errors.New("gone"))
+		})
+		queue.HandleFunc(q, "remind", func(ctx context.Context, r reminder) error {
+			return nil
+		})
+
+		_, err := q.Enqueue("broken", nil, queue.EnqueueOptions{})
+		assert.NoError(t, err)
+		_, err = q.Enqueue("remind", "not an object", queue.EnqueueOptions{})
+		assert.NoError(t, err)
+
+		runUntil(t, q, func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			return len(store.dead) == 2
+		})
+		assert.Equal(t, "gone", store.dead[1])
+		assert.Contains(t, store.dead[2], "decoding remind payload")
+	})
+
+	t.Run("Panics Fail The Attempt", func(t *testing.T) {
+		store := newMemoryStore()
+		q := queue.New(store)
+		q.Handle("panicky", func(ctx context.Context, job queue.Job) error {
+			panic("boom")
+		})
+
+		_, err := q.Enqueue("panicky", nil, queue.EnqueueOptions{MaxAttempts: 1})
+		assert.NoError(t, err)
+
+		runUntil(t, q, func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			return len(store.dead) == 1
+		})
+		assert.Equal(t, "panic: boom", store.dead[1])
+	})
+}
@@ -0,0 +1,129 @@
+package realtime
+
+import (
+	"database/sql"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+const (
+	notifyChannel = "subscout_events"
+	// NOTIFY payloads are limited to 8000 bytes; larger data is replaced
+	// by a marker telling clients to reload.
+	maxNotifyData = 7000
+)
+
+// wireEvent is an event as sent through NOTIFY.
+type wireEvent struct {
+	ID     int64           `json:"id"`
+	UserID int             `json:"user_id"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// PostgresBroker shares events between replicas with LISTEN/NOTIFY. Every
+// replica's hub receives every event, its own included, so any replica can
+// resume any client. IDs come from the realtime_event_id sequence.
+type PostgresBroker struct {
+	*Hub
+	db       *sql.DB
+	listener *pq.Listener
+}
+
+// NewPostgresBroker listens on its own connection opened from dsn and
+// publishes through db.
+func NewPostgresBroker(db *sql.DB, dsn string, size int) (*PostgresBroker, error) {
+	// keep IDs on the clock like the in-process hub, so Last-Event-IDs
+	// stay comparable across restarts and a switch of broker
+	var start int64
+	err := db.QueryRow(`
+		SELECT setval('realtime_event_id', GREATEST(
+			(SELECT last_value FROM realtime_event_id),
+			(extract(epoch FROM clock_timestamp()) * 1000000)::bigint
+		))
+	`).Scan(&start)
+	if err != nil {
+		return nil, err
+	}
+
+	listener := pq.NewListener(dsn, time.Second, time.Minute, func(ev pq.ListenerEventType, err error) {
+		if err != nil {
+			log.Printf("realtime listener: %v", err)
+		}
+	})
+	if err := listener.Listen(notifyChannel); err != nil {
+		listener.Close()
+		return nil, err
+	}
+
+	b := &PostgresBroker{Hub: newHub(size, start), db: db, listener: listener}
+	go b.listen()
+	return b, nil
+}
+
+func (b *PostgresBroker) Publish(userID int, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	if len(raw) > maxNotifyData {
+		raw = json.RawMessage(`{"truncated":true}`)
+	}
+
+	payload, err := json.Marshal(wireEvent{UserID: userID, Type: eventType, Data: raw})
+	if err != nil {
+		return err
+	}
+
+	_, err = b.db.Exec(`
+		SELECT pg_notify($1, jsonb_set($2::jsonb, '{id}', to_jsonb(nextval('realtime_event_id')))::text)
+	`, notifyChannel, string(payload))
+	return err
+}
+
+func (b *PostgresBroker) listen() {
+	for n := range b.listener.Notify {
+		if n == nil {
+			b.reconnected()
+			continue
+		}
+
+		var e wireEvent
+		if err := json.Unmarshal([]byte(n.Extra), &e); err != nil {
+			log.Printf("realtime listener: bad event: %v", err)
+			continue
+		}
+
+		b.mu.Lock()
+		b.deliver(Event{ID: e.ID, UserID: e.UserID, Type: e.Type, Data: e.Data})
+		b.mu.Unlock()
+	}
+}
+
+// reconnected runs after the listener lost its connection. Events sent in
+// the meantime were never received, so clients resuming from before now
+// are told they missed some.
+func (b *PostgresBroker) reconnected() {
+	var last int64
+	if err := b.db.QueryRow(`SELECT last_value FROM realtime_event_id`).Scan(&last); err != nil {
+		log.Printf("realtime listener: %v", err)
+		return
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if last > b.horizon {
+		b.horizon = last
+	}
+	if last > b.lastID {
+		b.lastID = last
+	}
+}
+
+// Close stops listening.
+func (b *PostgresBroker) Close() error {
+	return b.listener.Close()
+}
@@ -0,0 +1,164 @@
+// Package realtime pushes events to the clients a user has connected, e.g.
+// over Server-Sent Events or a WebSocket.
+package realtime
+
+import (
+	"encoding/json"
+	"sync"
+	"time"
+)
+
+const (
+	DefaultBufferSize = 1000
+	subscriberBuffer  = 64
+)
+
+// Event is a message for all of one user's connected clients. IDs only
+// grow, so a client that reconnects can say which event it saw last.
+type Event struct {
+	ID     int64           `json:"id"`
+	UserID int             `json:"-"`
+	Type   string          `json:"type"`
+	Data   json.RawMessage `json:"data"`
+}
+
+// Broker fans events out to the subscriptions of the user they belong to.
+type Broker interface {
+	Publish(userID int, eventType string, data any) error
+	// Subscribe streams the user's events. Buffered events newer than
+	// lastEventID are replayed first; zero replays nothing.
+	Subscribe(userID int, lastEventID int64) *Subscription
+}
+
+// Subscription is one connected client.
+type Subscription struct {
+	// Replay holds the buffered events the client missed.
+	Replay []Event
+	// Missed is set when events after lastEventID may have been dropped
+	// from the buffer already, so the client should reload instead.
+	Missed bool
+	// Events is closed when the client falls too far behind; it should
+	// reconnect and resume from the last event it received.
+	Events <-chan Event
+
+	hub    *Hub
+	userID int
+	ch     chan Event
+}
+
+// Close stops the subscription.
+func (s *Subscription) Close() {
+	s.hub.mu.Lock()
+	defer s.hub.mu.Unlock()
+	s.hub.remove(s)
+}
+
+// Hub is an in-process broker. It keeps the latest events of all users in
+// one ring buffer for resuming.
+type Hub struct {
+	mu     sync.Mutex
+	lastID int64
+	// horizon is the newest ID that is no longer buffered; every event
+	// after it can still be replayed.
+	horizon int64
+	buffer  []Event
+	next    int // ring position of the oldest event once the buffer is full
+	subs    map[int]map[*Subscription]bool
+}
+
+// NewHub buffers the last size events. IDs start from the clock so they
+// keep growing across restarts and stale Last-Event-IDs are detected.
+func NewHub(size int) *Hub {
+	return newHub(size, time.Now().UnixMicro())
+}
+
+func newHub(size int, start int64) *Hub {
+	if size <= 0 {
+		size = DefaultBufferSize
+	}
+	return &Hub{
+		lastID:  start,
+		horizon: start,
+		buffer:  make([]Event, 0, size),
+		subs:    map[int]map[*Subscription]bool{},
+	}
+}
+
+func (h *Hub) Publish(userID int, eventType string, data any) error {
+	raw, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.deliver(Event{ID: h.lastID + 1, UserID: userID, Type: eventType, Data: raw})
+	return nil
+}
+
+// deliver buffers e and hands it to the user's subscriptions. A
+// subscription whose channel is full is dropped rather than blocking
+// everyone else. The caller holds mu.
+func (h *Hub) deliver(e Event) {
+	if e.ID > h.lastID {
+		h.lastID = e.ID
+	}
+
+	if len(h.buffer) < cap(h.buffer) {
+		h.buffer = append(h.buffer, e)
+	} else {
+		h.horizon = h.buffer[h.next].ID
+		h.buffer[h.next] = e
+		h.next = (h.next + 1) % len(h.buffer)
+	}
+
+	for sub := range h.subs[e.UserID] {
+		select {
+		case sub.ch <- e:
+		default:
+			h.remove(sub)
+		}
+	}
+}
+
+func (h *Hub) Subscribe(userID int, lastEventID int64) *Subscription {
+	ch := make(chan Event, subscriberBuffer)
+	sub := &Subscription{Events: ch, hub: h, userID: userID, ch: ch}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if lastEventID > 0 {
+		// an ID from the future was issued before a restart or by another
+		// broker, so nothing can be said about what came after it
+		sub.Missed = lastEventID < h.horizon || lastEventID > h.lastID
+		for i := range h.buffer {
+			e := h.buffer[(h.next+i)%len(h.buffer)]
+			if e.UserID == userID && e.ID > lastEventID {
+				sub.Replay = append(sub.Replay, e)
+			}
+		}
+	}
+
+	if h.subs[userID] == nil {
+		h.subs[userID] = map[*Subscription]bool{}
+	}
+	h.subs[userID][sub] = true
+
+	return sub
+}
+
+// remove closes sub unless it is already gone. The caller holds mu.
+func (h *Hub) remove(sub *Subscription) {
+	subs := h.subs[sub.userID]
+	if !subs[sub] {
+		return
+	}
+
+	delete(subs, sub)
+	close(sub.ch)
+	if len(subs) == 0 {
+		delete(h.subs, sub.userID)
+	}
+}
@@ -0,0 +1,86 @@
+package realtime_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/realtime"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHub(t *testing.T) {
+	t.Run("Events Reach Every Device Of The User", func(t *testing.T) {
+		hub := realtime.NewHub(10)
+		phone := hub.Subscribe(1, 0)
+		tablet := hub.Subscribe(1, 0)
+		other := hub.Subscribe(2, 0)
+		defer phone.Close()
+		defer tablet.Close()
+		defer other.Close()
+
+		err := hub.Publish(1, "subscription.created", map[string]string{"name": "Netflix"})
+
+		assert.NoError(t, err)
+		e := <-phone.Events
+		assert.Equal(t, "subscription.created", e.Type)
+		assert.JSONEq(t, `{"name":"Netflix"}`, string(e.Data))
+		assert.Equal(t, e, <-tablet.Events)
+		assert.Empty(t, other.Events)
+	})
+
+	t.Run("Resume Replays Newer Events", func(t *testing.T) {
+		hub := realtime.NewHub(10)
+		first := hub.Subscribe(1, 0)
+		hub.Publish(1, "a", nil)
+		hub.Publish(2, "b", nil)
+		hub.Publish(1, "c", nil)
+		seen := <-first.Events
+		first.Close()
+
+		resumed := hub.Subscribe(1, seen.ID)
+		defer resumed.Close()
+
+		assert.False(t, resumed.Missed)
+		assert.Len(t, resumed.Replay, 1)
+		assert.Equal(t, "c", resumed.Replay[0].Type)
+	})
+
+	t.Run("Resume Past The Buffer Is Missed", func(t *testing.T) {
+		hub := realtime.NewHub(2)
+		first := hub.Subscribe(1, 0)
+		hub.Publish(1, "a", nil)
+		seen := <-first.Events
+		first.Close()
+		hub.Publish(1, "b", nil)
+		hub.Publish(1, "c", nil)
+
+		resumed := hub.Subscribe(1, seen.ID)
+		stale := hub.Subscribe(1, seen.ID+1000)
+		defer resumed.Close()
+		defer stale.Close()
+
+		assert.False(t, resumed.Missed)
+		assert.Len(t, resumed.Replay, 2)
+
+		hub.Publish(1, "d", nil)
+		again := hub.Subscribe(1, seen.ID)
+		defer again.Close()
+		assert.True(t, again.Missed)
+		assert.True(t, stale.Missed)
+	})
+
+	t.Run("Slow Subscriber Is Dropped", func(t *testing.T) {
+		hub := realtime.NewHub(10)
+		slow := hub.Subscribe(1, 0)
+
+		for i := 0; i < 100; i++ {
+			hub.Publish(1, "tick", i)
+		}
+
+		count := 0
+		for range slow.Events {
+			count++
+		}
+		assert.Less(t, count, 100)
+		slow.Close() // closing again is harmless
+	})
+}
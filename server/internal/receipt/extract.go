@@ -0,0 +1,272 @@
+package receipt
+
+import (
+	"errors"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrNoReceipt = errors.New("no receipt data found in message")
+
+// Receipt is what an extractor found in a message. Zero values mean the
+// field could not be determined.
+type Receipt struct {
+	Extractor       string
+	Merchant        string
+	Amount          float64
+	Currency        string
+	BillingCycle    string
+	Date            time.Time // when the receipt was issued
+	NextBillingDate time.Time
+	// Confidence is between 0 and 1; vendor specific extractors know
+	// their senders and score higher than the generic heuristics.
+	Confidence float64
+}
+
+// Extractor pulls receipt data out of messages it recognises.
+type Extractor interface {
+	Name() string
+	Match(msg *Message) bool
+	Extract(msg *Message) (*Receipt, error)
+}
+
+// Parser runs the first matching extractor on a message.
+type Parser struct {
+	extractors []Extractor
+}
+
+// NewParser builds a parser trying extractors in order. The generic
+// extractor is always appended as the last resort.
+func NewParser(extractors ...Extractor) *Parser {
+	return &Parser{extractors: append(extractors, GenericExtractor{})}
+}
+
+// NewDefaultParser knows the vendors in DefaultVendors.
+func NewDefaultParser() *Parser {
+	var extractors []Extractor
+	for _, v := range DefaultVendors {
+		extractors = append(extractors, v)
+	}
+	return NewParser(extractors...)
+}
+
+func (p *Parser) Parse(raw []byte) (*Message, *Receipt, error) {
+	msg, err := ParseMessage(raw)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, e := range p.extractors {
+		if !e.Match(msg) {
+			continue
+		}
+		r, err := e.Extract(msg)
+		if err != nil {
+			return msg, nil, err
+		}
+		r.Extractor = e.Name()
+		if r.Date.IsZero() {
+			r.Date = msg.Date
+		}
+		return msg, r, nil
+	}
+
+	return msg, nil, ErrNoReceipt
+}
+
+// GenericExtractor works on any message using text heuristics: the sender
+// name as merchant, the amount on a "total" line and a date next to words
+// like "next billing date" or "renews on".
+type GenericExtractor struct{}
+
+func (GenericExtractor) Name() string {
+	return "generic"
+}
+
+func (GenericExtractor) Match(*Message) bool {
+	return true
+}
+
+func (GenericExtractor) Extract(msg *Message) (*Receipt, error) {
+	r := extractCommon(msg)
+	if r.Amount == 0 {
+		return nil, ErrNoReceipt
+	}
+
+	r.Merchant = senderName(msg)
+	if r.Merchant == "" {
+		return nil, ErrNoReceipt
+	}
+
+	r.Confidence = 0.4
+	if r.BillingCycle != "" {
+		r.Confidence += 0.1
+	}
+	if !r.NextBillingDate.IsZero() {
+		r.Confidence += 0.1
+	}
+	return r, nil
+}
+
+// extractCommon fills amount, currency, billing cycle and next billing
+// date from the subject and body.
+func extractCommon(msg *Message) *Receipt {
+	r := &Receipt{Date: msg.Date}
+	text := msg.Subject + "\n" + msg.Text
+	lines := strings.Split(text, "\n")
+
+	r.Amount, r.Currency = findTotal(lines)
+	r.BillingCycle = findCycle(text)
+	r.NextBillingDate = findNextBillingDate(lines)
+
+	return r
+}
+
+var (
+	amountPattern = regexp.MustCompile(
+		`(?i)(US\$|S\$|\$|€|£|¥|฿|THB|USD|EUR|GBP|JPY|SGD)\s?(\d{1,3}(?:,\d{3})*(?:\.\d{1,2})?|\d+(?:\.\d{1,2})?)` +
+			`|(\d{1,3}(?:,\d{3})*(?:\.\d{1,2})?|\d+(?:\.\d{1,2})?)\s?(THB|USD|EUR|GBP|JPY|SGD|บาท|฿|€)`)
+	totalWords = []string{"total", "amount", "charged", "you paid", "price", "ยอดรวม", "รวม"}
+)
+
+var currencySymbols = map[string]string{
+	"$": "USD", "US$": "USD", "S$": "SGD", "€": "EUR", "£": "GBP",
+	"¥": "JPY", "฿": "THB", "บาท": "THB",
+}
+
+// findTotal prefers an amount on a line mentioning a total, falling back
+// to the first amount in the message.
+func findTotal(lines []string) (float64, string) {
+	var firstAmount float64
+	var firstCurrency string
+
+	for _, line := range lines {
+		m := amountPattern.FindStringSubmatch(line)
+		if m == nil {
+			continue
+		}
+
+		symbol, number := m[1], m[2]
+		if number == "" {
+			number, symbol = m[3], m[4]
+		}
+		amount, err := strconv.ParseFloat(strings.ReplaceAll(number, ",", ""), 64)
+		if err != nil || amount == 0 {
+			continue
+		}
+		currency := strings.ToUpper(symbol)
+		if c, ok := currencySymbols[symbol]; ok {
+			currency = c
+		}
+
+		lower := strings.ToLower(line)
+		for _, w := range totalWords {
+			if strings.Contains(lower, w) {
+				return amount, currency
+			}
+		}
+		if firstAmount == 0 {
+			firstAmount, firstCurrency = amount, currency
+		}
+	}
+
+	return firstAmount, firstCurrency
+}
+
+var cyclePatterns = []struct {
+	cycle   string
+	pattern *regexp.Regexp
+}{
+	{"yearly", regexp.MustCompile(`(?i)\b(yearly|annual|annually|per year|a year|/\s?yr|/\s?year|12 months)\b`)},
+	{"monthly", regexp.MustCompile(`(?i)\b(monthly|per month|a month|/\s?mo|/\s?month|every month)\b`)},
+	{"weekly", regexp.MustCompile(`(?i)\b(weekly|per week|every week)\b`)},
+}
+
+func findCycle(text string) string {
+	for _, c := range cyclePatterns {
+		if c.pattern.MatchString(text) {
+			return c.cycle
+		}
+	}
+	return ""
+}
+
+var (
+	nextBillingWords = regexp.MustCompile(`(?i)(next (billing|payment|charge|renewal)|renews? on|renewal date|will be (charged|billed|renewed) on|billing date)`)
+	datePatterns     = []struct {
+		pattern *regexp.Regexp
+		layouts []string
+	}{
+		{regexp.MustCompile(`\d{4}-\d{2}-\d{2}`), []string{"2006-01-02"}},
+		{regexp.MustCompile(`(?i)(January|February|March|April|May|June|July|August|September|October|November|December|Jan|Feb|Mar|Apr|Jun|Jul|Aug|Sep|Sept|Oct|Nov|Dec)\.? \d{1,2},? \d{4}`),
+			[]string{"January 2, 2006", "January 2 2006", "Jan 2, 2006", "Jan 2 2006", "Jan. 2, 2006"}},
+		{regexp.MustCompile(`(?i)\d{1,2} (January|February|March|April|May|June|July|August|September|October|November|December|Jan|Feb|Mar|Apr|Jun|Jul|Aug|Sep|Oct|Nov|Dec) \d{4}`),
+			[]string{"2 January 2006", "2 Jan 2006"}},
+		{regexp.MustCompile(`\d{1,2}/\d{1,2}/\d{4}`), []string{"2/1/2006"}},
+	}
+)
+
+// findNextBillingDate looks for a date on the line that announces the
+// next payment, or on the line right after it.
+func findNextBillingDate(lines []string) time.Time {
+	for i, line := range lines {
+		if !nextBillingWords.MatchString(line) {
+			continue
+		}
+		candidates := []string{line[nextBillingWords.FindStringIndex(line)[0]:]}
+		if i+1 < len(lines) {
+			candidates = append(candidates, lines[i+1])
+		}
+		for _, c := range candidates {
+			if d, ok := findDate(c); ok {
+				return d
+			}
+		}
+	}
+	return time.Time{}
+}
+
+func findDate(text string) (time.Time, bool) {
+	for _, p := range datePatterns {
+		match := p.pattern.FindString(text)
+		if match == "" {
+			continue
+		}
+		match = strings.Join(strings.Fields(match), " ")
+		for _, layout := range p.layouts {
+			if d, err := time.Parse(layout, match); err == nil {
+				return d, true
+			}
+		}
+	}
+	return time.Time{}, false
+}
+
+// senderName uses the display name of the sender, or the second level
+// domain of its address, as merchant name.
+func senderName(msg *Message) string {
+	if msg.From == nil {
+		return ""
+	}
+
+	name := strings.TrimSpace(msg.From.Name)
+	for _, suffix := range []string{" Billing", " Receipts", " Support", " Team", " No-Reply"} {
+		name = strings.TrimSuffix(name, suffix)
+	}
+	if name != "" {
+		return name
+	}
+
+	parts := strings.Split(msg.SenderDomain(), ".")
+	if len(parts) < 2 {
+		return ""
+	}
+	domain := parts[len(parts)-2]
+	if len(parts) > 2 && len(domain) <= 3 {
+		// example.co.th
+		domain = parts[len(parts)-3]
+	}
+	return strings.ToUpper(domain[:1]) + domain[1:]
+}
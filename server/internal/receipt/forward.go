@@ -0,0 +1,93 @@
+package receipt
+
+import (
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// forwardMarker starts the quoted original of a forwarded message, as
+// written by Gmail, Apple Mail and Outlook.
+var forwardMarker = regexp.MustCompile(
+	`(?im)^[ \t>]*(-+ ?Forwarded message ?-+|Begin forwarded message:|-+ ?Original Message ?-+)[ \t]*$`)
+
+// forwardDateLayouts are the ways mail clients print the original date.
+var forwardDateLayouts = []string{
+	"Mon, Jan 2, 2006 at 3:04 PM",        // Gmail
+	"January 2, 2006 at 3:04:05 PM MST",  // Apple Mail
+	"Monday, January 2, 2006 3:04 PM",    // Outlook
+	"Monday, January 2, 2006 at 3:04 PM", // Outlook on the web
+}
+
+// unwrapForward replaces the sender, subject, date and text of a forwarded
+// message with those of the original, so extractors see the vendor
+// rather than the person who forwarded it. When the original sender
+// cannot be read From is left empty; the outer sender is never used as the
+// vendor.
+func unwrapForward(m *Message) {
+	text := strings.ReplaceAll(m.Text, "\r\n", "\n")
+	loc := forwardMarker.FindStringIndex(text)
+	if loc == nil {
+		return
+	}
+
+	lines := strings.Split(text[loc[1]:], "\n")
+	headers := map[string]string{}
+	i := 0
+	for ; i < len(lines); i++ {
+		line := unquote(lines[i])
+		if line == "" {
+			if len(headers) > 0 {
+				break
+			}
+			continue
+		}
+		key, value, ok := strings.Cut(line, ":")
+		if !ok || strings.ContainsAny(key, " \t") {
+			break
+		}
+		headers[strings.ToLower(key)] = strings.TrimSpace(value)
+	}
+
+	m.ForwardedBy, m.From = m.From, nil
+	if from, err := mail.ParseAddress(headers["from"]); err == nil {
+		m.From = from
+	}
+	if subject, ok := headers["subject"]; ok {
+		m.Subject = subject
+	}
+	date := headers["date"]
+	if date == "" {
+		date = headers["sent"]
+	}
+	if d, ok := parseForwardDate(date); ok {
+		m.Date = d
+	}
+
+	body := make([]string, 0, len(lines)-i)
+	for _, line := range lines[i:] {
+		body = append(body, unquote(line))
+	}
+	m.Text = strings.TrimSpace(strings.Join(body, "\n"))
+}
+
+// unquote strips the "> " quoting some clients add to forwarded lines.
+func unquote(line string) string {
+	return strings.TrimSpace(strings.TrimLeft(line, "> \t"))
+}
+
+func parseForwardDate(value string) (time.Time, bool) {
+	if value == "" {
+		return time.Time{}, false
+	}
+	if d, err := mail.ParseDate(value); err == nil {
+		return d, true
+	}
+	for _, layout := range forwardDateLayouts {
+		if d, err := time.Parse(layout, value); err == nil {
+			return d, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -0,0 +1,74 @@
+package receipt
+
+import (
+	"context"
+	"log"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// MaildirPoller hands every new message of a Maildir to a handler and
+// then files it under cur/, marked seen, or flagged when the handler
+// failed so it is not retried forever.
+type MaildirPoller struct {
+	dir      string
+	interval time.Duration
+	handle   func(raw []byte) error
+}
+
+func NewMaildirPoller(dir string, interval time.Duration, handle func(raw []byte) error) *MaildirPoller {
+	return &MaildirPoller{dir: dir, interval: interval, handle: handle}
+}
+
+// Run polls until ctx is cancelled.
+func (p *MaildirPoller) Run(ctx context.Context) {
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+
+	for {
+		if err := p.Poll(); err != nil {
+			log.Println("Error while polling maildir: ", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll processes the messages currently in new/.
+func (p *MaildirPoller) Poll() error {
+	entries, err := os.ReadDir(filepath.Join(p.dir, "new"))
+	if err != nil {
+		return err
+	}
+
+	for _, entry := range entries {
+		if entry.IsDir() || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		path := filepath.Join(p.dir, "new", entry.Name())
+		raw, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+
+		flags := "S"
+		if err := p.handle(raw); err != nil {
+			log.Printf("Error while processing receipt %s: %v", entry.Name(), err)
+			flags = "FS"
+		}
+
+		base := strings.SplitN(entry.Name(), ":", 2)[0]
+		if err := os.Rename(path, filepath.Join(p.dir, "cur", base+":2,"+flags)); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,183 @@
+// Package receipt reads subscription receipts sent by email and extracts
+// what was paid, to whom and when the next payment is due.
+package receipt
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"html"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var ErrInvalidMessage = errors.New("invalid email message")
+
+// Message is the part of an RFC 822 message extractors work with.
+type Message struct {
+	From *mail.Address
+	To   []*mail.Address
+	// Recipients are the lower-cased addresses from To, Cc and the
+	// Delivered-To and X-Original-To headers the receiving server adds.
+	Recipients []string
+	// ForwardedBy is the outer sender of a forwarded message. From,
+	// Subject, Date and Text then describe the forwarded original.
+	ForwardedBy *mail.Address
+	Subject     string
+	Date        time.Time
+	Text        string // plain text body, or the HTML body reduced to text
+	FromHTML    bool
+}
+
+// SenderDomain returns the lower-cased domain of the From address.
+func (m *Message) SenderDomain() string {
+	if m.From == nil {
+		return ""
+	}
+	at := strings.LastIndex(m.From.Address, "@")
+	if at < 0 {
+		return ""
+	}
+	return strings.ToLower(m.From.Address[at+1:])
+}
+
+var wordDecoder = &mime.WordDecoder{
+	// only UTF-8 and US-ASCII are decoded, other charsets are kept as is
+	CharsetReader: func(charset string, input io.Reader) (io.Reader, error) {
+		return input, nil
+	},
+}
+
+// ParseMessage reads a raw .eml message.
+func ParseMessage(raw []byte) (*Message, error) {
+	msg, err := mail.ReadMessage(bytes.NewReader(raw))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	m := &Message{}
+	if from, err := msg.Header.AddressList("From"); err == nil && len(from) > 0 {
+		m.From = from[0]
+	}
+	if to, err := msg.Header.AddressList("To"); err == nil {
+		m.To = to
+	}
+	for _, key := range []string{"To", "Cc", "Delivered-To", "X-Original-To"} {
+		for _, value := range msg.Header[key] {
+			addresses, err := mail.ParseAddressList(value)
+			if err != nil {
+				continue
+			}
+			for _, addr := range addresses {
+				m.Recipients = append(m.Recipients, strings.ToLower(addr.Address))
+			}
+		}
+	}
+	if subject, err := wordDecoder.DecodeHeader(msg.Header.Get("Subject")); err == nil {
+		m.Subject = subject
+	} else {
+		m.Subject = msg.Header.Get("Subject")
+	}
+	if date, err := msg.Header.Date(); err == nil {
+		m.Date = date
+	}
+
+	plain, htmlBody, err := readBody(msg.Header.Get("Content-Type"), msg.Header.Get("Content-Transfer-Encoding"), msg.Body)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidMessage, err)
+	}
+
+	m.Text = plain
+	if strings.TrimSpace(m.Text) == "" && htmlBody != "" {
+		m.Text = htmlToText(htmlBody)
+		m.FromHTML = true
+	}
+	unwrapForward(m)
+
+	return m, nil
+}
+
+// readBody walks (possibly nested) multipart bodies and returns the first
+// text/plain and text/html parts it finds.
+func readBody(contentType, encoding string, body io.Reader) (string, string, error) {
+	mediaType, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		mediaType = "text/plain"
+	}
+
+	if strings.HasPrefix(mediaType, "multipart/") {
+		var plain, htmlBody string
+		reader := multipart.NewReader(body, params["boundary"])
+		for {
+			part, err := reader.NextPart()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return "", "", err
+			}
+
+			p, h, err := readBody(part.Header.Get("Content-Type"), part.Header.Get("Content-Transfer-Encoding"), part)
+			if err != nil {
+				return "", "", err
+			}
+			if plain == "" {
+				plain = p
+			}
+			if htmlBody == "" {
+				htmlBody = h
+			}
+		}
+		return plain, htmlBody, nil
+	}
+
+	if !strings.HasPrefix(mediaType, "text/") {
+		return "", "", nil
+	}
+
+	decoded, err := io.ReadAll(decodeTransfer(encoding, body))
+	if err != nil {
+		return "", "", err
+	}
+
+	if mediaType == "text/html" {
+		return "", string(decoded), nil
+	}
+	return string(decoded), "", nil
+}
+
+func decodeTransfer(encoding string, body io.Reader) io.Reader {
+	switch strings.ToLower(strings.TrimSpace(encoding)) {
+	case "quoted-printable":
+		return quotedprintable.NewReader(body)
+	case "base64":
+		// the decoder skips the line breaks of wrapped base64 itself
+		return base64.NewDecoder(base64.StdEncoding, body)
+	}
+	return body
+}
+
+var (
+	htmlDropPattern  = regexp.MustCompile(`(?is)<(script|style|head)[^>]*>.*?</(script|style|head)>`)
+	htmlBreakPattern = regexp.MustCompile(`(?i)<(br|/p|/div|/tr|/li|/h[1-6]|/table)[^>]*>`)
+	htmlTagPattern   = regexp.MustCompile(`<[^>]+>`)
+	blankPattern     = regexp.MustCompile(`[ \t\x{00a0}]+`)
+	blankLines       = regexp.MustCompile(`\n\s*\n+`)
+)
+
+func htmlToText(body string) string {
+	text := htmlDropPattern.ReplaceAllString(body, "")
+	text = htmlBreakPattern.ReplaceAllString(text, "\n")
+	text = htmlTagPattern.ReplaceAllString(text, " ")
+	text = html.UnescapeString(text)
+	text = blankPattern.ReplaceAllString(text, " ")
+	text = blankLines.ReplaceAllString(text, "\n")
+	return strings.TrimSpace(text)
+}
@@ -0,0 +1,134 @@
+package receipt_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/receipt"
+	"github.com/stretchr/testify/assert"
+)
+
+const netflixReceipt = "From: Netflix <info@account.netflix.com>\r\n" +
+	"To: john@test.com\r\n" +
+	"Subject: Your Netflix receipt\r\n" +
+	"Date: Mon, 03 Feb 2025 08:00:00 +0700\r\n" +
+	"MIME-Version: 1.0\r\n" +
+	"Content-Type: multipart/alternative; boundary=\"b1\"\r\n" +
+	"\r\n" +
+	"--b1\r\n" +
+	"Content-Type: text/html; charset=utf-8\r\n" +
+	"Content-Transfer-Encoding: quoted-printable\r\n" +
+	"\r\n" +
+	"<html><body><p>Premium plan</p><table><tr><td>Total</td><td>419.00 THB</td></tr></table>=\r\n" +
+	"<p>Next billing date: March 3, 2025</p></body></html>\r\n" +
+	"--b1--\r\n"
+
+const genericReceipt = "From: Acme Cloud Billing <billing@acme-cloud.io>\r\n" +
+	"To: john@test.com\r\n" +
+	"Subject: =?UTF-8?Q?Payment_received?=\r\n" +
+	"Date: Tue, 04 Feb 2025 10:00:00 +0000\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"Thanks for your payment.\r\n" +
+	"Plan: Pro (billed annually)\r\n" +
+	"Amount charged: $120.00\r\n" +
+	"Your plan renews on 2026-02-04.\r\n"
+
+const forwardedReceipt = "From: Mallory <info@netflix.com>\r\n" +
+	"To: receipts+abc123@subscout.test\r\n" +
+	"Subject: Fwd: Your receipt\r\n" +
+	"Date: Wed, 05 Feb 2025 09:00:00 +0000\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"fyi\r\n" +
+	"\r\n" +
+	"---------- Forwarded message ---------\r\n" +
+	"From: Acme Cloud Billing <billing@acme-cloud.io>\r\n" +
+	"Date: Tue, Feb 4, 2025 at 10:00 AM\r\n" +
+	"Subject: Payment received\r\n" +
+	"To: <john@test.com>\r\n" +
+	"\r\n" +
+	"> Amount charged: $120.00\r\n" +
+	"> Your plan renews on 2026-02-04.\r\n"
+
+func TestParse(t *testing.T) {
+	parser := receipt.NewDefaultParser()
+
+	t.Run("Vendor Receipt", func(t *testing.T) {
+		msg, r, err := parser.Parse([]byte(netflixReceipt))
+
+		assert.NoError(t, err)
+		assert.True(t, msg.FromHTML)
+		assert.Equal(t, "netflix", r.Extractor)
+		assert.Equal(t, "Netflix", r.Merchant)
+		assert.Equal(t, 419.0, r.Amount)
+		assert.Equal(t, "THB", r.Currency)
+		assert.Equal(t, "monthly", r.BillingCycle)
+		assert.Equal(t, "2025-03-03", r.NextBillingDate.Format("2006-01-02"))
+		assert.Equal(t, 0.9, r.Confidence)
+	})
+
+	t.Run("Generic Receipt", func(t *testing.T) {
+		msg, r, err := parser.Parse([]byte(genericReceipt))
+
+		assert.NoError(t, err)
+		assert.Equal(t, "Payment received", msg.Subject)
+		assert.Equal(t, "generic", r.Extractor)
+		assert.Equal(t, "Acme Cloud", r.Merchant)
+		assert.Equal(t, 120.0, r.Amount)
+		assert.Equal(t, "USD", r.Currency)
+		assert.Equal(t, "yearly", r.BillingCycle)
+		assert.Equal(t, "2026-02-04", r.NextBillingDate.Format("2006-01-02"))
+	})
+
+	t.Run("Forwarded Receipt", func(t *testing.T) {
+		msg, r, err := parser.Parse([]byte(forwardedReceipt))
+
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"receipts+abc123@subscout.test"}, msg.Recipients)
+		assert.Equal(t, "info@netflix.com", msg.ForwardedBy.Address)
+		assert.Equal(t, "billing@acme-cloud.io", msg.From.Address)
+		assert.Equal(t, "Payment received", msg.Subject)
+		assert.Equal(t, "2025-02-04", msg.Date.Format("2006-01-02"))
+		assert.Equal(t, "generic", r.Extractor)
+		assert.Equal(t, "Acme Cloud", r.Merchant)
+		assert.Equal(t, 120.0, r.Amount)
+		assert.Equal(t, "2026-02-04", r.NextBillingDate.Format("2006-01-02"))
+	})
+
+	t.Run("Forward Without Original Sender", func(t *testing.T) {
+		raw := "From: Netflix <info@netflix.com>\r\nSubject: Fwd: receipt\r\n\r\n" +
+			"Begin forwarded message:\r\n\r\nTotal: 419.00 THB\r\n"
+
+		_, r, err := parser.Parse([]byte(raw))
+
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, receipt.ErrNoReceipt)
+	})
+
+	t.Run("No Amount", func(t *testing.T) {
+		_, r, err := parser.Parse([]byte("From: a@b.com\r\nSubject: hi\r\n\r\nhello\r\n"))
+
+		assert.Nil(t, r)
+		assert.ErrorIs(t, err, receipt.ErrNoReceipt)
+	})
+}
+
+func TestMaildirPoller(t *testing.T) {
+	dir := t.TempDir()
+	for _, sub := range []string{"new", "cur", "tmp"} {
+		assert.NoError(t, os.Mkdir(filepath.Join(dir, sub), 0o755))
+	}
+	assert.NoError(t, os.WriteFile(filepath.Join(dir, "new", "1.eml"), []byte(genericReceipt), 0o644))
+
+	var handled int
+	poller := receipt.NewMaildirPoller(dir, 0, func(raw []byte) error {
+		handled++
+		return nil
+	})
+
+	assert.NoError(t, poller.Poll())
+	assert.Equal(t, 1, handled)
+	assert.FileExists(t, filepath.Join(dir, "cur", "1.eml:2,S"))
+}
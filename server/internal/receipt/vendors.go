@@ -0,0 +1,54 @@
+package receipt
+
+import "strings"
+
+// Vendor is an extractor for a known sender. It reuses the generic text
+// heuristics but trusts the merchant name and the usual billing cycle.
+type Vendor struct {
+	Merchant string
+	Domains  []string
+	// Cycle is used when the receipt does not state one.
+	Cycle string
+}
+
+// DefaultVendors are the senders recognised out of the box.
+var DefaultVendors = []Vendor{
+	{Merchant: "Netflix", Domains: []string{"netflix.com"}, Cycle: "monthly"},
+	{Merchant: "Spotify", Domains: []string{"spotify.com"}, Cycle: "monthly"},
+	{Merchant: "YouTube Premium", Domains: []string{"youtube.com"}, Cycle: "monthly"},
+	{Merchant: "Disney+", Domains: []string{"disneyplus.com"}, Cycle: "monthly"},
+	{Merchant: "Apple", Domains: []string{"apple.com", "email.apple.com"}},
+	{Merchant: "GitHub", Domains: []string{"github.com"}},
+}
+
+func (v Vendor) Name() string {
+	return strings.ToLower(v.Merchant)
+}
+
+func (v Vendor) Match(msg *Message) bool {
+	domain := msg.SenderDomain()
+	for _, d := range v.Domains {
+		if domain == d || strings.HasSuffix(domain, "."+d) {
+			return true
+		}
+	}
+	return false
+}
+
+func (v Vendor) Extract(msg *Message) (*Receipt, error) {
+	r := extractCommon(msg)
+	if r.Amount == 0 {
+		return nil, ErrNoReceipt
+	}
+
+	r.Merchant = v.Merchant
+	if r.BillingCycle == "" {
+		r.BillingCycle = v.Cycle
+	}
+
+	r.Confidence = 0.8
+	if !r.NextBillingDate.IsZero() {
+		r.Confidence = 0.9
+	}
+	return r, nil
+}
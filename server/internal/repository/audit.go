@@ -0,0 +1,78 @@
+package repository
+
+// Actor is who makes a change and where the request came from, as the
+// audit log records it. UserID is zero for a visitor who is not signed in
+// yet, e.g. while registering, and for the system.
+type Actor struct {
+	UserID    int
+	IP        string
+	UserAgent string
+	// System marks changes that background jobs make on their own.
+	System bool
+}
+
+// SystemActor makes the changes no user asked for, such as purging the
+// trash.
+var SystemActor = Actor{System: true}
+
+// Kinds of actor in the audit log.
+const (
+	AuditActorUser   = "user"
+	AuditActorSystem = "system"
+)
+
+// Entities and actions recorded in the audit log. Subscription actions are
+// the subscription.* events.
+const (
+	AuditEntitySubscription = "subscription"
+	AuditEntityUser         = "user"
+	// shares are recorded under the id of their subscription, with the
+	// share.* events as actions
+	AuditEntityShare = "share"
+
+	AuditUserRegistered      = "user.registered"
+	AuditUserSettingsUpdated = "user.settings_updated"
+
+	AuditCalendarTokenRotated = "user.calendar_token_rotated"
+	AuditReceiptTokenRotated  = "user.receipt_token_rotated"
+
+	// AuditSubscriptionPurged is recorded, by the SystemActor, when a
+	// subscription is removed from the trash for good.
+	AuditSubscriptionPurged = "subscription.purged"
+)
+
+// AuditEntry is one change in the append-only audit log. Changes maps each
+// field that changed to its before and after value as JSON, e.g.
+// {"amount":{"before":99,"after":129}}; secrets are redacted.
+type AuditEntry struct {
+	EntryID     int    `db:"id"`
+	ActorID     *int   `db:"actor_id"`   // nil when no one was signed in
+	ActorType   string `db:"actor_type"` // user or system
+	IP          string `db:"ip"`
+	UserAgent   string `db:"user_agent"`
+	Action      string `db:"action"`
+	EntityType  string `db:"entity_type"`
+	EntityID    int    `db:"entity_id"`
+	WorkspaceID *int   `db:"workspace_id"` // the workspace of a subscription
+	Changes     string `db:"changes"`
+	CreatedAt   string `db:"created_at"`
+}
+
+// AuditFilter narrows List; zero values do not filter. From and To are
+// YYYY-MM-DD dates, both included. BeforeID pages back from an entry.
+type AuditFilter struct {
+	EntityType string
+	EntityID   *int
+	Action     string
+	ActorID    *int
+	From       string
+	To         string
+	BeforeID   int
+	Limit      int
+}
+
+type AuditRepository interface {
+	// List returns the newest entries the user may see: changes they made,
+	// and changes to workspaces they own or administer.
+	List(userID int, filter AuditFilter) ([]AuditEntry, error)
+}
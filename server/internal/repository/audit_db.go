@@ -0,0 +1,219 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+type auditRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewAuditRepositoryDB(db *sql.DB) AuditRepository {
+	return auditRepositoryDB{db: db}
+}
+
+// auditSecrets are fields whose values never reach the audit log; only the
+// fact that they changed does.
+var auditSecrets = map[string]bool{
+	"password":            true,
+	"calendar_token_hash": true,
+	"receipt_token_hash":  true,
+}
+
+const redacted = "[redacted]"
+
+type auditChange struct {
+	Before any `json:"before"`
+	After  any `json:"after"`
+}
+
+// auditFields flattens v, a struct or a pointer to one, into its fields
+// keyed by their db tag, or json tag for structs without one. A map of
+// field names is taken as is. Empty slices count as nil so that they
+// compare equal.
+func auditFields(v any) map[string]any {
+	if m, ok := v.(map[string]any); ok {
+		return m
+	}
+
+	fields := map[string]any{}
+
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Pointer {
+		if rv.IsNil() {
+			return fields
+		}
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return fields
+	}
+
+	rt := rv.Type()
+	for i := range rt.NumField() {
+		f := rt.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+
+		name, _, _ := strings.Cut(f.Tag.Get("db"), ",")
+		if name == "" {
+			name, _, _ = strings.Cut(f.Tag.Get("json"), ",")
+		}
+		if name == "" || name == "-" {
+			name = f.Name
+		}
+
+		value := rv.Field(i)
+		if value.Kind() == reflect.Slice && value.Len() == 0 {
+			fields[name] = nil
+			continue
+		}
+		fields[name] = value.Interface()
+	}
+
+	return fields
+}
+
+// auditChanges is the JSON diff between before and after; nil stands for
+// an entity that did not exist yet or no longer exists.
+func auditChanges(before, after any) (string, error) {
+	b, a := auditFields(before), auditFields(after)
+
+	changes := map[string]auditChange{}
+	for name, bv := range b {
+		av, ok := a[name]
+		if ok && reflect.DeepEqual(bv, av) {
+			continue
+		}
+		changes[name] = auditChange{Before: bv, After: av}
+	}
+	for name, av := range a {
+		if _, ok := b[name]; !ok {
+			changes[name] = auditChange{After: av}
+		}
+	}
+
+	for name, c := range changes {
+		if !auditSecrets[name] {
+			continue
+		}
+		if c.Before != nil {
+			c.Before = redacted
+		}
+		if c.After != nil {
+			c.After = redacted
+		}
+		changes[name] = c
+	}
+
+	data, err := json.Marshal(changes)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// recordAudit appends an entry to the audit log. q must be the transaction
+// that makes the change, so the entry exists exactly when the change does.
+func recordAudit(q queryer, actor Actor, action string, entityType string, entityID int, workspaceID *int, before, after any) error {
+	changes, err := auditChanges(before, after)
+	if err != nil {
+		return err
+	}
+
+	actorType := AuditActorUser
+	if actor.System {
+		actorType = AuditActorSystem
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO audit_log (actor_id, actor_type, ip, user_agent, action, entity_type, entity_id, workspace_id, changes)
+		VALUES (NULLIF($1::int, 0), $2, NULLIF($3, ''), NULLIF($4, ''), $5, $6, $7, $8, $9)
+	`, actor.UserID, actorType, actor.IP, actor.UserAgent, action, entityType, entityID, workspaceID, changes)
+	return err
+}
+
+func (r auditRepositoryDB) List(userID int, filter AuditFilter) ([]AuditEntry, error) {
+	query := `
+		SELECT a.id, a.actor_id, a.actor_type, COALESCE(a.ip, ''), COALESCE(a.user_agent, ''), a.action,
+		       a.entity_type, a.entity_id, a.workspace_id, a.changes, a.created_at
+		FROM audit_log a
+		WHERE (
+			a.actor_id = $1
+			OR (a.entity_type = 'user' AND a.entity_id = $1)
+			OR a.workspace_id IN (
+				SELECT wm.workspace_id FROM workspace_members wm
+				WHERE wm.user_id = $1 AND wm.role IN ('owner', 'admin')
+			)
+		)
+	`
+	args := []any{userID}
+
+	where := func(cond string, arg any) {
+		args = append(args, arg)
+		query += `
+		  AND ` + strings.ReplaceAll(cond, "?", "$"+strconv.Itoa(len(args))) + `
+		`
+	}
+	if filter.EntityType != "" {
+		where("a.entity_type = ?", filter.EntityType)
+	}
+	if filter.EntityID != nil {
+		where("a.entity_id = ?", *filter.EntityID)
+	}
+	if filter.Action != "" {
+		where("a.action = ?", filter.Action)
+	}
+	if filter.ActorID != nil {
+		where("a.actor_id = ?", *filter.ActorID)
+	}
+	if filter.From != "" {
+		where("a.created_at >= ?::date", filter.From)
+	}
+	if filter.To != "" {
+		where("a.created_at < ?::date + 1", filter.To)
+	}
+	if filter.BeforeID > 0 {
+		where("a.id < ?", filter.BeforeID)
+	}
+
+	args = append(args, filter.Limit)
+	query += `
+		ORDER BY a.id DESC
+		LIMIT $` + strconv.Itoa(len(args))
+
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	entries := []AuditEntry{}
+	for rows.Next() {
+		var e AuditEntry
+		err := rows.Scan(
+			&e.EntryID,
+			&e.ActorID,
+			&e.ActorType,
+			&e.IP,
+			&e.UserAgent,
+			&e.Action,
+			&e.EntityType,
+			&e.EntityID,
+			&e.WorkspaceID,
+			&e.Changes,
+			&e.CreatedAt,
+		)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, e)
+	}
+
+	return entries, rows.Err()
+}
@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type auditRepositoryMock struct {
+	mock.Mock
+}
+
+func NewAuditRepositoryMock() *auditRepositoryMock {
+	return &auditRepositoryMock{}
+}
+
+func (m *auditRepositoryMock) List(userID int, filter AuditFilter) ([]AuditEntry, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]AuditEntry), args.Error(1)
+}
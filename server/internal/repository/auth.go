@@ -1,13 +1,32 @@
 package repository
 
 type User struct {
-	ID       int    `json:"id"`
-	Name     string `json:"name"`
-	Email    string `json:"email"`
-	Password string `json:"password"`
+	ID              int    `json:"id"`
+	Name            string `json:"name"`
+	Email           string `json:"email"`
+	Password        string `json:"password"`
+	BaseCurrency    string `json:"base_currency"`
+	ReminderDays    int    `json:"reminder_days"`
+	Timezone        string `json:"timezone"`         // IANA name, e.g. Asia/Bangkok
+	DigestFrequency string `json:"digest_frequency"` // off, weekly, monthly
 }
 
+type UserSettings struct {
+	BaseCurrency    string `db:"base_currency"`
+	ReminderDays    int    `db:"reminder_days"`
+	Timezone        string `db:"timezone"`
+	DigestFrequency string `db:"digest_frequency"`
+}
+
+// UserRepository records an entry in the audit log within the same
+// transaction as a registration, a settings change or a token rotation.
 type UserRepository interface {
-	Create(name, email, password string) (*User, error)
+	Create(name, email, password string, actor Actor) (*User, error)
 	GetByEmail(email string) (*User, error)
+	GetByID(id int) (*User, error)
+	UpdateSettings(id int, settings UserSettings, actor Actor) (*User, error)
+	GetByCalendarTokenHash(hash string) (*User, error)
+	SetCalendarTokenHash(id int, hash string, actor Actor) error
+	GetByReceiptTokenHash(hash string) (*User, error)
+	SetReceiptTokenHash(id int, hash string, actor Actor) error
 }
@@ -10,20 +10,36 @@ func NewUserRepositoryDB(db *sql.DB) UserRepository {
 	return userRepositoryDB{db: db}
 }
 
-func (r userRepositoryDB) Create(name, email, password string) (*User, error) {
+func (r userRepositoryDB) Create(name, email, password string, actor Actor) (*User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
 	var user User
 
-	err := r.db.QueryRow(`
+	err = tx.QueryRow(`
 		INSERT INTO users (name, email, password)
 		VALUES ($1, $2, $3)
-		RETURNING id, name, email
+		RETURNING id, name, email, base_currency, reminder_days, timezone, digest_frequency
 	`, name, email, password).
-		Scan(&user.ID, &user.Name, &user.Email)
+		Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency)
 
 	if err != nil {
 		return nil, err
 	}
 
+	// whoever registers becomes the new user
+	actor.UserID = user.ID
+	if err := recordAudit(tx, actor, AuditUserRegistered, AuditEntityUser, user.ID, nil, nil, user); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
 	return &user, nil
 }
 
@@ -46,3 +62,161 @@ func (r userRepositoryDB) GetByEmail(email string) (*User, error) {
 
 	return &user, nil
 }
+
+func (r userRepositoryDB) GetByID(id int) (*User, error) {
+	var user User
+
+	err := r.db.QueryRow(`
+		SELECT id, name, email, base_currency, reminder_days, timezone, digest_frequency
+		FROM users
+		WHERE id = $1
+	`, id).
+		Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r userRepositoryDB) UpdateSettings(id int, settings UserSettings, actor Actor) (*User, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var before, user User
+
+	err = tx.QueryRow(`
+		SELECT id, name, email, base_currency, reminder_days, timezone, digest_frequency
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`, id).
+		Scan(&before.ID, &before.Name, &before.Email, &before.BaseCurrency, &before.ReminderDays, &before.Timezone, &before.DigestFrequency)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(`
+		UPDATE users
+		SET base_currency = $2, reminder_days = $3, timezone = $4, digest_frequency = $5
+		WHERE id = $1
+		RETURNING id, name, email, base_currency, reminder_days, timezone, digest_frequency
+	`, id, settings.BaseCurrency, settings.ReminderDays, settings.Timezone, settings.DigestFrequency).
+		Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency)
+
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordAudit(tx, actor, AuditUserSettingsUpdated, AuditEntityUser, id, nil, before, user); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r userRepositoryDB) GetByCalendarTokenHash(hash string) (*User, error) {
+	var user User
+
+	err := r.db.QueryRow(`
+		SELECT id, name, email, base_currency, reminder_days, timezone, digest_frequency
+		FROM users
+		WHERE calendar_token_hash = $1
+	`, hash).
+		Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r userRepositoryDB) SetCalendarTokenHash(id int, hash string, actor Actor) error {
+	return r.setTokenHash(id, "calendar_token_hash", hash, AuditCalendarTokenRotated, actor)
+}
+
+func (r userRepositoryDB) GetByReceiptTokenHash(hash string) (*User, error) {
+	var user User
+
+	err := r.db.QueryRow(`
+		SELECT id, name, email, base_currency, reminder_days, timezone, digest_frequency
+		FROM users
+		WHERE receipt_token_hash = $1
+	`, hash).
+		Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r userRepositoryDB) SetReceiptTokenHash(id int, hash string, actor Actor) error {
+	return r.setTokenHash(id, "receipt_token_hash", hash, AuditReceiptTokenRotated, actor)
+}
+
+// setTokenHash replaces the hash in column, one of the users' token hash
+// columns, and records the rotation; the audit log only shows that the
+// hash changed.
+func (r userRepositoryDB) setTokenHash(id int, column string, hash string, action string, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var before *string
+	err = tx.QueryRow(`
+		SELECT `+column+`
+		FROM users
+		WHERE id = $1
+		FOR UPDATE
+	`, id).Scan(&before)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		UPDATE users
+		SET `+column+` = $2
+		WHERE id = $1
+	`, id, hash)
+	if err != nil {
+		return err
+	}
+
+	var previous any
+	if before != nil {
+		previous = *before
+	}
+	err = recordAudit(tx, actor, action, AuditEntityUser, id, nil,
+		map[string]any{column: previous}, map[string]any{column: hash})
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
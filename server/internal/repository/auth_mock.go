@@ -10,8 +10,8 @@ func NewUserRepositoryMock() *userRepositoryMock {
 	return &userRepositoryMock{}
 }
 
-func (m *userRepositoryMock) Create(name, email, password string) (*User, error) {
-	args := m.Called(name, email, password)
+func (m *userRepositoryMock) Create(name, email, password string, actor Actor) (*User, error) {
+	args := m.Called(name, email, password, actor)
 	return args.Get(0).(*User), args.Error(1)
 }
 
@@ -19,3 +19,33 @@ func (m *userRepositoryMock) GetByEmail(email string) (*User, error) {
 	args := m.Called(email)
 	return args.Get(0).(*User), args.Error(1)
 }
+
+func (m *userRepositoryMock) GetByID(id int) (*User, error) {
+	args := m.Called(id)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *userRepositoryMock) UpdateSettings(id int, settings UserSettings, actor Actor) (*User, error) {
+	args := m.Called(id, settings, actor)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *userRepositoryMock) GetByCalendarTokenHash(hash string) (*User, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *userRepositoryMock) SetCalendarTokenHash(id int, hash string, actor Actor) error {
+	args := m.Called(id, hash, actor)
+	return args.Error(0)
+}
+
+func (m *userRepositoryMock) GetByReceiptTokenHash(hash string) (*User, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*User), args.Error(1)
+}
+
+func (m *userRepositoryMock) SetReceiptTokenHash(id int, hash string, actor Actor) error {
+	args := m.Called(id, hash, actor)
+	return args.Error(0)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateBudget = errors.New("a budget for this category already exists")
+
+// Budget caps monthly spend in the user's base currency, either in total
+// (CategoryID nil) or for a single category.
+type Budget struct {
+	BudgetID     int     `db:"id"`
+	CategoryID   *int    `db:"category_id"`
+	CategoryName string  `db:"category_name"`
+	Amount       float64 `db:"amount"`
+	Thresholds   []int   `db:"thresholds"` // percentages, ascending
+}
+
+// BudgetAlert records that spend crossed one of a budget's thresholds.
+type BudgetAlert struct {
+	BudgetID  int    `db:"budget_id"`
+	Period    string `db:"period"` // first day of the month
+	Basis     string `db:"basis"`  // projected, actual
+	Threshold int    `db:"threshold"`
+}
+
+type BudgetRepository interface {
+	GetAll(userID int) ([]Budget, error)
+	GetById(id int, userID int) (*Budget, error)
+	Create(b *Budget, userID int) (*Budget, error)
+	Update(b *Budget, userID int) (*Budget, error)
+	Delete(id int, userID int) error
+	// GetUserIDs lists every user with at least one budget.
+	GetUserIDs() ([]int, error)
+	// RecordAlert stores the alert and the notification announcing it in one
+	// transaction. It returns false, without notifying, when the alert was
+	// already recorded.
+	RecordAlert(alert BudgetAlert, n Notification, userID int) (bool, error)
+}
@@ -0,0 +1,203 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type budgetRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewBudgetRepositoryDB(db *sql.DB) BudgetRepository {
+	return budgetRepositoryDB{db: db}
+}
+
+const budgetColumns = `
+	b.id, b.category_id, COALESCE(c.name, ''), b.amount, b.thresholds
+`
+
+const budgetFrom = `
+	budgets b
+	LEFT JOIN categories c ON c.id = b.category_id
+`
+
+func scanBudget(row scanner) (*Budget, error) {
+	var b Budget
+	var thresholds pq.Int64Array
+	err := row.Scan(
+		&b.BudgetID,
+		&b.CategoryID,
+		&b.CategoryName,
+		&b.Amount,
+		&thresholds,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	b.Thresholds = make([]int, len(thresholds))
+	for i, t := range thresholds {
+		b.Thresholds[i] = int(t)
+	}
+	return &b, nil
+}
+
+func thresholdArray(thresholds []int) pq.Int64Array {
+	arr := make(pq.Int64Array, len(thresholds))
+	for i, t := range thresholds {
+		arr[i] = int64(t)
+	}
+	return arr
+}
+
+func (r budgetRepositoryDB) GetAll(userID int) ([]Budget, error) {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM ` + budgetFrom + `
+		WHERE b.user_id = $1
+		ORDER BY b.category_id NULLS FIRST, lower(c.name)
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var budgets []Budget
+	for rows.Next() {
+		b, err := scanBudget(rows)
+		if err != nil {
+			return nil, err
+		}
+		budgets = append(budgets, *b)
+	}
+
+	return budgets, rows.Err()
+}
+
+func (r budgetRepositoryDB) GetById(id int, userID int) (*Budget, error) {
+	query := `
+		SELECT ` + budgetColumns + `
+		FROM ` + budgetFrom + `
+		WHERE b.id = $1 AND b.user_id = $2
+	`
+
+	b, err := scanBudget(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (r budgetRepositoryDB) Create(b *Budget, userID int) (*Budget, error) {
+	query := `
+		INSERT INTO budgets (user_id, category_id, amount, thresholds)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT (user_id, (COALESCE(category_id, 0))) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, userID, b.CategoryID, b.Amount, thresholdArray(b.Thresholds)).Scan(&b.BudgetID)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateBudget
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Update changes the amount and thresholds; a budget never moves to
+// another category.
+func (r budgetRepositoryDB) Update(b *Budget, userID int) (*Budget, error) {
+	query := `
+		UPDATE budgets
+		SET amount = $1, thresholds = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND user_id = $4
+		RETURNING category_id
+	`
+
+	err := r.db.QueryRow(query, b.Amount, thresholdArray(b.Thresholds), b.BudgetID, userID).Scan(&b.CategoryID)
+	if err != nil {
+		return nil, err
+	}
+
+	return b, nil
+}
+
+func (r budgetRepositoryDB) Delete(id int, userID int) error {
+	query := `
+		DELETE FROM budgets
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r budgetRepositoryDB) GetUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`SELECT DISTINCT user_id FROM budgets ORDER BY user_id`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r budgetRepositoryDB) RecordAlert(alert BudgetAlert, n Notification, userID int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO budget_alerts (budget_id, period, basis, threshold)
+		VALUES ($1, $2, $3, $4)
+		ON CONFLICT DO NOTHING
+	`, alert.BudgetID, alert.Period, alert.Basis, alert.Threshold)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if rows == 0 {
+		return false, nil
+	}
+
+	if err := insertNotification(tx, n, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,46 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type budgetRepositoryMock struct {
+	mock.Mock
+}
+
+func NewBudgetRepositoryMock() *budgetRepositoryMock {
+	return &budgetRepositoryMock{}
+}
+
+func (m *budgetRepositoryMock) GetAll(userID int) ([]Budget, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Budget), args.Error(1)
+}
+
+func (m *budgetRepositoryMock) GetById(id int, userID int) (*Budget, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*Budget), args.Error(1)
+}
+
+func (m *budgetRepositoryMock) Create(b *Budget, userID int) (*Budget, error) {
+	args := m.Called(b, userID)
+	return args.Get(0).(*Budget), args.Error(1)
+}
+
+func (m *budgetRepositoryMock) Update(b *Budget, userID int) (*Budget, error) {
+	args := m.Called(b, userID)
+	return args.Get(0).(*Budget), args.Error(1)
+}
+
+func (m *budgetRepositoryMock) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *budgetRepositoryMock) GetUserIDs() ([]int, error) {
+	args := m.Called()
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *budgetRepositoryMock) RecordAlert(alert BudgetAlert, n Notification, userID int) (bool, error) {
+	args := m.Called(alert, n, userID)
+	return args.Bool(0), args.Error(1)
+}
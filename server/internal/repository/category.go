@@ -0,0 +1,25 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateCategory = errors.New("a category with this name already exists")
+
+type Category struct {
+	CategoryID    int      `db:"id"`
+	Name          string   `db:"name"`
+	Color         string   `db:"color"`
+	Icon          string   `db:"icon"`
+	MonthlyBudget *float64 `db:"monthly_budget"` // amount of the category's budget, nil = none
+}
+
+type CategoryRepository interface {
+	GetAll(userID int) ([]Category, error)
+	GetById(id int, userID int) (*Category, error)
+	Create(c *Category, userID int) (*Category, error)
+	// Create and Update also write MonthlyBudget to the category's budget.
+	// Update renames the category on every subscription filed under it,
+	// and Delete leaves them uncategorized; both record each subscription
+	// as updated by actor.
+	Update(c *Category, actor Actor) (*Category, error)
+	Delete(id int, actor Actor) error
+}
@@ -0,0 +1,225 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+type categoryRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewCategoryRepositoryDB(db *sql.DB) CategoryRepository {
+	return categoryRepositoryDB{db: db}
+}
+
+// categoryColumns reads from categories c LEFT JOIN budgets b, since a
+// category's monthly budget is stored as a budget row.
+const categoryColumns = `
+	c.id, c.name, COALESCE(c.color, ''), COALESCE(c.icon, ''), b.amount
+`
+
+const categoryFrom = `
+	categories c
+	LEFT JOIN budgets b ON b.category_id = c.id
+`
+
+func scanCategory(row scanner) (*Category, error) {
+	var c Category
+	err := row.Scan(
+		&c.CategoryID,
+		&c.Name,
+		&c.Color,
+		&c.Icon,
+		&c.MonthlyBudget,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &c, nil
+}
+
+func (r categoryRepositoryDB) GetAll(userID int) ([]Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM ` + categoryFrom + `
+		WHERE c.user_id = $1
+		ORDER BY lower(c.name)
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var categories []Category
+	for rows.Next() {
+		c, err := scanCategory(rows)
+		if err != nil {
+			return nil, err
+		}
+		categories = append(categories, *c)
+	}
+
+	return categories, rows.Err()
+}
+
+func (r categoryRepositoryDB) GetById(id int, userID int) (*Category, error) {
+	query := `
+		SELECT ` + categoryColumns + `
+		FROM ` + categoryFrom + `
+		WHERE c.id = $1 AND c.user_id = $2
+	`
+
+	c, err := scanCategory(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r categoryRepositoryDB) Create(c *Category, userID int) (*Category, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO categories (user_id, name, color, icon)
+		VALUES ($1, $2, NULLIF($3, ''), NULLIF($4, ''))
+		ON CONFLICT (user_id, (lower(name))) DO NOTHING
+		RETURNING id
+	`, userID, c.Name, c.Color, c.Icon).Scan(&c.CategoryID)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateCategory
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := setCategoryBudget(tx, c.CategoryID, c.MonthlyBudget, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (r categoryRepositoryDB) Update(c *Category, actor Actor) (*Category, error) {
+	userID := actor.UserID
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var exists bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM categories
+			WHERE user_id = $1 AND lower(name) = lower($2) AND id <> $3
+		)
+	`, userID, c.Name, c.CategoryID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrDuplicateCategory
+	}
+
+	result, err := tx.Exec(`
+		UPDATE categories
+		SET name = $1, color = NULLIF($2, ''), icon = NULLIF($3, ''),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND user_id = $5
+	`, c.Name, c.Color, c.Icon, c.CategoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	filed, err := lockSubscriptions(tx, "s.category_id = $1 AND s.user_id = $2", c.CategoryID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if err := rewriteSubscriptions(tx, actor, filed, "category = $2", c.Name); err != nil {
+		return nil, err
+	}
+
+	if err := setCategoryBudget(tx, c.CategoryID, c.MonthlyBudget, userID); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+// setCategoryBudget stores amount as the category's budget, keeping any
+// thresholds already configured, or removes the budget when amount is nil.
+func setCategoryBudget(q queryer, categoryID int, amount *float64, userID int) error {
+	if amount == nil {
+		_, err := q.Exec(`
+			DELETE FROM budgets
+			WHERE category_id = $1 AND user_id = $2
+		`, categoryID, userID)
+		return err
+	}
+
+	_, err := q.Exec(`
+		INSERT INTO budgets (user_id, category_id, amount)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (user_id, (COALESCE(category_id, 0)))
+		DO UPDATE SET amount = EXCLUDED.amount, updated_at = CURRENT_TIMESTAMP
+	`, userID, categoryID, *amount)
+	return err
+}
+
+func (r categoryRepositoryDB) Delete(id int, actor Actor) error {
+	userID := actor.UserID
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	filed, err := lockSubscriptions(tx, "s.category_id = $1 AND s.user_id = $2", id, userID)
+	if err != nil {
+		return err
+	}
+	if err := rewriteSubscriptions(tx, actor, filed, "category = NULL, category_id = NULL"); err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM categories
+		WHERE id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
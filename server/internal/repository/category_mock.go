@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type categoryRepositoryMock struct {
+	mock.Mock
+}
+
+func NewCategoryRepositoryMock() *categoryRepositoryMock {
+	return &categoryRepositoryMock{}
+}
+
+func (m *categoryRepositoryMock) GetAll(userID int) ([]Category, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Category), args.Error(1)
+}
+
+func (m *categoryRepositoryMock) GetById(id int, userID int) (*Category, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*Category), args.Error(1)
+}
+
+func (m *categoryRepositoryMock) Create(c *Category, userID int) (*Category, error) {
+	args := m.Called(c, userID)
+	return args.Get(0).(*Category), args.Error(1)
+}
+
+func (m *categoryRepositoryMock) Update(c *Category, actor Actor) (*Category, error) {
+	args := m.Called(c, actor)
+	return args.Get(0).(*Category), args.Error(1)
+}
+
+func (m *categoryRepositoryMock) Delete(id int, actor Actor) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
@@ -0,0 +1,33 @@
+package repository
+
+// Charge is an amount actually paid for a subscription, as opposed to the
+// expected amount stored on the subscription itself.
+type Charge struct {
+	ChargeID         int     `db:"id"`
+	SubscriptionID   *int    `db:"subscription_id"`
+	SubscriptionName string  `db:"subscription_name"`
+	ChargedOn        string  `db:"charged_on"`
+	Amount           float32 `db:"amount"`
+	Currency         string  `db:"currency"`
+	Source           string  `db:"source"`
+}
+
+// PriceChange is recorded by a trigger whenever a subscription's amount or
+// currency is updated.
+type PriceChange struct {
+	PriceChangeID    int     `db:"id"`
+	SubscriptionID   int     `db:"subscription_id"`
+	SubscriptionName string  `db:"subscription_name"`
+	OldAmount        float32 `db:"old_amount"`
+	NewAmount        float32 `db:"new_amount"`
+	OldCurrency      string  `db:"old_currency"`
+	NewCurrency      string  `db:"new_currency"`
+	ChangedAt        string  `db:"changed_at"`
+}
+
+type ChargeRepository interface {
+	EachCharge(userID int, fn func(Charge) error) error
+	// GetChargesBetween lists charges with from <= charged_on <= to.
+	GetChargesBetween(userID int, from, to string) ([]Charge, error)
+	EachPriceChange(userID int, fn func(PriceChange) error) error
+}
@@ -0,0 +1,122 @@
+package repository
+
+import "database/sql"
+
+type chargeRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewChargeRepositoryDB(db *sql.DB) ChargeRepository {
+	return chargeRepositoryDB{db: db}
+}
+
+func (r chargeRepositoryDB) EachCharge(userID int, fn func(Charge) error) error {
+	query := `
+		SELECT c.id, s.id, COALESCE(s.name, ''),
+		       c.charged_on, c.amount, c.currency, c.source
+		FROM subscription_charges c
+		LEFT JOIN subscriptions s ON s.id = c.subscription_id AND s.deleted_at IS NULL
+		WHERE c.user_id = $1
+		ORDER BY c.charged_on, c.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var charge Charge
+		if err := rows.Scan(
+			&charge.ChargeID,
+			&charge.SubscriptionID,
+			&charge.SubscriptionName,
+			&charge.ChargedOn,
+			&charge.Amount,
+			&charge.Currency,
+			&charge.Source,
+		); err != nil {
+			return err
+		}
+		if err := fn(charge); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
+
+func (r chargeRepositoryDB) GetChargesBetween(userID int, from, to string) ([]Charge, error) {
+	query := `
+		SELECT c.id, s.id, COALESCE(s.name, ''),
+		       c.charged_on, c.amount, c.currency, c.source
+		FROM subscription_charges c
+		LEFT JOIN subscriptions s ON s.id = c.subscription_id AND s.deleted_at IS NULL
+		WHERE c.user_id = $1 AND c.charged_on BETWEEN $2 AND $3
+		ORDER BY c.charged_on, c.id
+	`
+
+	rows, err := r.db.Query(query, userID, from, to)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var charges []Charge
+	for rows.Next() {
+		var charge Charge
+		if err := rows.Scan(
+			&charge.ChargeID,
+			&charge.SubscriptionID,
+			&charge.SubscriptionName,
+			&charge.ChargedOn,
+			&charge.Amount,
+			&charge.Currency,
+			&charge.Source,
+		); err != nil {
+			return nil, err
+		}
+		charges = append(charges, charge)
+	}
+
+	return charges, rows.Err()
+}
+
+func (r chargeRepositoryDB) EachPriceChange(userID int, fn func(PriceChange) error) error {
+	query := `
+		SELECT p.id, p.subscription_id, s.name,
+		       p.old_amount, p.new_amount, p.old_currency, p.new_currency, p.changed_at
+		FROM subscription_price_history p
+		JOIN subscriptions s ON s.id = p.subscription_id
+		WHERE s.deleted_at IS NULL AND ` + accessibleBy("$1", viewRoles) + `
+		ORDER BY p.changed_at, p.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var change PriceChange
+		if err := rows.Scan(
+			&change.PriceChangeID,
+			&change.SubscriptionID,
+			&change.SubscriptionName,
+			&change.OldAmount,
+			&change.NewAmount,
+			&change.OldCurrency,
+			&change.NewCurrency,
+			&change.ChangedAt,
+		); err != nil {
+			return err
+		}
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type chargeRepositoryMock struct {
+	mock.Mock
+}
+
+func NewChargeRepositoryMock() *chargeRepositoryMock {
+	return &chargeRepositoryMock{}
+}
+
+func (m *chargeRepositoryMock) EachCharge(userID int, fn func(Charge) error) error {
+	args := m.Called(userID, fn)
+	for _, charge := range args.Get(0).([]Charge) {
+		if err := fn(charge); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *chargeRepositoryMock) GetChargesBetween(userID int, from, to string) ([]Charge, error) {
+	args := m.Called(userID, from, to)
+	return args.Get(0).([]Charge), args.Error(1)
+}
+
+func (m *chargeRepositoryMock) EachPriceChange(userID int, fn func(PriceChange) error) error {
+	args := m.Called(userID, fn)
+	for _, change := range args.Get(0).([]PriceChange) {
+		if err := fn(change); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
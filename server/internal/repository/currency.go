@@ -0,0 +1,12 @@
+package repository
+
+// ExchangeRate is the value of one unit of Currency expressed in the pivot
+// currency (THB). Converting between two currencies goes through the pivot.
+type ExchangeRate struct {
+	Currency string  `db:"currency"`
+	Rate     float64 `db:"rate"`
+}
+
+type CurrencyRepository interface {
+	GetRates() (map[string]float64, error)
+}
@@ -0,0 +1,33 @@
+package repository
+
+import "database/sql"
+
+type currencyRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewCurrencyRepositoryDB(db *sql.DB) CurrencyRepository {
+	return currencyRepositoryDB{db: db}
+}
+
+func (r currencyRepositoryDB) GetRates() (map[string]float64, error) {
+	rows, err := r.db.Query(`
+		SELECT currency, rate
+		FROM exchange_rates
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	rates := map[string]float64{}
+	for rows.Next() {
+		var rate ExchangeRate
+		if err := rows.Scan(&rate.Currency, &rate.Rate); err != nil {
+			return nil, err
+		}
+		rates[rate.Currency] = rate.Rate
+	}
+
+	return rates, rows.Err()
+}
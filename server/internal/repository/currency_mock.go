@@ -0,0 +1,16 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type currencyRepositoryMock struct {
+	mock.Mock
+}
+
+func NewCurrencyRepositoryMock() *currencyRepositoryMock {
+	return &currencyRepositoryMock{}
+}
+
+func (m *currencyRepositoryMock) GetRates() (map[string]float64, error) {
+	args := m.Called()
+	return args.Get(0).(map[string]float64), args.Error(1)
+}
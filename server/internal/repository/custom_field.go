@@ -0,0 +1,30 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateCustomField = errors.New("a custom field with this key already exists")
+
+// CustomField defines a piece of metadata the user tracks on their
+// subscriptions. Values live in Subscription.CustomFields under Key.
+type CustomField struct {
+	FieldID int      `db:"id"`
+	Key     string   `db:"key"`
+	Label   string   `db:"label"`
+	Type    string   `db:"type"`    // text, number, date, select, url
+	Options []string `db:"options"` // choices of a select field
+}
+
+type CustomFieldRepository interface {
+	GetAll(userID int) ([]CustomField, error)
+	// GetShared lists the definitions of the user and of everyone sharing
+	// a workspace with them, whose subscriptions the user can see. When
+	// several define the same key, the user's own definition wins.
+	GetShared(userID int) ([]CustomField, error)
+	Create(f *CustomField, userID int) (*CustomField, error)
+	// Update changes the label and options; key and type are fixed once
+	// values exist.
+	Update(f *CustomField, userID int) (*CustomField, error)
+	// Delete also removes the field's values from every subscription,
+	// recording each as updated by actor.
+	Delete(id int, actor Actor) error
+}
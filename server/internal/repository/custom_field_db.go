@@ -0,0 +1,131 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type customFieldRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewCustomFieldRepositoryDB(db *sql.DB) CustomFieldRepository {
+	return customFieldRepositoryDB{db: db}
+}
+
+func (r customFieldRepositoryDB) GetAll(userID int) ([]CustomField, error) {
+	query := `
+		SELECT id, key, label, type, options
+		FROM custom_fields
+		WHERE user_id = $1
+		ORDER BY id
+	`
+
+	return queryCustomFields(r.db, query, userID)
+}
+
+func (r customFieldRepositoryDB) GetShared(userID int) ([]CustomField, error) {
+	query := `
+		SELECT id, key, label, type, options
+		FROM (
+			SELECT DISTINCT ON (f.key) f.id, f.key, f.label, f.type, f.options
+			FROM custom_fields f
+			WHERE f.user_id = $1 OR f.user_id IN (
+				SELECT other.user_id
+				FROM workspace_members me
+				JOIN workspace_members other ON other.workspace_id = me.workspace_id
+				WHERE me.user_id = $1
+			)
+			ORDER BY f.key, f.user_id <> $1, f.id
+		) shared
+		ORDER BY id
+	`
+
+	return queryCustomFields(r.db, query, userID)
+}
+
+func queryCustomFields(q queryer, query string, args ...any) ([]CustomField, error) {
+	rows, err := q.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fields []CustomField
+	for rows.Next() {
+		var f CustomField
+		var options pq.StringArray
+		if err := rows.Scan(&f.FieldID, &f.Key, &f.Label, &f.Type, &options); err != nil {
+			return nil, err
+		}
+		f.Options = options
+		fields = append(fields, f)
+	}
+
+	return fields, rows.Err()
+}
+
+func (r customFieldRepositoryDB) Create(f *CustomField, userID int) (*CustomField, error) {
+	query := `
+		INSERT INTO custom_fields (user_id, key, label, type, options)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT (user_id, key) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, userID, f.Key, f.Label, f.Type, pq.StringArray(f.Options)).Scan(&f.FieldID)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateCustomField
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (r customFieldRepositoryDB) Update(f *CustomField, userID int) (*CustomField, error) {
+	query := `
+		UPDATE custom_fields
+		SET label = $1, options = $2, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $3 AND user_id = $4
+		RETURNING key, type
+	`
+
+	err := r.db.QueryRow(query, f.Label, pq.StringArray(f.Options), f.FieldID, userID).Scan(&f.Key, &f.Type)
+	if err != nil {
+		return nil, err
+	}
+
+	return f, nil
+}
+
+func (r customFieldRepositoryDB) Delete(id int, actor Actor) error {
+	userID := actor.UserID
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var key string
+	err = tx.QueryRow(`
+		DELETE FROM custom_fields
+		WHERE id = $1 AND user_id = $2
+		RETURNING key
+	`, id, userID).Scan(&key)
+	if err != nil {
+		return err
+	}
+
+	filled, err := lockSubscriptions(tx, "s.user_id = $1 AND s.custom_fields ? $2", userID, key)
+	if err != nil {
+		return err
+	}
+	if err := rewriteSubscriptions(tx, actor, filled, "custom_fields = s.custom_fields - $2::text", key); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type customFieldRepositoryMock struct {
+	mock.Mock
+}
+
+func NewCustomFieldRepositoryMock() *customFieldRepositoryMock {
+	return &customFieldRepositoryMock{}
+}
+
+func (m *customFieldRepositoryMock) GetAll(userID int) ([]CustomField, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]CustomField), args.Error(1)
+}
+
+func (m *customFieldRepositoryMock) GetShared(userID int) ([]CustomField, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]CustomField), args.Error(1)
+}
+
+func (m *customFieldRepositoryMock) Create(f *CustomField, userID int) (*CustomField, error) {
+	args := m.Called(f, userID)
+	return args.Get(0).(*CustomField), args.Error(1)
+}
+
+func (m *customFieldRepositoryMock) Update(f *CustomField, userID int) (*CustomField, error) {
+	args := m.Called(f, userID)
+	return args.Get(0).(*CustomField), args.Error(1)
+}
+
+func (m *customFieldRepositoryMock) Delete(id int, actor Actor) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
@@ -0,0 +1,11 @@
+package repository
+
+type DigestRepository interface {
+	// GetRecipients lists the users who chose a weekly or monthly digest.
+	GetRecipients() ([]User, error)
+	// Claim marks the digest of the period starting on periodStart as sent
+	// and reports false when it already was, so each period is mailed once.
+	Claim(userID int, frequency string, periodStart string) (bool, error)
+	// Release undoes a claim whose digest could not be sent.
+	Release(userID int, frequency string, periodStart string) error
+}
@@ -0,0 +1,61 @@
+package repository
+
+import "database/sql"
+
+type digestRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewDigestRepositoryDB(db *sql.DB) DigestRepository {
+	return digestRepositoryDB{db: db}
+}
+
+func (r digestRepositoryDB) GetRecipients() ([]User, error) {
+	rows, err := r.db.Query(`
+		SELECT id, name, email, base_currency, reminder_days, timezone, digest_frequency
+		FROM users
+		WHERE digest_frequency <> 'off'
+		ORDER BY id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var users []User
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(&user.ID, &user.Name, &user.Email, &user.BaseCurrency, &user.ReminderDays, &user.Timezone, &user.DigestFrequency); err != nil {
+			return nil, err
+		}
+		users = append(users, user)
+	}
+
+	return users, rows.Err()
+}
+
+func (r digestRepositoryDB) Claim(userID int, frequency string, periodStart string) (bool, error) {
+	result, err := r.db.Exec(`
+		INSERT INTO digest_sends (user_id, frequency, period_start)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, userID, frequency, periodStart)
+	if err != nil {
+		return false, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return rows == 1, nil
+}
+
+func (r digestRepositoryDB) Release(userID int, frequency string, periodStart string) error {
+	_, err := r.db.Exec(`
+		DELETE FROM digest_sends
+		WHERE user_id = $1 AND frequency = $2 AND period_start = $3
+	`, userID, frequency, periodStart)
+	return err
+}
@@ -0,0 +1,26 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type digestRepositoryMock struct {
+	mock.Mock
+}
+
+func NewDigestRepositoryMock() *digestRepositoryMock {
+	return &digestRepositoryMock{}
+}
+
+func (m *digestRepositoryMock) GetRecipients() ([]User, error) {
+	args := m.Called()
+	return args.Get(0).([]User), args.Error(1)
+}
+
+func (m *digestRepositoryMock) Claim(userID int, frequency string, periodStart string) (bool, error) {
+	args := m.Called(userID, frequency, periodStart)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *digestRepositoryMock) Release(userID int, frequency string, periodStart string) error {
+	args := m.Called(userID, frequency, periodStart)
+	return args.Error(0)
+}
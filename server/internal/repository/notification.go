@@ -0,0 +1,20 @@
+package repository
+
+// Notification is an in-app message for a user, e.g. a renewal reminder or
+// a budget alert.
+type Notification struct {
+	NotificationID int    `db:"id"`
+	Type           string `db:"type"`
+	Title          string `db:"title"`
+	Message        string `db:"message"`
+	IsRead         bool   `db:"is_read"`
+	CreatedAt      string `db:"created_at"`
+}
+
+func insertNotification(q queryer, n Notification, userID int) error {
+	_, err := q.Exec(`
+		INSERT INTO notifications (user_id, type, title, message)
+		VALUES ($1, $2, $3, $4)
+	`, userID, n.Type, n.Title, n.Message)
+	return err
+}
@@ -0,0 +1,64 @@
+package repository
+
+import "time"
+
+// NotificationChannel is somewhere outside the app a user receives their
+// notifications. Target is the address on that channel: an email
+// address, a webhook URL, a Telegram chat id or a LINE user id.
+type NotificationChannel struct {
+	ChannelID int    `db:"id"`
+	Type      string `db:"type"` // email, webhook, telegram, line
+	Target    string `db:"target"`
+	Label     string `db:"label"`
+	Enabled   bool   `db:"enabled"`
+	CreatedAt string `db:"created_at"`
+}
+
+// DispatchedNotification is a new notification picked up by FanOut.
+type DispatchedNotification struct {
+	Notification
+	UserID int `db:"user_id"`
+}
+
+// ChannelDelivery is a notification on its way to one channel.
+type ChannelDelivery struct {
+	DeliveryID       int    `db:"id"`
+	NotificationID   int    `db:"notification_id"`
+	ChannelID        int    `db:"channel_id"`
+	UserID           int    `db:"user_id"`
+	ChannelType      string `db:"channel_type"`
+	Target           string `db:"target"`
+	NotificationType string `db:"notification_type"`
+	Title            string `db:"title"`
+	Message          string `db:"message"`
+	Status           string `db:"status"` // pending, sent, failed
+	Attempts         int    `db:"attempts"`
+	LastError        string `db:"last_error"`
+	// the user's quiet hours, during which the delivery waits
+	Timezone        string  `db:"timezone"`
+	QuietHoursStart *string `db:"quiet_hours_start"`
+	QuietHoursEnd   *string `db:"quiet_hours_end"`
+}
+
+type NotificationChannelRepository interface {
+	GetAll(userID int) ([]NotificationChannel, error)
+	GetById(id int, userID int) (*NotificationChannel, error)
+	Create(ch *NotificationChannel, userID int) (*NotificationChannel, error)
+	Update(ch *NotificationChannel, userID int) (*NotificationChannel, error)
+	Delete(id int, userID int) error
+
+	// Notify stores an in-app notification for the user, which FanOut then
+	// picks up. A non-empty key stores it at most once per user.
+	Notify(n Notification, key string, userID int) error
+	// FanOut marks every undispatched notification as dispatched, queues a
+	// delivery to each enabled channel of its user that their preferences
+	// allow for its type and returns the notifications it picked up.
+	FanOut() ([]DispatchedNotification, error)
+	// ClaimDue picks up to limit pending deliveries that are due and moves
+	// their next attempt lease into the future, so a concurrent dispatcher
+	// skips them and a crashed one has them retried.
+	ClaimDue(limit int, lease time.Duration) ([]ChannelDelivery, error)
+	// RecordAttempt stores the outcome of sending d. A pending delivery is
+	// retried after retryIn.
+	RecordAttempt(d ChannelDelivery, retryIn time.Duration) error
+}
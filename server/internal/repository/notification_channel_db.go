@@ -0,0 +1,257 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+)
+
+type notificationChannelRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewNotificationChannelRepositoryDB(db *sql.DB) NotificationChannelRepository {
+	return notificationChannelRepositoryDB{db: db}
+}
+
+const notificationChannelColumns = `c.id, c.type, c.target, COALESCE(c.label, ''), c.enabled, c.created_at`
+
+func scanNotificationChannel(row scanner) (*NotificationChannel, error) {
+	var ch NotificationChannel
+	err := row.Scan(&ch.ChannelID, &ch.Type, &ch.Target, &ch.Label, &ch.Enabled, &ch.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+	return &ch, nil
+}
+
+func (r notificationChannelRepositoryDB) GetAll(userID int) ([]NotificationChannel, error) {
+	query := `
+		SELECT ` + notificationChannelColumns + `
+		FROM notification_channels c
+		WHERE c.user_id = $1
+		ORDER BY c.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var channels []NotificationChannel
+	for rows.Next() {
+		ch, err := scanNotificationChannel(rows)
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, *ch)
+	}
+
+	return channels, rows.Err()
+}
+
+func (r notificationChannelRepositoryDB) GetById(id int, userID int) (*NotificationChannel, error) {
+	query := `
+		SELECT ` + notificationChannelColumns + `
+		FROM notification_channels c
+		WHERE c.id = $1 AND c.user_id = $2
+	`
+
+	ch, err := scanNotificationChannel(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+func (r notificationChannelRepositoryDB) Create(ch *NotificationChannel, userID int) (*NotificationChannel, error) {
+	query := `
+		INSERT INTO notification_channels (user_id, type, target, label, enabled)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, userID, ch.Type, ch.Target, ch.Label, ch.Enabled).
+		Scan(&ch.ChannelID, &ch.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+func (r notificationChannelRepositoryDB) Update(ch *NotificationChannel, userID int) (*NotificationChannel, error) {
+	query := `
+		UPDATE notification_channels
+		SET type = $1, target = $2, label = NULLIF($3, ''), enabled = $4, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $5 AND user_id = $6
+		RETURNING created_at
+	`
+
+	err := r.db.QueryRow(query, ch.Type, ch.Target, ch.Label, ch.Enabled, ch.ChannelID, userID).
+		Scan(&ch.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+func (r notificationChannelRepositoryDB) Delete(id int, userID int) error {
+	query := `
+		DELETE FROM notification_channels
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r notificationChannelRepositoryDB) Notify(n Notification, key string, userID int) error {
+	_, err := r.db.Exec(`
+		INSERT INTO notifications (user_id, type, title, message, event_key)
+		VALUES ($1, $2, $3, $4, NULLIF($5, ''))
+		ON CONFLICT (user_id, event_key) WHERE event_key IS NOT NULL DO NOTHING
+	`, userID, n.Type, n.Title, n.Message, key)
+	return err
+}
+
+func (r notificationChannelRepositoryDB) FanOut() ([]DispatchedNotification, error) {
+	// marking and queueing in one statement means a notification inserted
+	// meanwhile is left for the next run instead of being skipped
+	query := `
+		WITH fresh AS (
+			UPDATE notifications
+			SET dispatched_at = CURRENT_TIMESTAMP
+			WHERE dispatched_at IS NULL
+			RETURNING id, user_id, type, title, message, is_read, created_at
+		), queued AS (
+			INSERT INTO notification_deliveries (notification_id, channel_id)
+			SELECT fresh.id, c.id
+			FROM fresh
+			JOIN notification_channels c ON c.user_id = fresh.user_id AND c.enabled
+			LEFT JOIN notification_preferences p ON p.user_id = fresh.user_id AND p.type = fresh.type
+			WHERE p.user_id IS NULL OR c.id = ANY(p.channel_ids)
+			ON CONFLICT DO NOTHING
+		)
+		SELECT id, user_id, COALESCE(type, ''), COALESCE(title, ''), COALESCE(message, ''),
+		       COALESCE(is_read, false), created_at
+		FROM fresh
+		ORDER BY id
+	`
+
+	rows, err := r.db.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var fresh []DispatchedNotification
+	for rows.Next() {
+		var n DispatchedNotification
+		err := rows.Scan(&n.NotificationID, &n.UserID, &n.Type, &n.Title, &n.Message, &n.IsRead, &n.CreatedAt)
+		if err != nil {
+			return nil, err
+		}
+		fresh = append(fresh, n)
+	}
+
+	return fresh, rows.Err()
+}
+
+func (r notificationChannelRepositoryDB) ClaimDue(limit int, lease time.Duration) ([]ChannelDelivery, error) {
+	query := `
+		UPDATE notification_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM notification_channels c, notifications n, users u
+		WHERE c.id = d.channel_id AND n.id = d.notification_id AND u.id = c.user_id
+		AND d.id IN (
+			SELECT due.id
+			FROM notification_deliveries due
+			WHERE due.status = 'pending' AND due.next_attempt_at <= CURRENT_TIMESTAMP
+			ORDER BY due.next_attempt_at, due.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING d.id, d.notification_id, d.channel_id, c.user_id, c.type, c.target,
+		          COALESCE(n.type, ''), COALESCE(n.title, ''), COALESCE(n.message, ''),
+		          d.status, d.attempts, COALESCE(d.last_error, ''),
+		          u.timezone, u.quiet_hours_start, u.quiet_hours_end
+	`
+
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []ChannelDelivery
+	for rows.Next() {
+		var d ChannelDelivery
+		err := rows.Scan(
+			&d.DeliveryID,
+			&d.NotificationID,
+			&d.ChannelID,
+			&d.UserID,
+			&d.ChannelType,
+			&d.Target,
+			&d.NotificationType,
+			&d.Title,
+			&d.Message,
+			&d.Status,
+			&d.Attempts,
+			&d.LastError,
+			&d.Timezone,
+			&d.QuietHoursStart,
+			&d.QuietHoursEnd,
+		)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, d)
+	}
+
+	return due, rows.Err()
+}
+
+func (r notificationChannelRepositoryDB) RecordAttempt(d ChannelDelivery, retryIn time.Duration) error {
+	query := `
+		UPDATE notification_deliveries
+		SET status = $1, attempts = $2,
+		    next_attempt_at = CASE WHEN $1 = 'pending' THEN CURRENT_TIMESTAMP + make_interval(secs => $3) END,
+		    last_error = NULLIF($4, ''),
+		    sent_at = CASE WHEN $1 = 'sent' THEN CURRENT_TIMESTAMP END
+		WHERE id = $5
+	`
+
+	result, err := r.db.Exec(query, d.Status, d.Attempts, retryIn.Seconds(), d.LastError, d.DeliveryID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
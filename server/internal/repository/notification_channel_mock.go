@@ -0,0 +1,60 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type notificationChannelRepositoryMock struct {
+	mock.Mock
+}
+
+func NewNotificationChannelRepositoryMock() *notificationChannelRepositoryMock {
+	return &notificationChannelRepositoryMock{}
+}
+
+func (m *notificationChannelRepositoryMock) GetAll(userID int) ([]NotificationChannel, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]NotificationChannel), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) GetById(id int, userID int) (*NotificationChannel, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*NotificationChannel), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) Create(ch *NotificationChannel, userID int) (*NotificationChannel, error) {
+	args := m.Called(ch, userID)
+	return args.Get(0).(*NotificationChannel), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) Update(ch *NotificationChannel, userID int) (*NotificationChannel, error) {
+	args := m.Called(ch, userID)
+	return args.Get(0).(*NotificationChannel), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *notificationChannelRepositoryMock) Notify(n Notification, key string, userID int) error {
+	args := m.Called(n, key, userID)
+	return args.Error(0)
+}
+
+func (m *notificationChannelRepositoryMock) FanOut() ([]DispatchedNotification, error) {
+	args := m.Called()
+	return args.Get(0).([]DispatchedNotification), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) ClaimDue(limit int, lease time.Duration) ([]ChannelDelivery, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]ChannelDelivery), args.Error(1)
+}
+
+func (m *notificationChannelRepositoryMock) RecordAttempt(d ChannelDelivery, retryIn time.Duration) error {
+	args := m.Called(d, retryIn)
+	return args.Error(0)
+}
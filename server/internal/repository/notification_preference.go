@@ -0,0 +1,34 @@
+package repository
+
+// NotificationPreferences decide where and when a user's notifications go
+// out. Channels maps a notification type to the ids of the channels it is
+// sent to; types that are missing go to every enabled channel. Quiet hours
+// are "HH:MM" in Timezone, which is only read here.
+type NotificationPreferences struct {
+	QuietHoursStart *string          `db:"quiet_hours_start"`
+	QuietHoursEnd   *string          `db:"quiet_hours_end"`
+	Timezone        string           `db:"timezone"`
+	Channels        map[string][]int `db:"channels"`
+}
+
+// SubscriptionSnooze silences one user's reminders for a subscription
+// until the end of Until.
+type SubscriptionSnooze struct {
+	SubscriptionID   int    `db:"subscription_id"`
+	SubscriptionName string `db:"subscription_name"`
+	Until            string `db:"until"`
+}
+
+type NotificationPreferenceRepository interface {
+	Get(userID int) (*NotificationPreferences, error)
+	// Update replaces the quiet hours and every channel preference.
+	Update(p *NotificationPreferences, userID int) error
+
+	GetSnoozes(userID int) ([]SubscriptionSnooze, error)
+	// Snooze sets or moves the snooze of a subscription the user can see.
+	// It returns nil when there is no such subscription.
+	// Snoozing and unsnoozing record their event and audit entry in the
+	// same transaction.
+	Snooze(subscriptionID int, until string, actor Actor) (*SubscriptionSnooze, error)
+	Unsnooze(subscriptionID int, actor Actor) error
+}
@@ -0,0 +1,243 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type notificationPreferenceRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewNotificationPreferenceRepositoryDB(db *sql.DB) NotificationPreferenceRepository {
+	return notificationPreferenceRepositoryDB{db: db}
+}
+
+func (r notificationPreferenceRepositoryDB) Get(userID int) (*NotificationPreferences, error) {
+	p := NotificationPreferences{Channels: map[string][]int{}}
+
+	err := r.db.QueryRow(`
+		SELECT quiet_hours_start, quiet_hours_end, timezone
+		FROM users
+		WHERE id = $1
+	`, userID).
+		Scan(&p.QuietHoursStart, &p.QuietHoursEnd, &p.Timezone)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := r.db.Query(`
+		SELECT type, channel_ids
+		FROM notification_preferences
+		WHERE user_id = $1
+		ORDER BY type
+	`, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var kind string
+		var ids pq.Int64Array
+		if err := rows.Scan(&kind, &ids); err != nil {
+			return nil, err
+		}
+		channels := make([]int, len(ids))
+		for i, id := range ids {
+			channels[i] = int(id)
+		}
+		p.Channels[kind] = channels
+	}
+
+	return &p, rows.Err()
+}
+
+func (r notificationPreferenceRepositoryDB) Update(p *NotificationPreferences, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE users
+		SET quiet_hours_start = $2, quiet_hours_end = $3
+		WHERE id = $1
+	`, userID, p.QuietHoursStart, p.QuietHoursEnd)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	if _, err := tx.Exec(`DELETE FROM notification_preferences WHERE user_id = $1`, userID); err != nil {
+		return err
+	}
+
+	for kind, channels := range p.Channels {
+		ids := make(pq.Int64Array, len(channels))
+		for i, id := range channels {
+			ids[i] = int64(id)
+		}
+		if _, err := tx.Exec(`
+			INSERT INTO notification_preferences (user_id, type, channel_ids)
+			VALUES ($1, $2, $3)
+		`, userID, kind, ids); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+func (r notificationPreferenceRepositoryDB) GetSnoozes(userID int) ([]SubscriptionSnooze, error) {
+	query := `
+		SELECT z.subscription_id, s.name, z.until::text
+		FROM subscription_snoozes z
+		JOIN subscriptions s ON s.id = z.subscription_id
+		WHERE z.user_id = $1 AND z.until >= CURRENT_DATE
+		AND s.deleted_at IS NULL AND ` + accessibleBy("$1", viewRoles) + `
+		ORDER BY z.until, s.name
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var snoozes []SubscriptionSnooze
+	for rows.Next() {
+		var z SubscriptionSnooze
+		if err := rows.Scan(&z.SubscriptionID, &z.SubscriptionName, &z.Until); err != nil {
+			return nil, err
+		}
+		snoozes = append(snoozes, z)
+	}
+
+	return snoozes, rows.Err()
+}
+
+// lockSnooze reads the user's snooze of a subscription inside the
+// transaction q and locks it, or returns nil when there is none.
+func lockSnooze(q queryer, subscriptionID int, userID int) (*SubscriptionSnooze, error) {
+	var z SubscriptionSnooze
+	err := q.QueryRow(`
+		SELECT z.subscription_id, s.name, z.until::text
+		FROM subscription_snoozes z
+		JOIN subscriptions s ON s.id = z.subscription_id
+		WHERE z.subscription_id = $1 AND z.user_id = $2
+		FOR UPDATE OF z
+	`, subscriptionID, userID).Scan(&z.SubscriptionID, &z.SubscriptionName, &z.Until)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
+
+// recordSnoozeChange stores the event and the audit entry for a snooze
+// in the transaction q that makes it. A snooze only silences the user's
+// own reminders, so the entry is not shown to the workspace.
+func recordSnoozeChange(q queryer, actor Actor, event string, before, after *SubscriptionSnooze) error {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	err := recordEvent(q, AggregateSnooze, current.SubscriptionID, event, actor.UserID, current)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(q, actor, event, AuditEntitySubscription, current.SubscriptionID, nil, before, after)
+}
+
+func (r notificationPreferenceRepositoryDB) Snooze(subscriptionID int, until string, actor Actor) (*SubscriptionSnooze, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnooze(tx, subscriptionID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	query := `
+		INSERT INTO subscription_snoozes (subscription_id, user_id, until)
+		SELECT s.id, $2::int, $3::date
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND ` + accessibleBy("$2", viewRoles) + `
+		ON CONFLICT (subscription_id, user_id) DO UPDATE SET until = EXCLUDED.until
+		RETURNING subscription_id, (SELECT name FROM subscriptions WHERE id = $1), until::text
+	`
+
+	var z SubscriptionSnooze
+	err = tx.QueryRow(query, subscriptionID, actor.UserID, until).
+		Scan(&z.SubscriptionID, &z.SubscriptionName, &z.Until)
+
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if err := recordSnoozeChange(tx, actor, EventSubscriptionSnoozed, before, &z); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return &z, nil
+}
+
+func (r notificationPreferenceRepositoryDB) Unsnooze(subscriptionID int, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockSnooze(tx, subscriptionID, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM subscription_snoozes
+		WHERE subscription_id = $1 AND user_id = $2
+	`, subscriptionID, actor.UserID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordSnoozeChange(tx, actor, EventSubscriptionUnsnoozed, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type notificationPreferenceRepositoryMock struct {
+	mock.Mock
+}
+
+func NewNotificationPreferenceRepositoryMock() *notificationPreferenceRepositoryMock {
+	return &notificationPreferenceRepositoryMock{}
+}
+
+func (m *notificationPreferenceRepositoryMock) Get(userID int) (*NotificationPreferences, error) {
+	args := m.Called(userID)
+	return args.Get(0).(*NotificationPreferences), args.Error(1)
+}
+
+func (m *notificationPreferenceRepositoryMock) Update(p *NotificationPreferences, userID int) error {
+	args := m.Called(p, userID)
+	return args.Error(0)
+}
+
+func (m *notificationPreferenceRepositoryMock) GetSnoozes(userID int) ([]SubscriptionSnooze, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]SubscriptionSnooze), args.Error(1)
+}
+
+func (m *notificationPreferenceRepositoryMock) Snooze(subscriptionID int, until string, actor Actor) (*SubscriptionSnooze, error) {
+	args := m.Called(subscriptionID, until, actor)
+	return args.Get(0).(*SubscriptionSnooze), args.Error(1)
+}
+
+func (m *notificationPreferenceRepositoryMock) Unsnooze(subscriptionID int, actor Actor) error {
+	args := m.Called(subscriptionID, actor)
+	return args.Error(0)
+}
@@ -0,0 +1,66 @@
+package repository
+
+import "time"
+
+// Aggregates whose changes are recorded in the outbox. Shares and snoozes
+// use the id of their subscription.
+const (
+	AggregateSubscription = "subscription"
+	AggregateShare        = "share"
+	AggregateSnooze       = "snooze"
+)
+
+// Events recorded for subscriptions.
+const (
+	EventSubscriptionCreated  = "subscription.created"
+	EventSubscriptionUpdated  = "subscription.updated"
+	EventSubscriptionDeleted  = "subscription.deleted" // moved to the trash
+	EventSubscriptionRestored = "subscription.restored"
+)
+
+// Events recorded for shares. The payload is the share with its members
+// as the change left it; for a deletion it is the last stored state.
+const (
+	EventShareUpdated = "share.updated"
+	EventShareDeleted = "share.deleted"
+	EventShareSettled = "share.settled"
+)
+
+// Events recorded when a user snoozes a subscription's reminders or ends
+// the snooze. The payload is the SubscriptionSnooze.
+const (
+	EventSubscriptionSnoozed   = "subscription.snoozed"
+	EventSubscriptionUnsnoozed = "subscription.unsnoozed"
+)
+
+// OutboxEvent is a domain event stored in the same transaction as the
+// change it describes. Payload is the aggregate as that change left it,
+// encoded as JSON; for a deletion it is the last stored state.
+type OutboxEvent struct {
+	EventID       int     `db:"id"`
+	AggregateType string  `db:"aggregate_type"`
+	AggregateID   int     `db:"aggregate_id"`
+	Event         string  `db:"event"`
+	UserID        int     `db:"user_id"`
+	Payload       string  `db:"payload"`
+	Attempts      int     `db:"attempts"`
+	LastError     string  `db:"last_error"`
+	PublishedAt   *string `db:"published_at"`
+	CreatedAt     string  `db:"created_at"`
+}
+
+type OutboxRepository interface {
+	// ClaimDue picks up to limit due events, oldest first, and moves their
+	// next attempt lease into the future. Only the oldest unpublished event
+	// of an aggregate is ever claimed, so the events of one aggregate are
+	// published in the order they were stored.
+	ClaimDue(limit int, lease time.Duration) ([]OutboxEvent, error)
+	MarkPublished(id int) error
+	// MarkFailed stores a failed attempt; the event is retried after
+	// retryIn.
+	MarkFailed(id int, lastError string, retryIn time.Duration) error
+	// MarkDead stores the last failed attempt of an event that ran out of
+	// attempts. It stays in the outbox for inspection but no longer holds
+	// back the later events of its aggregate.
+	MarkDead(id int, lastError string) error
+}
@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"database/sql"
+	"encoding/json"
+	"sort"
+	"time"
+)
+
+type outboxRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewOutboxRepositoryDB(db *sql.DB) OutboxRepository {
+	return outboxRepositoryDB{db: db}
+}
+
+const outboxColumns = `
+	o.id, o.aggregate_type, o.aggregate_id, o.event, o.user_id, o.payload,
+	o.attempts, COALESCE(o.last_error, ''), o.published_at, o.created_at
+`
+
+func scanOutboxEvent(row scanner) (*OutboxEvent, error) {
+	var e OutboxEvent
+	err := row.Scan(
+		&e.EventID,
+		&e.AggregateType,
+		&e.AggregateID,
+		&e.Event,
+		&e.UserID,
+		&e.Payload,
+		&e.Attempts,
+		&e.LastError,
+		&e.PublishedAt,
+		&e.CreatedAt,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &e, nil
+}
+
+// recordEvent adds an event about an aggregate to the outbox. q must be the
+// transaction that changes the aggregate, after the change has locked its
+// row: events of one aggregate then get ids in the order their
+// transactions commit, which is the order they are published in.
+func recordEvent(q queryer, aggregateType string, aggregateID int, event string, userID int, payload any) error {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+
+	_, err = q.Exec(`
+		INSERT INTO outbox (aggregate_type, aggregate_id, event, user_id, payload)
+		VALUES ($1, $2, $3, $4, $5)
+	`, aggregateType, aggregateID, event, userID, string(data))
+	return err
+}
+
+func (r outboxRepositoryDB) ClaimDue(limit int, lease time.Duration) ([]OutboxEvent, error) {
+	query := `
+		UPDATE outbox o
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE o.id IN (
+			SELECT head.id
+			FROM outbox head
+			WHERE head.published_at IS NULL AND head.dead_at IS NULL
+			AND head.next_attempt_at <= CURRENT_TIMESTAMP
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox earlier
+				WHERE earlier.aggregate_type = head.aggregate_type
+				AND earlier.aggregate_id = head.aggregate_id
+				AND earlier.published_at IS NULL AND earlier.dead_at IS NULL
+				AND earlier.id < head.id
+			)
+			ORDER BY head.id
+			LIMIT $1
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + outboxColumns
+
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []OutboxEvent
+	for rows.Next() {
+		e, err := scanOutboxEvent(rows)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, *e)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	// RETURNING does not keep the order of the subquery
+	sort.Slice(due, func(i, j int) bool { return due[i].EventID < due[j].EventID })
+	return due, nil
+}
+
+func (r outboxRepositoryDB) MarkPublished(id int) error {
+	result, err := r.db.Exec(`
+		UPDATE outbox
+		SET published_at = CURRENT_TIMESTAMP, attempts = attempts + 1, last_error = NULL
+		WHERE id = $1 AND published_at IS NULL AND dead_at IS NULL
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r outboxRepositoryDB) MarkFailed(id int, lastError string, retryIn time.Duration) error {
+	result, err := r.db.Exec(`
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $1,
+		    next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		WHERE id = $3 AND published_at IS NULL AND dead_at IS NULL
+	`, lastError, retryIn.Seconds(), id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r outboxRepositoryDB) MarkDead(id int, lastError string) error {
+	result, err := r.db.Exec(`
+		UPDATE outbox
+		SET attempts = attempts + 1, last_error = $1, dead_at = CURRENT_TIMESTAMP
+		WHERE id = $2 AND published_at IS NULL AND dead_at IS NULL
+	`, lastError, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
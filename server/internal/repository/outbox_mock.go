@@ -0,0 +1,35 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type outboxRepositoryMock struct {
+	mock.Mock
+}
+
+func NewOutboxRepositoryMock() *outboxRepositoryMock {
+	return &outboxRepositoryMock{}
+}
+
+func (m *outboxRepositoryMock) ClaimDue(limit int, lease time.Duration) ([]OutboxEvent, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]OutboxEvent), args.Error(1)
+}
+
+func (m *outboxRepositoryMock) MarkPublished(id int) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *outboxRepositoryMock) MarkFailed(id int, lastError string, retryIn time.Duration) error {
+	args := m.Called(id, lastError, retryIn)
+	return args.Error(0)
+}
+
+func (m *outboxRepositoryMock) MarkDead(id int, lastError string) error {
+	args := m.Called(id, lastError)
+	return args.Error(0)
+}
@@ -0,0 +1,51 @@
+package repository
+
+// PaymentMethod is a card or account subscriptions are billed to. Only the
+// last four digits of a card are kept, never the full number.
+type PaymentMethod struct {
+	PaymentMethodID int    `db:"id"`
+	Label           string `db:"label"`
+	Type            string `db:"type"` // card, bank_account, paypal, wallet, other
+	Last4           string `db:"last4"`
+	ExpiryMonth     *int   `db:"expiry_month"`
+	ExpiryYear      *int   `db:"expiry_year"`
+	Subscriptions   int    `db:"subscriptions"` // linked subscriptions
+}
+
+// ExpiringPaymentMethod is a payment method found by the expiry check,
+// together with the user it belongs to.
+type ExpiringPaymentMethod struct {
+	PaymentMethod
+	UserID int `db:"user_id"`
+}
+
+// PaymentMethodAlert records that the user was warned DaysBefore days
+// ahead of ExpiresOn.
+type PaymentMethodAlert struct {
+	PaymentMethodID int    `db:"payment_method_id"`
+	ExpiresOn       string `db:"expires_on"`
+	DaysBefore      int    `db:"days_before"`
+}
+
+type PaymentMethodRepository interface {
+	GetAll(userID int) ([]PaymentMethod, error)
+	GetById(id int, userID int) (*PaymentMethod, error)
+	Create(pm *PaymentMethod, userID int) (*PaymentMethod, error)
+	Update(pm *PaymentMethod, userID int) (*PaymentMethod, error)
+	// Delete unlinks the payment method from its subscriptions.
+	Delete(id int, userID int) error
+	// GetSubscriptions lists the subscriptions billed to the payment method
+	// that the user can see.
+	GetSubscriptions(id int, userID int) ([]Subscription, error)
+	// Link bills a subscription the user may edit to one of their payment
+	// methods, or unlinks it when paymentMethodID is nil. It records the
+	// subscription.updated event and audit entry like any other edit.
+	Link(subscriptionID int, paymentMethodID *int, actor Actor) error
+	// GetExpiring lists every payment method, across users, whose expiry
+	// month ends on or before until.
+	GetExpiring(until string) ([]ExpiringPaymentMethod, error)
+	// RecordAlert stores the alert and the notification announcing it in one
+	// transaction. It returns false, without notifying, when the alert was
+	// already recorded.
+	RecordAlert(alert PaymentMethodAlert, n Notification, userID int) (bool, error)
+}
@@ -0,0 +1,251 @@
+package repository
+
+import "database/sql"
+
+type paymentMethodRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewPaymentMethodRepositoryDB(db *sql.DB) PaymentMethodRepository {
+	return paymentMethodRepositoryDB{db: db}
+}
+
+const paymentMethodColumns = `
+	pm.id, pm.label, pm.type, COALESCE(pm.last4, ''), pm.expiry_month, pm.expiry_year,
+	(SELECT count(*) FROM subscriptions s WHERE s.payment_method_id = pm.id AND s.deleted_at IS NULL)
+`
+
+func scanPaymentMethod(row scanner, extra ...any) (*PaymentMethod, error) {
+	var pm PaymentMethod
+	dest := append([]any{
+		&pm.PaymentMethodID,
+		&pm.Label,
+		&pm.Type,
+		&pm.Last4,
+		&pm.ExpiryMonth,
+		&pm.ExpiryYear,
+		&pm.Subscriptions,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &pm, nil
+}
+
+func (r paymentMethodRepositoryDB) GetAll(userID int) ([]PaymentMethod, error) {
+	query := `
+		SELECT ` + paymentMethodColumns + `
+		FROM payment_methods pm
+		WHERE pm.user_id = $1
+		ORDER BY lower(pm.label), pm.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var methods []PaymentMethod
+	for rows.Next() {
+		pm, err := scanPaymentMethod(rows)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, *pm)
+	}
+
+	return methods, rows.Err()
+}
+
+func (r paymentMethodRepositoryDB) GetById(id int, userID int) (*PaymentMethod, error) {
+	query := `
+		SELECT ` + paymentMethodColumns + `
+		FROM payment_methods pm
+		WHERE pm.id = $1 AND pm.user_id = $2
+	`
+
+	pm, err := scanPaymentMethod(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return pm, nil
+}
+
+func (r paymentMethodRepositoryDB) Create(pm *PaymentMethod, userID int) (*PaymentMethod, error) {
+	query := `
+		INSERT INTO payment_methods (user_id, label, type, last4, expiry_month, expiry_year)
+		VALUES ($1, $2, $3, NULLIF($4, ''), $5, $6)
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, userID, pm.Label, pm.Type, pm.Last4, pm.ExpiryMonth, pm.ExpiryYear).
+		Scan(&pm.PaymentMethodID)
+	if err != nil {
+		return nil, err
+	}
+
+	return pm, nil
+}
+
+func (r paymentMethodRepositoryDB) Update(pm *PaymentMethod, userID int) (*PaymentMethod, error) {
+	query := `
+		UPDATE payment_methods
+		SET label = $1, type = $2, last4 = NULLIF($3, ''), expiry_month = $4, expiry_year = $5,
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE id = $6 AND user_id = $7
+	`
+
+	result, err := r.db.Exec(query, pm.Label, pm.Type, pm.Last4, pm.ExpiryMonth, pm.ExpiryYear, pm.PaymentMethodID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return nil, err
+	}
+	if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	return pm, nil
+}
+
+func (r paymentMethodRepositoryDB) Delete(id int, userID int) error {
+	query := `
+		DELETE FROM payment_methods
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r paymentMethodRepositoryDB) GetSubscriptions(id int, userID int) ([]Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.payment_method_id = $1 AND s.deleted_at IS NULL AND ` + accessibleBy("$2", viewRoles) + `
+		ORDER BY lower(s.name), s.id
+	`
+
+	rows, err := r.db.Query(query, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, *sub)
+	}
+
+	return subs, rows.Err()
+}
+
+func (r paymentMethodRepositoryDB) Link(subscriptionID int, paymentMethodID *int, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockSubscription(tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	query := `
+		UPDATE subscriptions s
+		SET payment_method_id = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $2 AND s.deleted_at IS NULL AND ` + accessibleBy("$3", editRoles) + `
+		RETURNING ` + subscriptionColumns
+
+	after, err := scanSubscription(tx.QueryRow(query, paymentMethodID, subscriptionID, actor.UserID))
+	if err != nil {
+		return err
+	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionUpdated, before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r paymentMethodRepositoryDB) GetExpiring(until string) ([]ExpiringPaymentMethod, error) {
+	query := `
+		SELECT ` + paymentMethodColumns + `, pm.user_id
+		FROM payment_methods pm
+		WHERE pm.expiry_month IS NOT NULL AND pm.expiry_year IS NOT NULL
+		AND (make_date(pm.expiry_year, pm.expiry_month, 1) + INTERVAL '1 month' - INTERVAL '1 day')::date <= $1::date
+		ORDER BY pm.user_id, pm.id
+	`
+
+	rows, err := r.db.Query(query, until)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var methods []ExpiringPaymentMethod
+	for rows.Next() {
+		var userID int
+		pm, err := scanPaymentMethod(rows, &userID)
+		if err != nil {
+			return nil, err
+		}
+		methods = append(methods, ExpiringPaymentMethod{PaymentMethod: *pm, UserID: userID})
+	}
+
+	return methods, rows.Err()
+}
+
+func (r paymentMethodRepositoryDB) RecordAlert(alert PaymentMethodAlert, n Notification, userID int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO payment_method_alerts (payment_method_id, expires_on, days_before)
+		VALUES ($1, $2, $3)
+		ON CONFLICT DO NOTHING
+	`, alert.PaymentMethodID, alert.ExpiresOn, alert.DaysBefore)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if rows == 0 {
+		return false, nil
+	}
+
+	if err := insertNotification(tx, n, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
@@ -0,0 +1,56 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type paymentMethodRepositoryMock struct {
+	mock.Mock
+}
+
+func NewPaymentMethodRepositoryMock() *paymentMethodRepositoryMock {
+	return &paymentMethodRepositoryMock{}
+}
+
+func (m *paymentMethodRepositoryMock) GetAll(userID int) ([]PaymentMethod, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]PaymentMethod), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) GetById(id int, userID int) (*PaymentMethod, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*PaymentMethod), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) Create(pm *PaymentMethod, userID int) (*PaymentMethod, error) {
+	args := m.Called(pm, userID)
+	return args.Get(0).(*PaymentMethod), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) Update(pm *PaymentMethod, userID int) (*PaymentMethod, error) {
+	args := m.Called(pm, userID)
+	return args.Get(0).(*PaymentMethod), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *paymentMethodRepositoryMock) GetSubscriptions(id int, userID int) ([]Subscription, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).([]Subscription), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) Link(subscriptionID int, paymentMethodID *int, actor Actor) error {
+	args := m.Called(subscriptionID, paymentMethodID, actor)
+	return args.Error(0)
+}
+
+func (m *paymentMethodRepositoryMock) GetExpiring(until string) ([]ExpiringPaymentMethod, error) {
+	args := m.Called(until)
+	return args.Get(0).([]ExpiringPaymentMethod), args.Error(1)
+}
+
+func (m *paymentMethodRepositoryMock) RecordAlert(alert PaymentMethodAlert, n Notification, userID int) (bool, error) {
+	args := m.Called(alert, n, userID)
+	return args.Bool(0), args.Error(1)
+}
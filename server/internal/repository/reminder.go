@@ -0,0 +1,42 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateReminderRule = errors.New("this reminder rule already exists")
+
+// Reminder is a notification about an upcoming date of a subscription,
+// e.g. a renewal or the end of a trial, due on DueOn. DaysBefore is the
+// offset of the rule that raised it.
+type Reminder struct {
+	SubscriptionID int    `db:"subscription_id"`
+	Kind           string `db:"kind"` // renewal_reminder, trial_ending, cancellation_deadline
+	DueOn          string `db:"due_on"`
+	DaysBefore     int    `db:"days_before"`
+}
+
+// ReminderRule asks for a reminder DaysBefore days ahead of a date of the
+// subscription.
+type ReminderRule struct {
+	RuleID         int    `db:"id"`
+	SubscriptionID int    `db:"subscription_id"`
+	Anchor         string `db:"anchor"` // renewal, trial_end, cancellation_deadline
+	DaysBefore     int    `db:"days_before"`
+	CreatedAt      string `db:"created_at"`
+}
+
+type ReminderRepository interface {
+	// GetUserIDs lists the users who can see at least one subscription.
+	GetUserIDs() ([]int, error)
+	// Record notifies the user with n unless the reminder was raised for
+	// them before, and reports whether it was new.
+	Record(r Reminder, n Notification, userID int) (bool, error)
+
+	// GetRules lists the rules of every subscription the user can see.
+	GetRules(userID int) ([]ReminderRule, error)
+	GetSubscriptionRules(subscriptionID int, userID int) ([]ReminderRule, error)
+	// CreateRule adds a rule to a subscription the user can edit. It
+	// returns nil when there is no such subscription.
+	CreateRule(rule *ReminderRule, userID int) (*ReminderRule, error)
+	UpdateRule(rule *ReminderRule, userID int) (*ReminderRule, error)
+	DeleteRule(id int, subscriptionID int, userID int) error
+}
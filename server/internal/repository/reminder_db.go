@@ -0,0 +1,199 @@
+package repository
+
+import "database/sql"
+
+type reminderRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewReminderRepositoryDB(db *sql.DB) ReminderRepository {
+	return reminderRepositoryDB{db: db}
+}
+
+func (r reminderRepositoryDB) GetUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT wm.user_id
+		FROM workspace_members wm
+		WHERE EXISTS (
+			SELECT 1 FROM subscriptions s
+			WHERE s.workspace_id = wm.workspace_id AND s.deleted_at IS NULL
+		)
+		ORDER BY wm.user_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r reminderRepositoryDB) Record(reminder Reminder, n Notification, userID int) (bool, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		INSERT INTO subscription_reminders (user_id, subscription_id, kind, due_on, days_before)
+		VALUES ($1, $2, $3, $4, $5)
+		ON CONFLICT DO NOTHING
+	`, userID, reminder.SubscriptionID, reminder.Kind, reminder.DueOn, reminder.DaysBefore)
+	if err != nil {
+		return false, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return false, err
+	} else if rows == 0 {
+		return false, nil
+	}
+
+	if err := insertNotification(tx, n, userID); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+const reminderRuleColumns = `r.id, r.subscription_id, r.anchor, r.days_before, r.created_at`
+
+func scanReminderRule(row scanner) (*ReminderRule, error) {
+	var rule ReminderRule
+	if err := row.Scan(&rule.RuleID, &rule.SubscriptionID, &rule.Anchor, &rule.DaysBefore, &rule.CreatedAt); err != nil {
+		return nil, err
+	}
+	return &rule, nil
+}
+
+func (r reminderRepositoryDB) GetRules(userID int) ([]ReminderRule, error) {
+	return r.queryRules(`
+		SELECT `+reminderRuleColumns+`
+		FROM reminder_rules r
+		JOIN subscriptions s ON s.id = r.subscription_id
+		WHERE s.deleted_at IS NULL AND `+accessibleBy("$1", viewRoles)+`
+		ORDER BY r.subscription_id, r.anchor, r.days_before
+	`, userID)
+}
+
+func (r reminderRepositoryDB) GetSubscriptionRules(subscriptionID int, userID int) ([]ReminderRule, error) {
+	return r.queryRules(`
+		SELECT `+reminderRuleColumns+`
+		FROM reminder_rules r
+		JOIN subscriptions s ON s.id = r.subscription_id
+		WHERE r.subscription_id = $2 AND s.deleted_at IS NULL AND `+accessibleBy("$1", viewRoles)+`
+		ORDER BY r.anchor, r.days_before DESC
+	`, userID, subscriptionID)
+}
+
+func (r reminderRepositoryDB) queryRules(query string, args ...any) ([]ReminderRule, error) {
+	rows, err := r.db.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var rules []ReminderRule
+	for rows.Next() {
+		rule, err := scanReminderRule(rows)
+		if err != nil {
+			return nil, err
+		}
+		rules = append(rules, *rule)
+	}
+
+	return rules, rows.Err()
+}
+
+func (r reminderRepositoryDB) CreateRule(rule *ReminderRule, userID int) (*ReminderRule, error) {
+	query := `
+		INSERT INTO reminder_rules (subscription_id, anchor, days_before)
+		SELECT s.id, $2::text, $3::int
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND ` + accessibleBy("$4", editRoles) + `
+		ON CONFLICT (subscription_id, anchor, days_before)
+		DO UPDATE SET days_before = EXCLUDED.days_before
+		RETURNING id, subscription_id, anchor, days_before, created_at
+	`
+
+	// adding a rule that exists already returns it unchanged
+	created, err := scanReminderRule(r.db.QueryRow(query, rule.SubscriptionID, rule.Anchor, rule.DaysBefore, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return created, nil
+}
+
+func (r reminderRepositoryDB) UpdateRule(rule *ReminderRule, userID int) (*ReminderRule, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM reminder_rules
+			WHERE subscription_id = $1 AND anchor = $2 AND days_before = $3 AND id <> $4
+		)
+	`, rule.SubscriptionID, rule.Anchor, rule.DaysBefore, rule.RuleID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrDuplicateReminderRule
+	}
+
+	query := `
+		UPDATE reminder_rules r
+		SET anchor = $1, days_before = $2
+		FROM subscriptions s
+		WHERE s.id = r.subscription_id
+		AND r.id = $3 AND r.subscription_id = $4 AND s.deleted_at IS NULL
+		AND ` + accessibleBy("$5", editRoles) + `
+		RETURNING ` + reminderRuleColumns + `
+	`
+
+	updated, err := scanReminderRule(r.db.QueryRow(query, rule.Anchor, rule.DaysBefore, rule.RuleID, rule.SubscriptionID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return updated, nil
+}
+
+func (r reminderRepositoryDB) DeleteRule(id int, subscriptionID int, userID int) error {
+	query := `
+		DELETE FROM reminder_rules r
+		USING subscriptions s
+		WHERE s.id = r.subscription_id
+		AND r.id = $1 AND r.subscription_id = $2 AND s.deleted_at IS NULL
+		AND ` + accessibleBy("$3", editRoles) + `
+	`
+
+	result, err := r.db.Exec(query, id, subscriptionID, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
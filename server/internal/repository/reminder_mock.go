@@ -0,0 +1,46 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type reminderRepositoryMock struct {
+	mock.Mock
+}
+
+func NewReminderRepositoryMock() *reminderRepositoryMock {
+	return &reminderRepositoryMock{}
+}
+
+func (m *reminderRepositoryMock) GetUserIDs() ([]int, error) {
+	args := m.Called()
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) Record(r Reminder, n Notification, userID int) (bool, error) {
+	args := m.Called(r, n, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) GetRules(userID int) ([]ReminderRule, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]ReminderRule), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) GetSubscriptionRules(subscriptionID int, userID int) ([]ReminderRule, error) {
+	args := m.Called(subscriptionID, userID)
+	return args.Get(0).([]ReminderRule), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) CreateRule(rule *ReminderRule, userID int) (*ReminderRule, error) {
+	args := m.Called(rule, userID)
+	return args.Get(0).(*ReminderRule), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) UpdateRule(rule *ReminderRule, userID int) (*ReminderRule, error) {
+	args := m.Called(rule, userID)
+	return args.Get(0).(*ReminderRule), args.Error(1)
+}
+
+func (m *reminderRepositoryMock) DeleteRule(id int, subscriptionID int, userID int) error {
+	args := m.Called(id, subscriptionID, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,52 @@
+package repository
+
+// Share splits the cost of a subscription between its owner and one or
+// more members. The owner pays the provider; members owe the owner their
+// part of every billing period since StartedOn.
+type Share struct {
+	Subscription
+	OwnerID   int           `db:"owner_id"`
+	OwnerName string        `db:"owner_name"`
+	Method    string        `db:"split_method"` // equal, percentage, fixed
+	StartedOn string        `db:"started_on"`
+	Members   []ShareMember `db:"-"`
+}
+
+// ShareMember is either a registered user (UserID set) or someone without
+// an account, known only by name.
+type ShareMember struct {
+	MemberID int      `db:"id"`
+	UserID   *int     `db:"user_id"`
+	Name     string   `db:"name"`
+	Value    *float64 `db:"value"`   // percentage or fixed amount, nil for equal splits
+	Settled  float64  `db:"settled"` // total of the member's settlements
+}
+
+// Settlement is a payment from a member to the owner, in the
+// subscription's currency.
+type Settlement struct {
+	SettlementID int     `db:"id"`
+	MemberID     int     `db:"member_id"`
+	Amount       float64 `db:"amount"`
+	PaidOn       string  `db:"paid_on"`
+	Note         string  `db:"note"`
+}
+
+// ShareRepository reads shares of subscriptions the user can view and
+// changes shares of subscriptions the user can edit, following their
+// workspace role like SubscriptionRepository. Every change records its
+// event and audit entry in the same transaction.
+type ShareRepository interface {
+	// GetShare returns the split of a subscription, or nil when the
+	// subscription is not shared.
+	GetShare(subscriptionID int, userID int) (*Share, error)
+	// GetShares lists every share the user can view or is a member of.
+	GetShares(userID int) ([]Share, error)
+	// SaveShare creates or replaces the split of a subscription and fills
+	// in the member ids. Members are matched on user id or name so their
+	// settlements survive; members no longer listed are removed together
+	// with their settlements.
+	SaveShare(share *Share, actor Actor) error
+	DeleteShare(subscriptionID int, actor Actor) error
+	AddSettlement(subscriptionID int, s *Settlement, actor Actor) (*Settlement, error)
+}
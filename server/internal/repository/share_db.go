@@ -0,0 +1,330 @@
+package repository
+
+import (
+	"database/sql"
+
+	"github.com/lib/pq"
+)
+
+type shareRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewShareRepositoryDB(db *sql.DB) ShareRepository {
+	return shareRepositoryDB{db: db}
+}
+
+const shareQuery = `
+	SELECT ` + subscriptionColumns + `,
+		s.user_id, u.name, sh.split_method, sh.started_on
+	FROM subscription_shares sh
+	JOIN subscriptions s ON s.id = sh.subscription_id
+	JOIN users u ON u.id = s.user_id
+	WHERE s.deleted_at IS NULL
+`
+
+func scanShare(row scanner) (*Share, error) {
+	var sh Share
+	sub, err := scanSubscription(row, &sh.OwnerID, &sh.OwnerName, &sh.Method, &sh.StartedOn)
+	if err != nil {
+		return nil, err
+	}
+	sh.Subscription = *sub
+	return &sh, nil
+}
+
+func (r shareRepositoryDB) GetShare(subscriptionID int, userID int) (*Share, error) {
+	query := shareQuery + `
+		AND s.id = $1 AND ` + accessibleBy("$2", viewRoles) + `
+	`
+
+	sh, err := scanShare(r.db.QueryRow(query, subscriptionID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shares := []Share{*sh}
+	if err := loadMembers(r.db, shares); err != nil {
+		return nil, err
+	}
+
+	return &shares[0], nil
+}
+
+func (r shareRepositoryDB) GetShares(userID int) ([]Share, error) {
+	query := shareQuery + `
+		AND (
+			` + accessibleBy("$1", viewRoles) + `
+			OR EXISTS (
+				SELECT 1 FROM subscription_members m
+				WHERE m.subscription_id = s.id AND m.user_id = $1
+			)
+		)
+		ORDER BY lower(s.name), s.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shares []Share
+	for rows.Next() {
+		sh, err := scanShare(rows)
+		if err != nil {
+			return nil, err
+		}
+		shares = append(shares, *sh)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if err := loadMembers(r.db, shares); err != nil {
+		return nil, err
+	}
+
+	return shares, nil
+}
+
+// loadMembers fills in the members of every share, with the total each
+// one has settled so far.
+func loadMembers(q queryer, shares []Share) error {
+	if len(shares) == 0 {
+		return nil
+	}
+
+	index := map[int]int{}
+	ids := make(pq.Int64Array, len(shares))
+	for i, sh := range shares {
+		index[sh.SubscriptionID] = i
+		ids[i] = int64(sh.SubscriptionID)
+	}
+
+	query := `
+		SELECT m.subscription_id, m.id, m.user_id, m.name, m.value,
+			COALESCE(SUM(st.amount), 0)
+		FROM subscription_members m
+		LEFT JOIN share_settlements st ON st.member_id = m.id
+		WHERE m.subscription_id = ANY($1)
+		GROUP BY m.id
+		ORDER BY m.subscription_id, m.id
+	`
+
+	rows, err := q.Query(query, ids)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var subscriptionID int
+		var m ShareMember
+		err := rows.Scan(&subscriptionID, &m.MemberID, &m.UserID, &m.Name, &m.Value, &m.Settled)
+		if err != nil {
+			return err
+		}
+		i := index[subscriptionID]
+		shares[i].Members = append(shares[i].Members, m)
+	}
+
+	return rows.Err()
+}
+
+// lockShare reads the share of a subscription, with its members, inside
+// the transaction q and locks it, so the events of concurrent changes are
+// stored in commit order. It returns nil when the subscription is not
+// shared.
+func lockShare(q queryer, subscriptionID int) (*Share, error) {
+	sh, err := scanShare(q.QueryRow(shareQuery+` AND s.id = $1 FOR UPDATE OF sh`, subscriptionID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	shares := []Share{*sh}
+	if err := loadMembers(q, shares); err != nil {
+		return nil, err
+	}
+
+	return &shares[0], nil
+}
+
+// recordShareChange stores the event and the audit entry for a change to
+// a share in the transaction q that makes it. before is nil for a new
+// share and after for a deleted one.
+func recordShareChange(q queryer, actor Actor, event string, before, after *Share) error {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	err := recordEvent(q, AggregateShare, current.SubscriptionID, event, actor.UserID, current)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(q, actor, event, AuditEntityShare, current.SubscriptionID, current.WorkspaceID, before, after)
+}
+
+func (r shareRepositoryDB) SaveShare(share *Share, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockShare(tx, share.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	err = tx.QueryRow(`
+		INSERT INTO subscription_shares (subscription_id, split_method, started_on)
+		SELECT s.id, $2, $3
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND `+accessibleBy("$4", editRoles)+`
+		ON CONFLICT (subscription_id) DO UPDATE
+		SET split_method = EXCLUDED.split_method,
+			started_on = EXCLUDED.started_on,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING subscription_id
+	`, share.SubscriptionID, share.Method, share.StartedOn, actor.UserID).Scan(&share.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	kept := pq.Int64Array{}
+	for i := range share.Members {
+		m := &share.Members[i]
+
+		query := `
+			INSERT INTO subscription_members (subscription_id, user_id, name, value)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (subscription_id, lower(name)) WHERE user_id IS NULL
+			DO UPDATE SET name = EXCLUDED.name, value = EXCLUDED.value
+			RETURNING id
+		`
+		if m.UserID != nil {
+			query = `
+				INSERT INTO subscription_members (subscription_id, user_id, name, value)
+				VALUES ($1, $2, $3, $4)
+				ON CONFLICT (subscription_id, user_id) WHERE user_id IS NOT NULL
+				DO UPDATE SET name = EXCLUDED.name, value = EXCLUDED.value
+				RETURNING id
+			`
+		}
+
+		err := tx.QueryRow(query, share.SubscriptionID, m.UserID, m.Name, m.Value).Scan(&m.MemberID)
+		if err != nil {
+			return err
+		}
+		kept = append(kept, int64(m.MemberID))
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM subscription_members
+		WHERE subscription_id = $1 AND NOT (id = ANY($2))
+	`, share.SubscriptionID, kept)
+	if err != nil {
+		return err
+	}
+
+	after, err := lockShare(tx, share.SubscriptionID)
+	if err != nil {
+		return err
+	}
+	if err := recordShareChange(tx, actor, EventShareUpdated, before, after); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r shareRepositoryDB) DeleteShare(subscriptionID int, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var editable int
+	err = tx.QueryRow(`
+		SELECT s.id
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND `+accessibleBy("$2", editRoles)+`
+	`, subscriptionID, actor.UserID).Scan(&editable)
+	if err != nil {
+		return err
+	}
+
+	before, err := lockShare(tx, subscriptionID)
+	if err != nil {
+		return err
+	}
+	if before == nil {
+		return sql.ErrNoRows
+	}
+
+	_, err = tx.Exec(`
+		DELETE FROM subscription_shares
+		WHERE subscription_id = $1
+	`, subscriptionID)
+	if err != nil {
+		return err
+	}
+
+	if err := recordShareChange(tx, actor, EventShareDeleted, before, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r shareRepositoryDB) AddSettlement(subscriptionID int, st *Settlement, actor Actor) (*Settlement, error) {
+	query := `
+		INSERT INTO share_settlements (member_id, amount, paid_on, note)
+		SELECT m.id, $2, $3, NULLIF($4, '')
+		FROM subscription_members m
+		JOIN subscriptions s ON s.id = m.subscription_id
+		WHERE m.id = $1 AND m.subscription_id = $5 AND s.deleted_at IS NULL AND ` + accessibleBy("$6", editRoles) + `
+		RETURNING id
+	`
+
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	before, err := lockShare(tx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = tx.QueryRow(query, st.MemberID, st.Amount, st.PaidOn, st.Note, subscriptionID, actor.UserID).
+		Scan(&st.SettlementID)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := lockShare(tx, subscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordShareChange(tx, actor, EventShareSettled, before, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return st, nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type shareRepositoryMock struct {
+	mock.Mock
+}
+
+func NewShareRepositoryMock() *shareRepositoryMock {
+	return &shareRepositoryMock{}
+}
+
+func (m *shareRepositoryMock) GetShare(subscriptionID int, ownerID int) (*Share, error) {
+	args := m.Called(subscriptionID, ownerID)
+	return args.Get(0).(*Share), args.Error(1)
+}
+
+func (m *shareRepositoryMock) GetShares(userID int) ([]Share, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Share), args.Error(1)
+}
+
+func (m *shareRepositoryMock) SaveShare(share *Share, actor Actor) error {
+	args := m.Called(share, actor)
+	return args.Error(0)
+}
+
+func (m *shareRepositoryMock) DeleteShare(subscriptionID int, actor Actor) error {
+	args := m.Called(subscriptionID, actor)
+	return args.Error(0)
+}
+
+func (m *shareRepositoryMock) AddSettlement(subscriptionID int, s *Settlement, actor Actor) (*Settlement, error) {
+	args := m.Called(subscriptionID, s, actor)
+	return args.Get(0).(*Settlement), args.Error(1)
+}
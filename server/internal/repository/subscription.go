@@ -1,20 +1,82 @@
 package repository
 
 type Subscription struct {
-	SubscriptionID int     `db:"id"`
-	Name           string  `db:"name"`
-	Category       string  `db:"category"`
-	Amount         float32 `db:"amount"`
-	Currency       string  `db:"currency"`
-	BillingCycle   string  `db:"billing_cycle"`
-	BillingDate    string  `db:"billing_date"`
-	Status         string  `db:"status"`
-	Trial          bool    `db:"is_trial"`
+	SubscriptionID   int      `db:"id"`
+	Name             string   `db:"name"`
+	Category         string   `db:"category"`
+	CategoryID       *int     `db:"category_id"`
+	Amount           float32  `db:"amount"`
+	Currency         string   `db:"currency"`
+	BillingCycle     string   `db:"billing_cycle"`
+	BillingDate      string   `db:"billing_date"`
+	Status           string   `db:"status"`
+	Trial            bool     `db:"is_trial"`
+	Notes            string   `db:"notes"` // markdown
+	Tags             []string `db:"tags"`
+	CustomFields     string   `db:"custom_fields"`     // JSON object keyed by CustomField.Key
+	WorkspaceID      *int     `db:"workspace_id"`      // nil on create means the creator's personal workspace
+	PaymentMethodID  *int     `db:"payment_method_id"` // set through PaymentMethodRepository.Link
+	AutoRenew        bool     `db:"auto_renew"`
+	NoticePeriodDays int      `db:"notice_period_days"` // days before a renewal by which it must be cancelled
+	CreatedBy        int      `db:"user_id"`            // pays the provider; access comes from the workspace
 }
 
+// SubscriptionFilter narrows List. A subscription must carry every tag in
+// Tags and match every custom field value in CustomFields, both compared
+// case-insensitively. WorkspaceID limits the list to one workspace instead
+// of every workspace the user belongs to.
+type SubscriptionFilter struct {
+	WorkspaceID  *int
+	Tags         []string
+	CustomFields map[string]string
+}
+
+// TrashedSubscription is a deleted subscription that can still be restored
+// until it is purged.
+type TrashedSubscription struct {
+	Subscription
+	DeletedAt string `db:"deleted_at"`
+}
+
+type SubscriptionSearchResult struct {
+	Subscription
+	Rank              float64 `db:"rank"`
+	NameHighlight     string  `db:"name_highlight"`
+	CategoryHighlight string  `db:"category_highlight"`
+	NotesHighlight    string  `db:"notes_highlight"`
+}
+
+// SubscriptionRepository records a subscription.* event in the outbox and
+// an entry in the audit log within the same transaction as every change it
+// makes. Changes are made with the access of actor.UserID.
+//
+// Delete moves a subscription to the trash; every other method except the
+// trash ones ignores trashed subscriptions.
 type SubscriptionRepository interface {
 	GetAll(userID int) ([]Subscription, error)
+	List(userID int, filter SubscriptionFilter) ([]Subscription, error)
 	GetById(id int, userID int) (*Subscription, error)
-	Create(sub *Subscription, userID int) (*Subscription, error)
-	Delete(id int, userID int) error
+	Create(sub *Subscription, actor Actor) (*Subscription, error)
+	Delete(id int, actor Actor) error
+	Search(query string, userID int, limit int) ([]SubscriptionSearchResult, error)
+	Import(creates []Subscription, updates []Subscription, actor Actor) error
+	Each(userID int, fn func(Subscription) error) error
+	// Update overwrites sub.SubscriptionID with sub, including the
+	// auto-renew flag and notice period, keeping its custom fields. It
+	// returns ErrDuplicateSubscription when the new name is already taken.
+	Update(sub *Subscription, actor Actor) (*Subscription, error)
+	// SetCustomFields merges fields, a JSON object, into the custom field
+	// values of a subscription. Keys set to null are removed; keys not in
+	// fields keep their values.
+	SetCustomFields(id int, actor Actor, fields string) error
+
+	// GetTrash returns the user's trashed subscriptions, newest first.
+	GetTrash(userID int) ([]TrashedSubscription, error)
+	// Restore takes a subscription out of the trash. It returns
+	// ErrDuplicateSubscription when a subscription with the same name has
+	// been created since.
+	Restore(id int, actor Actor) (*Subscription, error)
+	// Purge permanently deletes up to limit subscriptions that were trashed
+	// at least retentionDays ago, and returns how many it deleted.
+	Purge(retentionDays int, limit int) (int, error)
 }
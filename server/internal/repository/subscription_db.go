@@ -2,6 +2,13 @@ package repository
 
 import (
 	"database/sql"
+	"html"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/lib/pq"
 )
 
 type subscriptionRepositoryDB struct {
@@ -12,15 +19,133 @@ func NewSubscriptionRepositoryDB(db *sql.DB) SubscriptionRepository {
 	return subscriptionRepositoryDB{db: db}
 }
 
+// subscriptionColumns matches the order scanSubscription reads them in.
+// Queries must alias the subscriptions row as s.
+const subscriptionColumns = `
+	s.id, s.name, COALESCE(s.category, ''), s.category_id, s.amount, s.currency,
+	s.billing_cycle, s.billing_date, s.status, s.is_trial, COALESCE(s.notes, ''),
+	ARRAY(
+		SELECT t.name
+		FROM subscription_tags st
+		JOIN tags t ON t.id = st.tag_id
+		WHERE st.subscription_id = s.id
+		ORDER BY lower(t.name)
+	),
+	s.custom_fields::text, s.workspace_id, s.payment_method_id,
+	s.auto_renew, s.notice_period_days, s.user_id
+`
+
+// Roles allowed to read and to change a workspace's subscriptions.
+const (
+	viewRoles = `'owner', 'admin', 'editor', 'viewer'`
+	editRoles = `'owner', 'admin', 'editor'`
+)
+
+// accessibleBy restricts a query to subscriptions in workspaces where the
+// user bound to param holds one of roles. Access comes from workspace
+// membership; subscriptions.user_id only records who created the row.
+func accessibleBy(param string, roles string) string {
+	return `EXISTS (
+		SELECT 1 FROM workspace_members wm
+		WHERE wm.workspace_id = s.workspace_id
+		AND wm.user_id = ` + param + `
+		AND wm.role IN (` + roles + `)
+	)`
+}
+
+type scanner interface {
+	Scan(dest ...any) error
+}
+
+// queryer is satisfied by both *sql.DB and *sql.Tx.
+type queryer interface {
+	Query(query string, args ...any) (*sql.Rows, error)
+	QueryRow(query string, args ...any) *sql.Row
+	Exec(query string, args ...any) (sql.Result, error)
+}
+
+// scanSubscription reads subscriptionColumns, followed by any extra
+// columns the query selected.
+func scanSubscription(row scanner, extra ...any) (*Subscription, error) {
+	var sub Subscription
+	var tags pq.StringArray
+	dest := append([]any{
+		&sub.SubscriptionID,
+		&sub.Name,
+		&sub.Category,
+		&sub.CategoryID,
+		&sub.Amount,
+		&sub.Currency,
+		&sub.BillingCycle,
+		&sub.BillingDate,
+		&sub.Status,
+		&sub.Trial,
+		&sub.Notes,
+		&tags,
+		&sub.CustomFields,
+		&sub.WorkspaceID,
+		&sub.PaymentMethodID,
+		&sub.AutoRenew,
+		&sub.NoticePeriodDays,
+		&sub.CreatedBy,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	sub.Tags = tags
+	return &sub, nil
+}
+
 func (r subscriptionRepositoryDB) GetAll(userID int) ([]Subscription, error) {
+	return r.List(userID, SubscriptionFilter{})
+}
+
+func (r subscriptionRepositoryDB) List(userID int, filter SubscriptionFilter) ([]Subscription, error) {
 	query := `
-		SELECT id, name, category, amount, currency,
-		       billing_cycle, billing_date, status, is_trial
-		FROM subscriptions
-		WHERE user_id = $1
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.deleted_at IS NULL AND ` + accessibleBy("$1", viewRoles) + `
 	`
+	args := []any{userID}
 
-	rows, err := r.db.Query(query, userID)
+	if filter.WorkspaceID != nil {
+		args = append(args, *filter.WorkspaceID)
+		query += `
+		  AND s.workspace_id = $` + strconv.Itoa(len(args)) + `
+		`
+	}
+
+	if len(filter.Tags) > 0 {
+		lowered := make([]string, len(filter.Tags))
+		for i, tag := range filter.Tags {
+			lowered[i] = strings.ToLower(tag)
+		}
+		args = append(args, pq.StringArray(lowered))
+		query += `
+		  AND (
+			SELECT count(DISTINCT lower(t.name))
+			FROM subscription_tags st
+			JOIN tags t ON t.id = st.tag_id
+			WHERE st.subscription_id = s.id AND lower(t.name) = ANY($` + strconv.Itoa(len(args)) + `)
+		  ) = cardinality($` + strconv.Itoa(len(args)) + `::text[])
+		`
+	}
+
+	keys := make([]string, 0, len(filter.CustomFields))
+	for key := range filter.CustomFields {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		args = append(args, key, filter.CustomFields[key])
+		query += `
+		  AND lower(s.custom_fields ->> $` + strconv.Itoa(len(args)-1) + `::text) = lower($` + strconv.Itoa(len(args)) + `::text)
+		`
+	}
+
+	rows, err := r.db.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -28,47 +153,53 @@ func (r subscriptionRepositoryDB) GetAll(userID int) ([]Subscription, error) {
 
 	var subs []Subscription
 	for rows.Next() {
-		var sub Subscription
-		if err := rows.Scan(
-			&sub.SubscriptionID,
-			&sub.Name,
-			&sub.Category,
-			&sub.Amount,
-			&sub.Currency,
-			&sub.BillingCycle,
-			&sub.BillingDate,
-			&sub.Status,
-			&sub.Trial,
-		); err != nil {
+		sub, err := scanSubscription(rows)
+		if err != nil {
 			return nil, err
 		}
-		subs = append(subs, sub)
+		subs = append(subs, *sub)
 	}
 
-	return subs, nil
+	return subs, rows.Err()
 }
 
-func (r subscriptionRepositoryDB) GetById(id int, userID int) (*Subscription, error) {
+// Each calls fn for every subscription of the user while the rows are
+// still being read, so callers can stream large result sets.
+func (r subscriptionRepositoryDB) Each(userID int, fn func(Subscription) error) error {
 	query := `
-		SELECT id, name, category, amount, currency,
-		       billing_cycle, billing_date, status, is_trial
-		FROM subscriptions
-		WHERE id = $1 AND user_id = $2
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.deleted_at IS NULL AND ` + accessibleBy("$1", viewRoles) + `
+		ORDER BY s.id
 	`
 
-	var sub Subscription
-	err := r.db.QueryRow(query, id, userID).Scan(
-		&sub.SubscriptionID,
-		&sub.Name,
-		&sub.Category,
-		&sub.Amount,
-		&sub.Currency,
-		&sub.BillingCycle,
-		&sub.BillingDate,
-		&sub.Status,
-		&sub.Trial,
-	)
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return err
+		}
+		if err := fn(*sub); err != nil {
+			return err
+		}
+	}
+
+	return rows.Err()
+}
 
+func (r subscriptionRepositoryDB) GetById(id int, userID int) (*Subscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND ` + accessibleBy("$2", viewRoles) + `
+	`
+
+	sub, err := scanSubscription(r.db.QueryRow(query, id, userID))
 	if err == sql.ErrNoRows {
 		return nil, nil
 	}
@@ -76,56 +207,686 @@ func (r subscriptionRepositoryDB) GetById(id int, userID int) (*Subscription, er
 		return nil, err
 	}
 
-	return &sub, nil
+	return sub, nil
+}
+
+func (r subscriptionRepositoryDB) Create(sub *Subscription, actor Actor) (*Subscription, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	if err := insertSubscription(tx, sub, actor.UserID); err != nil {
+		return nil, err
+	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionCreated, nil, sub); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
 }
 
-func (r subscriptionRepositoryDB) Create(sub *Subscription, userID int) (*Subscription, error) {
+// resolveCategory returns the user's category with this name, compared
+// case-insensitively, creating it when it does not exist yet. The stored
+// spelling wins, so "entertainment" files under "Entertainment".
+func resolveCategory(q queryer, name string, userID int) (*int, string, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return nil, "", nil
+	}
+
+	var id int
+	err := q.QueryRow(`
+		INSERT INTO categories (user_id, name)
+		VALUES ($1, $2)
+		ON CONFLICT (user_id, (lower(name))) DO UPDATE SET name = categories.name
+		RETURNING id, name
+	`, userID, name).Scan(&id, &name)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return &id, name, nil
+}
+
+// writableWorkspace returns the workspace a new subscription goes into:
+// the requested one when the user may edit it, or the user's personal
+// workspace when none was requested.
+func writableWorkspace(q queryer, workspaceID *int, userID int) (int, error) {
+	if workspaceID == nil {
+		return personalWorkspace(q, userID)
+	}
+
+	var id int
+	err := q.QueryRow(`
+		SELECT workspace_id
+		FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2 AND role IN (`+editRoles+`)
+	`, *workspaceID, userID).Scan(&id)
+	if err == sql.ErrNoRows {
+		return 0, ErrWorkspaceAccessDenied
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func insertSubscription(q queryer, sub *Subscription, userID int) error {
+	workspaceID, err := writableWorkspace(q, sub.WorkspaceID, userID)
+	if err != nil {
+		return err
+	}
+	sub.WorkspaceID = &workspaceID
+
+	categoryID, category, err := resolveCategory(q, sub.Category, userID)
+	if err != nil {
+		return err
+	}
+	sub.CategoryID, sub.Category = categoryID, category
+
 	query := `
 		INSERT INTO subscriptions
-		(name, category, amount, currency, billing_cycle, billing_date, status, is_trial, user_id)
-		VALUES ($1,$2,$3,$4,$5,$6,$7,$8,$9)
+		(name, category, category_id, amount, currency, billing_cycle, billing_date, status, is_trial, notes,
+		 custom_fields, user_id, workspace_id, auto_renew, notice_period_days)
+		VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, $7, $8, $9, NULLIF($10, ''),
+		        jsonb_strip_nulls(COALESCE(NULLIF($11, '')::jsonb, '{}')), $12, $13, $14, $15)
 		RETURNING id
 	`
 
-	err := r.db.QueryRow(
+	err = q.QueryRow(
 		query,
 		sub.Name,
 		sub.Category,
+		sub.CategoryID,
 		sub.Amount,
 		sub.Currency,
 		sub.BillingCycle,
 		sub.BillingDate,
 		sub.Status,
 		sub.Trial,
+		sub.Notes,
+		sub.CustomFields,
 		userID,
+		workspaceID,
+		sub.AutoRenew,
+		sub.NoticePeriodDays,
 	).Scan(&sub.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	return setSubscriptionTags(q, sub, userID)
+}
+
+// updateSubscription overwrites the subscription with sub, except that an
+// empty CustomFields keeps the stored values since imports do not carry them.
+// Categories and tags are resolved for the subscription's creator, who owns
+// them, rather than for the user making the change.
+func updateSubscription(q queryer, sub *Subscription, userID int) error {
+	var creatorID int
+	err := q.QueryRow(`
+		SELECT s.user_id
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND `+accessibleBy("$2", editRoles)+`
+	`, sub.SubscriptionID, userID).Scan(&creatorID)
+	if err != nil {
+		return err
+	}
+
+	categoryID, category, err := resolveCategory(q, sub.Category, creatorID)
+	if err != nil {
+		return err
+	}
+	sub.CategoryID, sub.Category = categoryID, category
+
+	result, err := q.Exec(`
+		UPDATE subscriptions s
+		SET name = $1, category = NULLIF($2, ''), category_id = $3, amount = $4, currency = $5,
+		    billing_cycle = $6, billing_date = $7, status = $8, is_trial = $9,
+		    notes = NULLIF($10, ''), custom_fields = COALESCE(NULLIF($11, '')::jsonb, custom_fields),
+		    updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $12 AND s.deleted_at IS NULL AND `+accessibleBy("$13", editRoles)+`
+	`,
+		sub.Name,
+		sub.Category,
+		sub.CategoryID,
+		sub.Amount,
+		sub.Currency,
+		sub.BillingCycle,
+		sub.BillingDate,
+		sub.Status,
+		sub.Trial,
+		sub.Notes,
+		sub.CustomFields,
+		sub.SubscriptionID,
+		userID,
+	)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return setSubscriptionTags(q, sub, creatorID)
+}
+
+// setSubscriptionTags replaces the tags of sub with sub.Tags, creating any
+// tag the user does not have yet. Like categories, tags match
+// case-insensitively and keep their stored spelling, which is written back
+// to sub.Tags.
+func setSubscriptionTags(q queryer, sub *Subscription, userID int) error {
+	_, err := q.Exec(`
+		DELETE FROM subscription_tags
+		WHERE subscription_id = $1
+	`, sub.SubscriptionID)
+	if err != nil {
+		return err
+	}
+
+	tags := make([]string, 0, len(sub.Tags))
+	for _, name := range sub.Tags {
+		var tagID int
+		err := q.QueryRow(`
+			INSERT INTO tags (user_id, name)
+			VALUES ($1, $2)
+			ON CONFLICT (user_id, (lower(name))) DO UPDATE SET name = tags.name
+			RETURNING id, name
+		`, userID, name).Scan(&tagID, &name)
+		if err != nil {
+			return err
+		}
 
+		_, err = q.Exec(`
+			INSERT INTO subscription_tags (subscription_id, tag_id)
+			VALUES ($1, $2)
+			ON CONFLICT DO NOTHING
+		`, sub.SubscriptionID, tagID)
+		if err != nil {
+			return err
+		}
+		tags = append(tags, name)
+	}
+	sub.Tags = tags
+
+	return nil
+}
+
+func (r subscriptionRepositoryDB) Update(sub *Subscription, actor Actor) (*Subscription, error) {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return nil, err
 	}
+	defer tx.Rollback()
 
-	return sub, nil
+	before, err := lockSubscription(tx, sub.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	var taken bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions other
+			WHERE other.workspace_id = s.workspace_id AND other.name = $3 AND other.id <> s.id
+			AND other.deleted_at IS NULL
+		)
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND `+accessibleBy("$2", editRoles)+`
+	`, sub.SubscriptionID, actor.UserID, sub.Name).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrDuplicateSubscription
+	}
+
+	sub.CustomFields = ""
+	if err := updateSubscription(tx, sub, actor.UserID); err != nil {
+		return nil, err
+	}
+
+	// updateSubscription is shared with imports, which carry neither
+	_, err = tx.Exec(`
+		UPDATE subscriptions
+		SET auto_renew = $1, notice_period_days = $2
+		WHERE id = $3
+	`, sub.AutoRenew, sub.NoticePeriodDays, sub.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+
+	after, err := lockSubscription(tx, sub.SubscriptionID)
+	if err != nil {
+		return nil, err
+	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionUpdated, before, after); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return after, nil
 }
 
-func (r subscriptionRepositoryDB) Delete(id int, userID int) error {
+func (r subscriptionRepositoryDB) SetCustomFields(id int, actor Actor, fields string) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	before, err := lockSubscription(tx, id)
+	if err != nil {
+		return err
+	}
+
 	query := `
-		DELETE FROM subscriptions
-		WHERE id = $1 AND user_id = $2
-	`
+		UPDATE subscriptions s
+		SET custom_fields = jsonb_strip_nulls(s.custom_fields || $1::jsonb), updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $2 AND s.deleted_at IS NULL AND ` + accessibleBy("$3", editRoles) + `
+		RETURNING ` + subscriptionColumns
 
-	result, err := r.db.Exec(query, id, userID)
+	after, err := scanSubscription(tx.QueryRow(query, fields, id, actor.UserID))
 	if err != nil {
 		return err
 	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionUpdated, before, after); err != nil {
+		return err
+	}
 
-	rows, err := result.RowsAffected()
+	return tx.Commit()
+}
+
+func (r subscriptionRepositoryDB) Delete(id int, actor Actor) error {
+	tx, err := r.db.Begin()
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	if rows == 0 {
-		return sql.ErrNoRows
+	query := `
+		UPDATE subscriptions s
+		SET deleted_at = CURRENT_TIMESTAMP, updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $1 AND s.deleted_at IS NULL AND ` + accessibleBy("$2", editRoles) + `
+		RETURNING ` + subscriptionColumns
+
+	sub, err := scanSubscription(tx.QueryRow(query, id, actor.UserID))
+	if err != nil {
+		return err
+	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionDeleted, sub, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+func (r subscriptionRepositoryDB) GetTrash(userID int) ([]TrashedSubscription, error) {
+	query := `
+		SELECT ` + subscriptionColumns + `, s.deleted_at
+		FROM subscriptions s
+		WHERE s.deleted_at IS NOT NULL AND ` + accessibleBy("$1", viewRoles) + `
+		ORDER BY s.deleted_at DESC, s.id DESC
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var trash []TrashedSubscription
+	for rows.Next() {
+		var t TrashedSubscription
+		sub, err := scanSubscription(rows, &t.DeletedAt)
+		if err != nil {
+			return nil, err
+		}
+		t.Subscription = *sub
+		trash = append(trash, t)
+	}
+
+	return trash, rows.Err()
+}
+
+func (r subscriptionRepositoryDB) Restore(id int, actor Actor) (*Subscription, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	var taken bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions live
+			WHERE live.workspace_id = s.workspace_id AND live.name = s.name AND live.deleted_at IS NULL
+		)
+		FROM subscriptions s
+		WHERE s.id = $1 AND s.deleted_at IS NOT NULL AND `+accessibleBy("$2", editRoles)+`
+		FOR UPDATE OF s
+	`, id, actor.UserID).Scan(&taken)
+	if err != nil {
+		return nil, err
+	}
+	if taken {
+		return nil, ErrDuplicateSubscription
+	}
+
+	query := `
+		UPDATE subscriptions s
+		SET deleted_at = NULL, updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $1
+		RETURNING ` + subscriptionColumns
+
+	sub, err := scanSubscription(tx.QueryRow(query, id))
+	if err != nil {
+		return nil, err
+	}
+	if err := recordSubscriptionChange(tx, actor, EventSubscriptionRestored, nil, sub); err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+// Purge writes an audit entry without an actor for every subscription it
+// deletes. No event is recorded; webhooks heard of the deletion when the
+// subscription was trashed.
+func (r subscriptionRepositoryDB) Purge(retentionDays int, limit int) (int, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	query := `
+		DELETE FROM subscriptions s
+		WHERE s.id IN (
+			SELECT expired.id
+			FROM subscriptions expired
+			WHERE expired.deleted_at < CURRENT_TIMESTAMP - make_interval(days => $1)
+			ORDER BY expired.deleted_at
+			LIMIT $2
+			FOR UPDATE SKIP LOCKED
+		)
+		RETURNING ` + subscriptionColumns
+
+	rows, err := tx.Query(query, retentionDays, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	// RETURNING still sees the tags, which are only removed by the cascade
+	// once the statement is done
+	var purged []Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			rows.Close()
+			return 0, err
+		}
+		purged = append(purged, *sub)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for _, sub := range purged {
+		err := recordAudit(tx, SystemActor, AuditSubscriptionPurged, AuditEntitySubscription, sub.SubscriptionID, sub.WorkspaceID, sub, nil)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return len(purged), nil
+}
+
+// Import inserts creates and overwrites updates (matched by id) in a single
+// transaction, so either every row is written or none is. Every row gets
+// its own created or updated event and audit entry.
+func (r subscriptionRepositoryDB) Import(creates []Subscription, updates []Subscription, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for i := range creates {
+		if err := insertSubscription(tx, &creates[i], actor.UserID); err != nil {
+			return err
+		}
+		if err := recordSubscriptionChange(tx, actor, EventSubscriptionCreated, nil, &creates[i]); err != nil {
+			return err
+		}
+	}
+
+	for i := range updates {
+		before, err := lockSubscription(tx, updates[i].SubscriptionID)
+		if err != nil {
+			return err
+		}
+		if err := updateSubscription(tx, &updates[i], actor.UserID); err != nil {
+			return err
+		}
+
+		// imports do not carry every column, so the change is read back
+		after, err := lockSubscription(tx, updates[i].SubscriptionID)
+		if err != nil {
+			return err
+		}
+		if err := recordSubscriptionChange(tx, actor, EventSubscriptionUpdated, before, after); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit()
+}
+
+// Search ranks the user's subscriptions against q using full-text search
+// (with prefix matching, so "spot" finds "Spotify") combined with trigram
+// word similarity to tolerate typos. Names weigh most, then categories and
+// tags, then notes.
+func (r subscriptionRepositoryDB) Search(q string, userID int, limit int) ([]SubscriptionSearchResult, error) {
+	query := `
+		WITH q AS (
+			SELECT to_tsquery('simple', $2) AS tsq, $3::text AS raw
+		), tagged AS (
+			SELECT s.*,
+			       COALESCE((
+			           SELECT string_agg(t.name, ' ')
+			           FROM subscription_tags st
+			           JOIN tags t ON t.id = st.tag_id
+			           WHERE st.subscription_id = s.id
+			       ), '') AS tag_text
+			FROM subscriptions s
+			WHERE s.deleted_at IS NULL AND ` + accessibleBy("$1", viewRoles) + `
+		), docs AS (
+			SELECT tagged.*,
+			       setweight(to_tsvector('simple', name), 'A') ||
+			       setweight(to_tsvector('simple', COALESCE(category, '')), 'B') ||
+			       setweight(to_tsvector('simple', tag_text), 'B') ||
+			       setweight(to_tsvector('simple', COALESCE(notes, '')), 'C') AS document
+			FROM tagged
+		)
+		SELECT ` + subscriptionColumns + `,
+		       ts_rank(s.document, q.tsq)
+		         + GREATEST(
+		             word_similarity(q.raw, s.name),
+		             word_similarity(q.raw, COALESCE(s.category, '')),
+		             word_similarity(q.raw, s.tag_text)
+		           ) AS rank,
+		       ts_headline('simple', s.name, q.tsq, '` + headlineSelectors + `, HighlightAll=true'),
+		       ts_headline('simple', COALESCE(s.category, ''), q.tsq, '` + headlineSelectors + `, HighlightAll=true'),
+		       CASE WHEN COALESCE(s.notes, '') = '' THEN ''
+		            ELSE ts_headline('simple', s.notes, q.tsq, '` + headlineSelectors + `, MaxFragments=2, MaxWords=20, MinWords=5')
+		       END
+		FROM docs s, q
+		WHERE (
+			s.document @@ q.tsq
+			OR q.raw <% s.name
+			OR q.raw <% COALESCE(s.category, '')
+			OR q.raw <% s.tag_text
+		  )
+		ORDER BY rank DESC, s.name
+		LIMIT $4
+	`
+
+	rows, err := r.db.Query(query, userID, toPrefixTSQuery(q), q, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var results []SubscriptionSearchResult
+	for rows.Next() {
+		var res SubscriptionSearchResult
+		sub, err := scanSubscription(rows, &res.Rank, &res.NameHighlight, &res.CategoryHighlight, &res.NotesHighlight)
+		if err != nil {
+			return nil, err
+		}
+		res.NameHighlight = markHighlight(res.NameHighlight)
+		res.CategoryHighlight = markHighlight(res.CategoryHighlight)
+		res.NotesHighlight = markHighlight(res.NotesHighlight)
+		res.Subscription = *sub
+		results = append(results, res)
+	}
+
+	return results, rows.Err()
+}
+
+// Headlines are selected with private-use characters rather than <mark>
+// so the user's text can be HTML-escaped before the tags are put in.
+const (
+	headlineStart     = "\uE000"
+	headlineStop      = "\uE001"
+	headlineSelectors = "StartSel=" + headlineStart + ", StopSel=" + headlineStop
+)
+
+// markHighlight escapes a ts_headline fragment for HTML and wraps the
+// matched words in <mark>.
+func markHighlight(fragment string) string {
+	escaped := html.EscapeString(fragment)
+	escaped = strings.ReplaceAll(escaped, headlineStart, "<mark>")
+	return strings.ReplaceAll(escaped, headlineStop, "</mark>")
+}
+
+// toPrefixTSQuery turns free text into a tsquery where every word is
+// matched as a prefix, e.g. "spot prem" -> "spot:* & prem:*". Anything
+// that is not a letter or digit is dropped so user input can never
+// produce a tsquery syntax error.
+func toPrefixTSQuery(q string) string {
+	words := strings.FieldsFunc(strings.ToLower(q), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	terms := make([]string, 0, len(words))
+	for _, w := range words {
+		terms = append(terms, w+":*")
+	}
+
+	return strings.Join(terms, " & ")
+}
+
+// lockSubscription reads the subscription and locks its row until the
+// transaction q ends, whoever may access it.
+func lockSubscription(q queryer, id int) (*Subscription, error) {
+	return scanSubscription(q.QueryRow(`
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions s
+		WHERE s.id = $1
+		FOR UPDATE OF s
+	`, id))
+}
+
+// lockSubscriptions is lockSubscription for every subscription matching
+// where, which refers to the table as s.
+func lockSubscriptions(q queryer, where string, args ...any) ([]*Subscription, error) {
+	rows, err := q.Query(`
+		SELECT `+subscriptionColumns+`
+		FROM subscriptions s
+		WHERE `+where+`
+		ORDER BY s.id
+		FOR UPDATE OF s
+	`, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var subs []*Subscription
+	for rows.Next() {
+		sub, err := scanSubscription(rows)
+		if err != nil {
+			return nil, err
+		}
+		subs = append(subs, sub)
+	}
+
+	return subs, rows.Err()
+}
+
+// rewriteSubscriptions applies set to each of the locked subscriptions and
+// records it as updated by actor, for changes to a category or custom field
+// that reach every subscription using it. In set, $1 is the subscription
+// and args follow from $2.
+func rewriteSubscriptions(q queryer, actor Actor, locked []*Subscription, set string, args ...any) error {
+	query := `
+		UPDATE subscriptions s
+		SET ` + set + `, updated_at = CURRENT_TIMESTAMP
+		WHERE s.id = $1
+		RETURNING ` + subscriptionColumns
+
+	for _, before := range locked {
+		after, err := scanSubscription(q.QueryRow(query, append([]any{before.SubscriptionID}, args...)...))
+		if err != nil {
+			return err
+		}
+		if err := recordSubscriptionChange(q, actor, EventSubscriptionUpdated, before, after); err != nil {
+			return err
+		}
 	}
 
 	return nil
 }
+
+// recordSubscriptionChange stores the event and the audit entry for a
+// change in the transaction q that makes it. before is nil for a new
+// subscription and after for a deleted one.
+func recordSubscriptionChange(q queryer, actor Actor, event string, before, after *Subscription) error {
+	current := after
+	if current == nil {
+		current = before
+	}
+
+	err := recordEvent(q, AggregateSubscription, current.SubscriptionID, event, actor.UserID, current)
+	if err != nil {
+		return err
+	}
+
+	return recordAudit(q, actor, event, AuditEntitySubscription, current.SubscriptionID, current.WorkspaceID, before, after)
+}
@@ -15,17 +15,67 @@ func (m *subscriptionRepositoryMock) GetAll(userID int) ([]Subscription, error)
 	return args.Get(0).([]Subscription), args.Error(1)
 }
 
+func (m *subscriptionRepositoryMock) List(userID int, filter SubscriptionFilter) ([]Subscription, error) {
+	args := m.Called(userID, filter)
+	return args.Get(0).([]Subscription), args.Error(1)
+}
+
 func (m *subscriptionRepositoryMock) GetById(id int, userID int) (*Subscription, error) {
 	args := m.Called(id, userID)
 	return args.Get(0).(*Subscription), args.Error(1)
 }
 
-func (m *subscriptionRepositoryMock) Create(sub *Subscription, userID int) (*Subscription, error) {
-	args := m.Called(sub, userID)
+func (m *subscriptionRepositoryMock) Create(sub *Subscription, actor Actor) (*Subscription, error) {
+	args := m.Called(sub, actor)
 	return args.Get(0).(*Subscription), args.Error(1)
 }
 
-func (m *subscriptionRepositoryMock) Delete(id int, userID int) error {
-	args := m.Called(id, userID)
+func (m *subscriptionRepositoryMock) Update(sub *Subscription, actor Actor) (*Subscription, error) {
+	args := m.Called(sub, actor)
+	return args.Get(0).(*Subscription), args.Error(1)
+}
+
+func (m *subscriptionRepositoryMock) Delete(id int, actor Actor) error {
+	args := m.Called(id, actor)
 	return args.Error(0)
 }
+
+func (m *subscriptionRepositoryMock) Search(query string, userID int, limit int) ([]SubscriptionSearchResult, error) {
+	args := m.Called(query, userID, limit)
+	return args.Get(0).([]SubscriptionSearchResult), args.Error(1)
+}
+
+func (m *subscriptionRepositoryMock) Import(creates []Subscription, updates []Subscription, actor Actor) error {
+	args := m.Called(creates, updates, actor)
+	return args.Error(0)
+}
+
+func (m *subscriptionRepositoryMock) Each(userID int, fn func(Subscription) error) error {
+	args := m.Called(userID, fn)
+	for _, sub := range args.Get(0).([]Subscription) {
+		if err := fn(sub); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *subscriptionRepositoryMock) SetCustomFields(id int, actor Actor, fields string) error {
+	args := m.Called(id, actor, fields)
+	return args.Error(0)
+}
+
+func (m *subscriptionRepositoryMock) GetTrash(userID int) ([]TrashedSubscription, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]TrashedSubscription), args.Error(1)
+}
+
+func (m *subscriptionRepositoryMock) Restore(id int, actor Actor) (*Subscription, error) {
+	args := m.Called(id, actor)
+	return args.Get(0).(*Subscription), args.Error(1)
+}
+
+func (m *subscriptionRepositoryMock) Purge(retentionDays int, limit int) (int, error) {
+	args := m.Called(retentionDays, limit)
+	return args.Int(0), args.Error(1)
+}
@@ -0,0 +1,37 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateSubscription = errors.New("a subscription with this name already exists in the workspace")
+
+// Suggestion is a subscription proposed by the server, e.g. from recurring
+// bank statement charges, that the user still has to accept or reject.
+type Suggestion struct {
+	SuggestionID          int     `db:"id"`
+	Source                string  `db:"source"`
+	MerchantKey           string  `db:"merchant_key"`
+	Name                  string  `db:"name"`
+	Amount                float32 `db:"amount"`
+	Currency              string  `db:"currency"`
+	BillingCycle          string  `db:"billing_cycle"`
+	BillingDate           string  `db:"billing_date"`
+	Confidence            float64 `db:"confidence"`
+	AmountVariation       float64 `db:"amount_variation"`
+	MatchedSubscriptionID *int    `db:"matched_subscription_id"`
+	Evidence              string  `db:"evidence"` // JSON array
+	Status                string  `db:"status"`
+}
+
+type SuggestionRepository interface {
+	// Save stores a pending suggestion, replacing the pending one for the
+	// same source and merchant. It returns nil when the user has already
+	// rejected that merchant.
+	Save(s *Suggestion, userID int) (*Suggestion, error)
+	GetAll(userID int, status string) ([]Suggestion, error)
+	GetById(id int, userID int) (*Suggestion, error)
+	// Accept creates sub, or updates its amount, currency and billing date
+	// when it has an id, records the charges against it and marks the
+	// suggestion accepted, all in one transaction.
+	Accept(id int, actor Actor, sub *Subscription, charges []Charge) (*Subscription, error)
+	Reject(id int, userID int) error
+}
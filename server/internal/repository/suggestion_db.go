@@ -0,0 +1,249 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+type suggestionRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewSuggestionRepositoryDB(db *sql.DB) SuggestionRepository {
+	return suggestionRepositoryDB{db: db}
+}
+
+const suggestionColumns = `
+	id, source, merchant_key, name, amount, currency,
+	COALESCE(billing_cycle, ''), COALESCE(billing_date::text, ''),
+	confidence, amount_variation, matched_subscription_id, evidence, status
+`
+
+func scanSuggestion(row interface{ Scan(...any) error }) (*Suggestion, error) {
+	var s Suggestion
+	err := row.Scan(
+		&s.SuggestionID,
+		&s.Source,
+		&s.MerchantKey,
+		&s.Name,
+		&s.Amount,
+		&s.Currency,
+		&s.BillingCycle,
+		&s.BillingDate,
+		&s.Confidence,
+		&s.AmountVariation,
+		&s.MatchedSubscriptionID,
+		&s.Evidence,
+		&s.Status,
+	)
+	if err != nil {
+		return nil, err
+	}
+	return &s, nil
+}
+
+func (r suggestionRepositoryDB) Save(s *Suggestion, userID int) (*Suggestion, error) {
+	query := `
+		INSERT INTO subscription_suggestions
+		(user_id, source, merchant_key, name, amount, currency, billing_cycle, billing_date,
+		 confidence, amount_variation, matched_subscription_id, evidence)
+		SELECT $1::int, $2::text, $3::text, $4::text, $5::numeric, $6::text,
+		       NULLIF($7::text, ''), NULLIF($8::text, '')::date,
+		       $9::numeric, $10::numeric, $11::int, $12::jsonb
+		WHERE NOT EXISTS (
+			SELECT 1 FROM subscription_suggestions
+			WHERE user_id = $1 AND source = $2 AND merchant_key = $3 AND status = 'rejected'
+		)
+		ON CONFLICT (user_id, source, merchant_key) WHERE status = 'pending'
+		DO UPDATE SET
+			name = EXCLUDED.name,
+			amount = EXCLUDED.amount,
+			currency = EXCLUDED.currency,
+			billing_cycle = EXCLUDED.billing_cycle,
+			billing_date = EXCLUDED.billing_date,
+			confidence = EXCLUDED.confidence,
+			amount_variation = EXCLUDED.amount_variation,
+			matched_subscription_id = EXCLUDED.matched_subscription_id,
+			evidence = EXCLUDED.evidence,
+			updated_at = CURRENT_TIMESTAMP
+		RETURNING ` + suggestionColumns
+
+	saved, err := scanSuggestion(r.db.QueryRow(
+		query,
+		userID,
+		s.Source,
+		s.MerchantKey,
+		s.Name,
+		s.Amount,
+		s.Currency,
+		s.BillingCycle,
+		s.BillingDate,
+		s.Confidence,
+		s.AmountVariation,
+		s.MatchedSubscriptionID,
+		s.Evidence,
+	))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return saved, err
+}
+
+func (r suggestionRepositoryDB) GetAll(userID int, status string) ([]Suggestion, error) {
+	query := `
+		SELECT ` + suggestionColumns + `
+		FROM subscription_suggestions
+		WHERE user_id = $1 AND ($2 = '' OR status = $2)
+		ORDER BY created_at DESC, id DESC
+	`
+
+	rows, err := r.db.Query(query, userID, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var suggestions []Suggestion
+	for rows.Next() {
+		s, err := scanSuggestion(rows)
+		if err != nil {
+			return nil, err
+		}
+		suggestions = append(suggestions, *s)
+	}
+
+	return suggestions, rows.Err()
+}
+
+func (r suggestionRepositoryDB) GetById(id int, userID int) (*Suggestion, error) {
+	query := `
+		SELECT ` + suggestionColumns + `
+		FROM subscription_suggestions
+		WHERE id = $1 AND user_id = $2
+	`
+
+	s, err := scanSuggestion(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return s, err
+}
+
+func (r suggestionRepositoryDB) Accept(id int, actor Actor, sub *Subscription, charges []Charge) (*Subscription, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	result, err := tx.Exec(`
+		UPDATE subscription_suggestions
+		SET status = 'accepted', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND status = 'pending'
+	`, id, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if rows, err := result.RowsAffected(); err != nil {
+		return nil, err
+	} else if rows == 0 {
+		return nil, sql.ErrNoRows
+	}
+
+	if sub.SubscriptionID != 0 {
+		before, err := lockSubscription(tx, sub.SubscriptionID)
+		if err != nil {
+			return nil, err
+		}
+
+		after, err := scanSubscription(tx.QueryRow(`
+			UPDATE subscriptions s
+			SET amount = $1, currency = $2, billing_date = $3, updated_at = CURRENT_TIMESTAMP
+			WHERE s.id = $4 AND s.deleted_at IS NULL AND `+accessibleBy("$5", editRoles)+`
+			RETURNING `+subscriptionColumns,
+			sub.Amount, sub.Currency, sub.BillingDate, sub.SubscriptionID, actor.UserID,
+		))
+		if err != nil {
+			return nil, err
+		}
+		if err := recordSubscriptionChange(tx, actor, EventSubscriptionUpdated, before, after); err != nil {
+			return nil, err
+		}
+		sub = after
+	} else {
+		workspaceID, err := personalWorkspace(tx, actor.UserID)
+		if err != nil {
+			return nil, err
+		}
+		sub.WorkspaceID = &workspaceID
+
+		var taken bool
+		err = tx.QueryRow(`
+			SELECT EXISTS (
+				SELECT 1 FROM subscriptions
+				WHERE workspace_id = $1 AND name = $2 AND deleted_at IS NULL
+			)
+		`, workspaceID, sub.Name).Scan(&taken)
+		if err != nil {
+			return nil, err
+		}
+		if taken {
+			return nil, ErrDuplicateSubscription
+		}
+
+		if err := insertSubscription(tx, sub, actor.UserID); err != nil {
+			return nil, err
+		}
+		if err := recordSubscriptionChange(tx, actor, EventSubscriptionCreated, nil, sub); err != nil {
+			return nil, err
+		}
+	}
+
+	for _, charge := range charges {
+		_, err := tx.Exec(`
+			INSERT INTO subscription_charges
+			(user_id, subscription_id, charged_on, amount, currency, source)
+			VALUES ($1, $2, $3, $4, $5, $6)
+			ON CONFLICT (subscription_id, charged_on, amount) DO NOTHING
+		`, actor.UserID, sub.SubscriptionID, charge.ChargedOn, charge.Amount, charge.Currency, charge.Source)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = tx.Exec(`
+		UPDATE subscription_suggestions
+		SET matched_subscription_id = $1
+		WHERE id = $2
+	`, sub.SubscriptionID, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	return sub, nil
+}
+
+func (r suggestionRepositoryDB) Reject(id int, userID int) error {
+	result, err := r.db.Exec(`
+		UPDATE subscription_suggestions
+		SET status = 'rejected', updated_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND user_id = $2 AND status = 'pending'
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,36 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type suggestionRepositoryMock struct {
+	mock.Mock
+}
+
+func NewSuggestionRepositoryMock() *suggestionRepositoryMock {
+	return &suggestionRepositoryMock{}
+}
+
+func (m *suggestionRepositoryMock) Save(s *Suggestion, userID int) (*Suggestion, error) {
+	args := m.Called(s, userID)
+	return args.Get(0).(*Suggestion), args.Error(1)
+}
+
+func (m *suggestionRepositoryMock) GetAll(userID int, status string) ([]Suggestion, error) {
+	args := m.Called(userID, status)
+	return args.Get(0).([]Suggestion), args.Error(1)
+}
+
+func (m *suggestionRepositoryMock) GetById(id int, userID int) (*Suggestion, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*Suggestion), args.Error(1)
+}
+
+func (m *suggestionRepositoryMock) Accept(id int, actor Actor, sub *Subscription, charges []Charge) (*Subscription, error) {
+	args := m.Called(id, actor, sub, charges)
+	return args.Get(0).(*Subscription), args.Error(1)
+}
+
+func (m *suggestionRepositoryMock) Reject(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,20 @@
+package repository
+
+import "errors"
+
+var ErrDuplicateTag = errors.New("a tag with this name already exists")
+
+type Tag struct {
+	TagID         int    `db:"id"`
+	Name          string `db:"name"`
+	Color         string `db:"color"`
+	Subscriptions int    `db:"subscriptions"` // number of subscriptions carrying the tag
+}
+
+type TagRepository interface {
+	GetAll(userID int) ([]Tag, error)
+	Create(t *Tag, userID int) (*Tag, error)
+	Update(t *Tag, userID int) (*Tag, error)
+	// Delete removes the tag from every subscription carrying it.
+	Delete(id int, userID int) error
+}
@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"database/sql"
+)
+
+type tagRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewTagRepositoryDB(db *sql.DB) TagRepository {
+	return tagRepositoryDB{db: db}
+}
+
+func (r tagRepositoryDB) GetAll(userID int) ([]Tag, error) {
+	query := `
+		SELECT t.id, t.name, COALESCE(t.color, ''), count(st.subscription_id)
+		FROM tags t
+		LEFT JOIN subscription_tags st ON st.tag_id = t.id
+		AND EXISTS (SELECT 1 FROM subscriptions s WHERE s.id = st.subscription_id AND s.deleted_at IS NULL)
+		WHERE t.user_id = $1
+		GROUP BY t.id
+		ORDER BY lower(t.name)
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var tags []Tag
+	for rows.Next() {
+		var t Tag
+		if err := rows.Scan(&t.TagID, &t.Name, &t.Color, &t.Subscriptions); err != nil {
+			return nil, err
+		}
+		tags = append(tags, t)
+	}
+
+	return tags, rows.Err()
+}
+
+func (r tagRepositoryDB) Create(t *Tag, userID int) (*Tag, error) {
+	query := `
+		INSERT INTO tags (user_id, name, color)
+		VALUES ($1, $2, NULLIF($3, ''))
+		ON CONFLICT (user_id, (lower(name))) DO NOTHING
+		RETURNING id
+	`
+
+	err := r.db.QueryRow(query, userID, t.Name, t.Color).Scan(&t.TagID)
+	if err == sql.ErrNoRows {
+		return nil, ErrDuplicateTag
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r tagRepositoryDB) Update(t *Tag, userID int) (*Tag, error) {
+	var exists bool
+	err := r.db.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM tags
+			WHERE user_id = $1 AND lower(name) = lower($2) AND id <> $3
+		)
+	`, userID, t.Name, t.TagID).Scan(&exists)
+	if err != nil {
+		return nil, err
+	}
+	if exists {
+		return nil, ErrDuplicateTag
+	}
+
+	query := `
+		UPDATE tags t
+		SET name = $1, color = NULLIF($2, '')
+		WHERE t.id = $3 AND t.user_id = $4
+		RETURNING (
+			SELECT count(*)
+			FROM subscription_tags st
+			JOIN subscriptions s ON s.id = st.subscription_id
+			WHERE st.tag_id = t.id AND s.deleted_at IS NULL
+		)
+	`
+
+	err = r.db.QueryRow(query, t.Name, t.Color, t.TagID, userID).Scan(&t.Subscriptions)
+	if err != nil {
+		return nil, err
+	}
+
+	return t, nil
+}
+
+func (r tagRepositoryDB) Delete(id int, userID int) error {
+	query := `
+		DELETE FROM tags
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
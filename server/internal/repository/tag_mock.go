@@ -0,0 +1,31 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type tagRepositoryMock struct {
+	mock.Mock
+}
+
+func NewTagRepositoryMock() *tagRepositoryMock {
+	return &tagRepositoryMock{}
+}
+
+func (m *tagRepositoryMock) GetAll(userID int) ([]Tag, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Tag), args.Error(1)
+}
+
+func (m *tagRepositoryMock) Create(t *Tag, userID int) (*Tag, error) {
+	args := m.Called(t, userID)
+	return args.Get(0).(*Tag), args.Error(1)
+}
+
+func (m *tagRepositoryMock) Update(t *Tag, userID int) (*Tag, error) {
+	args := m.Called(t, userID)
+	return args.Get(0).(*Tag), args.Error(1)
+}
+
+func (m *tagRepositoryMock) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
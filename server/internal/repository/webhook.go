@@ -0,0 +1,67 @@
+package repository
+
+import "time"
+
+// Webhook is an endpoint a user registered to receive events. Events lists
+// the event types it subscribes to; empty means every event.
+type Webhook struct {
+	WebhookID int      `db:"id"`
+	URL       string   `db:"url"`
+	Secret    string   `db:"secret"`
+	Events    []string `db:"events"`
+	Active    bool     `db:"active"`
+	CreatedAt string   `db:"created_at"`
+}
+
+// WebhookDelivery is one event queued for a webhook. Pending deliveries
+// are retried at NextAttemptAt until they are delivered or fail for good.
+type WebhookDelivery struct {
+	DeliveryID     int     `db:"id"`
+	WebhookID      int     `db:"webhook_id"`
+	Event          string  `db:"event"`
+	Payload        string  `db:"payload"`
+	Status         string  `db:"status"` // pending, delivered, failed
+	Attempts       int     `db:"attempts"`
+	NextAttemptAt  *string `db:"next_attempt_at"`
+	ResponseStatus *int    `db:"response_status"`
+	LastError      string  `db:"last_error"`
+	DeliveredAt    *string `db:"delivered_at"`
+	CreatedAt      string  `db:"created_at"`
+}
+
+// DueDelivery is a delivery claimed for sending, with the endpoint it goes
+// to.
+type DueDelivery struct {
+	WebhookDelivery
+	URL    string `db:"url"`
+	Secret string `db:"secret"`
+}
+
+type WebhookRepository interface {
+	GetAll(userID int) ([]Webhook, error)
+	GetById(id int, userID int) (*Webhook, error)
+	Create(w *Webhook, userID int) (*Webhook, error)
+	// Update changes the URL, events and active flag; the secret is kept.
+	Update(w *Webhook, userID int) (*Webhook, error)
+	Delete(id int, userID int) error
+	// GetUserIDs lists the users with at least one active webhook.
+	GetUserIDs() ([]int, error)
+
+	// Enqueue queues payload for every active webhook of the user that
+	// subscribes to event and returns how many deliveries were queued. A
+	// webhook gets at most one delivery per non-empty key.
+	Enqueue(userID int, event string, key string, payload string) (int, error)
+	// EnqueueTo queues payload for one webhook, active or not.
+	EnqueueTo(webhookID int, event string, payload string, userID int) (*WebhookDelivery, error)
+	// Redeliver queues a new delivery with the payload of an earlier one.
+	Redeliver(deliveryID int, webhookID int, userID int) (*WebhookDelivery, error)
+	GetDeliveries(webhookID int, userID int, limit int) ([]WebhookDelivery, error)
+
+	// ClaimDue picks up to limit pending deliveries that are due and moves
+	// their next attempt lease into the future, so a concurrent sender skips
+	// them and a crashed one has them retried.
+	ClaimDue(limit int, lease time.Duration) ([]DueDelivery, error)
+	// RecordAttempt stores the outcome of sending d: its status, attempt
+	// count and last response. A pending delivery is retried after retryIn.
+	RecordAttempt(d WebhookDelivery, retryIn time.Duration) error
+}
@@ -0,0 +1,335 @@
+package repository
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+)
+
+type webhookRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewWebhookRepositoryDB(db *sql.DB) WebhookRepository {
+	return webhookRepositoryDB{db: db}
+}
+
+const webhookColumns = `w.id, w.url, w.secret, w.events, w.active, w.created_at`
+
+const webhookDeliveryColumns = `
+	d.id, d.webhook_id, d.event, d.payload, d.status, d.attempts, d.next_attempt_at,
+	d.response_status, COALESCE(d.last_error, ''), d.delivered_at, d.created_at
+`
+
+func scanWebhook(row scanner) (*Webhook, error) {
+	var w Webhook
+	var events pq.StringArray
+
+	err := row.Scan(&w.WebhookID, &w.URL, &w.Secret, &events, &w.Active, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	w.Events = []string(events)
+	return &w, nil
+}
+
+func scanWebhookDelivery(row scanner, extra ...any) (*WebhookDelivery, error) {
+	var d WebhookDelivery
+	dest := append([]any{
+		&d.DeliveryID,
+		&d.WebhookID,
+		&d.Event,
+		&d.Payload,
+		&d.Status,
+		&d.Attempts,
+		&d.NextAttemptAt,
+		&d.ResponseStatus,
+		&d.LastError,
+		&d.DeliveredAt,
+		&d.CreatedAt,
+	}, extra...)
+
+	if err := row.Scan(dest...); err != nil {
+		return nil, err
+	}
+	return &d, nil
+}
+
+func (r webhookRepositoryDB) GetAll(userID int) ([]Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks w
+		WHERE w.user_id = $1
+		ORDER BY w.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var webhooks []Webhook
+	for rows.Next() {
+		w, err := scanWebhook(rows)
+		if err != nil {
+			return nil, err
+		}
+		webhooks = append(webhooks, *w)
+	}
+
+	return webhooks, rows.Err()
+}
+
+func (r webhookRepositoryDB) GetById(id int, userID int) (*Webhook, error) {
+	query := `
+		SELECT ` + webhookColumns + `
+		FROM webhooks w
+		WHERE w.id = $1 AND w.user_id = $2
+	`
+
+	w, err := scanWebhook(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r webhookRepositoryDB) Create(w *Webhook, userID int) (*Webhook, error) {
+	query := `
+		INSERT INTO webhooks (user_id, url, secret, events, active)
+		VALUES ($1, $2, $3, $4, $5)
+		RETURNING id, created_at
+	`
+
+	err := r.db.QueryRow(query, userID, w.URL, w.Secret, pq.StringArray(w.Events), w.Active).
+		Scan(&w.WebhookID, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r webhookRepositoryDB) Update(w *Webhook, userID int) (*Webhook, error) {
+	query := `
+		UPDATE webhooks
+		SET url = $1, events = $2, active = $3, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $4 AND user_id = $5
+		RETURNING secret, created_at
+	`
+
+	err := r.db.QueryRow(query, w.URL, pq.StringArray(w.Events), w.Active, w.WebhookID, userID).
+		Scan(&w.Secret, &w.CreatedAt)
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r webhookRepositoryDB) Delete(id int, userID int) error {
+	query := `
+		DELETE FROM webhooks
+		WHERE id = $1 AND user_id = $2
+	`
+
+	result, err := r.db.Exec(query, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r webhookRepositoryDB) GetUserIDs() ([]int, error) {
+	rows, err := r.db.Query(`
+		SELECT DISTINCT user_id
+		FROM webhooks
+		WHERE active
+		ORDER BY user_id
+	`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var ids []int
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids = append(ids, id)
+	}
+
+	return ids, rows.Err()
+}
+
+func (r webhookRepositoryDB) Enqueue(userID int, event string, key string, payload string) (int, error) {
+	query := `
+		INSERT INTO webhook_deliveries (webhook_id, event, dedupe_key, payload)
+		SELECT w.id, $2, NULLIF($3, ''), $4
+		FROM webhooks w
+		WHERE w.user_id = $1 AND w.active
+		AND (cardinality(w.events) = 0 OR $2 = ANY(w.events))
+		ON CONFLICT DO NOTHING
+	`
+
+	result, err := r.db.Exec(query, userID, event, key, payload)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(rows), nil
+}
+
+func (r webhookRepositoryDB) EnqueueTo(webhookID int, event string, payload string, userID int) (*WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries AS d (webhook_id, event, payload)
+		SELECT w.id, $2, $3
+		FROM webhooks w
+		WHERE w.id = $1 AND w.user_id = $4
+		RETURNING ` + webhookDeliveryColumns
+
+	d, err := scanWebhookDelivery(r.db.QueryRow(query, webhookID, event, payload, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (r webhookRepositoryDB) Redeliver(deliveryID int, webhookID int, userID int) (*WebhookDelivery, error) {
+	query := `
+		INSERT INTO webhook_deliveries AS d (webhook_id, event, payload)
+		SELECT prev.webhook_id, prev.event, prev.payload
+		FROM webhook_deliveries prev
+		JOIN webhooks w ON w.id = prev.webhook_id
+		WHERE prev.id = $1 AND w.id = $2 AND w.user_id = $3
+		RETURNING ` + webhookDeliveryColumns
+
+	d, err := scanWebhookDelivery(r.db.QueryRow(query, deliveryID, webhookID, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+func (r webhookRepositoryDB) GetDeliveries(webhookID int, userID int, limit int) ([]WebhookDelivery, error) {
+	query := `
+		SELECT ` + webhookDeliveryColumns + `
+		FROM webhook_deliveries d
+		JOIN webhooks w ON w.id = d.webhook_id
+		WHERE d.webhook_id = $1 AND w.user_id = $2
+		ORDER BY d.id DESC
+		LIMIT $3
+	`
+
+	rows, err := r.db.Query(query, webhookID, userID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var deliveries []WebhookDelivery
+	for rows.Next() {
+		d, err := scanWebhookDelivery(rows)
+		if err != nil {
+			return nil, err
+		}
+		deliveries = append(deliveries, *d)
+	}
+
+	return deliveries, rows.Err()
+}
+
+func (r webhookRepositoryDB) ClaimDue(limit int, lease time.Duration) ([]DueDelivery, error) {
+	query := `
+		UPDATE webhook_deliveries d
+		SET next_attempt_at = CURRENT_TIMESTAMP + make_interval(secs => $2)
+		FROM webhooks w
+		WHERE w.id = d.webhook_id
+		AND d.id IN (
+			SELECT due.id
+			FROM webhook_deliveries due
+			JOIN webhooks hook ON hook.id = due.webhook_id
+			WHERE due.status = 'pending' AND due.next_attempt_at <= CURRENT_TIMESTAMP
+			AND hook.active
+			ORDER BY due.next_attempt_at, due.id
+			LIMIT $1
+			FOR UPDATE OF due SKIP LOCKED
+		)
+		RETURNING ` + webhookDeliveryColumns + `, w.url, w.secret
+	`
+
+	rows, err := r.db.Query(query, limit, lease.Seconds())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var due []DueDelivery
+	for rows.Next() {
+		var url, secret string
+		d, err := scanWebhookDelivery(rows, &url, &secret)
+		if err != nil {
+			return nil, err
+		}
+		due = append(due, DueDelivery{WebhookDelivery: *d, URL: url, Secret: secret})
+	}
+
+	return due, rows.Err()
+}
+
+func (r webhookRepositoryDB) RecordAttempt(d WebhookDelivery, retryIn time.Duration) error {
+	query := `
+		UPDATE webhook_deliveries
+		SET status = $1, attempts = $2,
+		    next_attempt_at = CASE WHEN $1 = 'pending' THEN CURRENT_TIMESTAMP + make_interval(secs => $3) END,
+		    response_status = $4, last_error = NULLIF($5, ''),
+		    delivered_at = CASE WHEN $1 = 'delivered' THEN CURRENT_TIMESTAMP END
+		WHERE id = $6
+	`
+
+	result, err := r.db.Exec(query, d.Status, d.Attempts, retryIn.Seconds(), d.ResponseStatus, d.LastError, d.DeliveryID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
@@ -0,0 +1,75 @@
+package repository
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/mock"
+)
+
+type webhookRepositoryMock struct {
+	mock.Mock
+}
+
+func NewWebhookRepositoryMock() *webhookRepositoryMock {
+	return &webhookRepositoryMock{}
+}
+
+func (m *webhookRepositoryMock) GetAll(userID int) ([]Webhook, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Webhook), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) GetById(id int, userID int) (*Webhook, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*Webhook), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) Create(w *Webhook, userID int) (*Webhook, error) {
+	args := m.Called(w, userID)
+	return args.Get(0).(*Webhook), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) Update(w *Webhook, userID int) (*Webhook, error) {
+	args := m.Called(w, userID)
+	return args.Get(0).(*Webhook), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) Delete(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *webhookRepositoryMock) GetUserIDs() ([]int, error) {
+	args := m.Called()
+	return args.Get(0).([]int), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) Enqueue(userID int, event string, key string, payload string) (int, error) {
+	args := m.Called(userID, event, key, payload)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) EnqueueTo(webhookID int, event string, payload string, userID int) (*WebhookDelivery, error) {
+	args := m.Called(webhookID, event, payload, userID)
+	return args.Get(0).(*WebhookDelivery), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) Redeliver(deliveryID int, webhookID int, userID int) (*WebhookDelivery, error) {
+	args := m.Called(deliveryID, webhookID, userID)
+	return args.Get(0).(*WebhookDelivery), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) GetDeliveries(webhookID int, userID int, limit int) ([]WebhookDelivery, error) {
+	args := m.Called(webhookID, userID, limit)
+	return args.Get(0).([]WebhookDelivery), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) ClaimDue(limit int, lease time.Duration) ([]DueDelivery, error) {
+	args := m.Called(limit, lease)
+	return args.Get(0).([]DueDelivery), args.Error(1)
+}
+
+func (m *webhookRepositoryMock) RecordAttempt(d WebhookDelivery, retryIn time.Duration) error {
+	args := m.Called(d, retryIn)
+	return args.Error(0)
+}
@@ -0,0 +1,64 @@
+package repository
+
+import "errors"
+
+var (
+	ErrWorkspaceAccessDenied = errors.New("you cannot add subscriptions to this workspace")
+	ErrWorkspaceNotEmpty     = errors.New("the workspace still has subscriptions")
+)
+
+// Workspace groups subscriptions that several users can see. Every user
+// also has a personal workspace that only they belong to.
+type Workspace struct {
+	WorkspaceID int    `db:"id"`
+	Name        string `db:"name"`
+	Personal    bool   `db:"personal"`
+	Role        string `db:"role"` // the requesting user's role
+	Members     int    `db:"members"`
+}
+
+type WorkspaceMember struct {
+	UserID int    `db:"user_id"`
+	Name   string `db:"name"`
+	Email  string `db:"email"`
+	Role   string `db:"role"` // owner, admin, editor, viewer
+}
+
+// WorkspaceInvitation lets whoever holds the token join the workspace with
+// Role, provided they sign in with Email. Only a hash of the token is
+// stored.
+type WorkspaceInvitation struct {
+	InvitationID  int    `db:"id"`
+	WorkspaceID   int    `db:"workspace_id"`
+	WorkspaceName string `db:"workspace_name"`
+	Email         string `db:"email"`
+	Role          string `db:"role"`
+	InvitedBy     int    `db:"invited_by"`
+	ExpiresAt     string `db:"expires_at"`
+}
+
+type WorkspaceRepository interface {
+	// GetAll lists the user's workspaces, creating their personal
+	// workspace on first use.
+	GetAll(userID int) ([]Workspace, error)
+	// GetById returns the workspace with the user's role in it, or nil
+	// when the user is not a member.
+	GetById(id int, userID int) (*Workspace, error)
+	// Create makes the user the owner of a new workspace.
+	Create(w *Workspace, userID int) (*Workspace, error)
+	Rename(id int, name string) error
+	// Delete refuses while the workspace holds subscriptions, and moves
+	// those in the trash to their creator's personal workspace, recording
+	// each as updated by actor.
+	Delete(id int, actor Actor) error
+	GetMembers(id int) ([]WorkspaceMember, error)
+	SetMemberRole(id int, userID int, role string) error
+	RemoveMember(id int, userID int) error
+	CreateInvitation(inv *WorkspaceInvitation, tokenHash string) (*WorkspaceInvitation, error)
+	// GetInvitationByTokenHash returns a pending, unexpired invitation, or
+	// nil when there is none.
+	GetInvitationByTokenHash(hash string) (*WorkspaceInvitation, error)
+	// AcceptInvitation marks the invitation used and adds the user with its
+	// role. Someone who is already a member keeps their current role.
+	AcceptInvitation(id int, userID int) error
+}
@@ -0,0 +1,348 @@
+package repository
+
+import "database/sql"
+
+type workspaceRepositoryDB struct {
+	db *sql.DB
+}
+
+func NewWorkspaceRepositoryDB(db *sql.DB) WorkspaceRepository {
+	return workspaceRepositoryDB{db: db}
+}
+
+const personalWorkspaceName = "Personal"
+
+// workspaceColumns reads a workspace together with the role of the member
+// joined as wm.
+const workspaceColumns = `
+	w.id, w.name, w.personal, wm.role,
+	(SELECT count(*) FROM workspace_members m WHERE m.workspace_id = w.id)
+`
+
+func scanWorkspace(row scanner) (*Workspace, error) {
+	var w Workspace
+	err := row.Scan(&w.WorkspaceID, &w.Name, &w.Personal, &w.Role, &w.Members)
+	if err != nil {
+		return nil, err
+	}
+	return &w, nil
+}
+
+// personalWorkspace returns the id of the user's personal workspace,
+// creating it, with the user as owner, when it does not exist yet.
+func personalWorkspace(q queryer, userID int) (int, error) {
+	var id int
+	err := q.QueryRow(`
+		WITH w AS (
+			INSERT INTO workspaces (name, personal, created_by)
+			VALUES ($2, TRUE, $1)
+			ON CONFLICT (created_by) WHERE personal DO UPDATE SET name = workspaces.name
+			RETURNING id
+		)
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		SELECT id, $1, 'owner' FROM w
+		ON CONFLICT (workspace_id, user_id) DO UPDATE SET role = workspace_members.role
+		RETURNING workspace_id
+	`, userID, personalWorkspaceName).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+func (r workspaceRepositoryDB) GetAll(userID int) ([]Workspace, error) {
+	if _, err := personalWorkspace(r.db, userID); err != nil {
+		return nil, err
+	}
+
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members wm ON wm.workspace_id = w.id
+		WHERE wm.user_id = $1
+		ORDER BY w.personal DESC, lower(w.name), w.id
+	`
+
+	rows, err := r.db.Query(query, userID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var workspaces []Workspace
+	for rows.Next() {
+		w, err := scanWorkspace(rows)
+		if err != nil {
+			return nil, err
+		}
+		workspaces = append(workspaces, *w)
+	}
+
+	return workspaces, rows.Err()
+}
+
+func (r workspaceRepositoryDB) GetById(id int, userID int) (*Workspace, error) {
+	query := `
+		SELECT ` + workspaceColumns + `
+		FROM workspaces w
+		JOIN workspace_members wm ON wm.workspace_id = w.id
+		WHERE w.id = $1 AND wm.user_id = $2
+	`
+
+	w, err := scanWorkspace(r.db.QueryRow(query, id, userID))
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return w, nil
+}
+
+func (r workspaceRepositoryDB) Create(w *Workspace, userID int) (*Workspace, error) {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	err = tx.QueryRow(`
+		INSERT INTO workspaces (name, created_by)
+		VALUES ($1, $2)
+		RETURNING id
+	`, w.Name, userID).Scan(&w.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, 'owner')
+	`, w.WorkspaceID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return nil, err
+	}
+
+	w.Role = "owner"
+	w.Members = 1
+	return w, nil
+}
+
+func (r workspaceRepositoryDB) Rename(id int, name string) error {
+	result, err := r.db.Exec(`
+		UPDATE workspaces
+		SET name = $1, updated_at = CURRENT_TIMESTAMP
+		WHERE id = $2
+	`, name, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r workspaceRepositoryDB) Delete(id int, actor Actor) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var inUse bool
+	err = tx.QueryRow(`
+		SELECT EXISTS (
+			SELECT 1 FROM subscriptions
+			WHERE workspace_id = $1 AND deleted_at IS NULL
+		)
+	`, id).Scan(&inUse)
+	if err != nil {
+		return err
+	}
+	if inUse {
+		return ErrWorkspaceNotEmpty
+	}
+
+	trashed, err := lockSubscriptions(tx, "s.workspace_id = $1", id)
+	if err != nil {
+		return err
+	}
+	err = rewriteSubscriptions(tx, actor, trashed, `workspace_id = (
+		SELECT w.id FROM workspaces w
+		WHERE w.personal AND w.created_by = s.user_id
+	)`)
+	if err != nil {
+		return err
+	}
+
+	result, err := tx.Exec(`
+		DELETE FROM workspaces
+		WHERE id = $1 AND NOT personal
+	`, id)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return tx.Commit()
+}
+
+func (r workspaceRepositoryDB) GetMembers(id int) ([]WorkspaceMember, error) {
+	query := `
+		SELECT u.id, u.name, u.email, wm.role
+		FROM workspace_members wm
+		JOIN users u ON u.id = wm.user_id
+		WHERE wm.workspace_id = $1
+		ORDER BY lower(u.name), u.id
+	`
+
+	rows, err := r.db.Query(query, id)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var members []WorkspaceMember
+	for rows.Next() {
+		var m WorkspaceMember
+		if err := rows.Scan(&m.UserID, &m.Name, &m.Email, &m.Role); err != nil {
+			return nil, err
+		}
+		members = append(members, m)
+	}
+
+	return members, rows.Err()
+}
+
+func (r workspaceRepositoryDB) SetMemberRole(id int, userID int, role string) error {
+	result, err := r.db.Exec(`
+		UPDATE workspace_members
+		SET role = $1
+		WHERE workspace_id = $2 AND user_id = $3
+	`, role, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r workspaceRepositoryDB) RemoveMember(id int, userID int) error {
+	result, err := r.db.Exec(`
+		DELETE FROM workspace_members
+		WHERE workspace_id = $1 AND user_id = $2
+	`, id, userID)
+	if err != nil {
+		return err
+	}
+
+	rows, err := result.RowsAffected()
+	if err != nil {
+		return err
+	}
+	if rows == 0 {
+		return sql.ErrNoRows
+	}
+
+	return nil
+}
+
+func (r workspaceRepositoryDB) CreateInvitation(inv *WorkspaceInvitation, tokenHash string) (*WorkspaceInvitation, error) {
+	err := r.db.QueryRow(`
+		INSERT INTO workspace_invitations (workspace_id, email, role, token_hash, invited_by, expires_at)
+		VALUES ($1, $2, $3, $4, $5, $6)
+		RETURNING id
+	`, inv.WorkspaceID, inv.Email, inv.Role, tokenHash, inv.InvitedBy, inv.ExpiresAt).Scan(&inv.InvitationID)
+	if err != nil {
+		return nil, err
+	}
+
+	return inv, nil
+}
+
+func (r workspaceRepositoryDB) GetInvitationByTokenHash(hash string) (*WorkspaceInvitation, error) {
+	var inv WorkspaceInvitation
+	err := r.db.QueryRow(`
+		SELECT i.id, i.workspace_id, w.name, i.email, i.role, COALESCE(i.invited_by, 0), i.expires_at
+		FROM workspace_invitations i
+		JOIN workspaces w ON w.id = i.workspace_id
+		WHERE i.token_hash = $1
+		AND i.accepted_at IS NULL
+		AND i.expires_at > CURRENT_TIMESTAMP
+	`, hash).Scan(
+		&inv.InvitationID,
+		&inv.WorkspaceID,
+		&inv.WorkspaceName,
+		&inv.Email,
+		&inv.Role,
+		&inv.InvitedBy,
+		&inv.ExpiresAt,
+	)
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return &inv, nil
+}
+
+func (r workspaceRepositoryDB) AcceptInvitation(id int, userID int) error {
+	tx, err := r.db.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	var workspaceID int
+	var role string
+	err = tx.QueryRow(`
+		UPDATE workspace_invitations
+		SET accepted_at = CURRENT_TIMESTAMP
+		WHERE id = $1 AND accepted_at IS NULL AND expires_at > CURRENT_TIMESTAMP
+		RETURNING workspace_id, role
+	`, id).Scan(&workspaceID, &role)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Exec(`
+		INSERT INTO workspace_members (workspace_id, user_id, role)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (workspace_id, user_id) DO NOTHING
+	`, workspaceID, userID, role)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
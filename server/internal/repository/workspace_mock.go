@@ -0,0 +1,66 @@
+package repository
+
+import "github.com/stretchr/testify/mock"
+
+type workspaceRepositoryMock struct {
+	mock.Mock
+}
+
+func NewWorkspaceRepositoryMock() *workspaceRepositoryMock {
+	return &workspaceRepositoryMock{}
+}
+
+func (m *workspaceRepositoryMock) GetAll(userID int) ([]Workspace, error) {
+	args := m.Called(userID)
+	return args.Get(0).([]Workspace), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) GetById(id int, userID int) (*Workspace, error) {
+	args := m.Called(id, userID)
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) Create(w *Workspace, userID int) (*Workspace, error) {
+	args := m.Called(w, userID)
+	return args.Get(0).(*Workspace), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) Rename(id int, name string) error {
+	args := m.Called(id, name)
+	return args.Error(0)
+}
+
+func (m *workspaceRepositoryMock) Delete(id int, actor Actor) error {
+	args := m.Called(id, actor)
+	return args.Error(0)
+}
+
+func (m *workspaceRepositoryMock) GetMembers(id int) ([]WorkspaceMember, error) {
+	args := m.Called(id)
+	return args.Get(0).([]WorkspaceMember), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) SetMemberRole(id int, userID int, role string) error {
+	args := m.Called(id, userID, role)
+	return args.Error(0)
+}
+
+func (m *workspaceRepositoryMock) RemoveMember(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
+
+func (m *workspaceRepositoryMock) CreateInvitation(inv *WorkspaceInvitation, tokenHash string) (*WorkspaceInvitation, error) {
+	args := m.Called(inv, tokenHash)
+	return args.Get(0).(*WorkspaceInvitation), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) GetInvitationByTokenHash(hash string) (*WorkspaceInvitation, error) {
+	args := m.Called(hash)
+	return args.Get(0).(*WorkspaceInvitation), args.Error(1)
+}
+
+func (m *workspaceRepositoryMock) AcceptInvitation(id int, userID int) error {
+	args := m.Called(id, userID)
+	return args.Error(0)
+}
@@ -0,0 +1,38 @@
+package service
+
+import "encoding/json"
+
+// AuditFilter narrows GetAudit; zero values do not filter. From and To are
+// YYYY-MM-DD dates, both included. Before pages back from an entry id.
+type AuditFilter struct {
+	EntityType string
+	EntityID   *int
+	Action     string
+	ActorID    *int
+	From       string
+	To         string
+	Before     int
+	Limit      int
+}
+
+// AuditEntryResponse is one change. Changes maps each field that changed
+// to its before and after value; secrets are redacted.
+type AuditEntryResponse struct {
+	EntryID     int             `json:"id"`
+	ActorID     *int            `json:"actor_id"`
+	ActorType   string          `json:"actor_type"` // user or system
+	IP          string          `json:"ip,omitempty"`
+	UserAgent   string          `json:"user_agent,omitempty"`
+	Action      string          `json:"action"`
+	EntityType  string          `json:"entity_type"`
+	EntityID    int             `json:"entity_id"`
+	WorkspaceID *int            `json:"workspace_id"`
+	Changes     json.RawMessage `json:"changes"`
+	CreatedAt   string          `json:"created_at"`
+}
+
+type AuditService interface {
+	// GetAudit returns the newest changes the user may see: their own, and
+	// those in workspaces they own or administer.
+	GetAudit(userID int, filter AuditFilter) ([]AuditEntryResponse, error)
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+var ErrInvalidAuditFilter = errors.New("invalid audit filter")
+
+const (
+	defaultAuditLimit = 50
+	maxAuditLimit     = 200
+)
+
+type auditService struct {
+	auditRepo repository.AuditRepository
+}
+
+func NewAuditService(auditRepo repository.AuditRepository) AuditService {
+	return auditService{auditRepo: auditRepo}
+}
+
+func (s auditService) GetAudit(userID int, filter AuditFilter) ([]AuditEntryResponse, error) {
+	switch filter.EntityType {
+	case "", repository.AuditEntitySubscription, repository.AuditEntityShare, repository.AuditEntityUser:
+	default:
+		return nil, fmt.Errorf("%w: unknown entity_type %q", ErrInvalidAuditFilter, filter.EntityType)
+	}
+
+	var from, to time.Time
+	var err error
+	if filter.From != "" {
+		if from, err = time.Parse(dateLayout, filter.From); err != nil {
+			return nil, fmt.Errorf("%w: from must be YYYY-MM-DD", ErrInvalidAuditFilter)
+		}
+	}
+	if filter.To != "" {
+		if to, err = time.Parse(dateLayout, filter.To); err != nil {
+			return nil, fmt.Errorf("%w: to must be YYYY-MM-DD", ErrInvalidAuditFilter)
+		}
+	}
+	if filter.From != "" && filter.To != "" && to.Before(from) {
+		return nil, fmt.Errorf("%w: to is before from", ErrInvalidAuditFilter)
+	}
+
+	limit := filter.Limit
+	if limit == 0 {
+		limit = defaultAuditLimit
+	}
+	if limit < 0 || limit > maxAuditLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidAuditFilter, maxAuditLimit)
+	}
+
+	entries, err := s.auditRepo.List(userID, repository.AuditFilter{
+		EntityType: filter.EntityType,
+		EntityID:   filter.EntityID,
+		Action:     filter.Action,
+		ActorID:    filter.ActorID,
+		From:       filter.From,
+		To:         filter.To,
+		BeforeID:   filter.Before,
+		Limit:      limit,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	res := []AuditEntryResponse{}
+	for _, e := range entries {
+		res = append(res, AuditEntryResponse{
+			EntryID:     e.EntryID,
+			ActorID:     e.ActorID,
+			ActorType:   e.ActorType,
+			IP:          e.IP,
+			UserAgent:   e.UserAgent,
+			Action:      e.Action,
+			EntityType:  e.EntityType,
+			EntityID:    e.EntityID,
+			WorkspaceID: e.WorkspaceID,
+			Changes:     json.RawMessage(e.Changes),
+			CreatedAt:   e.CreatedAt,
+		})
+	}
+
+	return res, nil
+}
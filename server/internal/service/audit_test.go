@@ -0,0 +1,105 @@
+package service_test
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetAudit(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// arrange
+		auditRepo := repository.NewAuditRepositoryMock()
+		subscriptionID := 3
+		actorID := 10
+
+		auditRepo.
+			On("List", 10, repository.AuditFilter{
+				EntityType: "subscription",
+				EntityID:   &subscriptionID,
+				From:       "2026-01-01",
+				To:         "2026-01-31",
+				Limit:      50,
+			}).
+			Return([]repository.AuditEntry{{
+				EntryID:    7,
+				ActorID:    &actorID,
+				ActorType:  "user",
+				IP:         "203.0.113.7",
+				Action:     repository.EventSubscriptionUpdated,
+				EntityType: "subscription",
+				EntityID:   3,
+				Changes:    `{"amount": {"after": 129, "before": 99}}`,
+			}}, nil)
+
+		svc := service.NewAuditService(auditRepo)
+
+		// act
+		res, err := svc.GetAudit(10, service.AuditFilter{
+			EntityType: "subscription",
+			EntityID:   &subscriptionID,
+			From:       "2026-01-01",
+			To:         "2026-01-31",
+		})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "subscription.updated", res[0].Action)
+		assert.Equal(t, "user", res[0].ActorType)
+
+		var changes map[string]map[string]float64
+		assert.NoError(t, json.Unmarshal(res[0].Changes, &changes))
+		assert.Equal(t, map[string]float64{"before": 99, "after": 129}, changes["amount"])
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("Share Entries", func(t *testing.T) {
+		// arrange
+		auditRepo := repository.NewAuditRepositoryMock()
+
+		auditRepo.
+			On("List", 10, repository.AuditFilter{EntityType: "share", Limit: 50}).
+			Return([]repository.AuditEntry{{
+				EntryID:    8,
+				ActorType:  "user",
+				Action:     repository.EventShareUpdated,
+				EntityType: "share",
+				EntityID:   3,
+			}}, nil)
+
+		svc := service.NewAuditService(auditRepo)
+
+		// act
+		res, err := svc.GetAudit(10, service.AuditFilter{EntityType: "share"})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "share.updated", res[0].Action)
+		auditRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Filter", func(t *testing.T) {
+		// arrange
+		auditRepo := repository.NewAuditRepositoryMock()
+		svc := service.NewAuditService(auditRepo)
+
+		// act & assert
+		for _, filter := range []service.AuditFilter{
+			{EntityType: "invoice"},
+			{From: "01/01/2026"},
+			{From: "2026-02-01", To: "2026-01-31"},
+			{Limit: 201},
+			{Limit: -1},
+		} {
+			_, err := svc.GetAudit(10, filter)
+			assert.ErrorIs(t, err, service.ErrInvalidAuditFilter)
+		}
+		auditRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
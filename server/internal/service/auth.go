@@ -2,7 +2,18 @@ package service
 
 import "github.com/NetlutZ/subscout/internal/repository"
 
+// UpdateSettingsRequest only changes the fields that are present.
+type UpdateSettingsRequest struct {
+	BaseCurrency    *string `json:"base_currency"`
+	ReminderDays    *int    `json:"reminder_days"`
+	Timezone        *string `json:"timezone"`
+	DigestFrequency *string `json:"digest_frequency"`
+}
+
 type AuthService interface {
-	Register(name, email, password string) (*repository.User, error)
+	Register(name, email, password string, actor repository.Actor) (*repository.User, error)
 	Login(email, password string) (string, *repository.User, error)
+	GetProfile(userID int) (*repository.User, error)
+	// UpdateSettings changes the settings of actor.UserID.
+	UpdateSettings(actor repository.Actor, req UpdateSettingsRequest) (*repository.User, error)
 }
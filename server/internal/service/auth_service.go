@@ -2,6 +2,7 @@ package service
 
 import (
 	"errors"
+	"fmt"
 	"log"
 	"os"
 	"time"
@@ -12,21 +13,32 @@ import (
 	"golang.org/x/crypto/bcrypt"
 )
 
+var (
+	ErrUserNotFound    = errors.New("user not found")
+	ErrInvalidCurrency = errors.New("currency must be a 3 letter ISO 4217 code")
+	ErrInvalidReminder = errors.New("reminder_days must be between 0 and 60")
+	ErrInvalidTimezone = errors.New("timezone must be an IANA time zone name")
+	ErrInvalidDigest   = errors.New("digest_frequency must be off, weekly or monthly")
+)
+
+const maxReminderDays = 60
+
 type authService struct {
-	userRepo repository.UserRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
 }
 
-func NewAuthService(userRepo repository.UserRepository) AuthService {
-	return authService{userRepo: userRepo}
+func NewAuthService(userRepo repository.UserRepository, currencyRepo repository.CurrencyRepository) AuthService {
+	return authService{userRepo: userRepo, currencyRepo: currencyRepo}
 }
 
-func (s authService) Register(name, email, password string) (*repository.User, error) {
+func (s authService) Register(name, email, password string, actor repository.Actor) (*repository.User, error) {
 	hash, err := bcrypt.GenerateFromPassword([]byte(password), 10)
 	if err != nil {
 		return nil, err
 	}
 
-	user, err := s.userRepo.Create(name, email, string(hash))
+	user, err := s.userRepo.Create(name, email, string(hash), actor)
 	if err != nil {
 		return nil, err
 	}
@@ -74,3 +86,77 @@ func (s authService) Login(email, password string) (string, *repository.User, er
 	user.Password = "" // never expose hash
 	return signed, user, nil
 }
+
+func (s authService) GetProfile(userID int) (*repository.User, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return user, nil
+}
+
+func (s authService) UpdateSettings(actor repository.Actor, req UpdateSettingsRequest) (*repository.User, error) {
+	user, err := s.GetProfile(actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+
+	settings := repository.UserSettings{
+		BaseCurrency:    user.BaseCurrency,
+		ReminderDays:    user.ReminderDays,
+		Timezone:        user.Timezone,
+		DigestFrequency: user.DigestFrequency,
+	}
+
+	if req.BaseCurrency != nil {
+		currency := normalizeCurrency(*req.BaseCurrency)
+		if !isCurrencyCode(currency) {
+			return nil, ErrInvalidCurrency
+		}
+		// every total is converted into the base currency, so one without
+		// a rate would leave nothing to convert into
+		rates, err := s.currencyRepo.GetRates()
+		if err != nil {
+			return nil, err
+		}
+		if _, ok := rates[currency]; !ok {
+			return nil, fmt.Errorf("%w %s", ErrUnknownCurrency, currency)
+		}
+		settings.BaseCurrency = currency
+	}
+
+	if req.ReminderDays != nil {
+		if *req.ReminderDays < 0 || *req.ReminderDays > maxReminderDays {
+			return nil, ErrInvalidReminder
+		}
+		settings.ReminderDays = *req.ReminderDays
+	}
+
+	if req.Timezone != nil {
+		if _, err := loadTimezone(*req.Timezone); err != nil {
+			return nil, ErrInvalidTimezone
+		}
+		settings.Timezone = *req.Timezone
+	}
+
+	if req.DigestFrequency != nil {
+		if !isDigestFrequency(*req.DigestFrequency) {
+			return nil, ErrInvalidDigest
+		}
+		settings.DigestFrequency = *req.DigestFrequency
+	}
+
+	updated, err := s.userRepo.UpdateSettings(actor.UserID, settings, actor)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrUserNotFound
+	}
+
+	return updated, nil
+}
@@ -17,17 +17,17 @@ func TestRegister(t *testing.T) {
 		userRepo := repository.NewUserRepositoryMock()
 
 		userRepo.
-			On("Create", "John", "john@test.com", mock.AnythingOfType("string")).
+			On("Create", "John", "john@test.com", mock.AnythingOfType("string"), repository.Actor{IP: "203.0.113.7"}).
 			Return(&repository.User{
 				ID:    1,
 				Name:  "John",
 				Email: "john@test.com",
 			}, nil)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
-		user, err := svc.Register("John", "john@test.com", "password123")
+		user, err := svc.Register("John", "john@test.com", "password123", repository.Actor{IP: "203.0.113.7"})
 
 		// assert
 		assert.NoError(t, err)
@@ -44,13 +44,13 @@ func TestRegister(t *testing.T) {
 		expectedErr := errors.New("insert failed")
 
 		userRepo.
-			On("Create", mock.Anything, mock.Anything, mock.Anything).
+			On("Create", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
 			Return((*repository.User)(nil), expectedErr)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
-		user, err := svc.Register("John", "john@test.com", "password123")
+		user, err := svc.Register("John", "john@test.com", "password123", repository.Actor{IP: "203.0.113.7"})
 
 		// assert
 		assert.Nil(t, user)
@@ -77,7 +77,7 @@ func TestLogin(t *testing.T) {
 				Password: string(hashedPassword),
 			}, nil)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
 		token, user, err := svc.Login("john@test.com", "password123")
@@ -100,7 +100,7 @@ func TestLogin(t *testing.T) {
 			On("GetByEmail", "john@test.com").
 			Return((*repository.User)(nil), nil)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
 		token, user, err := svc.Login("john@test.com", "password123")
@@ -128,7 +128,7 @@ func TestLogin(t *testing.T) {
 				Password: string(hashedPassword),
 			}, nil)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
 		token, user, err := svc.Login("john@test.com", "wrongpassword")
@@ -150,7 +150,7 @@ func TestLogin(t *testing.T) {
 			On("GetByEmail", "john@test.com").
 			Return((*repository.User)(nil), expectedErr)
 
-		svc := service.NewAuthService(userRepo)
+		svc := service.NewAuthService(userRepo, nil)
 
 		// act
 		token, user, err := svc.Login("john@test.com", "password123")
@@ -164,3 +164,31 @@ func TestLogin(t *testing.T) {
 	})
 
 }
+
+func TestUpdateSettings(t *testing.T) {
+	t.Run("Currency Without Rate", func(t *testing.T) {
+		// arrange
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB", ReminderDays: 3}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 36}, nil)
+
+		svc := service.NewAuthService(userRepo, currencyRepo)
+		currency := "aud"
+
+		// act
+		user, err := svc.UpdateSettings(repository.Actor{UserID: 10}, service.UpdateSettingsRequest{BaseCurrency: &currency})
+
+		// assert
+		assert.Nil(t, user)
+		assert.ErrorIs(t, err, service.ErrUnknownCurrency)
+
+		userRepo.AssertExpectations(t)
+		currencyRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const dateLayout = "2006-01-02"
+
+var ErrInvalidDate = errors.New("invalid date, expected YYYY-MM-DD")
+
+// billingCycle describes how often a subscription renews. Exactly one of
+// days or months is set.
+type billingCycle struct {
+	days   int
+	months int
+}
+
+var billingCycles = map[string]billingCycle{
+	"daily":     {days: 1},
+	"weekly":    {days: 7},
+	"biweekly":  {days: 14},
+	"monthly":   {months: 1},
+	"quarterly": {months: 3},
+	"yearly":    {months: 12},
+	"annually":  {months: 12},
+	"annual":    {months: 12},
+}
+
+func parseBillingCycle(cycle string) (billingCycle, bool) {
+	c, ok := billingCycles[strings.ToLower(strings.TrimSpace(cycle))]
+	return c, ok
+}
+
+// occurrence returns the n-th renewal counted from anchor (n = 0 is the
+// anchor itself). Month based cycles are always computed from the anchor
+// so that a subscription billed on the 31st comes back to the 31st after
+// passing through shorter months.
+func (c billingCycle) occurrence(anchor time.Time, n int) time.Time {
+	if c.days > 0 {
+		return anchor.AddDate(0, 0, c.days*n)
+	}
+	return addMonthsClamped(anchor, c.months*n)
+}
+
+// cancellationDeadline returns the last day sub can be cancelled without
+// paying for another term, together with the renewal it avoids: the first
+// upcoming renewal whose notice period has not started yet. ok is false for
+// subscriptions that are inactive or do not renew automatically.
+func cancellationDeadline(sub repository.Subscription, day time.Time) (deadline, renewal time.Time, ok bool, err error) {
+	if !sub.AutoRenew || !isActive(sub.Status) {
+		return time.Time{}, time.Time{}, false, nil
+	}
+
+	anchor, err := parseDate(sub.BillingDate)
+	if err != nil {
+		return time.Time{}, time.Time{}, false, err
+	}
+
+	cycle, known := parseBillingCycle(sub.BillingCycle)
+	if !known {
+		return anchor.AddDate(0, 0, -sub.NoticePeriodDays), anchor, true, nil
+	}
+
+	for n := 0; ; n++ {
+		renewal = cycle.occurrence(anchor, n)
+		deadline = renewal.AddDate(0, 0, -sub.NoticePeriodDays)
+		if !deadline.Before(day) {
+			return deadline, renewal, true, nil
+		}
+	}
+}
+
+// monthlyFactor is the number of renewals per average month, used to
+// normalize amounts to a monthly figure.
+func (c billingCycle) monthlyFactor() float64 {
+	if c.days > 0 {
+		return 365.0 / 12.0 / float64(c.days)
+	}
+	return 1 / float64(c.months)
+}
+
+func addMonthsClamped(t time.Time, months int) time.Time {
+	first := time.Date(t.Year(), t.Month()+time.Month(months), 1, 0, 0, 0, 0, t.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+
+	day := t.Day()
+	if day > lastDay {
+		day = lastDay
+	}
+
+	return time.Date(first.Year(), first.Month(), day, 0, 0, 0, 0, t.Location())
+}
+
+// parseDate accepts the plain YYYY-MM-DD form used by the API as well as
+// the RFC 3339 timestamps the Postgres driver produces for DATE columns.
+func parseDate(value string) (time.Time, error) {
+	for _, layout := range []string{dateLayout, time.RFC3339} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC), nil
+		}
+	}
+	return time.Time{}, ErrInvalidDate
+}
+
+func today() time.Time {
+	now := time.Now().UTC()
+	return time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// localDay is the calendar day it is in loc at t, as a UTC midnight like
+// today and the dates parsed from the database.
+func localDay(t time.Time, loc *time.Location) time.Time {
+	local := t.In(loc)
+	return time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// loadTimezone resolves an IANA time zone name. "Local" is refused because
+// it is whatever zone the server happens to run in.
+func loadTimezone(name string) (*time.Location, error) {
+	if name == "" || name == "Local" {
+		return nil, fmt.Errorf("unknown time zone %q", name)
+	}
+	return time.LoadLocation(name)
+}
+
+func isActive(status string) bool {
+	status = strings.ToLower(strings.TrimSpace(status))
+	return status == "" || status == "active"
+}
+
+func roundMoney(v float64) float64 {
+	return math.Round(v*100) / 100
+}
@@ -0,0 +1,58 @@
+package service
+
+import "context"
+
+const (
+	BudgetBasisProjected = "projected"
+	BudgetBasisActual    = "actual"
+)
+
+type BudgetResponse struct {
+	BudgetID     int     `json:"id"`
+	CategoryID   *int    `json:"category_id"` // null for the total budget
+	CategoryName string  `json:"category_name"`
+	Amount       float64 `json:"amount"`
+	Thresholds   []int   `json:"thresholds"`
+}
+
+// BudgetRequest creates or updates a budget. CategoryID is only read on
+// create; Thresholds defaults to 80% and 100%.
+type BudgetRequest struct {
+	CategoryID *int    `json:"category_id"`
+	Amount     float64 `json:"amount"`
+	Thresholds []int   `json:"thresholds"`
+}
+
+// BudgetStatus compares a budget with the normalized monthly cost of the
+// active subscriptions it covers (projected) and with the charges recorded
+// so far this month (actual).
+type BudgetStatus struct {
+	BudgetResponse
+	Projected        float64 `json:"projected"`
+	ProjectedPercent float64 `json:"projected_percent"`
+	Actual           float64 `json:"actual"`
+	ActualPercent    float64 `json:"actual_percent"`
+	Status           string  `json:"status"` // ok, warning, over
+}
+
+type BudgetEvaluationResponse struct {
+	Period       string              `json:"period"` // YYYY-MM
+	BaseCurrency string              `json:"base_currency"`
+	Budgets      []BudgetStatus      `json:"budgets"`
+	Unconverted  []UnconvertedAmount `json:"unconverted"`
+}
+
+type BudgetService interface {
+	GetBudgets(userID int) ([]BudgetResponse, error)
+	CreateBudget(req BudgetRequest, userID int) (*BudgetResponse, error)
+	UpdateBudget(id int, req BudgetRequest, userID int) (*BudgetResponse, error)
+	DeleteBudget(id int, userID int) error
+	// GetBudgetStatus reports this month's spend against every budget
+	// without notifying anyone, so reads and previews have no side effects.
+	GetBudgetStatus(userID int) (*BudgetEvaluationResponse, error)
+	// EvaluateBudgets is GetBudgetStatus that also notifies the user the
+	// first time a threshold is crossed.
+	EvaluateBudgets(userID int) (*BudgetEvaluationResponse, error)
+	// EvaluateAll runs EvaluateBudgets for every user with a budget.
+	EvaluateAll(ctx context.Context) error
+}
@@ -0,0 +1,393 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	maxBudgetThresholds  = 10
+	budgetAlertType      = "budget_alert"
+	budgetStatusOK       = "ok"
+	budgetStatusWarning  = "warning"
+	budgetStatusOver     = "over"
+	overBudgetPercentage = 100
+)
+
+var (
+	ErrBudgetNotFound = errors.New("budget not found")
+	ErrInvalidBudget  = errors.New("invalid budget")
+)
+
+var defaultBudgetThresholds = []int{80, 100}
+
+type budgetService struct {
+	budgetRepo   repository.BudgetRepository
+	categoryRepo repository.CategoryRepository
+	subRepo      repository.SubscriptionRepository
+	chargeRepo   repository.ChargeRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewBudgetService(
+	budgetRepo repository.BudgetRepository,
+	categoryRepo repository.CategoryRepository,
+	subRepo repository.SubscriptionRepository,
+	chargeRepo repository.ChargeRepository,
+	userRepo repository.UserRepository,
+	currencyRepo repository.CurrencyRepository,
+) BudgetService {
+	return budgetService{
+		budgetRepo:   budgetRepo,
+		categoryRepo: categoryRepo,
+		subRepo:      subRepo,
+		chargeRepo:   chargeRepo,
+		userRepo:     userRepo,
+		currencyRepo: currencyRepo,
+	}
+}
+
+func toBudgetResponse(b repository.Budget) BudgetResponse {
+	return BudgetResponse{
+		BudgetID:     b.BudgetID,
+		CategoryID:   b.CategoryID,
+		CategoryName: b.CategoryName,
+		Amount:       b.Amount,
+		Thresholds:   b.Thresholds,
+	}
+}
+
+func (s budgetService) GetBudgets(userID int) ([]BudgetResponse, error) {
+	budgets, err := s.budgetRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []BudgetResponse{}
+	for _, b := range budgets {
+		res = append(res, toBudgetResponse(b))
+	}
+
+	return res, nil
+}
+
+func (s budgetService) CreateBudget(req BudgetRequest, userID int) (*BudgetResponse, error) {
+	budget, err := normalizeBudgetRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.CategoryID != nil {
+		category, err := s.categoryRepo.GetById(*req.CategoryID, userID)
+		if err != nil {
+			return nil, err
+		}
+		if category == nil {
+			return nil, ErrCategoryNotFound
+		}
+		budget.CategoryID = &category.CategoryID
+		budget.CategoryName = category.Name
+	}
+
+	created, err := s.budgetRepo.Create(budget, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toBudgetResponse(*created)
+	return &res, nil
+}
+
+func (s budgetService) UpdateBudget(id int, req BudgetRequest, userID int) (*BudgetResponse, error) {
+	budget, err := normalizeBudgetRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	budget.BudgetID = id
+
+	if _, err := s.budgetRepo.Update(budget, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrBudgetNotFound
+		}
+		return nil, err
+	}
+
+	// re-read for the category name
+	updated, err := s.budgetRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrBudgetNotFound
+	}
+
+	res := toBudgetResponse(*updated)
+	return &res, nil
+}
+
+func (s budgetService) DeleteBudget(id int, userID int) error {
+	err := s.budgetRepo.Delete(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrBudgetNotFound
+	}
+	return err
+}
+
+func (s budgetService) EvaluateAll(ctx context.Context) error {
+	userIDs, err := s.budgetRepo.GetUserIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// one user's failure must not stop everyone else's alerts
+		if _, err := s.EvaluateBudgets(userID); err != nil {
+			log.Printf("budget evaluation failed for user %d: %v", userID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s budgetService) GetBudgetStatus(userID int) (*BudgetEvaluationResponse, error) {
+	res, _, _, err := s.evaluate(userID)
+	return res, err
+}
+
+func (s budgetService) EvaluateBudgets(userID int) (*BudgetEvaluationResponse, error) {
+	res, budgets, period, err := s.evaluate(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	for i, b := range budgets {
+		status := res.Budgets[i]
+		if err := s.alert(b, BudgetBasisProjected, status.Projected, status.ProjectedPercent, period, res.BaseCurrency, userID); err != nil {
+			return nil, err
+		}
+		if err := s.alert(b, BudgetBasisActual, status.Actual, status.ActualPercent, period, res.BaseCurrency, userID); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// evaluate computes the status of every budget, in the order of the
+// budgets it returns with it, and the first day of the period.
+func (s budgetService) evaluate(userID int) (*BudgetEvaluationResponse, []repository.Budget, string, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+	if user == nil {
+		return nil, nil, "", ErrUserNotFound
+	}
+
+	rates, err := s.currencyRepo.GetRates()
+	if err != nil {
+		return nil, nil, "", err
+	}
+	converter := newCurrencyConverter(user.BaseCurrency, rates)
+
+	budgets, err := s.budgetRepo.GetAll(userID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	now := today()
+	periodStart := now.AddDate(0, 0, 1-now.Day())
+	periodEnd := periodStart.AddDate(0, 1, -1)
+
+	charges, err := s.chargeRepo.GetChargesBetween(userID, periodStart.Format(dateLayout), periodEnd.Format(dateLayout))
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// spend is keyed by category id, with 0 holding the total
+	unconverted := []UnconvertedAmount{}
+	projected := map[int]float64{}
+	actual := map[int]float64{}
+	categoryOf := map[int]int{}
+
+	for _, sub := range subs {
+		if sub.CategoryID != nil {
+			categoryOf[sub.SubscriptionID] = *sub.CategoryID
+		}
+
+		monthly, ok, err := monthlyAmount(sub, converter)
+		if errors.Is(err, ErrUnknownCurrency) {
+			unconverted = append(unconverted, unconvertedSubscription(sub))
+			continue
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		if !ok {
+			continue
+		}
+		projected[0] += monthly
+		if sub.CategoryID != nil {
+			projected[*sub.CategoryID] += monthly
+		}
+	}
+
+	for _, charge := range charges {
+		converted, err := converter.convert(float64(charge.Amount), charge.Currency)
+		if errors.Is(err, ErrUnknownCurrency) {
+			unconverted = append(unconverted, UnconvertedAmount{
+				SubscriptionID: charge.SubscriptionID,
+				Name:           charge.SubscriptionName,
+				Amount:         float64(charge.Amount),
+				Currency:       normalizeCurrency(charge.Currency),
+			})
+			continue
+		}
+		if err != nil {
+			return nil, nil, "", err
+		}
+		actual[0] += converted
+		if charge.SubscriptionID != nil {
+			if categoryID, ok := categoryOf[*charge.SubscriptionID]; ok {
+				actual[categoryID] += converted
+			}
+		}
+	}
+
+	res := &BudgetEvaluationResponse{
+		Period:       periodStart.Format("2006-01"),
+		BaseCurrency: converter.base,
+		Budgets:      []BudgetStatus{},
+		Unconverted:  unconverted,
+	}
+
+	for _, b := range budgets {
+		key := 0
+		if b.CategoryID != nil {
+			key = *b.CategoryID
+		}
+
+		status := BudgetStatus{
+			BudgetResponse: toBudgetResponse(b),
+			Projected:      roundMoney(projected[key]),
+			Actual:         roundMoney(actual[key]),
+		}
+		status.ProjectedPercent = budgetPercent(status.Projected, b.Amount)
+		status.ActualPercent = budgetPercent(status.Actual, b.Amount)
+		status.Status = budgetStatus(b.Thresholds, status.ProjectedPercent, status.ActualPercent)
+
+		res.Budgets = append(res.Budgets, status)
+	}
+
+	return res, budgets, periodStart.Format(dateLayout), nil
+}
+
+// alert notifies the user about the highest threshold spend has reached.
+// Lower thresholds crossed at the same time are skipped rather than sent as
+// a burst of notifications; each threshold is only ever alerted once per
+// period and basis.
+func (s budgetService) alert(b repository.Budget, basis string, spend, percent float64, period, currency string, userID int) error {
+	threshold := 0
+	for _, t := range b.Thresholds {
+		if percent >= float64(t) && t > threshold {
+			threshold = t
+		}
+	}
+	if threshold == 0 {
+		return nil
+	}
+
+	name := "Total"
+	if b.CategoryName != "" {
+		name = b.CategoryName
+	}
+
+	var message string
+	if basis == BudgetBasisProjected {
+		message = fmt.Sprintf("Your subscriptions are expected to cost %.2f %s a month, %.0f%% of your %.2f %s budget.",
+			spend, currency, percent, b.Amount, currency)
+	} else {
+		message = fmt.Sprintf("You have been charged %.2f %s this month, %.0f%% of your %.2f %s budget.",
+			spend, currency, percent, b.Amount, currency)
+	}
+
+	_, err := s.budgetRepo.RecordAlert(repository.BudgetAlert{
+		BudgetID:  b.BudgetID,
+		Period:    period,
+		Basis:     basis,
+		Threshold: threshold,
+	}, repository.Notification{
+		Type:    budgetAlertType,
+		Title:   fmt.Sprintf("%s budget reached %d%%", name, threshold),
+		Message: message,
+	}, userID)
+
+	return err
+}
+
+func budgetPercent(spend, amount float64) float64 {
+	if amount <= 0 {
+		return 0
+	}
+	return roundMoney(spend / amount * 100)
+}
+
+func budgetStatus(thresholds []int, percents ...float64) string {
+	status := budgetStatusOK
+	for _, p := range percents {
+		if p >= overBudgetPercentage {
+			return budgetStatusOver
+		}
+		if len(thresholds) > 0 && p >= float64(thresholds[0]) {
+			status = budgetStatusWarning
+		}
+	}
+	return status
+}
+
+func normalizeBudgetRequest(req BudgetRequest) (*repository.Budget, error) {
+	if req.Amount <= 0 {
+		return nil, fmt.Errorf("%w: amount must be greater than zero", ErrInvalidBudget)
+	}
+
+	thresholds := req.Thresholds
+	if len(thresholds) == 0 {
+		thresholds = defaultBudgetThresholds
+	}
+	if len(thresholds) > maxBudgetThresholds {
+		return nil, fmt.Errorf("%w: at most %d thresholds", ErrInvalidBudget, maxBudgetThresholds)
+	}
+
+	seen := map[int]bool{}
+	unique := []int{}
+	for _, t := range thresholds {
+		if t < 1 || t > 1000 {
+			return nil, fmt.Errorf("%w: thresholds must be between 1 and 1000", ErrInvalidBudget)
+		}
+		if !seen[t] {
+			seen[t] = true
+			unique = append(unique, t)
+		}
+	}
+	sort.Ints(unique)
+
+	return &repository.Budget{
+		Amount:     roundMoney(req.Amount),
+		Thresholds: unique,
+	}, nil
+}
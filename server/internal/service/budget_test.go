@@ -0,0 +1,173 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateBudget(t *testing.T) {
+	t.Run("Create Budget Default Thresholds", func(t *testing.T) {
+		// arrange
+		budgetRepo := repository.NewBudgetRepositoryMock()
+
+		budgetRepo.
+			On("Create", &repository.Budget{Amount: 1500, Thresholds: []int{80, 100}}, 10).
+			Return(&repository.Budget{BudgetID: 1, Amount: 1500, Thresholds: []int{80, 100}}, nil)
+
+		budgetService := service.NewBudgetService(budgetRepo, nil, nil, nil, nil, nil)
+
+		// act
+		res, err := budgetService.CreateBudget(service.BudgetRequest{Amount: 1500}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Nil(t, res.CategoryID)
+		assert.Equal(t, []int{80, 100}, res.Thresholds)
+		budgetRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Budget Unknown Category", func(t *testing.T) {
+		// arrange
+		budgetRepo := repository.NewBudgetRepositoryMock()
+		categoryRepo := repository.NewCategoryRepositoryMock()
+		categoryID := 7
+
+		categoryRepo.
+			On("GetById", 7, 10).
+			Return((*repository.Category)(nil), nil)
+
+		budgetService := service.NewBudgetService(budgetRepo, categoryRepo, nil, nil, nil, nil)
+
+		// act
+		_, err := budgetService.CreateBudget(service.BudgetRequest{CategoryID: &categoryID, Amount: 300}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+		budgetRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create Budget Invalid", func(t *testing.T) {
+		// arrange
+		budgetService := service.NewBudgetService(repository.NewBudgetRepositoryMock(), nil, nil, nil, nil, nil)
+
+		// act & assert
+		for _, req := range []service.BudgetRequest{
+			{Amount: 0},
+			{Amount: 100, Thresholds: []int{0}},
+			{Amount: 100, Thresholds: []int{5000}},
+		} {
+			_, err := budgetService.CreateBudget(req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidBudget)
+		}
+	})
+}
+
+func TestEvaluateBudgets(t *testing.T) {
+	t.Run("Evaluate Budgets Success", func(t *testing.T) {
+		// arrange
+		budgetRepo := repository.NewBudgetRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		chargeRepo := repository.NewChargeRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+		streaming := 1
+		netflix := 5
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+		budgetRepo.
+			On("GetAll", 10).
+			Return([]repository.Budget{
+				{BudgetID: 1, Amount: 1000, Thresholds: []int{80, 100}},
+				{BudgetID: 2, CategoryID: &streaming, CategoryName: "Streaming", Amount: 400, Thresholds: []int{80, 100}},
+			}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: netflix, Name: "Netflix", CategoryID: &streaming, Amount: 419, Currency: "THB", BillingCycle: "monthly", Status: "active"},
+				{SubscriptionID: 6, Name: "Domain", Amount: 12, Currency: "USD", BillingCycle: "yearly", Status: "active"},
+			}, nil)
+		chargeRepo.
+			On("GetChargesBetween", 10, mock.Anything, mock.Anything).
+			Return([]repository.Charge{
+				{SubscriptionID: &netflix, Amount: 419, Currency: "THB"},
+				{Amount: 10, Currency: "USD"},
+			}, nil)
+
+		// total: projected 454 (45%), actual 769 (77%) -> nothing to send
+		// streaming: projected and actual 419 (105%) -> 100% alert for both
+		budgetRepo.
+			On("RecordAlert", mock.MatchedBy(func(a repository.BudgetAlert) bool {
+				return a.BudgetID == 2 && a.Threshold == 100
+			}), mock.MatchedBy(func(n repository.Notification) bool {
+				return n.Type == "budget_alert" && n.Title == "Streaming budget reached 100%"
+			}), 10).
+			Return(true, nil).
+			Twice()
+
+		budgetService := service.NewBudgetService(budgetRepo, nil, subscriptionRepo, chargeRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := budgetService.EvaluateBudgets(10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "THB", res.BaseCurrency)
+		assert.Len(t, res.Budgets, 2)
+
+		assert.Equal(t, 454.0, res.Budgets[0].Projected)
+		assert.Equal(t, 769.0, res.Budgets[0].Actual)
+		assert.Equal(t, "ok", res.Budgets[0].Status)
+
+		assert.Equal(t, 419.0, res.Budgets[1].Projected)
+		assert.Equal(t, 104.75, res.Budgets[1].ActualPercent)
+		assert.Equal(t, "over", res.Budgets[1].Status)
+
+		budgetRepo.AssertExpectations(t)
+	})
+
+	t.Run("Status Sends Nothing", func(t *testing.T) {
+		// arrange
+		budgetRepo := repository.NewBudgetRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		chargeRepo := repository.NewChargeRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1}, nil)
+		budgetRepo.
+			On("GetAll", 10).
+			Return([]repository.Budget{{BudgetID: 1, Amount: 100, Thresholds: []int{80, 100}}}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 5, Name: "Netflix", Amount: 419, Currency: "THB", BillingCycle: "monthly", Status: "active"},
+			}, nil)
+		chargeRepo.
+			On("GetChargesBetween", 10, mock.Anything, mock.Anything).
+			Return([]repository.Charge{}, nil)
+
+		budgetService := service.NewBudgetService(budgetRepo, nil, subscriptionRepo, chargeRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := budgetService.GetBudgetStatus(10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "over", res.Budgets[0].Status)
+		budgetRepo.AssertNotCalled(t, "RecordAlert", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
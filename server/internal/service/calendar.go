@@ -0,0 +1,13 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+type CalendarTokenResponse struct {
+	Token string `json:"token"`
+	URL   string `json:"url"`
+}
+
+type CalendarService interface {
+	GetFeed(token string) ([]byte, error)
+	RotateToken(actor repository.Actor) (*CalendarTokenResponse, error)
+}
@@ -0,0 +1,128 @@
+package service
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const calendarTokenBytes = 32
+
+var ErrCalendarNotFound = errors.New("calendar not found")
+
+type calendarService struct {
+	subRepo  repository.SubscriptionRepository
+	userRepo repository.UserRepository
+}
+
+func NewCalendarService(
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+) CalendarService {
+	return calendarService{subRepo: subRepo, userRepo: userRepo}
+}
+
+// RotateToken issues a new feed token, invalidating the previous one. Only
+// a hash is stored, so the token is shown to the user exactly once.
+func (s calendarService) RotateToken(actor repository.Actor) (*CalendarTokenResponse, error) {
+	raw := make([]byte, calendarTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.userRepo.SetCalendarTokenHash(actor.UserID, hashToken(token), actor); err != nil {
+		return nil, err
+	}
+
+	return &CalendarTokenResponse{
+		Token: token,
+		URL:   "/calendar/" + token + ".ics",
+	}, nil
+}
+
+func (s calendarService) GetFeed(token string) ([]byte, error) {
+	if token == "" {
+		return nil, ErrCalendarNotFound
+	}
+
+	user, err := s.userRepo.GetByCalendarTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrCalendarNotFound
+	}
+
+	subs, err := s.subRepo.GetAll(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return buildCalendar(subs, user.ReminderDays, time.Now())
+}
+
+func buildCalendar(subs []repository.Subscription, reminderDays int, now time.Time) ([]byte, error) {
+	w := &icsWriter{}
+	w.line("BEGIN", "VCALENDAR")
+	w.line("VERSION", "2.0")
+	w.line("PRODID", "-//Subscout//Renewals//EN")
+	w.line("CALSCALE", "GREGORIAN")
+	w.line("METHOD", "PUBLISH")
+	w.text("X-WR-CALNAME", "Subscout renewals")
+
+	for _, sub := range subs {
+		if !isActive(sub.Status) {
+			continue
+		}
+
+		start, err := parseDate(sub.BillingDate)
+		if err != nil {
+			return nil, err
+		}
+
+		w.line("BEGIN", "VEVENT")
+		// the UID only depends on the subscription so calendar apps
+		// replace the event when the feed changes instead of adding one
+		w.line("UID", fmt.Sprintf("subscription-%d@subscout", sub.SubscriptionID))
+		w.line("DTSTAMP", icsTimestamp(now))
+		w.line("DTSTART;VALUE=DATE", icsDate(start))
+		w.line("DTEND;VALUE=DATE", icsDate(start.AddDate(0, 0, 1)))
+		if cycle, ok := parseBillingCycle(sub.BillingCycle); ok {
+			w.line("RRULE", icsRecurrenceRule(cycle, start))
+		}
+		w.text("SUMMARY", fmt.Sprintf("%s renewal (%.2f %s)", sub.Name, sub.Amount, normalizeCurrency(sub.Currency)))
+		if sub.Category != "" {
+			w.text("CATEGORIES", sub.Category)
+		}
+		w.line("TRANSP", "TRANSPARENT")
+
+		// Auto-renewing subscriptions with a notice period must be cancelled
+		// before the notice starts, so the reminder moves back with it.
+		w.line("BEGIN", "VALARM")
+		w.line("ACTION", "DISPLAY")
+		if sub.AutoRenew && sub.NoticePeriodDays > 0 {
+			w.text("DESCRIPTION", fmt.Sprintf("%s: last day to cancel is in %d days", sub.Name, reminderDays))
+			w.line("TRIGGER", fmt.Sprintf("-P%dD", sub.NoticePeriodDays+reminderDays))
+		} else {
+			w.text("DESCRIPTION", sub.Name+" renews soon")
+			w.line("TRIGGER", fmt.Sprintf("-P%dD", reminderDays))
+		}
+		w.line("END", "VALARM")
+
+		w.line("END", "VEVENT")
+	}
+
+	w.line("END", "VCALENDAR")
+	return w.bytes(), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
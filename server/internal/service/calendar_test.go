@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestGetFeed(t *testing.T) {
+	t.Run("Get Feed Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+
+		userRepo.
+			On("GetByCalendarTokenHash", mock.AnythingOfType("string")).
+			Return(&repository.User{ID: 10, ReminderDays: 3}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{
+					SubscriptionID: 1,
+					Name:           "Netflix, Premium",
+					Amount:         419,
+					Currency:       "THB",
+					BillingCycle:   "monthly",
+					BillingDate:    "2025-01-31",
+					Status:         "active",
+				},
+				{
+					SubscriptionID:   2,
+					Name:             "Domain",
+					Amount:           12,
+					Currency:         "USD",
+					BillingCycle:     "yearly",
+					BillingDate:      "2025-03-10",
+					Status:           "active",
+					AutoRenew:        true,
+					NoticePeriodDays: 30,
+				},
+				{
+					SubscriptionID: 3,
+					Name:           "Gym",
+					BillingCycle:   "monthly",
+					BillingDate:    "2025-03-10",
+					Status:         "canceled",
+				},
+			}, nil)
+
+		calendarService := service.NewCalendarService(subscriptionRepo, userRepo)
+
+		// act
+		feed, err := calendarService.GetFeed("secret")
+
+		// assert
+		assert.NoError(t, err)
+
+		ics := string(feed)
+		assert.Contains(t, ics, "BEGIN:VCALENDAR\r\n")
+		assert.Contains(t, ics, "UID:subscription-1@subscout\r\n")
+		assert.Contains(t, ics, "SUMMARY:Netflix\\, Premium renewal (419.00 THB)\r\n")
+		assert.Contains(t, ics, "DTSTART;VALUE=DATE:20250131\r\n")
+		assert.Contains(t, ics, "RRULE:FREQ=MONTHLY;INTERVAL=1;BYMONTHDAY=28,29,30,31;BYSETPOS=-1\r\n")
+		assert.Contains(t, ics, "RRULE:FREQ=YEARLY;INTERVAL=1;BYMONTH=3\r\n")
+		assert.Contains(t, ics, "TRIGGER:-P3D\r\n")
+		assert.Contains(t, ics, "TRIGGER:-P33D\r\n")
+		assert.NotContains(t, ics, "subscription-3@subscout")
+
+		subscriptionRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Token", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+
+		userRepo.
+			On("GetByCalendarTokenHash", mock.AnythingOfType("string")).
+			Return((*repository.User)(nil), nil)
+
+		calendarService := service.NewCalendarService(subscriptionRepo, userRepo)
+
+		// act
+		feed, err := calendarService.GetFeed("wrong")
+
+		// assert
+		assert.Nil(t, feed)
+		assert.ErrorIs(t, err, service.ErrCalendarNotFound)
+	})
+}
+
+func TestRotateToken(t *testing.T) {
+	t.Run("Rotate Token Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+
+		userRepo.
+			On("SetCalendarTokenHash", 10, mock.AnythingOfType("string"), actor).
+			Return(nil)
+
+		calendarService := service.NewCalendarService(subscriptionRepo, userRepo)
+
+		// act
+		res, err := calendarService.RotateToken(actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res.Token, 64)
+		assert.Equal(t, "/calendar/"+res.Token+".ics", res.URL)
+
+		storedHash := userRepo.Calls[0].Arguments.String(1)
+		assert.NotEqual(t, res.Token, storedHash)
+		userRepo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,47 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+type CategoryResponse struct {
+	CategoryID    int      `json:"id"`
+	Name          string   `json:"name"`
+	Color         string   `json:"color"`
+	Icon          string   `json:"icon"`
+	MonthlyBudget *float64 `json:"monthly_budget"`
+}
+
+type CategoryRequest struct {
+	Name          string   `json:"name"`
+	Color         string   `json:"color"`
+	Icon          string   `json:"icon"`
+	MonthlyBudget *float64 `json:"monthly_budget"`
+}
+
+// CategorySpend compares a category's normalized monthly spend with its
+// budget. Remaining and PercentUsed are nil when there is no budget.
+type CategorySpend struct {
+	CategoryID    *int     `json:"id"`
+	Name          string   `json:"name"`
+	Color         string   `json:"color"`
+	Icon          string   `json:"icon"`
+	Subscriptions int      `json:"subscriptions"`
+	MonthlySpend  float64  `json:"monthly_spend"`
+	MonthlyBudget *float64 `json:"monthly_budget"`
+	Remaining     *float64 `json:"remaining"`
+	PercentUsed   *float64 `json:"percent_used"`
+}
+
+type CategorySpendResponse struct {
+	BaseCurrency string              `json:"base_currency"`
+	Categories   []CategorySpend     `json:"categories"`
+	Total        float64             `json:"total"`
+	Unconverted  []UnconvertedAmount `json:"unconverted"`
+}
+
+type CategoryService interface {
+	GetCategories(userID int) ([]CategoryResponse, error)
+	CreateCategory(req CategoryRequest, userID int) (*CategoryResponse, error)
+	UpdateCategory(id int, req CategoryRequest, actor repository.Actor) (*CategoryResponse, error)
+	DeleteCategory(id int, actor repository.Actor) error
+	GetCategorySpend(userID int) (*CategorySpendResponse, error)
+}
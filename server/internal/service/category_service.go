@@ -0,0 +1,254 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const uncategorizedName = "Uncategorized"
+
+var (
+	ErrCategoryNotFound = errors.New("category not found")
+	ErrInvalidCategory  = errors.New("invalid category")
+)
+
+var colorPattern = regexp.MustCompile(`^#[0-9A-Fa-f]{6}$`)
+
+type categoryService struct {
+	categoryRepo repository.CategoryRepository
+	subRepo      repository.SubscriptionRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewCategoryService(
+	categoryRepo repository.CategoryRepository,
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	currencyRepo repository.CurrencyRepository,
+) CategoryService {
+	return categoryService{
+		categoryRepo: categoryRepo,
+		subRepo:      subRepo,
+		userRepo:     userRepo,
+		currencyRepo: currencyRepo,
+	}
+}
+
+func toCategoryResponse(c repository.Category) CategoryResponse {
+	return CategoryResponse{
+		CategoryID:    c.CategoryID,
+		Name:          c.Name,
+		Color:         c.Color,
+		Icon:          c.Icon,
+		MonthlyBudget: c.MonthlyBudget,
+	}
+}
+
+func (s categoryService) GetCategories(userID int) ([]CategoryResponse, error) {
+	categories, err := s.categoryRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []CategoryResponse{}
+	for _, c := range categories {
+		res = append(res, toCategoryResponse(c))
+	}
+
+	return res, nil
+}
+
+func (s categoryService) CreateCategory(req CategoryRequest, userID int) (*CategoryResponse, error) {
+	category, err := normalizeCategoryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.categoryRepo.Create(category, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toCategoryResponse(*created)
+	return &res, nil
+}
+
+func (s categoryService) UpdateCategory(id int, req CategoryRequest, actor repository.Actor) (*CategoryResponse, error) {
+	category, err := normalizeCategoryRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	category.CategoryID = id
+
+	updated, err := s.categoryRepo.Update(category, actor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCategoryNotFound
+		}
+		return nil, err
+	}
+
+	res := toCategoryResponse(*updated)
+	return &res, nil
+}
+
+func (s categoryService) DeleteCategory(id int, actor repository.Actor) error {
+	err := s.categoryRepo.Delete(id, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCategoryNotFound
+	}
+	return err
+}
+
+// GetCategorySpend totals the normalized monthly cost of every active
+// subscription per category, in the user's base currency. Subscriptions
+// without a category are reported under a trailing "Uncategorized" entry.
+func (s categoryService) GetCategorySpend(userID int) (*CategorySpendResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	rates, err := s.currencyRepo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	converter := newCurrencyConverter(user.BaseCurrency, rates)
+
+	categories, err := s.categoryRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &CategorySpendResponse{
+		BaseCurrency: converter.base,
+		Categories:   []CategorySpend{},
+		Unconverted:  []UnconvertedAmount{},
+	}
+
+	index := map[int]int{}
+	for _, c := range categories {
+		id := c.CategoryID
+		index[id] = len(res.Categories)
+		res.Categories = append(res.Categories, CategorySpend{
+			CategoryID:    &id,
+			Name:          c.Name,
+			Color:         c.Color,
+			Icon:          c.Icon,
+			MonthlyBudget: c.MonthlyBudget,
+		})
+	}
+
+	uncategorized := CategorySpend{Name: uncategorizedName}
+
+	for _, sub := range subs {
+		monthly, ok, err := monthlyAmount(sub, converter)
+		if errors.Is(err, ErrUnknownCurrency) {
+			res.Unconverted = append(res.Unconverted, unconvertedSubscription(sub))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		spend := &uncategorized
+		if sub.CategoryID != nil {
+			if i, found := index[*sub.CategoryID]; found {
+				spend = &res.Categories[i]
+			}
+		}
+		spend.MonthlySpend += monthly
+		spend.Subscriptions++
+		res.Total += monthly
+	}
+
+	if uncategorized.Subscriptions > 0 {
+		res.Categories = append(res.Categories, uncategorized)
+	}
+
+	for i := range res.Categories {
+		c := &res.Categories[i]
+		c.MonthlySpend = roundMoney(c.MonthlySpend)
+		if c.MonthlyBudget != nil {
+			remaining := roundMoney(*c.MonthlyBudget - c.MonthlySpend)
+			c.Remaining = &remaining
+			if *c.MonthlyBudget > 0 {
+				percent := roundMoney(c.MonthlySpend / *c.MonthlyBudget * 100)
+				c.PercentUsed = &percent
+			}
+		}
+	}
+	res.Total = roundMoney(res.Total)
+
+	return res, nil
+}
+
+// monthlyAmount is the average monthly cost of sub in the converter's base
+// currency. ok is false for subscriptions that do not recur, either because
+// they are inactive or because their billing cycle is unknown.
+func monthlyAmount(sub repository.Subscription, converter currencyConverter) (float64, bool, error) {
+	if !isActive(sub.Status) {
+		return 0, false, nil
+	}
+
+	cycle, ok := parseBillingCycle(sub.BillingCycle)
+	if !ok {
+		return 0, false, nil
+	}
+
+	converted, err := converter.convert(float64(sub.Amount), sub.Currency)
+	if err != nil {
+		return 0, false, err
+	}
+
+	return converted * cycle.monthlyFactor(), true, nil
+}
+
+func normalizeCategoryRequest(req CategoryRequest) (*repository.Category, error) {
+	name := strings.TrimSpace(req.Name)
+	color := strings.TrimSpace(req.Color)
+	icon := strings.TrimSpace(req.Icon)
+
+	switch {
+	case name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidCategory)
+	case len(name) > 50:
+		return nil, fmt.Errorf("%w: name must be at most 50 characters", ErrInvalidCategory)
+	case color != "" && !colorPattern.MatchString(color):
+		return nil, fmt.Errorf("%w: color must look like #RRGGBB", ErrInvalidCategory)
+	case len(icon) > 50:
+		return nil, fmt.Errorf("%w: icon must be at most 50 characters", ErrInvalidCategory)
+	case req.MonthlyBudget != nil && *req.MonthlyBudget < 0:
+		return nil, fmt.Errorf("%w: monthly_budget must not be negative", ErrInvalidCategory)
+	}
+
+	var budget *float64
+	if req.MonthlyBudget != nil {
+		b := roundMoney(*req.MonthlyBudget)
+		budget = &b
+	}
+
+	return &repository.Category{
+		Name:          name,
+		Color:         strings.ToUpper(color),
+		Icon:          icon,
+		MonthlyBudget: budget,
+	}, nil
+}
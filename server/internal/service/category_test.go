@@ -0,0 +1,150 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCategory(t *testing.T) {
+	t.Run("Create Category Success", func(t *testing.T) {
+		// arrange
+		categoryRepo := repository.NewCategoryRepositoryMock()
+		budget := 500.0
+
+		categoryRepo.
+			On("Create", &repository.Category{Name: "Streaming", Color: "#FF0000", MonthlyBudget: &budget}, 10).
+			Return(&repository.Category{CategoryID: 1, Name: "Streaming", Color: "#FF0000", MonthlyBudget: &budget}, nil)
+
+		categoryService := service.NewCategoryService(categoryRepo, nil, nil, nil)
+
+		// act
+		res, err := categoryService.CreateCategory(service.CategoryRequest{
+			Name:          "  Streaming ",
+			Color:         "#ff0000",
+			MonthlyBudget: &budget,
+		}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.CategoryID)
+		assert.Equal(t, "#FF0000", res.Color)
+		categoryRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Category Invalid", func(t *testing.T) {
+		// arrange
+		categoryRepo := repository.NewCategoryRepositoryMock()
+		negative := -1.0
+
+		categoryService := service.NewCategoryService(categoryRepo, nil, nil, nil)
+
+		// act & assert
+		for _, req := range []service.CategoryRequest{
+			{Name: " "},
+			{Name: "Music", Color: "red"},
+			{Name: "Music", MonthlyBudget: &negative},
+		} {
+			_, err := categoryService.CreateCategory(req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidCategory)
+		}
+		categoryRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Create Category Duplicate", func(t *testing.T) {
+		// arrange
+		categoryRepo := repository.NewCategoryRepositoryMock()
+
+		categoryRepo.
+			On("Create", mock.Anything, 10).
+			Return((*repository.Category)(nil), repository.ErrDuplicateCategory)
+
+		categoryService := service.NewCategoryService(categoryRepo, nil, nil, nil)
+
+		// act
+		_, err := categoryService.CreateCategory(service.CategoryRequest{Name: "music"}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, repository.ErrDuplicateCategory)
+	})
+}
+
+func TestUpdateCategory(t *testing.T) {
+	t.Run("Update Category Not Found", func(t *testing.T) {
+		// arrange
+		categoryRepo := repository.NewCategoryRepositoryMock()
+
+		categoryRepo.
+			On("Update", mock.Anything, actor).
+			Return((*repository.Category)(nil), sql.ErrNoRows)
+
+		categoryService := service.NewCategoryService(categoryRepo, nil, nil, nil)
+
+		// act
+		_, err := categoryService.UpdateCategory(99, service.CategoryRequest{Name: "Music"}, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrCategoryNotFound)
+	})
+}
+
+func TestGetCategorySpend(t *testing.T) {
+	t.Run("Get Category Spend Success", func(t *testing.T) {
+		// arrange
+		categoryRepo := repository.NewCategoryRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+		streaming, tools := 1, 2
+		budget := 400.0
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+		categoryRepo.
+			On("GetAll", 10).
+			Return([]repository.Category{
+				{CategoryID: streaming, Name: "Streaming", MonthlyBudget: &budget},
+				{CategoryID: tools, Name: "Tools"},
+			}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{Name: "Netflix", CategoryID: &streaming, Amount: 419, Currency: "THB", BillingCycle: "monthly", Status: "active"},
+				{Name: "Disney+", CategoryID: &streaming, Amount: 1188, Currency: "THB", BillingCycle: "yearly", Status: "canceled"},
+				{Name: "Domain", CategoryID: &tools, Amount: 12, Currency: "USD", BillingCycle: "yearly", Status: "active"},
+				{Name: "Gym", Amount: 1000, Currency: "THB", BillingCycle: "quarterly"},
+			}, nil)
+
+		categoryService := service.NewCategoryService(categoryRepo, subscriptionRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := categoryService.GetCategorySpend(10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "THB", res.BaseCurrency)
+		assert.Len(t, res.Categories, 3)
+
+		assert.Equal(t, 419.0, res.Categories[0].MonthlySpend)
+		assert.Equal(t, 1, res.Categories[0].Subscriptions)
+		assert.Equal(t, -19.0, *res.Categories[0].Remaining)
+		assert.Equal(t, 104.75, *res.Categories[0].PercentUsed)
+
+		assert.Equal(t, 35.0, res.Categories[1].MonthlySpend)
+		assert.Nil(t, res.Categories[1].Remaining)
+
+		assert.Nil(t, res.Categories[2].CategoryID)
+		assert.Equal(t, "Uncategorized", res.Categories[2].Name)
+		assert.Equal(t, 333.33, res.Categories[2].MonthlySpend)
+
+		assert.Equal(t, 787.33, res.Total)
+	})
+}
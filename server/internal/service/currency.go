@@ -0,0 +1,82 @@
+package service
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const defaultCurrency = "THB"
+
+var ErrUnknownCurrency = errors.New("no exchange rate for currency")
+
+// UnconvertedAmount is left out of a total because there is no exchange
+// rate for its currency. Reporting it keeps one odd currency from failing
+// the whole response.
+type UnconvertedAmount struct {
+	SubscriptionID *int    `json:"subscription_id"`
+	Name           string  `json:"name"`
+	Amount         float64 `json:"amount"`
+	Currency       string  `json:"currency"`
+}
+
+func unconvertedSubscription(sub repository.Subscription) UnconvertedAmount {
+	id := sub.SubscriptionID
+	return UnconvertedAmount{
+		SubscriptionID: &id,
+		Name:           sub.Name,
+		Amount:         float64(sub.Amount),
+		Currency:       normalizeCurrency(sub.Currency),
+	}
+}
+
+// currencyConverter converts amounts into a user's base currency using
+// rates expressed against a single pivot currency.
+type currencyConverter struct {
+	base  string
+	rates map[string]float64
+}
+
+func newCurrencyConverter(base string, rates map[string]float64) currencyConverter {
+	return currencyConverter{base: normalizeCurrency(base), rates: rates}
+}
+
+func (c currencyConverter) convert(amount float64, from string) (float64, error) {
+	from = normalizeCurrency(from)
+	if from == c.base {
+		return amount, nil
+	}
+
+	fromRate, ok := c.rates[from]
+	if !ok || fromRate == 0 {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, from)
+	}
+	baseRate, ok := c.rates[c.base]
+	if !ok || baseRate == 0 {
+		return 0, fmt.Errorf("%w %s", ErrUnknownCurrency, c.base)
+	}
+
+	return amount * fromRate / baseRate, nil
+}
+
+func normalizeCurrency(currency string) string {
+	currency = strings.ToUpper(strings.TrimSpace(currency))
+	if currency == "" {
+		return defaultCurrency
+	}
+	return currency
+}
+
+func isCurrencyCode(currency string) bool {
+	if len(currency) != 3 {
+		return false
+	}
+	for _, r := range currency {
+		if r < 'A' || r > 'Z' {
+			return false
+		}
+	}
+	return true
+}
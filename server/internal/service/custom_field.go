@@ -0,0 +1,35 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+const (
+	CustomFieldTypeText   = "text"
+	CustomFieldTypeNumber = "number"
+	CustomFieldTypeDate   = "date"
+	CustomFieldTypeSelect = "select"
+	CustomFieldTypeURL    = "url"
+)
+
+type CustomFieldResponse struct {
+	FieldID int      `json:"id"`
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	Type    string   `json:"type"`
+	Options []string `json:"options"`
+}
+
+// CustomFieldRequest defines a field. Key defaults to a slug of Label;
+// key and type cannot change after creation.
+type CustomFieldRequest struct {
+	Key     string   `json:"key"`
+	Label   string   `json:"label"`
+	Type    string   `json:"type"`
+	Options []string `json:"options"`
+}
+
+type CustomFieldService interface {
+	GetCustomFields(userID int) ([]CustomFieldResponse, error)
+	CreateCustomField(req CustomFieldRequest, userID int) (*CustomFieldResponse, error)
+	UpdateCustomField(id int, req CustomFieldRequest, userID int) (*CustomFieldResponse, error)
+	DeleteCustomField(id int, actor repository.Actor) error
+}
@@ -0,0 +1,308 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	maxCustomFieldOptions = 50
+	maxCustomTextLength   = 500
+)
+
+var (
+	ErrCustomFieldNotFound = errors.New("custom field not found")
+	ErrInvalidCustomField  = errors.New("invalid custom field")
+	ErrUnknownCustomField  = errors.New("unknown custom field")
+)
+
+var customFieldKeyPattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,49}$`)
+
+type customFieldService struct {
+	fieldRepo repository.CustomFieldRepository
+}
+
+func NewCustomFieldService(fieldRepo repository.CustomFieldRepository) CustomFieldService {
+	return customFieldService{fieldRepo: fieldRepo}
+}
+
+func toCustomFieldResponse(f repository.CustomField) CustomFieldResponse {
+	options := f.Options
+	if options == nil {
+		options = []string{}
+	}
+	return CustomFieldResponse{
+		FieldID: f.FieldID,
+		Key:     f.Key,
+		Label:   f.Label,
+		Type:    f.Type,
+		Options: options,
+	}
+}
+
+func (s customFieldService) GetCustomFields(userID int) ([]CustomFieldResponse, error) {
+	fields, err := s.fieldRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []CustomFieldResponse{}
+	for _, f := range fields {
+		res = append(res, toCustomFieldResponse(f))
+	}
+
+	return res, nil
+}
+
+func (s customFieldService) CreateCustomField(req CustomFieldRequest, userID int) (*CustomFieldResponse, error) {
+	field, err := normalizeCustomFieldRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	key := strings.TrimSpace(req.Key)
+	if key == "" {
+		key = slugify(field.Label)
+	}
+	if !customFieldKeyPattern.MatchString(key) {
+		return nil, fmt.Errorf("%w: key must be lowercase letters, digits and underscores, starting with a letter", ErrInvalidCustomField)
+	}
+	field.Key = key
+
+	created, err := s.fieldRepo.Create(field, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toCustomFieldResponse(*created)
+	return &res, nil
+}
+
+func (s customFieldService) UpdateCustomField(id int, req CustomFieldRequest, userID int) (*CustomFieldResponse, error) {
+	fields, err := s.fieldRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	var existing *repository.CustomField
+	for i := range fields {
+		if fields[i].FieldID == id {
+			existing = &fields[i]
+		}
+	}
+	if existing == nil {
+		return nil, ErrCustomFieldNotFound
+	}
+
+	// values are stored in the field's type, so it cannot change
+	req.Type = existing.Type
+	field, err := normalizeCustomFieldRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	field.FieldID = id
+
+	updated, err := s.fieldRepo.Update(field, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrCustomFieldNotFound
+		}
+		return nil, err
+	}
+
+	res := toCustomFieldResponse(*updated)
+	return &res, nil
+}
+
+func (s customFieldService) DeleteCustomField(id int, actor repository.Actor) error {
+	err := s.fieldRepo.Delete(id, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrCustomFieldNotFound
+	}
+	return err
+}
+
+func normalizeCustomFieldRequest(req CustomFieldRequest) (*repository.CustomField, error) {
+	label := strings.TrimSpace(req.Label)
+	fieldType := strings.ToLower(strings.TrimSpace(req.Type))
+
+	switch {
+	case label == "":
+		return nil, fmt.Errorf("%w: label is required", ErrInvalidCustomField)
+	case len(label) > 100:
+		return nil, fmt.Errorf("%w: label must be at most 100 characters", ErrInvalidCustomField)
+	}
+
+	switch fieldType {
+	case CustomFieldTypeText, CustomFieldTypeNumber, CustomFieldTypeDate, CustomFieldTypeURL:
+		return &repository.CustomField{Label: label, Type: fieldType, Options: []string{}}, nil
+	case CustomFieldTypeSelect:
+	default:
+		return nil, fmt.Errorf("%w: type must be text, number, date, select or url", ErrInvalidCustomField)
+	}
+
+	seen := map[string]bool{}
+	options := []string{}
+	for _, o := range req.Options {
+		o = strings.TrimSpace(o)
+		if o == "" || seen[strings.ToLower(o)] {
+			continue
+		}
+		if len(o) > 100 {
+			return nil, fmt.Errorf("%w: option %q is longer than 100 characters", ErrInvalidCustomField, o)
+		}
+		seen[strings.ToLower(o)] = true
+		options = append(options, o)
+	}
+	if len(options) == 0 {
+		return nil, fmt.Errorf("%w: a select field needs at least one option", ErrInvalidCustomField)
+	}
+	if len(options) > maxCustomFieldOptions {
+		return nil, fmt.Errorf("%w: at most %d options", ErrInvalidCustomField, maxCustomFieldOptions)
+	}
+
+	return &repository.CustomField{Label: label, Type: fieldType, Options: options}, nil
+}
+
+// validateCustomFields checks values against the field definitions of the
+// subscription's creator and returns them normalized and encoded as a JSON
+// object. A null value is kept so that it clears the field.
+func validateCustomFields(fields []repository.CustomField, values map[string]any) (string, []string) {
+	byKey := map[string]repository.CustomField{}
+	for _, f := range fields {
+		byKey[f.Key] = f
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var problems []string
+	normalized := map[string]any{}
+	for _, key := range keys {
+		field, ok := byKey[key]
+		if !ok {
+			problems = append(problems, fmt.Sprintf("unknown custom field %q", key))
+			continue
+		}
+		if values[key] == nil {
+			normalized[key] = nil
+			continue
+		}
+
+		value, err := normalizeCustomValue(field, values[key])
+		if err != nil {
+			problems = append(problems, fmt.Sprintf("%s: %v", key, err))
+			continue
+		}
+		normalized[key] = value
+	}
+
+	data, err := json.Marshal(normalized)
+	if err != nil {
+		problems = append(problems, err.Error())
+	}
+	return string(data), problems
+}
+
+func normalizeCustomValue(field repository.CustomField, value any) (any, error) {
+	if field.Type == CustomFieldTypeNumber {
+		switch v := value.(type) {
+		case float64:
+			return v, nil
+		case string:
+			n, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+			if err != nil {
+				return nil, fmt.Errorf("%q is not a number", v)
+			}
+			return n, nil
+		}
+		return nil, errors.New("expected a number")
+	}
+
+	text, ok := value.(string)
+	if !ok {
+		return nil, errors.New("expected a string")
+	}
+	text = strings.TrimSpace(text)
+
+	switch field.Type {
+	case CustomFieldTypeDate:
+		d, err := parseDate(text)
+		if err != nil {
+			return nil, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+		}
+		return d.Format(dateLayout), nil
+	case CustomFieldTypeSelect:
+		for _, o := range field.Options {
+			if strings.EqualFold(o, text) {
+				return o, nil
+			}
+		}
+		return nil, fmt.Errorf("%q is not one of %s", text, strings.Join(field.Options, ", "))
+	case CustomFieldTypeURL:
+		u, err := url.Parse(text)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("invalid url %q", text)
+		}
+		return text, nil
+	}
+
+	if len(text) > maxCustomTextLength {
+		return nil, fmt.Errorf("longer than %d characters", maxCustomTextLength)
+	}
+	return text, nil
+}
+
+// decodeCustomFields reads the JSON object stored on a subscription.
+func decodeCustomFields(data string) map[string]any {
+	values := map[string]any{}
+	if data != "" {
+		_ = json.Unmarshal([]byte(data), &values)
+	}
+	return values
+}
+
+// formatCustomValue renders a stored value for tabular exports.
+func formatCustomValue(value any) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+func slugify(label string) string {
+	var b strings.Builder
+	underscore := false
+	for _, r := range strings.ToLower(label) {
+		switch {
+		case r >= 'a' && r <= 'z', r >= '0' && r <= '9' && b.Len() > 0:
+			b.WriteRune(r)
+			underscore = false
+		case b.Len() > 0 && !underscore:
+			b.WriteByte('_')
+			underscore = true
+		}
+	}
+
+	slug := strings.TrimSuffix(b.String(), "_")
+	if len(slug) > 50 {
+		slug = strings.TrimSuffix(slug[:50], "_")
+	}
+	return slug
+}
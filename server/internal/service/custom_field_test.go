@@ -0,0 +1,158 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreateCustomField(t *testing.T) {
+	t.Run("Key From Label", func(t *testing.T) {
+		// arrange
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		fieldRepo.
+			On("Create", &repository.CustomField{Key: "account_e_mail", Label: "Account e-mail", Type: "text", Options: []string{}}, 10).
+			Return(&repository.CustomField{FieldID: 1, Key: "account_e_mail", Label: "Account e-mail", Type: "text"}, nil)
+
+		fieldService := service.NewCustomFieldService(fieldRepo)
+
+		// act
+		res, err := fieldService.CreateCustomField(service.CustomFieldRequest{Label: "Account e-mail", Type: "Text"}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "account_e_mail", res.Key)
+		assert.Equal(t, []string{}, res.Options)
+		fieldRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Definitions", func(t *testing.T) {
+		// arrange
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+		fieldService := service.NewCustomFieldService(fieldRepo)
+
+		// act & assert
+		for _, req := range []service.CustomFieldRequest{
+			{Label: "Seats", Type: "integer"},
+			{Label: "Plan", Type: "select"},
+			{Label: "Cost center", Key: "Cost Center", Type: "text"},
+			{Label: " ", Type: "text"},
+		} {
+			_, err := fieldService.CreateCustomField(req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidCustomField)
+		}
+		fieldRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSetCustomFields(t *testing.T) {
+	fields := []repository.CustomField{
+		{FieldID: 1, Key: "cost_center", Type: "text"},
+		{FieldID: 2, Key: "seats", Type: "number"},
+		{FieldID: 3, Key: "renewal_notice", Type: "date"},
+		{FieldID: 4, Key: "plan", Type: "select", Options: []string{"Basic", "Premium"}},
+		{FieldID: 5, Key: "login_url", Type: "url"},
+	}
+
+	t.Run("Values Are Normalized", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		fieldRepo.On("GetAll", 10).Return(fields, nil)
+		subscriptionRepo.
+			On("SetCustomFields", 1, actor, `{"cost_center":"IT","login_url":"https://example.com/login","plan":"Premium","renewal_notice":"2025-03-01","seats":4}`).
+			Return(nil)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, CustomFields: `{"seats": 4}`, CreatedBy: 10}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, fieldRepo)
+
+		// act
+		res, err := subService.SetCustomFields(1, map[string]any{
+			"cost_center":    " IT ",
+			"seats":          "4",
+			"renewal_notice": "2025-03-01T00:00:00Z",
+			"plan":           "premium",
+			"login_url":      "https://example.com/login",
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, map[string]any{"seats": float64(4)}, res.CustomFields)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Values", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		fieldRepo.On("GetAll", 10).Return(fields, nil)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, CreatedBy: 10}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, fieldRepo)
+
+		// act & assert
+		for _, values := range []map[string]any{
+			{"contract": "A-1"},
+			{"seats": "four"},
+			{"renewal_notice": "next week"},
+			{"plan": "Family"},
+			{"login_url": "javascript:alert(1)"},
+		} {
+			_, err := subService.SetCustomFields(1, values, actor)
+			assert.ErrorIs(t, err, service.ErrInvalidSubscription)
+		}
+		subscriptionRepo.AssertNotCalled(t, "SetCustomFields", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Fields Of The Creator", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		fieldRepo.On("GetAll", 20).Return(fields, nil)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, CustomFields: `{"plan":"Basic","seats":4}`, CreatedBy: 20}, nil)
+		subscriptionRepo.
+			On("SetCustomFields", 1, actor, `{"plan":null,"seats":5}`).
+			Return(nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, fieldRepo)
+
+		// act
+		_, err := subService.SetCustomFields(1, map[string]any{"seats": float64(5), "plan": nil}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		fieldRepo.AssertNotCalled(t, "GetAll", 10)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Unknown Filter Field", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		fieldRepo.On("GetShared", 10).Return(fields, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, fieldRepo)
+
+		// act
+		_, err := subService.GetSubscriptions(10, service.SubscriptionFilter{
+			CustomFields: map[string]string{"contract": "A-1"},
+		})
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrUnknownCustomField)
+	})
+}
@@ -0,0 +1,55 @@
+package service
+
+import "context"
+
+const (
+	DigestOff     = "off"
+	DigestWeekly  = "weekly"
+	DigestMonthly = "monthly"
+)
+
+type DigestPriceChange struct {
+	SubscriptionID int     `json:"subscription_id"`
+	Name           string  `json:"name"`
+	OldAmount      float32 `json:"old_amount"`
+	NewAmount      float32 `json:"new_amount"`
+	OldCurrency    string  `json:"old_currency"`
+	NewCurrency    string  `json:"new_currency"`
+	ChangedOn      string  `json:"changed_on"`
+}
+
+// Digest summarizes one period for a user. Weekly periods start on Monday
+// and monthly ones on the 1st, both in the user's timezone. Renewals and
+// trials cover the rest of the period; price changes go back to the start
+// of the previous one.
+type Digest struct {
+	Frequency    string              `json:"frequency"`
+	PeriodStart  string              `json:"period_start"`
+	PeriodEnd    string              `json:"period_end"`
+	From         string              `json:"from"`
+	BaseCurrency string              `json:"base_currency"`
+	Renewals     []RenewalOccurrence `json:"renewals"`
+	RenewalTotal float64             `json:"renewal_total"`
+	TrialsEnding []RenewalOccurrence `json:"trials_ending"`
+	BudgetPeriod string              `json:"budget_period"` // YYYY-MM
+	Budgets      []BudgetStatus      `json:"budgets"`
+	PriceChanges []DigestPriceChange `json:"price_changes"`
+}
+
+// DigestPreview is a digest rendered the way it would be emailed now.
+type DigestPreview struct {
+	Subject string `json:"subject"`
+	Text    string `json:"text"`
+	HTML    string `json:"html"`
+	Digest  Digest `json:"digest"`
+}
+
+type DigestService interface {
+	// PreviewDigest renders the user's digest without sending it. An empty
+	// frequency uses the user's setting, or weekly when digests are off.
+	PreviewDigest(userID int, frequency string) (*DigestPreview, error)
+	// SendDue emails every user whose current period has not had its
+	// digest yet. Periods are claimed before sending, so a restart never
+	// sends one twice.
+	SendDue(ctx context.Context) error
+}
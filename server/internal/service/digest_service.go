@@ -0,0 +1,253 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	// digestSendHour is when a period starts, in the user's timezone, so
+	// digests arrive in the morning rather than at midnight.
+	digestSendHour    = 8
+	digestSendTimeout = 30 * time.Second
+)
+
+type digestService struct {
+	digestRepo repository.DigestRepository
+	userRepo   repository.UserRepository
+	chargeRepo repository.ChargeRepository
+	renewals   RenewalService
+	budgets    BudgetService
+	mailer     notifier.Notifier
+}
+
+// NewDigestService builds digests from the renewal and budget services and
+// emails them with mailer. A nil mailer means email is not configured on
+// this server: digests can still be previewed but are never sent.
+func NewDigestService(
+	digestRepo repository.DigestRepository,
+	userRepo repository.UserRepository,
+	chargeRepo repository.ChargeRepository,
+	renewals RenewalService,
+	budgets BudgetService,
+	mailer notifier.Notifier,
+) DigestService {
+	return digestService{
+		digestRepo: digestRepo,
+		userRepo:   userRepo,
+		chargeRepo: chargeRepo,
+		renewals:   renewals,
+		budgets:    budgets,
+		mailer:     mailer,
+	}
+}
+
+func isDigestFrequency(frequency string) bool {
+	return frequency == DigestOff || frequency == DigestWeekly || frequency == DigestMonthly
+}
+
+// digestPeriod returns the period of frequency that is running at now in
+// loc, and the day inside it that now falls on.
+func digestPeriod(frequency string, now time.Time, loc *time.Location) (day, start, end, previous time.Time) {
+	day = localDay(now.Add(-digestSendHour*time.Hour), loc)
+
+	if frequency == DigestMonthly {
+		start = day.AddDate(0, 0, 1-day.Day())
+		return day, start, start.AddDate(0, 1, -1), start.AddDate(0, -1, 0)
+	}
+
+	start = weekStart(day)
+	return day, start, start.AddDate(0, 0, 6), start.AddDate(0, 0, -7)
+}
+
+func (s digestService) PreviewDigest(userID int, frequency string) (*DigestPreview, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	if frequency == "" {
+		frequency = user.DigestFrequency
+	}
+	if frequency == "" || frequency == DigestOff {
+		frequency = DigestWeekly
+	}
+	if !isDigestFrequency(frequency) {
+		return nil, ErrInvalidDigest
+	}
+
+	digest, err := s.build(*user, frequency, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	return renderDigest(*digest)
+}
+
+func (s digestService) SendDue(ctx context.Context) error {
+	if s.mailer == nil {
+		return nil
+	}
+
+	users, err := s.digestRepo.GetRecipients()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, user := range users {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// one user's failure must not hold up everyone else's digest
+		if err := s.send(ctx, user, now); err != nil {
+			log.Printf("digest failed for user %d: %v", user.ID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s digestService) send(ctx context.Context, user repository.User, now time.Time) error {
+	loc, err := loadTimezone(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	_, start, _, _ := digestPeriod(user.DigestFrequency, now, loc)
+	periodStart := start.Format(dateLayout)
+
+	claimed, err := s.digestRepo.Claim(user.ID, user.DigestFrequency, periodStart)
+	if err != nil || !claimed {
+		return err
+	}
+
+	// from here on the claim has to be released if nothing was sent, or
+	// the period would be skipped
+	sent := false
+	defer func() {
+		if !sent {
+			if err := s.digestRepo.Release(user.ID, user.DigestFrequency, periodStart); err != nil {
+				log.Printf("releasing digest of user %d failed: %v", user.ID, err)
+			}
+		}
+	}()
+
+	digest, err := s.build(user, user.DigestFrequency, now)
+	if err != nil {
+		return err
+	}
+	if digestIsEmpty(*digest) {
+		// nothing to report; the period still counts as done
+		sent = true
+		return nil
+	}
+
+	preview, err := renderDigest(*digest)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, digestSendTimeout)
+	defer cancel()
+
+	if err := s.mailer.Send(ctx, user.Email, notifier.Message{
+		Title: preview.Subject,
+		Body:  preview.Text,
+		HTML:  preview.HTML,
+	}); err != nil {
+		return fmt.Errorf("sending to %s: %w", user.Email, err)
+	}
+
+	sent = true
+	return nil
+}
+
+func (s digestService) build(user repository.User, frequency string, now time.Time) (*Digest, error) {
+	loc, err := loadTimezone(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	day, start, end, previous := digestPeriod(frequency, now, loc)
+
+	renewals, err := s.renewals.GetRenewals(user.ID, day.Format(dateLayout), end.Format(dateLayout))
+	if err != nil {
+		return nil, err
+	}
+
+	budgets, err := s.budgets.GetBudgetStatus(user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	digest := &Digest{
+		Frequency:    frequency,
+		PeriodStart:  start.Format(dateLayout),
+		PeriodEnd:    end.Format(dateLayout),
+		From:         day.Format(dateLayout),
+		BaseCurrency: renewals.BaseCurrency,
+		RenewalTotal: renewals.Total,
+		Renewals:     []RenewalOccurrence{},
+		TrialsEnding: []RenewalOccurrence{},
+		BudgetPeriod: budgets.Period,
+		Budgets:      budgets.Budgets,
+		PriceChanges: []DigestPriceChange{},
+	}
+
+	// a trial's first renewal is when it starts to be billed
+	for _, o := range renewals.Occurrences {
+		if o.Trial {
+			digest.TrialsEnding = append(digest.TrialsEnding, o)
+		} else {
+			digest.Renewals = append(digest.Renewals, o)
+		}
+	}
+
+	since := previous.Format(dateLayout)
+	err = s.chargeRepo.EachPriceChange(user.ID, func(p repository.PriceChange) error {
+		changedOn := p.ChangedAt
+		if len(changedOn) > len(dateLayout) {
+			changedOn = changedOn[:len(dateLayout)]
+		}
+		if changedOn < since {
+			return nil
+		}
+
+		digest.PriceChanges = append(digest.PriceChanges, DigestPriceChange{
+			SubscriptionID: p.SubscriptionID,
+			Name:           p.SubscriptionName,
+			OldAmount:      p.OldAmount,
+			NewAmount:      p.NewAmount,
+			OldCurrency:    p.OldCurrency,
+			NewCurrency:    p.NewCurrency,
+			ChangedOn:      changedOn,
+		})
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return digest, nil
+}
+
+// digestIsEmpty reports whether d has anything worth an email. Budgets
+// that are all fine on their own are not.
+func digestIsEmpty(d Digest) bool {
+	if len(d.Renewals) > 0 || len(d.TrialsEnding) > 0 || len(d.PriceChanges) > 0 {
+		return false
+	}
+	for _, b := range d.Budgets {
+		if b.Status != budgetStatusOK {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	htmltemplate "html/template"
+	"strings"
+	texttemplate "text/template"
+	"time"
+)
+
+var digestFuncs = map[string]any{
+	"money": func(v float64) string { return fmt.Sprintf("%.2f", v) },
+	"price": func(v float32) string { return fmt.Sprintf("%.2f", v) },
+	"day":   digestDay,
+	"title": func(s string) string {
+		if s == "" {
+			return s
+		}
+		return strings.ToUpper(s[:1]) + s[1:]
+	},
+}
+
+// digestDay shows a YYYY-MM-DD date as e.g. "Mon 6 Jan".
+func digestDay(date string) string {
+	t, err := time.Parse(dateLayout, date)
+	if err != nil {
+		return date
+	}
+	return t.Format("Mon 2 Jan")
+}
+
+var digestText = texttemplate.Must(texttemplate.New("digest").Funcs(digestFuncs).Parse(
+	`Your {{.Frequency}} digest, {{day .From}} to {{day .PeriodEnd}}
+{{if .Renewals}}
+Upcoming renewals ({{money .RenewalTotal}} {{.BaseCurrency}} in total)
+{{range .Renewals}}- {{day .Date}}: {{.Name}}, {{price .Amount}} {{.Currency}}
+{{end}}{{end}}{{if .TrialsEnding}}
+Trials ending
+{{range .TrialsEnding}}- {{day .Date}}: {{.Name}} starts billing {{price .Amount}} {{.Currency}}
+{{end}}{{end}}{{if .Budgets}}
+Spend versus budget for {{.BudgetPeriod}}
+{{range .Budgets}}- {{with .CategoryName}}{{.}}{{else}}Total{{end}}: {{money .Projected}} projected and {{money .Actual}} charged of {{money .Amount}} {{$.BaseCurrency}} ({{.Status}})
+{{end}}{{end}}{{if .PriceChanges}}
+Price changes
+{{range .PriceChanges}}- {{.Name}}: {{price .OldAmount}} {{.OldCurrency}} to {{price .NewAmount}} {{.NewCurrency}} on {{day .ChangedOn}}
+{{end}}{{end}}`))
+
+var digestHTML = htmltemplate.Must(htmltemplate.New("digest").Funcs(digestFuncs).Parse(
+	`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif; color: #222;">
+<h2>{{title .Frequency}} digest, {{day .From}} to {{day .PeriodEnd}}</h2>
+{{if .Renewals}}
+<h3>Upcoming renewals</h3>
+<table cellpadding="4">
+{{range .Renewals}}<tr><td>{{day .Date}}</td><td>{{.Name}}</td><td align="right">{{price .Amount}} {{.Currency}}</td></tr>
+{{end}}<tr><td colspan="2"><strong>Total</strong></td><td align="right"><strong>{{money .RenewalTotal}} {{.BaseCurrency}}</strong></td></tr>
+</table>
+{{end}}{{if .TrialsEnding}}
+<h3>Trials ending</h3>
+<ul>
+{{range .TrialsEnding}}<li>{{day .Date}}: {{.Name}} starts billing {{price .Amount}} {{.Currency}}</li>
+{{end}}</ul>
+{{end}}{{if .Budgets}}
+<h3>Spend versus budget for {{.BudgetPeriod}}</h3>
+<table cellpadding="4">
+<tr><th align="left">Budget</th><th align="right">Projected</th><th align="right">Charged</th><th align="right">Budget</th><th></th></tr>
+{{range .Budgets}}<tr><td>{{with .CategoryName}}{{.}}{{else}}Total{{end}}</td><td align="right">{{money .Projected}}</td><td align="right">{{money .Actual}}</td><td align="right">{{money .Amount}} {{$.BaseCurrency}}</td><td>{{.Status}}</td></tr>
+{{end}}</table>
+{{end}}{{if .PriceChanges}}
+<h3>Price changes</h3>
+<ul>
+{{range .PriceChanges}}<li>{{.Name}}: {{price .OldAmount}} {{.OldCurrency}} to {{price .NewAmount}} {{.NewCurrency}} on {{day .ChangedOn}}</li>
+{{end}}</ul>
+{{end}}
+</body>
+</html>
+`))
+
+// renderDigest builds the email for d: a subject, plain text and HTML.
+func renderDigest(d Digest) (*DigestPreview, error) {
+	var text, html strings.Builder
+	if err := digestText.Execute(&text, d); err != nil {
+		return nil, err
+	}
+	if err := digestHTML.Execute(&html, d); err != nil {
+		return nil, err
+	}
+
+	return &DigestPreview{
+		Subject: fmt.Sprintf("Your %s subscription digest: %s to %s", d.Frequency, digestDay(d.From), digestDay(d.PeriodEnd)),
+		Text:    text.String(),
+		HTML:    html.String(),
+		Digest:  d,
+	}, nil
+}
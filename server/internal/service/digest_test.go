@@ -0,0 +1,187 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// newDigestService wires a digest service to real renewal and budget
+// services over mocks holding a renewal and a trial ending on day, and one
+// price change made on day.
+func newDigestService(digestRepo repository.DigestRepository, mailer notifier.Notifier, day string) service.DigestService {
+	userRepo := repository.NewUserRepositoryMock()
+	subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+	currencyRepo := repository.NewCurrencyRepositoryMock()
+	budgetRepo := repository.NewBudgetRepositoryMock()
+	chargeRepo := repository.NewChargeRepositoryMock()
+
+	userRepo.
+		On("GetByID", 10).
+		Return(&repository.User{ID: 10, Email: "john@test.com", BaseCurrency: "THB", Timezone: "UTC", DigestFrequency: "weekly"}, nil)
+	currencyRepo.
+		On("GetRates").
+		Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+	subscriptionRepo.
+		On("GetAll", 10).
+		Return([]repository.Subscription{
+			{SubscriptionID: 1, Name: "Tom & Jerry+", Amount: 149, Currency: "THB", BillingCycle: "monthly", BillingDate: day, Status: "active"},
+			{SubscriptionID: 2, Name: "Cloud", Amount: 10, Currency: "USD", BillingCycle: "monthly", BillingDate: day, Status: "active", Trial: true},
+		}, nil)
+	budgetRepo.
+		On("GetAll", 10).
+		Return([]repository.Budget{}, nil)
+	chargeRepo.
+		On("GetChargesBetween", 10, mock.Anything, mock.Anything).
+		Return([]repository.Charge{}, nil)
+	chargeRepo.
+		On("EachPriceChange", 10, mock.Anything).
+		Return([]repository.PriceChange{
+			{SubscriptionID: 1, SubscriptionName: "Tom & Jerry+", OldAmount: 129, NewAmount: 149, OldCurrency: "THB", NewCurrency: "THB", ChangedAt: day + "T10:00:00Z"},
+			{SubscriptionID: 1, SubscriptionName: "Tom & Jerry+", OldAmount: 99, NewAmount: 129, OldCurrency: "THB", NewCurrency: "THB", ChangedAt: "2020-01-01T10:00:00Z"},
+		}, nil)
+
+	renewalService := service.NewRenewalService(subscriptionRepo, userRepo, currencyRepo)
+	budgetService := service.NewBudgetService(budgetRepo, nil, subscriptionRepo, chargeRepo, userRepo, currencyRepo)
+
+	return service.NewDigestService(digestRepo, userRepo, chargeRepo, renewalService, budgetService, mailer)
+}
+
+// digestDay is the day digests are built for right now in UTC; periods
+// start at 08:00.
+func digestDay() string {
+	return time.Now().UTC().Add(-8 * time.Hour).Format("2006-01-02")
+}
+
+func TestPreviewDigest(t *testing.T) {
+	t.Run("Preview Digest Success", func(t *testing.T) {
+		// arrange
+		day := digestDay()
+		digestService := newDigestService(repository.NewDigestRepositoryMock(), nil, day)
+
+		// act
+		res, err := digestService.PreviewDigest(10, "")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "weekly", res.Digest.Frequency)
+		assert.Equal(t, day, res.Digest.From)
+		assert.Len(t, res.Digest.Renewals, 1)
+		assert.Len(t, res.Digest.TrialsEnding, 1)
+		assert.Equal(t, 499.0, res.Digest.RenewalTotal)
+		assert.Len(t, res.Digest.PriceChanges, 1)
+		assert.Equal(t, day, res.Digest.PriceChanges[0].ChangedOn)
+
+		assert.Contains(t, res.Subject, "weekly")
+		assert.Contains(t, res.Text, "Tom & Jerry+, 149.00 THB")
+		assert.Contains(t, res.Text, "Cloud starts billing 10.00 USD")
+		assert.Contains(t, res.HTML, "Tom &amp; Jerry&#43;")
+		assert.NotContains(t, res.HTML, "Tom & Jerry")
+	})
+
+	t.Run("Preview Digest Monthly", func(t *testing.T) {
+		// arrange
+		digestService := newDigestService(repository.NewDigestRepositoryMock(), nil, digestDay())
+
+		// act
+		res, err := digestService.PreviewDigest(10, "monthly")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "monthly", res.Digest.Frequency)
+		assert.Equal(t, "01", res.Digest.PeriodStart[8:])
+	})
+
+	t.Run("Preview Digest Invalid Frequency", func(t *testing.T) {
+		// arrange
+		digestService := newDigestService(repository.NewDigestRepositoryMock(), nil, digestDay())
+
+		// act
+		_, err := digestService.PreviewDigest(10, "daily")
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidDigest)
+	})
+}
+
+func TestSendDueDigests(t *testing.T) {
+	recipients := []repository.User{
+		{ID: 10, Email: "john@test.com", Timezone: "UTC", DigestFrequency: "weekly"},
+	}
+
+	t.Run("Send Due Once Per Period", func(t *testing.T) {
+		// arrange
+		digestRepo := repository.NewDigestRepositoryMock()
+		mailer := &recordingNotifier{}
+
+		digestRepo.
+			On("GetRecipients").
+			Return(recipients, nil)
+		digestRepo.
+			On("Claim", 10, "weekly", mock.Anything).
+			Return(true, nil).
+			Once()
+		digestRepo.
+			On("Claim", 10, "weekly", mock.Anything).
+			Return(false, nil)
+
+		digestService := newDigestService(digestRepo, mailer, digestDay())
+
+		// act
+		err := digestService.SendDue(context.Background())
+		assert.NoError(t, err)
+		err = digestService.SendDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, mailer.sent, 1)
+		assert.Contains(t, mailer.sent[0], "john@test.com: Your weekly subscription digest")
+		digestRepo.AssertNotCalled(t, "Release", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Send Due Releases Claim On Failure", func(t *testing.T) {
+		// arrange
+		digestRepo := repository.NewDigestRepositoryMock()
+		mailer := &recordingNotifier{err: errors.New("smtp is down")}
+
+		digestRepo.
+			On("GetRecipients").
+			Return(recipients, nil)
+		digestRepo.
+			On("Claim", 10, "weekly", mock.Anything).
+			Return(true, nil)
+		digestRepo.
+			On("Release", 10, "weekly", mock.Anything).
+			Return(nil)
+
+		digestService := newDigestService(digestRepo, mailer, digestDay())
+
+		// act
+		err := digestService.SendDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, mailer.sent, 1)
+		digestRepo.AssertExpectations(t)
+	})
+
+	t.Run("Send Due Without Email", func(t *testing.T) {
+		// arrange
+		digestRepo := repository.NewDigestRepositoryMock()
+		digestService := newDigestService(digestRepo, nil, digestDay())
+
+		// act
+		err := digestService.SendDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		digestRepo.AssertNotCalled(t, "GetRecipients")
+	})
+}
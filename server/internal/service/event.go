@@ -0,0 +1,53 @@
+package service
+
+import "errors"
+
+// EventNotificationCreated is pushed to a user's connected clients when a
+// notification is dispatched.
+const EventNotificationCreated = "notification.created"
+
+// EventPublisher receives the events services raise once a change is
+// stored.
+type EventPublisher interface {
+	Publish(userID int, event string, data any) error
+}
+
+// Publishers hands every event to each of its publishers, e.g. webhooks
+// and the real-time stream, and reports all of their failures.
+type Publishers []EventPublisher
+
+func (p Publishers) Publish(userID int, event string, data any) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publisher.Publish(userID, event, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// KeyedPublisher is an EventPublisher that drops an event it has already
+// been handed under the same key, so publishing it again after a partial
+// failure reaches it only once.
+type KeyedPublisher interface {
+	PublishOnce(userID int, event string, key string, data any) error
+}
+
+// PublishOnce hands the event to each publisher, by key to those that
+// drop repeats.
+func (p Publishers) PublishOnce(userID int, event string, key string, data any) error {
+	var errs []error
+	for _, publisher := range p {
+		if err := publishOnce(publisher, userID, event, key, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func publishOnce(publisher EventPublisher, userID int, event string, key string, data any) error {
+	if keyed, ok := publisher.(KeyedPublisher); ok {
+		return keyed.PublishOnce(userID, event, key, data)
+	}
+	return publisher.Publish(userID, event, data)
+}
@@ -0,0 +1,48 @@
+package service
+
+import "io"
+
+const (
+	ExportFormatCSV  = "csv"
+	ExportFormatJSON = "json"
+	ExportFormatXLSX = "xlsx"
+
+	ExportDatasetSubscriptions = "subscriptions"
+	ExportDatasetCharges       = "charges"
+	ExportDatasetPriceHistory  = "price_history"
+)
+
+type ChargeResponse struct {
+	ChargeID         int     `json:"id"`
+	SubscriptionID   *int    `json:"subscription_id"`
+	SubscriptionName string  `json:"subscription_name"`
+	ChargedOn        string  `json:"charged_on"`
+	Amount           float32 `json:"amount"`
+	Currency         string  `json:"currency"`
+	Source           string  `json:"source"`
+}
+
+type PriceChangeResponse struct {
+	PriceChangeID    int     `json:"id"`
+	SubscriptionID   int     `json:"subscription_id"`
+	SubscriptionName string  `json:"subscription_name"`
+	OldAmount        float32 `json:"old_amount"`
+	NewAmount        float32 `json:"new_amount"`
+	OldCurrency      string  `json:"old_currency"`
+	NewCurrency      string  `json:"new_currency"`
+	ChangedAt        string  `json:"changed_at"`
+}
+
+// ExportFile describes the download before any data is written, so
+// handlers can validate the request and set headers up front.
+type ExportFile struct {
+	Format      string
+	Datasets    []string
+	ContentType string
+	Filename    string
+}
+
+type ExportService interface {
+	PrepareExport(format, dataset string) (*ExportFile, error)
+	Export(userID int, file ExportFile, w io.Writer) error
+}
@@ -0,0 +1,316 @@
+package service
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+var ErrInvalidExport = errors.New("invalid export request")
+
+// The column order of each dataset is part of the export format; append
+// new columns at the end so existing spreadsheets keep working.
+var exportColumns = map[string][]string{
+	ExportDatasetSubscriptions: {
+		"id", "name", "category", "amount", "currency",
+		"billing_cycle", "billing_date", "status", "is_trial", "notes", "tags",
+	},
+	ExportDatasetCharges: {
+		"id", "subscription_id", "subscription_name",
+		"charged_on", "amount", "currency", "source",
+	},
+	ExportDatasetPriceHistory: {
+		"id", "subscription_id", "subscription_name",
+		"old_amount", "new_amount", "old_currency", "new_currency", "changed_at",
+	},
+}
+
+var exportDatasets = []string{
+	ExportDatasetSubscriptions,
+	ExportDatasetCharges,
+	ExportDatasetPriceHistory,
+}
+
+type exportService struct {
+	subRepo    repository.SubscriptionRepository
+	chargeRepo repository.ChargeRepository
+	fieldRepo  repository.CustomFieldRepository
+}
+
+func NewExportService(
+	subRepo repository.SubscriptionRepository,
+	chargeRepo repository.ChargeRepository,
+	fieldRepo repository.CustomFieldRepository,
+) ExportService {
+	return exportService{subRepo: subRepo, chargeRepo: chargeRepo, fieldRepo: fieldRepo}
+}
+
+// PrepareExport validates format and dataset. A CSV file can only hold one
+// table, so it defaults to subscriptions; JSON and XLSX default to every
+// dataset.
+func (s exportService) PrepareExport(format, dataset string) (*ExportFile, error) {
+	file := &ExportFile{Format: strings.ToLower(format)}
+
+	switch file.Format {
+	case ExportFormatCSV:
+		file.ContentType = "text/csv; charset=utf-8"
+	case ExportFormatJSON:
+		file.ContentType = "application/json"
+	case ExportFormatXLSX:
+		file.ContentType = "application/vnd.openxmlformats-officedocument.spreadsheetml.sheet"
+	default:
+		return nil, fmt.Errorf("%w: format must be csv, json or xlsx", ErrInvalidExport)
+	}
+
+	switch {
+	case dataset != "":
+		if _, ok := exportColumns[dataset]; !ok {
+			return nil, fmt.Errorf("%w: unknown dataset %q", ErrInvalidExport, dataset)
+		}
+		file.Datasets = []string{dataset}
+	case file.Format == ExportFormatCSV:
+		file.Datasets = []string{ExportDatasetSubscriptions}
+	default:
+		file.Datasets = exportDatasets
+	}
+
+	name := "subscout"
+	if len(file.Datasets) == 1 {
+		name += "-" + file.Datasets[0]
+	}
+	file.Filename = fmt.Sprintf("%s-%s.%s", name, time.Now().Format(dateLayout), file.Format)
+
+	return file, nil
+}
+
+// Export writes every requested dataset to w, row by row as the
+// repository produces them.
+func (s exportService) Export(userID int, file ExportFile, w io.Writer) error {
+	var out exportWriter
+	switch file.Format {
+	case ExportFormatCSV:
+		out = &csvExportWriter{w: csv.NewWriter(w)}
+	case ExportFormatJSON:
+		out = &jsonExportWriter{w: w}
+	case ExportFormatXLSX:
+		out = &xlsxExportWriter{x: newXLSXWriter(w)}
+	default:
+		return fmt.Errorf("%w: format must be csv, json or xlsx", ErrInvalidExport)
+	}
+
+	for _, dataset := range file.Datasets {
+		columns := exportColumns[dataset]
+
+		// every custom field gets its own column after the fixed ones
+		var fields []repository.CustomField
+		if dataset == ExportDatasetSubscriptions {
+			var err error
+			fields, err = s.fieldRepo.GetShared(userID)
+			if err != nil {
+				return err
+			}
+			columns = append(columns[:len(columns):len(columns)], customFieldColumns(fields)...)
+		}
+
+		if err := out.begin(dataset, columns); err != nil {
+			return err
+		}
+
+		var err error
+		switch dataset {
+		case ExportDatasetSubscriptions:
+			err = s.subRepo.Each(userID, func(sub repository.Subscription) error {
+				res := toResponse(sub)
+				res.BillingDate = formatDate(sub.BillingDate)
+
+				values := []any{
+					res.SubscriptionID, res.Name, res.Category, res.Amount, res.Currency,
+					res.BillingCycle, res.BillingDate, res.Status, res.Trial,
+					res.Notes, strings.Join(res.Tags, "; "),
+				}
+				for _, f := range fields {
+					values = append(values, formatCustomValue(res.CustomFields[f.Key]))
+				}
+				return out.row(res, values...)
+			})
+		case ExportDatasetCharges:
+			err = s.chargeRepo.EachCharge(userID, func(charge repository.Charge) error {
+				res := ChargeResponse(charge)
+				res.ChargedOn = formatDate(charge.ChargedOn)
+
+				subscriptionID := ""
+				if res.SubscriptionID != nil {
+					subscriptionID = strconv.Itoa(*res.SubscriptionID)
+				}
+				return out.row(res,
+					res.ChargeID, subscriptionID, res.SubscriptionName,
+					res.ChargedOn, res.Amount, res.Currency, res.Source)
+			})
+		case ExportDatasetPriceHistory:
+			err = s.chargeRepo.EachPriceChange(userID, func(change repository.PriceChange) error {
+				res := PriceChangeResponse(change)
+				return out.row(res,
+					res.PriceChangeID, res.SubscriptionID, res.SubscriptionName,
+					res.OldAmount, res.NewAmount, res.OldCurrency, res.NewCurrency, res.ChangedAt)
+			})
+		}
+		if err != nil {
+			return err
+		}
+
+		if err := out.end(); err != nil {
+			return err
+		}
+	}
+
+	return out.close()
+}
+
+func customFieldColumns(fields []repository.CustomField) []string {
+	columns := make([]string, len(fields))
+	for i, f := range fields {
+		columns[i] = "cf_" + f.Key
+	}
+	return columns
+}
+
+func formatDate(value string) string {
+	t, err := parseDate(value)
+	if err != nil {
+		return value
+	}
+	return t.Format(dateLayout)
+}
+
+// exportWriter receives each row both as an object (for JSON) and as
+// values in column order (for tabular formats).
+type exportWriter interface {
+	begin(dataset string, columns []string) error
+	row(record any, values ...any) error
+	end() error
+	close() error
+}
+
+type csvExportWriter struct {
+	w *csv.Writer
+}
+
+// csvFormulaPrefixes make a spreadsheet run a cell as a formula.
+const csvFormulaPrefixes = "=+-@\t\r"
+
+// csvText neutralises user text that Excel or Sheets would run as a
+// formula by prefixing it with a quote, which both read as "this is text".
+func csvText(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+func (c *csvExportWriter) begin(_ string, columns []string) error {
+	return c.w.Write(columns)
+}
+
+func (c *csvExportWriter) row(_ any, values ...any) error {
+	record := make([]string, len(values))
+	for i, v := range values {
+		switch n := v.(type) {
+		case float32:
+			record[i] = strconv.FormatFloat(float64(n), 'f', 2, 32)
+		case string:
+			record[i] = csvText(n)
+		default:
+			record[i] = fmt.Sprint(v)
+		}
+	}
+	return c.w.Write(record)
+}
+
+func (c *csvExportWriter) end() error {
+	c.w.Flush()
+	return c.w.Error()
+}
+
+func (c *csvExportWriter) close() error {
+	return nil
+}
+
+type jsonExportWriter struct {
+	w       io.Writer
+	started bool
+	rows    int
+}
+
+func (j *jsonExportWriter) begin(dataset string, _ []string) error {
+	prefix := ","
+	if !j.started {
+		prefix = "{"
+		j.started = true
+	}
+	j.rows = 0
+	_, err := fmt.Fprintf(j.w, "%s%q:[", prefix, dataset)
+	return err
+}
+
+func (j *jsonExportWriter) row(record any, _ ...any) error {
+	b, err := json.Marshal(record)
+	if err != nil {
+		return err
+	}
+	if j.rows > 0 {
+		if _, err := io.WriteString(j.w, ","); err != nil {
+			return err
+		}
+	}
+	j.rows++
+	_, err = j.w.Write(b)
+	return err
+}
+
+func (j *jsonExportWriter) end() error {
+	_, err := io.WriteString(j.w, "]")
+	return err
+}
+
+func (j *jsonExportWriter) close() error {
+	if !j.started {
+		_, err := io.WriteString(j.w, "{}")
+		return err
+	}
+	_, err := io.WriteString(j.w, "}")
+	return err
+}
+
+type xlsxExportWriter struct {
+	x *xlsxWriter
+}
+
+func (e *xlsxExportWriter) begin(dataset string, columns []string) error {
+	if err := e.x.startSheet(dataset); err != nil {
+		return err
+	}
+	header := make([]any, len(columns))
+	for i, c := range columns {
+		header[i] = c
+	}
+	return e.x.writeRow(header...)
+}
+
+func (e *xlsxExportWriter) row(_ any, values ...any) error {
+	return e.x.writeRow(values...)
+}
+
+func (e *xlsxExportWriter) end() error {
+	return e.x.endSheet()
+}
+
+func (e *xlsxExportWriter) close() error {
+	return e.x.close()
+}
@@ -0,0 +1,169 @@
+package service_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestExport(t *testing.T) {
+	subscriptionID := 1
+	subs := []repository.Subscription{
+		{
+			SubscriptionID: 1,
+			Name:           "Netflix, Premium",
+			Category:       "Entertainment",
+			Amount:         419,
+			Currency:       "THB",
+			BillingCycle:   "monthly",
+			BillingDate:    "2025-02-01T00:00:00Z",
+			Status:         "active",
+			Tags:           []string{"family", "shared"},
+			CustomFields:   `{"cost_center": "IT", "seats": 4}`,
+		},
+	}
+	charges := []repository.Charge{
+		{ChargeID: 3, SubscriptionID: &subscriptionID, SubscriptionName: "Netflix, Premium", ChargedOn: "2025-01-01", Amount: 419, Currency: "THB", Source: "statement"},
+	}
+	changes := []repository.PriceChange{
+		{PriceChangeID: 5, SubscriptionID: 1, SubscriptionName: "Netflix, Premium", OldAmount: 349, NewAmount: 419, OldCurrency: "THB", NewCurrency: "THB"},
+	}
+
+	setup := func() service.ExportService {
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		chargeRepo := repository.NewChargeRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+
+		subscriptionRepo.On("Each", 10, mock.Anything).Return(subs, nil).Maybe()
+		chargeRepo.On("EachCharge", 10, mock.Anything).Return(charges, nil).Maybe()
+		chargeRepo.On("EachPriceChange", 10, mock.Anything).Return(changes, nil).Maybe()
+		fieldRepo.On("GetShared", 10).Return([]repository.CustomField{
+			{FieldID: 1, Key: "cost_center", Label: "Cost center", Type: "text"},
+			{FieldID: 2, Key: "seats", Label: "Seats", Type: "number"},
+			{FieldID: 3, Key: "login_url", Label: "Login URL", Type: "url"},
+		}, nil).Maybe()
+
+		return service.NewExportService(subscriptionRepo, chargeRepo, fieldRepo)
+	}
+
+	t.Run("CSV Subscriptions", func(t *testing.T) {
+		// arrange
+		exportService := setup()
+		file, err := exportService.PrepareExport("csv", "")
+		assert.NoError(t, err)
+
+		// act
+		var buf bytes.Buffer
+		err = exportService.Export(10, *file, &buf)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{service.ExportDatasetSubscriptions}, file.Datasets)
+		assert.Equal(t,
+			"id,name,category,amount,currency,billing_cycle,billing_date,status,is_trial,notes,tags,cf_cost_center,cf_seats,cf_login_url\n"+
+				"1,\"Netflix, Premium\",Entertainment,419.00,THB,monthly,2025-02-01,active,false,,family; shared,IT,4,\n",
+			buf.String())
+	})
+
+	t.Run("JSON All Datasets", func(t *testing.T) {
+		// arrange
+		exportService := setup()
+		file, err := exportService.PrepareExport("json", "")
+		assert.NoError(t, err)
+
+		// act
+		var buf bytes.Buffer
+		err = exportService.Export(10, *file, &buf)
+
+		// assert
+		assert.NoError(t, err)
+
+		var out map[string][]map[string]any
+		assert.NoError(t, json.Unmarshal(buf.Bytes(), &out))
+		assert.Len(t, out["subscriptions"], 1)
+		assert.Len(t, out["charges"], 1)
+		assert.Len(t, out["price_history"], 1)
+		assert.Equal(t, float64(349), out["price_history"][0]["old_amount"])
+	})
+
+	t.Run("XLSX Workbook", func(t *testing.T) {
+		// arrange
+		exportService := setup()
+		file, err := exportService.PrepareExport("xlsx", "")
+		assert.NoError(t, err)
+
+		// act
+		var buf bytes.Buffer
+		err = exportService.Export(10, *file, &buf)
+
+		// assert
+		assert.NoError(t, err)
+
+		zr, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+		assert.NoError(t, err)
+
+		names := []string{}
+		for _, f := range zr.File {
+			names = append(names, f.Name)
+		}
+		assert.Contains(t, names, "xl/workbook.xml")
+		assert.Contains(t, names, "xl/worksheets/sheet3.xml")
+	})
+
+	t.Run("Formulas Stay Text", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		fieldRepo := repository.NewCustomFieldRepositoryMock()
+		subscriptionRepo.On("Each", 10, mock.Anything).Return([]repository.Subscription{{
+			SubscriptionID: 2,
+			Name:           `=HYPERLINK("https://evil.example","Netflix")`,
+			Category:       "@SUM(A1)",
+			Amount:         -5,
+			Notes:          "+1 seat",
+			CustomFields:   `{"cost_center": "-IT"}`,
+		}}, nil)
+		fieldRepo.On("GetShared", 10).Return([]repository.CustomField{
+			{FieldID: 1, Key: "cost_center", Label: "Cost center", Type: "text"},
+		}, nil)
+		exportService := service.NewExportService(subscriptionRepo, repository.NewChargeRepositoryMock(), fieldRepo)
+
+		// act
+		var csvBuf, xlsxBuf bytes.Buffer
+		csvErr := exportService.Export(10, service.ExportFile{Format: "csv", Datasets: []string{"subscriptions"}}, &csvBuf)
+		xlsxErr := exportService.Export(10, service.ExportFile{Format: "xlsx", Datasets: []string{"subscriptions"}}, &xlsxBuf)
+
+		// assert
+		assert.NoError(t, csvErr)
+		assert.Contains(t, csvBuf.String(), `"'=HYPERLINK(""https://evil.example"",""Netflix"")",'@SUM(A1),-5.00,`)
+		assert.Contains(t, csvBuf.String(), `,'+1 seat,,'-IT`)
+
+		assert.NoError(t, xlsxErr)
+		zr, err := zip.NewReader(bytes.NewReader(xlsxBuf.Bytes()), int64(xlsxBuf.Len()))
+		assert.NoError(t, err)
+		f, err := zr.Open("xl/worksheets/sheet1.xml")
+		assert.NoError(t, err)
+		sheet, err := io.ReadAll(f)
+		assert.NoError(t, err)
+		assert.Contains(t, string(sheet), `<c r="B2" t="inlineStr"><is><t xml:space="preserve">=HYPERLINK(`)
+		assert.NotContains(t, string(sheet), "<f>")
+	})
+
+	t.Run("Invalid Format", func(t *testing.T) {
+		// arrange
+		exportService := setup()
+
+		// act
+		file, err := exportService.PrepareExport("pdf", "")
+
+		// assert
+		assert.Nil(t, file)
+		assert.ErrorIs(t, err, service.ErrInvalidExport)
+	})
+}
@@ -0,0 +1,94 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const icsMaxLineOctets = 75
+
+// icsWriter emits RFC 5545 content lines, taking care of CRLF line endings
+// and folding long lines.
+type icsWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *icsWriter) line(name, value string) {
+	w.fold(name + ":" + value)
+}
+
+func (w *icsWriter) text(name, value string) {
+	w.line(name, escapeICSText(value))
+}
+
+func (w *icsWriter) bytes() []byte {
+	return w.buf.Bytes()
+}
+
+// fold splits a content line so no physical line exceeds 75 octets,
+// never cutting a multi-byte character in half.
+func (w *icsWriter) fold(line string) {
+	limit := icsMaxLineOctets
+	for len(line) > limit {
+		cut := limit
+		for cut > 0 && !utf8.RuneStart(line[cut]) {
+			cut--
+		}
+		w.buf.WriteString(line[:cut])
+		w.buf.WriteString("\r\n ")
+		line = line[cut:]
+		// continuation lines start with a space, which counts
+		limit = icsMaxLineOctets - 1
+	}
+	w.buf.WriteString(line)
+	w.buf.WriteString("\r\n")
+}
+
+func escapeICSText(value string) string {
+	return strings.NewReplacer(
+		`\`, `\\`,
+		";", `\;`,
+		",", `\,`,
+		"\r\n", `\n`,
+		"\n", `\n`,
+	).Replace(value)
+}
+
+func icsDate(t time.Time) string {
+	return t.Format("20060102")
+}
+
+func icsTimestamp(t time.Time) string {
+	return t.UTC().Format("20060102T150405Z")
+}
+
+// icsRecurrenceRule translates a billing cycle anchored on anchor into an
+// RRULE. Month based cycles anchored after the 28th use BYSETPOS so that
+// short months fall back to their last day, matching how renewals are
+// expanded everywhere else.
+func icsRecurrenceRule(cycle billingCycle, anchor time.Time) string {
+	if cycle.days > 0 {
+		if cycle.days%7 == 0 {
+			return fmt.Sprintf("FREQ=WEEKLY;INTERVAL=%d", cycle.days/7)
+		}
+		return fmt.Sprintf("FREQ=DAILY;INTERVAL=%d", cycle.days)
+	}
+
+	freq := fmt.Sprintf("FREQ=MONTHLY;INTERVAL=%d", cycle.months)
+	if cycle.months%12 == 0 {
+		freq = fmt.Sprintf("FREQ=YEARLY;INTERVAL=%d;BYMONTH=%d", cycle.months/12, anchor.Month())
+	}
+
+	if anchor.Day() <= 28 {
+		return freq
+	}
+
+	days := []string{}
+	for d := 28; d <= anchor.Day(); d++ {
+		days = append(days, fmt.Sprint(d))
+	}
+	return freq + ";BYMONTHDAY=" + strings.Join(days, ",") + ";BYSETPOS=-1"
+}
@@ -0,0 +1,46 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+const (
+	ImportFormatCSV  = "csv"
+	ImportFormatJSON = "json"
+
+	ImportActionCreate = "create"
+	ImportActionUpdate = "update"
+	ImportActionReject = "reject"
+)
+
+type ImportRequest struct {
+	Format string
+	Data   []byte
+	// Mapping maps subscription fields (name, amount, ...) to CSV column
+	// headers. Fields without a mapping use a column of the same name.
+	Mapping map[string]string
+	// Commit writes the valid rows; otherwise the import is a dry run.
+	Commit bool
+	// WorkspaceID is the workspace rows are matched against and created
+	// in; nil means the user's personal workspace.
+	WorkspaceID *int
+}
+
+type ImportRowResult struct {
+	Row          int                        `json:"row"`
+	Action       string                     `json:"action"`
+	Name         string                     `json:"name"`
+	ExistingID   int                        `json:"existing_id,omitempty"`
+	Subscription *CreateSubscriptionRequest `json:"subscription,omitempty"`
+	Errors       []string                   `json:"errors,omitempty"`
+}
+
+type ImportReport struct {
+	DryRun   bool              `json:"dry_run"`
+	Created  int               `json:"created"`
+	Updated  int               `json:"updated"`
+	Rejected int               `json:"rejected"`
+	Rows     []ImportRowResult `json:"rows"`
+}
+
+type ImportService interface {
+	ImportSubscriptions(req ImportRequest, actor repository.Actor) (*ImportReport, error)
+}
@@ -0,0 +1,396 @@
+package service
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/statement"
+)
+
+const maxImportRows = 5000
+
+var ErrInvalidImport = errors.New("invalid import file")
+
+var importFields = []string{
+	"name", "category", "amount", "currency",
+	"billing_cycle", "billing_date", "status", "is_trial",
+	"notes", "tags",
+}
+
+type importService struct {
+	subRepo       repository.SubscriptionRepository
+	workspaceRepo repository.WorkspaceRepository
+}
+
+func NewImportService(subRepo repository.SubscriptionRepository, workspaceRepo repository.WorkspaceRepository) ImportService {
+	return importService{subRepo: subRepo, workspaceRepo: workspaceRepo}
+}
+
+type importRow struct {
+	row    int
+	req    CreateSubscriptionRequest
+	errors []string
+	// present holds the import fields the input carries, as a column or
+	// a key; an update leaves every other field as stored.
+	present map[string]bool
+}
+
+// ImportSubscriptions classifies every row as a create, an update of the
+// subscription with the same name in the target workspace, or a reject.
+// Unless req.Commit is set nothing is written; when it is, all creates and
+// updates go through one transaction.
+func (s importService) ImportSubscriptions(req ImportRequest, actor repository.Actor) (*ImportReport, error) {
+	var rows []importRow
+	var err error
+
+	switch strings.ToLower(req.Format) {
+	case ImportFormatCSV:
+		rows, err = parseCSVImport(req.Data, req.Mapping)
+	case ImportFormatJSON:
+		rows, err = parseJSONImport(req.Data)
+	default:
+		return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidImport, req.Format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	if len(rows) > maxImportRows {
+		return nil, fmt.Errorf("%w: more than %d rows", ErrInvalidImport, maxImportRows)
+	}
+
+	workspaceID, err := s.targetWorkspace(req.WorkspaceID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	existing, err := s.subRepo.List(actor.UserID, repository.SubscriptionFilter{WorkspaceID: &workspaceID})
+	if err != nil {
+		return nil, err
+	}
+	existingByName := map[string]repository.Subscription{}
+	for _, sub := range existing {
+		existingByName[sub.Name] = sub
+	}
+
+	report := &ImportReport{DryRun: !req.Commit, Rows: []ImportRowResult{}}
+	var creates, updates []repository.Subscription
+	seen := map[string]int{}
+
+	for _, r := range rows {
+		result := ImportRowResult{Row: r.row, Name: r.req.Name}
+
+		current, exists := existingByName[strings.TrimSpace(r.req.Name)]
+		if exists {
+			r.req = mergeImportRow(r.req, r.present, current)
+		}
+
+		normalized, problems := normalizeSubscriptionRequest(r.req)
+		problems = append(r.errors, problems...)
+		if first, dup := seen[normalized.Name]; dup && normalized.Name != "" {
+			problems = append(problems, fmt.Sprintf("duplicate name, already used on row %d", first))
+		}
+
+		if len(problems) > 0 {
+			result.Action = ImportActionReject
+			result.Errors = problems
+			report.Rejected++
+			report.Rows = append(report.Rows, result)
+			continue
+		}
+		seen[normalized.Name] = r.row
+
+		sub := repository.Subscription{
+			WorkspaceID:  &workspaceID,
+			Name:         normalized.Name,
+			Category:     normalized.Category,
+			Amount:       normalized.Amount,
+			Currency:     normalized.Currency,
+			BillingCycle: normalized.BillingCycle,
+			BillingDate:  normalized.BillingDate,
+			Status:       normalized.Status,
+			Trial:        normalized.Trial,
+			Notes:        normalized.Notes,
+			Tags:         normalized.Tags,
+			AutoRenew:    true, // only applies to new rows; updates keep the stored flag
+		}
+
+		result.Subscription = &normalized
+		if exists {
+			sub.SubscriptionID = current.SubscriptionID
+			result.Action = ImportActionUpdate
+			result.ExistingID = current.SubscriptionID
+			updates = append(updates, sub)
+			report.Updated++
+		} else {
+			result.Action = ImportActionCreate
+			creates = append(creates, sub)
+			report.Created++
+		}
+
+		report.Rows = append(report.Rows, result)
+	}
+
+	if req.Commit && len(creates)+len(updates) > 0 {
+		if err := s.subRepo.Import(creates, updates, actor); err != nil {
+			return nil, err
+		}
+	}
+
+	return report, nil
+}
+
+// mergeImportRow fills the fields a row does not carry from the
+// subscription it updates, so a file without e.g. a notes column keeps
+// the stored notes instead of clearing them.
+func mergeImportRow(req CreateSubscriptionRequest, present map[string]bool, sub repository.Subscription) CreateSubscriptionRequest {
+	if !present["category"] {
+		req.Category = sub.Category
+	}
+	if !present["amount"] {
+		req.Amount = sub.Amount
+	}
+	if !present["currency"] {
+		req.Currency = sub.Currency
+	}
+	if !present["billing_cycle"] {
+		req.BillingCycle = sub.BillingCycle
+	}
+	if !present["billing_date"] {
+		req.BillingDate = sub.BillingDate
+	}
+	if !present["status"] {
+		req.Status = sub.Status
+	}
+	if !present["is_trial"] {
+		req.Trial = sub.Trial
+	}
+	if !present["notes"] {
+		req.Notes = sub.Notes
+	}
+	if !present["tags"] {
+		req.Tags = sub.Tags
+	}
+	return req
+}
+
+// targetWorkspace resolves the workspace an import writes to: the
+// requested one if the user may edit it, otherwise their personal one.
+func (s importService) targetWorkspace(requested *int, userID int) (int, error) {
+	workspaces, err := s.workspaceRepo.GetAll(userID)
+	if err != nil {
+		return 0, err
+	}
+	for _, w := range workspaces {
+		if requested == nil && w.Personal {
+			return w.WorkspaceID, nil
+		}
+		if requested != nil && w.WorkspaceID == *requested {
+			if roleRanks[w.Role] < roleRanks[RoleEditor] {
+				return 0, ErrWorkspaceForbidden
+			}
+			return w.WorkspaceID, nil
+		}
+	}
+	return 0, ErrWorkspaceNotFound
+}
+
+func parseCSVImport(data []byte, mapping map[string]string) ([]importRow, error) {
+	for field := range mapping {
+		if !isImportField(field) {
+			return nil, fmt.Errorf("%w: unknown field %q in mapping", ErrInvalidImport, field)
+		}
+	}
+
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidImport)
+	}
+
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	fieldIndex := map[string]int{}
+	for _, field := range importFields {
+		column := field
+		if mapped, ok := mapping[field]; ok {
+			column = mapped
+		}
+		if i, ok := columns[strings.ToLower(strings.TrimSpace(column))]; ok {
+			fieldIndex[field] = i
+		} else if _, ok := mapping[field]; ok {
+			return nil, fmt.Errorf("%w: column %q not found", ErrInvalidImport, column)
+		}
+	}
+	if _, ok := fieldIndex["name"]; !ok {
+		return nil, fmt.Errorf("%w: no column for name", ErrInvalidImport)
+	}
+
+	present := map[string]bool{}
+	for field := range fieldIndex {
+		present[field] = true
+	}
+
+	var rows []importRow
+	for line := 2; ; line++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidImport, err)
+		}
+
+		value := func(field string) string {
+			i, ok := fieldIndex[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		r := importRow{row: line, present: present}
+		r.req = CreateSubscriptionRequest{
+			Name:         value("name"),
+			Category:     value("category"),
+			Currency:     value("currency"),
+			BillingCycle: value("billing_cycle"),
+			BillingDate:  value("billing_date"),
+			Status:       value("status"),
+			Notes:        value("notes"),
+			Tags:         splitTags(value("tags")),
+		}
+
+		if raw := value("amount"); raw != "" {
+			amount, err := statement.ParseAmount(raw)
+			if err != nil {
+				r.errors = append(r.errors, fmt.Sprintf("invalid amount %q", raw))
+			}
+			r.req.Amount = float32(amount)
+		}
+
+		if raw := value("is_trial"); raw != "" {
+			trial, err := parseImportBool(raw)
+			if err != nil {
+				r.errors = append(r.errors, fmt.Sprintf("invalid is_trial %q", raw))
+			}
+			r.req.Trial = trial
+		}
+
+		rows = append(rows, r)
+	}
+
+	return rows, nil
+}
+
+func parseJSONImport(data []byte) ([]importRow, error) {
+	var items []json.RawMessage
+	if err := json.Unmarshal(data, &items); err != nil {
+		return nil, fmt.Errorf("%w: expected a JSON array of subscriptions", ErrInvalidImport)
+	}
+
+	rows := make([]importRow, 0, len(items))
+	for i, item := range items {
+		r := importRow{row: i + 1, present: map[string]bool{}}
+		var keys map[string]json.RawMessage
+		if err := json.Unmarshal(item, &keys); err == nil {
+			for key := range keys {
+				r.present[key] = true
+			}
+		}
+		if err := json.Unmarshal(item, &r.req); err != nil {
+			r.errors = append(r.errors, "invalid subscription object")
+		}
+		rows = append(rows, r)
+	}
+
+	return rows, nil
+}
+
+func parseImportBool(value string) (bool, error) {
+	switch strings.ToLower(value) {
+	case "yes", "y":
+		return true, nil
+	case "no", "n":
+		return false, nil
+	}
+	return strconv.ParseBool(value)
+}
+
+func isImportField(field string) bool {
+	for _, f := range importFields {
+		if f == field {
+			return true
+		}
+	}
+	return false
+}
+
+// normalizeSubscriptionRequest fills in defaults and returns every problem
+// found instead of stopping at the first one.
+func normalizeSubscriptionRequest(req CreateSubscriptionRequest) (CreateSubscriptionRequest, []string) {
+	var problems []string
+
+	req.Name = strings.TrimSpace(req.Name)
+	if req.Name == "" {
+		problems = append(problems, "name is required")
+	} else if utf8.RuneCountInString(req.Name) > 100 {
+		problems = append(problems, "name is longer than 100 characters")
+	}
+
+	req.Category = strings.TrimSpace(req.Category)
+	if utf8.RuneCountInString(req.Category) > 50 {
+		problems = append(problems, "category is longer than 50 characters")
+	}
+
+	if req.Amount <= 0 {
+		problems = append(problems, "amount must be greater than 0")
+	}
+
+	req.Currency = normalizeCurrency(req.Currency)
+	if !isCurrencyCode(req.Currency) {
+		problems = append(problems, fmt.Sprintf("invalid currency %q", req.Currency))
+	}
+
+	req.BillingCycle = strings.ToLower(strings.TrimSpace(req.BillingCycle))
+	if _, ok := parseBillingCycle(req.BillingCycle); !ok {
+		problems = append(problems, fmt.Sprintf("unknown billing_cycle %q", req.BillingCycle))
+	}
+
+	if d, err := parseDate(strings.TrimSpace(req.BillingDate)); err != nil {
+		problems = append(problems, fmt.Sprintf("invalid billing_date %q, expected YYYY-MM-DD", req.BillingDate))
+	} else {
+		req.BillingDate = d.Format(dateLayout)
+	}
+
+	tags, tagProblems := normalizeTags(req.Tags)
+	req.Tags = tags
+	problems = append(problems, tagProblems...)
+
+	req.Notes = strings.TrimSpace(req.Notes)
+	if utf8.RuneCountInString(req.Notes) > maxNotesLength {
+		problems = append(problems, fmt.Sprintf("notes are longer than %d characters", maxNotesLength))
+	}
+
+	req.Status = strings.ToLower(strings.TrimSpace(req.Status))
+	if req.Status == "" {
+		req.Status = "active"
+	}
+	if req.Status != "active" && req.Status != "canceled" {
+		problems = append(problems, fmt.Sprintf("unknown status %q", req.Status))
+	}
+
+	return req, problems
+}
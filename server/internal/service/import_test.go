@@ -0,0 +1,276 @@
+package service_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const importCSV = `Service,Price,Cycle,Next payment,Currency
+Netflix,419,monthly,2025-02-01,THB
+Spotify,"1,290.00",yearly,2025-06-10,
+Gym,abc,monthly,2025-02-01,THB
+Netflix,100,monthly,2025-03-01,THB
+`
+
+var importWorkspaces = []repository.Workspace{
+	{WorkspaceID: 1, Name: "Personal", Personal: true, Role: service.RoleOwner},
+	{WorkspaceID: 2, Name: "Family", Role: service.RoleViewer},
+}
+
+func TestImportSubscriptions(t *testing.T) {
+	personal := 1
+
+	mapping := map[string]string{
+		"name":          "Service",
+		"amount":        "Price",
+		"billing_cycle": "Cycle",
+		"billing_date":  "Next payment",
+	}
+
+	t.Run("Dry Run CSV", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		subscriptionRepo.
+			On("List", 10, repository.SubscriptionFilter{WorkspaceID: &personal}).
+			Return([]repository.Subscription{
+				{SubscriptionID: 7, Name: "Spotify"},
+			}, nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format:  service.ImportFormatCSV,
+			Data:    []byte(importCSV),
+			Mapping: mapping,
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Updated)
+		assert.Equal(t, 2, report.Rejected)
+
+		assert.Equal(t, service.ImportActionCreate, report.Rows[0].Action)
+		assert.Equal(t, service.ImportActionUpdate, report.Rows[1].Action)
+		assert.Equal(t, 7, report.Rows[1].ExistingID)
+		assert.Equal(t, float32(1290), report.Rows[1].Subscription.Amount)
+		assert.Equal(t, "THB", report.Rows[1].Subscription.Currency)
+		assert.Equal(t, service.ImportActionReject, report.Rows[2].Action)
+		assert.Contains(t, report.Rows[3].Errors, "duplicate name, already used on row 2")
+
+		subscriptionRepo.AssertNotCalled(t, "Import", mock.Anything, mock.Anything, mock.Anything)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Commit JSON", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		subscriptionRepo.
+			On("List", 10, repository.SubscriptionFilter{WorkspaceID: &personal}).
+			Return([]repository.Subscription{}, nil)
+		subscriptionRepo.
+			On("Import", mock.MatchedBy(func(creates []repository.Subscription) bool {
+				return len(creates) == 1 && creates[0].Name == "Netflix" && creates[0].Status == "active" &&
+					*creates[0].WorkspaceID == personal
+			}), []repository.Subscription(nil), actor).
+			Return(nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format: service.ImportFormatJSON,
+			Data: []byte(`[
+				{"name": "Netflix", "amount": 419, "billing_cycle": "monthly", "billing_date": "2025-02-01"},
+				{"name": "", "amount": 10}
+			]`),
+			Commit: true,
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, 1, report.Created)
+		assert.Equal(t, 1, report.Rejected)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update Keeps Missing Columns", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		subscriptionRepo.
+			On("List", 10, repository.SubscriptionFilter{WorkspaceID: &personal}).
+			Return([]repository.Subscription{
+				{
+					SubscriptionID: 7, Name: "Spotify", Category: "Music", Amount: 129, Currency: "THB",
+					BillingCycle: "monthly", BillingDate: "2025-02-10", Status: "canceled",
+					Notes: "family plan", Tags: []string{"shared"},
+				},
+			}, nil)
+		subscriptionRepo.
+			On("Import", []repository.Subscription(nil), mock.MatchedBy(func(updates []repository.Subscription) bool {
+				if len(updates) != 1 {
+					return false
+				}
+				u := updates[0]
+				return u.SubscriptionID == 7 && u.Amount == 159 &&
+					u.Category == "Music" && u.Status == "canceled" && u.Notes == "family plan" &&
+					assert.ObjectsAreEqual([]string{"shared"}, u.Tags)
+			}), actor).
+			Return(nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format: service.ImportFormatCSV,
+			Data:   []byte("Service,Price\nSpotify,159\n"),
+			Mapping: map[string]string{
+				"name":   "Service",
+				"amount": "Price",
+			},
+			Commit: true,
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, report.Updated)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Decimal Comma", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		subscriptionRepo.
+			On("List", 10, repository.SubscriptionFilter{WorkspaceID: &personal}).
+			Return([]repository.Subscription{}, nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format:  service.ImportFormatCSV,
+			Data:    []byte("Service,Price,Cycle,Next payment,Currency\nDeezer,\"9,99\",monthly,2025-02-01,EUR\n"),
+			Mapping: mapping,
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, float32(9.99), report.Rows[0].Subscription.Amount)
+	})
+
+	t.Run("Lengths Count Characters", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		subscriptionRepo.
+			On("List", 10, repository.SubscriptionFilter{WorkspaceID: &personal}).
+			Return([]repository.Subscription{}, nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// 60 Thai characters are 180 bytes, within the 100 character limit
+		name := strings.Repeat("ก", 60)
+		category := strings.Repeat("บ", 50)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format: service.ImportFormatJSON,
+			Data: []byte(`[
+				{"name": "` + name + `", "category": "` + category + `", "amount": 99, "billing_cycle": "monthly", "billing_date": "2025-02-01"},
+				{"name": "` + strings.Repeat("ก", 101) + `", "amount": 99, "billing_cycle": "monthly", "billing_date": "2025-02-01"}
+			]`),
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, service.ImportActionCreate, report.Rows[0].Action)
+		assert.Equal(t, name, report.Rows[0].Subscription.Name)
+		assert.Equal(t, service.ImportActionReject, report.Rows[1].Action)
+		assert.Contains(t, report.Rows[1].Errors, "name is longer than 100 characters")
+	})
+
+	t.Run("Viewer Cannot Import", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+		family := 2
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format:      service.ImportFormatCSV,
+			Data:        []byte(importCSV),
+			Mapping:     mapping,
+			WorkspaceID: &family,
+		}, actor)
+
+		// assert
+		assert.Nil(t, report)
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		subscriptionRepo.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Unknown Mapped Column", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetAll", 10).
+			Return(importWorkspaces, nil)
+		importService := service.NewImportService(subscriptionRepo, workspaceRepo)
+
+		// act
+		report, err := importService.ImportSubscriptions(service.ImportRequest{
+			Format:  service.ImportFormatCSV,
+			Data:    []byte(importCSV),
+			Mapping: map[string]string{"name": "Title"},
+		}, actor)
+
+		// assert
+		assert.Nil(t, report)
+		assert.ErrorIs(t, err, service.ErrInvalidImport)
+	})
+}
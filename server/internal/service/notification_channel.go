@@ -0,0 +1,60 @@
+package service
+
+import "context"
+
+// Notification channel types.
+const (
+	ChannelEmail    = "email"
+	ChannelWebhook  = "webhook" // Slack or Discord compatible incoming webhook
+	ChannelTelegram = "telegram"
+	ChannelLINE     = "line"
+)
+
+// NotificationResponse is the data of notification.created events.
+type NotificationResponse struct {
+	NotificationID int    `json:"id"`
+	Type           string `json:"type"`
+	Title          string `json:"title"`
+	Message        string `json:"message"`
+	IsRead         bool   `json:"is_read"`
+	CreatedAt      string `json:"created_at"`
+}
+
+type NotificationChannelResponse struct {
+	ChannelID int    `json:"id"`
+	Type      string `json:"type"`
+	Target    string `json:"target"`
+	Label     string `json:"label"`
+	Enabled   bool   `json:"enabled"`
+	CreatedAt string `json:"created_at"`
+}
+
+// NotificationChannelRequest adds or changes a channel. Target is an email
+// address, an https webhook URL, a Telegram chat id or a LINE user id
+// depending on Type. Enabled defaults to true.
+type NotificationChannelRequest struct {
+	Type    string `json:"type"`
+	Target  string `json:"target"`
+	Label   string `json:"label"`
+	Enabled *bool  `json:"enabled"`
+}
+
+// NotificationChannelService is also an outbox publisher: events that
+// concern users other than the one who caused them become notifications
+// for those users.
+type NotificationChannelService interface {
+	KeyedPublisher
+	EventPublisher
+	GetChannels(userID int) ([]NotificationChannelResponse, error)
+	CreateChannel(req NotificationChannelRequest, userID int) (*NotificationChannelResponse, error)
+	UpdateChannel(id int, req NotificationChannelRequest, userID int) (*NotificationChannelResponse, error)
+	DeleteChannel(id int, userID int) error
+	// TestChannel sends a test message right away and returns why it
+	// failed, if it did.
+	TestChannel(id int, userID int) error
+	// Dispatch fans new notifications out to their users' channels and
+	// connected clients, then sends the channel deliveries that are due,
+	// retrying failures with backoff. Channels follow the users'
+	// notification preferences and deliveries wait out quiet hours.
+	Dispatch(ctx context.Context) error
+}
@@ -0,0 +1,324 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	channelSendTimeout = 30 * time.Second
+	channelBatchSize   = 50
+	channelRetryBase   = time.Minute
+	channelRetryMax    = time.Hour
+	maxChannelAttempts = 5
+	maxChannelTarget   = 500
+	maxChannelLabel    = 50
+)
+
+// shareNotificationType is the type of the notifications members get
+// when a subscription is shared with them, its split changes or it is no
+// longer shared.
+const shareNotificationType = "share_update"
+
+const (
+	channelDeliveryPending = "pending"
+	channelDeliverySent    = "sent"
+	channelDeliveryFailed  = "failed"
+)
+
+var (
+	ErrNotificationChannelNotFound = errors.New("notification channel not found")
+	ErrInvalidNotificationChannel  = errors.New("invalid notification channel")
+	ErrChannelTestFailed           = errors.New("test message could not be sent")
+)
+
+type notificationChannelService struct {
+	channelRepo repository.NotificationChannelRepository
+	notifiers   map[string]notifier.Notifier
+	events      EventPublisher
+}
+
+// NewNotificationChannelService sends through notifiers, keyed by channel
+// type. Types without a notifier are not configured on this server and
+// cannot be added. New notifications are also published to events, which
+// may be nil.
+func NewNotificationChannelService(
+	channelRepo repository.NotificationChannelRepository,
+	notifiers map[string]notifier.Notifier,
+	events EventPublisher,
+) NotificationChannelService {
+	return notificationChannelService{channelRepo: channelRepo, notifiers: notifiers, events: events}
+}
+
+func toNotificationChannelResponse(ch repository.NotificationChannel) NotificationChannelResponse {
+	return NotificationChannelResponse{
+		ChannelID: ch.ChannelID,
+		Type:      ch.Type,
+		Target:    ch.Target,
+		Label:     ch.Label,
+		Enabled:   ch.Enabled,
+		CreatedAt: ch.CreatedAt,
+	}
+}
+
+func (s notificationChannelService) GetChannels(userID int) ([]NotificationChannelResponse, error) {
+	channels, err := s.channelRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []NotificationChannelResponse{}
+	for _, ch := range channels {
+		res = append(res, toNotificationChannelResponse(ch))
+	}
+
+	return res, nil
+}
+
+func (s notificationChannelService) CreateChannel(req NotificationChannelRequest, userID int) (*NotificationChannelResponse, error) {
+	ch, err := s.normalizeChannelRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.channelRepo.Create(ch, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toNotificationChannelResponse(*created)
+	return &res, nil
+}
+
+func (s notificationChannelService) UpdateChannel(id int, req NotificationChannelRequest, userID int) (*NotificationChannelResponse, error) {
+	ch, err := s.normalizeChannelRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	ch.ChannelID = id
+
+	updated, err := s.channelRepo.Update(ch, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotificationChannelNotFound
+		}
+		return nil, err
+	}
+
+	res := toNotificationChannelResponse(*updated)
+	return &res, nil
+}
+
+func (s notificationChannelService) DeleteChannel(id int, userID int) error {
+	err := s.channelRepo.Delete(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrNotificationChannelNotFound
+	}
+	return err
+}
+
+func (s notificationChannelService) TestChannel(id int, userID int) error {
+	ch, err := s.channelRepo.GetById(id, userID)
+	if err != nil {
+		return err
+	}
+	if ch == nil {
+		return ErrNotificationChannelNotFound
+	}
+
+	err = s.send(context.Background(), ch.Type, ch.Target, notifier.Message{
+		Title: "Subscout test message",
+		Body:  "Notifications will arrive here.",
+	})
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrChannelTestFailed, err)
+	}
+	return nil
+}
+
+func (s notificationChannelService) send(ctx context.Context, channelType, target string, msg notifier.Message) error {
+	n, ok := s.notifiers[channelType]
+	if !ok {
+		return fmt.Errorf("%s notifications are not configured", channelType)
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, channelSendTimeout)
+	defer cancel()
+	return n.Send(ctx, target, msg)
+}
+
+func (s notificationChannelService) Publish(userID int, event string, data any) error {
+	return s.PublishOnce(userID, event, "", data)
+}
+
+// PublishOnce notifies the registered members of a share, other than the
+// user who changed it, when it is updated or deleted. Other events are
+// left to the other publishers. A non-empty key notifies each member at
+// most once.
+func (s notificationChannelService) PublishOnce(userID int, event string, key string, data any) error {
+	share, ok := data.(*ShareResponse)
+	if !ok {
+		return nil
+	}
+
+	var errs []error
+	for _, m := range share.Members {
+		if m.UserID == nil || *m.UserID == userID {
+			continue
+		}
+
+		var n repository.Notification
+		switch event {
+		case EventShareUpdated:
+			n = repository.Notification{
+				Title: fmt.Sprintf("%s is shared with you", share.SubscriptionName),
+				Message: fmt.Sprintf("%s splits the cost of %s with you. Your part is %.2f %s %s.",
+					share.OwnerName, share.SubscriptionName, m.Share, share.Currency, share.BillingCycle),
+			}
+		case EventShareDeleted:
+			n = repository.Notification{
+				Title:   fmt.Sprintf("%s is no longer shared with you", share.SubscriptionName),
+				Message: fmt.Sprintf("%s no longer splits the cost of %s with you.", share.OwnerName, share.SubscriptionName),
+			}
+		default:
+			return nil
+		}
+		n.Type = shareNotificationType
+
+		if err := s.channelRepo.Notify(n, key, *m.UserID); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s notificationChannelService) Dispatch(ctx context.Context) error {
+	fresh, err := s.channelRepo.FanOut()
+	if err != nil {
+		return err
+	}
+
+	for _, n := range fresh {
+		if s.events == nil {
+			continue
+		}
+		err := s.events.Publish(n.UserID, EventNotificationCreated, NotificationResponse{
+			NotificationID: n.NotificationID,
+			Type:           n.Type,
+			Title:          n.Title,
+			Message:        n.Message,
+			IsRead:         n.IsRead,
+			CreatedAt:      n.CreatedAt,
+		})
+		if err != nil {
+			log.Printf("publishing notification %d failed: %v", n.NotificationID, err)
+		}
+	}
+
+	due, err := s.channelRepo.ClaimDue(channelBatchSize, 2*channelSendTimeout)
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, d := range due {
+		// the rest are sent by the next run once their lease is over
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		// held back until the user's quiet hours are over; this is not an
+		// attempt, so it does not count towards failing the delivery
+		if wait := quietHoursLeft(now, d.Timezone, d.QuietHoursStart, d.QuietHoursEnd); wait > 0 {
+			if err := s.channelRepo.RecordAttempt(d, wait); err != nil {
+				log.Printf("notification delivery %d could not be postponed: %v", d.DeliveryID, err)
+			}
+			continue
+		}
+
+		d.Attempts++
+		d.LastError = ""
+
+		err := s.send(ctx, d.ChannelType, d.Target, notifier.Message{Title: d.Title, Body: d.Message})
+
+		var retryIn time.Duration
+		switch {
+		case err == nil:
+			d.Status = channelDeliverySent
+		case d.Attempts >= maxChannelAttempts:
+			d.Status = channelDeliveryFailed
+			d.LastError = err.Error()
+		default:
+			d.Status = channelDeliveryPending
+			d.LastError = err.Error()
+			retryIn = retryBackoff(d.Attempts, channelRetryBase, channelRetryMax)
+		}
+
+		// keep sending the others when one cannot be recorded
+		if err := s.channelRepo.RecordAttempt(d, retryIn); err != nil {
+			log.Printf("notification delivery %d could not be recorded: %v", d.DeliveryID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s notificationChannelService) normalizeChannelRequest(req NotificationChannelRequest) (*repository.NotificationChannel, error) {
+	kind := strings.ToLower(strings.TrimSpace(req.Type))
+	target := strings.TrimSpace(req.Target)
+	label := strings.TrimSpace(req.Label)
+
+	switch kind {
+	case ChannelEmail, ChannelWebhook, ChannelTelegram, ChannelLINE:
+	default:
+		return nil, fmt.Errorf("%w: type must be email, webhook, telegram or line", ErrInvalidNotificationChannel)
+	}
+	if _, ok := s.notifiers[kind]; !ok {
+		return nil, fmt.Errorf("%w: %s notifications are not configured on this server", ErrInvalidNotificationChannel, kind)
+	}
+	if target == "" {
+		return nil, fmt.Errorf("%w: target is required", ErrInvalidNotificationChannel)
+	}
+	if len(target) > maxChannelTarget {
+		return nil, fmt.Errorf("%w: target must be at most %d characters", ErrInvalidNotificationChannel, maxChannelTarget)
+	}
+	if len(label) > maxChannelLabel {
+		return nil, fmt.Errorf("%w: label must be at most %d characters", ErrInvalidNotificationChannel, maxChannelLabel)
+	}
+
+	switch kind {
+	case ChannelEmail:
+		addr, err := mail.ParseAddress(target)
+		if err != nil || addr.Name != "" {
+			return nil, fmt.Errorf("%w: target must be an email address", ErrInvalidNotificationChannel)
+		}
+		target = addr.Address
+	case ChannelWebhook:
+		u, err := url.Parse(target)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			return nil, fmt.Errorf("%w: target must be an http or https webhook URL", ErrInvalidNotificationChannel)
+		}
+	case ChannelTelegram, ChannelLINE:
+		if strings.ContainsAny(target, " /?#") {
+			return nil, fmt.Errorf("%w: target must be a %s chat or user id", ErrInvalidNotificationChannel, kind)
+		}
+	}
+
+	return &repository.NotificationChannel{
+		Type:    kind,
+		Target:  target,
+		Label:   label,
+		Enabled: req.Enabled == nil || *req.Enabled,
+	}, nil
+}
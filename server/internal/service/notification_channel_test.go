@@ -0,0 +1,205 @@
+package service_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/notifier"
+	"github.com/NetlutZ/subscout/internal/realtime"
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingNotifier remembers what it sent and fails when err is set.
+type recordingNotifier struct {
+	sent []string
+	err  error
+}
+
+func (n *recordingNotifier) Send(ctx context.Context, target string, msg notifier.Message) error {
+	n.sent = append(n.sent, target+": "+msg.Title)
+	return n.err
+}
+
+func TestDispatch(t *testing.T) {
+	t.Run("Dispatch Success", func(t *testing.T) {
+		// arrange
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+		telegram := &recordingNotifier{}
+		line := &recordingNotifier{err: errors.New("line is down")}
+
+		channelRepo.
+			On("FanOut").
+			Return([]repository.DispatchedNotification{}, nil)
+		channelRepo.
+			On("ClaimDue", 50, time.Minute).
+			Return([]repository.ChannelDelivery{
+				{DeliveryID: 1, ChannelType: service.ChannelTelegram, Target: "42", Title: "Netflix renews tomorrow", Status: "pending"},
+				{DeliveryID: 2, ChannelType: service.ChannelLINE, Target: "U1", Title: "Netflix renews tomorrow", Status: "pending", Attempts: 1},
+			}, nil)
+		channelRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.ChannelDelivery) bool {
+				return d.DeliveryID == 1 && d.Status == "sent" && d.Attempts == 1
+			}), time.Duration(0)).
+			Return(nil)
+		channelRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.ChannelDelivery) bool {
+				return d.DeliveryID == 2 && d.Status == "pending" && d.Attempts == 2 && d.LastError == "line is down"
+			}), 2*time.Minute).
+			Return(nil)
+
+		channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{
+			service.ChannelTelegram: telegram,
+			service.ChannelLINE:     line,
+		}, nil)
+
+		// act
+		err := channelService.Dispatch(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"42: Netflix renews tomorrow"}, telegram.sent)
+		assert.Len(t, line.sent, 1)
+		channelRepo.AssertExpectations(t)
+	})
+
+	t.Run("Last Attempt Fails The Delivery", func(t *testing.T) {
+		// arrange
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+
+		channelRepo.
+			On("FanOut").
+			Return([]repository.DispatchedNotification{}, nil)
+		channelRepo.
+			On("ClaimDue", 50, time.Minute).
+			Return([]repository.ChannelDelivery{
+				{DeliveryID: 1, ChannelType: service.ChannelEmail, Target: "a@test.com", Status: "pending", Attempts: 4},
+			}, nil)
+		channelRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.ChannelDelivery) bool {
+				return d.Status == "failed" && d.Attempts == 5
+			}), time.Duration(0)).
+			Return(nil)
+
+		// email is not configured, so every attempt fails
+		channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{}, nil)
+
+		// act
+		err := channelService.Dispatch(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		channelRepo.AssertExpectations(t)
+	})
+
+	t.Run("Quiet Hours Postpone The Delivery", func(t *testing.T) {
+		// arrange
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+		telegram := &recordingNotifier{}
+		now := time.Now().UTC()
+		start := now.Add(-time.Hour).Format("15:04")
+		end := now.Add(time.Hour).Format("15:04")
+
+		channelRepo.
+			On("FanOut").
+			Return([]repository.DispatchedNotification{}, nil)
+		channelRepo.
+			On("ClaimDue", 50, time.Minute).
+			Return([]repository.ChannelDelivery{
+				{DeliveryID: 1, ChannelType: service.ChannelTelegram, Target: "42", Status: "pending", Attempts: 2,
+					Timezone: "UTC", QuietHoursStart: &start, QuietHoursEnd: &end},
+			}, nil)
+		channelRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.ChannelDelivery) bool {
+				return d.Status == "pending" && d.Attempts == 2
+			}), mock.MatchedBy(func(wait time.Duration) bool {
+				return wait > 0 && wait <= time.Hour
+			})).
+			Return(nil)
+
+		channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{
+			service.ChannelTelegram: telegram,
+		}, nil)
+
+		// act
+		err := channelService.Dispatch(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Empty(t, telegram.sent)
+		channelRepo.AssertExpectations(t)
+	})
+}
+
+func TestDispatchPublishesNotifications(t *testing.T) {
+	// arrange
+	channelRepo := repository.NewNotificationChannelRepositoryMock()
+	hub := realtime.NewHub(10)
+	stream := hub.Subscribe(10, 0)
+	defer stream.Close()
+
+	channelRepo.
+		On("FanOut").
+		Return([]repository.DispatchedNotification{
+			{Notification: repository.Notification{NotificationID: 3, Type: "budget_alert", Title: "Budget at 80%"}, UserID: 10},
+		}, nil)
+	channelRepo.
+		On("ClaimDue", 50, time.Minute).
+		Return([]repository.ChannelDelivery{}, nil)
+
+	channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{}, hub)
+
+	// act
+	err := channelService.Dispatch(context.Background())
+
+	// assert
+	assert.NoError(t, err)
+	e := <-stream.Events
+	assert.Equal(t, service.EventNotificationCreated, e.Type)
+	assert.Contains(t, string(e.Data), `"title":"Budget at 80%"`)
+	channelRepo.AssertExpectations(t)
+}
+
+func TestCreateChannel(t *testing.T) {
+	t.Run("Create Channel Success", func(t *testing.T) {
+		// arrange
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+
+		channelRepo.
+			On("Create", &repository.NotificationChannel{Type: "email", Target: "john@test.com", Enabled: true}, 10).
+			Return(&repository.NotificationChannel{ChannelID: 1, Type: "email", Target: "john@test.com", Enabled: true}, nil)
+
+		channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{
+			service.ChannelEmail: &recordingNotifier{},
+		}, nil)
+
+		// act
+		res, err := channelService.CreateChannel(service.NotificationChannelRequest{Type: " Email ", Target: "john@test.com"}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.ChannelID)
+		channelRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Channel Invalid", func(t *testing.T) {
+		// arrange
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+		channelService := service.NewNotificationChannelService(channelRepo, map[string]notifier.Notifier{
+			service.ChannelEmail: &recordingNotifier{},
+		}, nil)
+
+		// act
+		_, addressErr := channelService.CreateChannel(service.NotificationChannelRequest{Type: "email", Target: "not an address"}, 10)
+		_, unconfiguredErr := channelService.CreateChannel(service.NotificationChannelRequest{Type: "telegram", Target: "42"}, 10)
+
+		// assert
+		assert.ErrorIs(t, addressErr, service.ErrInvalidNotificationChannel)
+		assert.ErrorIs(t, unconfiguredErr, service.ErrInvalidNotificationChannel)
+		channelRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
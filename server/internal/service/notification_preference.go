@@ -0,0 +1,45 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+// QuietHours is a daily window, "HH:MM" in the user's timezone, during
+// which notifications wait instead of going out. End before Start wraps
+// past midnight.
+type QuietHours struct {
+	Start string `json:"start"`
+	End   string `json:"end"`
+}
+
+type SnoozeResponse struct {
+	SubscriptionID int    `json:"subscription_id"`
+	Name           string `json:"name"`
+	Until          string `json:"until"`
+}
+
+// SnoozeRequest silences a subscription's reminders up to and including
+// Until (YYYY-MM-DD).
+type SnoozeRequest struct {
+	Until string `json:"until"`
+}
+
+type NotificationPreferencesResponse struct {
+	Timezone   string           `json:"timezone"`
+	QuietHours *QuietHours      `json:"quiet_hours"` // null when there are none
+	Channels   map[string][]int `json:"channels"`
+	Snoozes    []SnoozeResponse `json:"snoozes"`
+}
+
+// NotificationPreferencesRequest replaces all preferences. Channels maps a
+// notification type to the channel ids it is sent to: a type that is left
+// out goes to every enabled channel, an empty list only to the app.
+type NotificationPreferencesRequest struct {
+	QuietHours *QuietHours      `json:"quiet_hours"`
+	Channels   map[string][]int `json:"channels"`
+}
+
+type NotificationPreferenceService interface {
+	GetPreferences(userID int) (*NotificationPreferencesResponse, error)
+	UpdatePreferences(req NotificationPreferencesRequest, userID int) (*NotificationPreferencesResponse, error)
+	Snooze(subscriptionID int, req SnoozeRequest, actor repository.Actor) (*SnoozeResponse, error)
+	Unsnooze(subscriptionID int, actor repository.Actor) error
+}
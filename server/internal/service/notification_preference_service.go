@@ -0,0 +1,204 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const clockLayout = "15:04"
+
+var (
+	ErrInvalidNotificationPreferences = errors.New("invalid notification preferences")
+	ErrInvalidSnooze                  = errors.New("invalid snooze")
+	ErrSnoozeNotFound                 = errors.New("snooze not found")
+)
+
+// notificationTypes are the types that preferences can route.
+var notificationTypes = map[string]bool{
+	reminderRenewalType:      true,
+	reminderTrialType:        true,
+	reminderCancellationType: true,
+	budgetAlertType:          true,
+	paymentMethodExpiryType:  true,
+	shareNotificationType:    true,
+}
+
+type notificationPreferenceService struct {
+	prefRepo    repository.NotificationPreferenceRepository
+	channelRepo repository.NotificationChannelRepository
+}
+
+func NewNotificationPreferenceService(
+	prefRepo repository.NotificationPreferenceRepository,
+	channelRepo repository.NotificationChannelRepository,
+) NotificationPreferenceService {
+	return notificationPreferenceService{prefRepo: prefRepo, channelRepo: channelRepo}
+}
+
+func toSnoozeResponse(z repository.SubscriptionSnooze) SnoozeResponse {
+	return SnoozeResponse{
+		SubscriptionID: z.SubscriptionID,
+		Name:           z.SubscriptionName,
+		Until:          z.Until,
+	}
+}
+
+func (s notificationPreferenceService) GetPreferences(userID int) (*NotificationPreferencesResponse, error) {
+	p, err := s.prefRepo.Get(userID)
+	if err != nil {
+		return nil, err
+	}
+	if p == nil {
+		return nil, ErrUserNotFound
+	}
+
+	snoozes, err := s.prefRepo.GetSnoozes(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &NotificationPreferencesResponse{
+		Timezone: p.Timezone,
+		Channels: p.Channels,
+		Snoozes:  []SnoozeResponse{},
+	}
+	if res.Channels == nil {
+		res.Channels = map[string][]int{}
+	}
+	if p.QuietHoursStart != nil && p.QuietHoursEnd != nil {
+		res.QuietHours = &QuietHours{Start: *p.QuietHoursStart, End: *p.QuietHoursEnd}
+	}
+	for _, z := range snoozes {
+		res.Snoozes = append(res.Snoozes, toSnoozeResponse(z))
+	}
+
+	return res, nil
+}
+
+func (s notificationPreferenceService) UpdatePreferences(req NotificationPreferencesRequest, userID int) (*NotificationPreferencesResponse, error) {
+	p := &repository.NotificationPreferences{Channels: map[string][]int{}}
+
+	if req.QuietHours != nil {
+		start, errStart := time.Parse(clockLayout, req.QuietHours.Start)
+		end, errEnd := time.Parse(clockLayout, req.QuietHours.End)
+		if errStart != nil || errEnd != nil {
+			return nil, fmt.Errorf("%w: quiet hours must be HH:MM", ErrInvalidNotificationPreferences)
+		}
+		if start.Equal(end) {
+			return nil, fmt.Errorf("%w: quiet hours must not start and end at the same time", ErrInvalidNotificationPreferences)
+		}
+		startClock, endClock := start.Format(clockLayout), end.Format(clockLayout)
+		p.QuietHoursStart, p.QuietHoursEnd = &startClock, &endClock
+	}
+
+	if len(req.Channels) > 0 {
+		channels, err := s.channelRepo.GetAll(userID)
+		if err != nil {
+			return nil, err
+		}
+		owned := map[int]bool{}
+		for _, ch := range channels {
+			owned[ch.ChannelID] = true
+		}
+
+		for kind, ids := range req.Channels {
+			if !notificationTypes[kind] {
+				return nil, fmt.Errorf("%w: unknown notification type %q", ErrInvalidNotificationPreferences, kind)
+			}
+
+			seen := map[int]bool{}
+			list := []int{}
+			for _, id := range ids {
+				if !owned[id] {
+					return nil, fmt.Errorf("%w: channel %d not found", ErrInvalidNotificationPreferences, id)
+				}
+				if !seen[id] {
+					seen[id] = true
+					list = append(list, id)
+				}
+			}
+			sort.Ints(list)
+			p.Channels[kind] = list
+		}
+	}
+
+	err := s.prefRepo.Update(p, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrUserNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	return s.GetPreferences(userID)
+}
+
+func (s notificationPreferenceService) Snooze(subscriptionID int, req SnoozeRequest, actor repository.Actor) (*SnoozeResponse, error) {
+	until, err := parseDate(req.Until)
+	if err != nil {
+		return nil, fmt.Errorf("%w: until must be a YYYY-MM-DD date", ErrInvalidSnooze)
+	}
+	if until.Before(today()) {
+		return nil, fmt.Errorf("%w: until must not be in the past", ErrInvalidSnooze)
+	}
+
+	z, err := s.prefRepo.Snooze(subscriptionID, until.Format(dateLayout), actor)
+	if err != nil {
+		return nil, err
+	}
+	if z == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	res := toSnoozeResponse(*z)
+	return &res, nil
+}
+
+func (s notificationPreferenceService) Unsnooze(subscriptionID int, actor repository.Actor) error {
+	err := s.prefRepo.Unsnooze(subscriptionID, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSnoozeNotFound
+	}
+	return err
+}
+
+// quietHoursLeft is how long the quiet hours running at now still last in
+// timezone, or zero outside them. Missing or unreadable hours mean none.
+func quietHoursLeft(now time.Time, timezone string, start, end *string) time.Duration {
+	if start == nil || end == nil {
+		return 0
+	}
+	from, errFrom := time.Parse(clockLayout, *start)
+	to, errTo := time.Parse(clockLayout, *end)
+	if errFrom != nil || errTo != nil {
+		return 0
+	}
+
+	loc, err := loadTimezone(timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	local := now.In(loc)
+	midnight := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, loc)
+	elapsed := local.Sub(midnight)
+
+	sinceMidnight := func(t time.Time) time.Duration {
+		return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute
+	}
+	begin, finish := sinceMidnight(from), sinceMidnight(to)
+
+	switch {
+	case begin < finish && elapsed >= begin && elapsed < finish:
+		return finish - elapsed
+	case begin > finish && elapsed >= begin:
+		return 24*time.Hour - elapsed + finish
+	case begin > finish && elapsed < finish:
+		return finish - elapsed
+	}
+	return 0
+}
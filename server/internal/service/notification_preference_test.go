@@ -0,0 +1,149 @@
+package service_test
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestUpdatePreferences(t *testing.T) {
+	t.Run("Update Preferences Success", func(t *testing.T) {
+		// arrange
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+		start, end := "22:00", "07:30"
+
+		channelRepo.
+			On("GetAll", 10).
+			Return([]repository.NotificationChannel{{ChannelID: 1}, {ChannelID: 2}}, nil)
+		prefRepo.
+			On("Update", &repository.NotificationPreferences{
+				QuietHoursStart: &start,
+				QuietHoursEnd:   &end,
+				Channels:        map[string][]int{"renewal_reminder": {1, 2}, "budget_alert": {}},
+			}, 10).
+			Return(nil)
+		prefRepo.
+			On("Get", 10).
+			Return(&repository.NotificationPreferences{
+				QuietHoursStart: &start,
+				QuietHoursEnd:   &end,
+				Timezone:        "Asia/Bangkok",
+				Channels:        map[string][]int{"renewal_reminder": {1, 2}, "budget_alert": {}},
+			}, nil)
+		prefRepo.
+			On("GetSnoozes", 10).
+			Return([]repository.SubscriptionSnooze{}, nil)
+
+		prefService := service.NewNotificationPreferenceService(prefRepo, channelRepo)
+
+		// act
+		res, err := prefService.UpdatePreferences(service.NotificationPreferencesRequest{
+			QuietHours: &service.QuietHours{Start: "22:00", End: "7:30"},
+			Channels:   map[string][]int{"renewal_reminder": {2, 1, 2}, "budget_alert": {}},
+		}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, &service.QuietHours{Start: "22:00", End: "07:30"}, res.QuietHours)
+		assert.Equal(t, "Asia/Bangkok", res.Timezone)
+		prefRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update Preferences Invalid", func(t *testing.T) {
+		// arrange
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+
+		channelRepo.
+			On("GetAll", 10).
+			Return([]repository.NotificationChannel{{ChannelID: 1}}, nil)
+
+		prefService := service.NewNotificationPreferenceService(prefRepo, channelRepo)
+
+		// act & assert
+		for _, req := range []service.NotificationPreferencesRequest{
+			{QuietHours: &service.QuietHours{Start: "25:00", End: "07:00"}},
+			{QuietHours: &service.QuietHours{Start: "08:00", End: "08:00"}},
+			{Channels: map[string][]int{"newsletter": {1}}},
+			{Channels: map[string][]int{"budget_alert": {9}}},
+		} {
+			_, err := prefService.UpdatePreferences(req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidNotificationPreferences)
+		}
+		prefRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+}
+
+func TestSnooze(t *testing.T) {
+	t.Run("Snooze Success", func(t *testing.T) {
+		// arrange
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		until := time.Now().UTC().AddDate(0, 0, 14).Format("2006-01-02")
+
+		prefRepo.
+			On("Snooze", 5, until, actor).
+			Return(&repository.SubscriptionSnooze{SubscriptionID: 5, SubscriptionName: "Netflix", Until: until}, nil)
+
+		prefService := service.NewNotificationPreferenceService(prefRepo, nil)
+
+		// act
+		res, err := prefService.Snooze(5, service.SnoozeRequest{Until: until}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "Netflix", res.Name)
+		assert.Equal(t, until, res.Until)
+	})
+
+	t.Run("Snooze In The Past", func(t *testing.T) {
+		// arrange
+		prefService := service.NewNotificationPreferenceService(repository.NewNotificationPreferenceRepositoryMock(), nil)
+
+		// act
+		_, err := prefService.Snooze(5, service.SnoozeRequest{Until: "2020-01-01"}, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidSnooze)
+	})
+
+	t.Run("Snooze Subscription Not Found", func(t *testing.T) {
+		// arrange
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		until := time.Now().UTC().Format("2006-01-02")
+
+		prefRepo.
+			On("Snooze", 5, until, actor).
+			Return((*repository.SubscriptionSnooze)(nil), nil)
+
+		prefService := service.NewNotificationPreferenceService(prefRepo, nil)
+
+		// act
+		_, err := prefService.Snooze(5, service.SnoozeRequest{Until: until}, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrSubscriptionNotFound)
+	})
+
+	t.Run("Unsnooze Not Snoozed", func(t *testing.T) {
+		// arrange
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+
+		prefRepo.
+			On("Unsnooze", 5, actor).
+			Return(sql.ErrNoRows)
+
+		prefService := service.NewNotificationPreferenceService(prefRepo, nil)
+
+		// act
+		err := prefService.Unsnooze(5, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrSnoozeNotFound)
+	})
+}
@@ -0,0 +1,20 @@
+package service
+
+import (
+	"context"
+	"time"
+)
+
+// OutboxService relays the domain events stored in the outbox to the
+// in-process publishers: webhooks, the real-time stream and notifications.
+type OutboxService interface {
+	// Relay publishes the events that are due. An event is delivered at
+	// least once and the events of one aggregate in the order they were
+	// stored; one that fails holds back the later events of its aggregate
+	// until it is retried. It stops between events once ctx is done.
+	Relay(ctx context.Context) error
+	// Run relays until ctx is done, waiting interval whenever nothing was
+	// due. Polling only reads an empty outbox, so a short interval is
+	// cheap.
+	Run(ctx context.Context, interval time.Duration)
+}
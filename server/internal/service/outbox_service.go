@@ -0,0 +1,208 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	outboxBatchSize = 100
+	// outboxLease is how long a claimed event is left to its relay before
+	// another one takes it over.
+	outboxLease      = time.Minute
+	outboxRetryBase  = 5 * time.Second
+	outboxRetryMax   = 10 * time.Minute
+	outboxMaxBatches = 10
+	// outboxMaxAttempts is how often an event is tried before it is
+	// dead-lettered; with the backoff above that is about half an hour.
+	outboxMaxAttempts = 10
+)
+
+type outboxService struct {
+	outboxRepo    repository.OutboxRepository
+	workspaceRepo repository.WorkspaceRepository
+	events        EventPublisher
+	notices       EventPublisher
+}
+
+// NewOutboxService hands events to every member of the workspace a
+// subscription or share belongs to, and notices once, for the user who
+// made the change, to publishers that act on their behalf, such as share
+// notifications to the other members.
+func NewOutboxService(
+	outboxRepo repository.OutboxRepository,
+	workspaceRepo repository.WorkspaceRepository,
+	events EventPublisher,
+	notices EventPublisher,
+) OutboxService {
+	return outboxService{outboxRepo: outboxRepo, workspaceRepo: workspaceRepo, events: events, notices: notices}
+}
+
+func (s outboxService) Run(ctx context.Context, interval time.Duration) {
+	for ctx.Err() == nil {
+		if err := s.Relay(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("relaying outbox events failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+		case <-time.After(interval):
+		}
+	}
+}
+
+func (s outboxService) Relay(ctx context.Context) error {
+	// a full batch means more may be waiting, but leave room for the next
+	// tick rather than draining a backlog in one go
+	for range outboxMaxBatches {
+		due, err := s.outboxRepo.ClaimDue(outboxBatchSize, outboxLease)
+		if err != nil {
+			return err
+		}
+
+		for _, e := range due {
+			// the rest are relayed again once their lease is over
+			if err := ctx.Err(); err != nil {
+				return err
+			}
+			s.relay(e)
+		}
+
+		if len(due) < outboxBatchSize {
+			break
+		}
+	}
+
+	return nil
+}
+
+// relay publishes one event and records the outcome. Failures are only
+// logged so that the other aggregates keep moving, and an event that keeps
+// failing is dead-lettered so that the later ones of its own aggregate do
+// too.
+func (s outboxService) relay(e repository.OutboxEvent) {
+	err := s.publish(e)
+	if err == nil {
+		err = s.outboxRepo.MarkPublished(e.EventID)
+		if err != nil {
+			log.Printf("marking outbox event %d published failed: %v", e.EventID, err)
+		}
+		return
+	}
+
+	attempts := e.Attempts + 1
+	if attempts >= outboxMaxAttempts {
+		if err := s.outboxRepo.MarkDead(e.EventID, err.Error()); err != nil {
+			log.Printf("dead-lettering outbox event %d failed: %v", e.EventID, err)
+		}
+		log.Printf("outbox event %d (%s) dead-lettered after %d attempts: %v", e.EventID, e.Event, attempts, err)
+		return
+	}
+
+	retryIn := retryBackoff(attempts, outboxRetryBase, outboxRetryMax)
+	if err := s.outboxRepo.MarkFailed(e.EventID, err.Error(), retryIn); err != nil {
+		log.Printf("recording outbox event %d failure failed: %v", e.EventID, err)
+	}
+	log.Printf("publishing outbox event %d (%s) failed: %v", e.EventID, e.Event, err)
+}
+
+// publish hands e to the publishers under a key of its own, so those that
+// drop repeats do not see a retried event twice.
+func (s outboxService) publish(e repository.OutboxEvent) error {
+	data, err := outboxEventData(e)
+	if err != nil {
+		return err
+	}
+	key := fmt.Sprintf("outbox:%d", e.EventID)
+
+	var errs []error
+	if s.events != nil {
+		recipients, err := s.recipients(e)
+		if err != nil {
+			return err
+		}
+		for _, userID := range recipients {
+			if err := publishOnce(s.events, userID, e.Event, key, data); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	if s.notices != nil {
+		if err := publishOnce(s.notices, e.UserID, e.Event, key, data); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+// recipients lists the user who made the change followed by the other
+// members of the workspace of the subscription it changed. Snoozes are
+// the user's own and reach only them.
+func (s outboxService) recipients(e repository.OutboxEvent) ([]int, error) {
+	recipients := []int{e.UserID}
+	if e.AggregateType != repository.AggregateSubscription && e.AggregateType != repository.AggregateShare {
+		return recipients, nil
+	}
+
+	// shares embed their subscription, so both carry its workspace
+	var scope struct{ WorkspaceID *int }
+	if err := json.Unmarshal([]byte(e.Payload), &scope); err != nil {
+		return nil, fmt.Errorf("decoding outbox event %d: %w", e.EventID, err)
+	}
+	if scope.WorkspaceID == nil {
+		return recipients, nil
+	}
+
+	members, err := s.workspaceRepo.GetMembers(*scope.WorkspaceID)
+	if err != nil {
+		return nil, err
+	}
+	for _, m := range members {
+		if m.UserID != e.UserID {
+			recipients = append(recipients, m.UserID)
+		}
+	}
+
+	return recipients, nil
+}
+
+// outboxEventData is what publishers receive for e: the subscription,
+// share or snooze as the API returns it, or just the id of a deleted
+// subscription.
+func outboxEventData(e repository.OutboxEvent) (any, error) {
+	switch e.AggregateType {
+	case repository.AggregateSubscription:
+		if e.Event == EventSubscriptionDeleted {
+			return map[string]int{"id": e.AggregateID}, nil
+		}
+
+		var sub repository.Subscription
+		if err := json.Unmarshal([]byte(e.Payload), &sub); err != nil {
+			return nil, fmt.Errorf("decoding outbox event %d: %w", e.EventID, err)
+		}
+		return toResponse(sub), nil
+
+	case repository.AggregateShare:
+		var sh repository.Share
+		if err := json.Unmarshal([]byte(e.Payload), &sh); err != nil {
+			return nil, fmt.Errorf("decoding outbox event %d: %w", e.EventID, err)
+		}
+		return toShareResponse(sh, today())
+
+	case repository.AggregateSnooze:
+		var z repository.SubscriptionSnooze
+		if err := json.Unmarshal([]byte(e.Payload), &z); err != nil {
+			return nil, fmt.Errorf("decoding outbox event %d: %w", e.EventID, err)
+		}
+		return toSnoozeResponse(z), nil
+	}
+
+	return json.RawMessage(e.Payload), nil
+}
@@ -0,0 +1,269 @@
+package service_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+// recordingPublisher keeps each event it is handed as "user event data".
+type recordingPublisher struct {
+	published []string
+	err       error
+}
+
+func (p *recordingPublisher) Publish(userID int, event string, data any) error {
+	body, _ := json.Marshal(data)
+	p.published = append(p.published, fmt.Sprintf("%d %s %s", userID, event, body))
+	return p.err
+}
+
+func TestRelayOutbox(t *testing.T) {
+	t.Run("Relay Publishes To Webhooks Once", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		webhookRepo := repository.NewWebhookRepositoryMock()
+		stream := &recordingPublisher{}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       7,
+				AggregateType: "subscription",
+				AggregateID:   1,
+				Event:         "subscription.created",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":1,"Name":"Netflix","Amount":419,"Currency":"THB"}`,
+			}}, nil)
+		webhookRepo.
+			On("Enqueue", 5, service.EventSubscriptionCreated, "outbox:7", mock.MatchedBy(func(payload string) bool {
+				return strings.Contains(payload, `"type":"subscription.created"`) && strings.Contains(payload, `"name":"Netflix"`)
+			})).
+			Return(1, nil)
+		outboxRepo.
+			On("MarkPublished", 7).
+			Return(nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+		outboxService := service.NewOutboxService(outboxRepo, nil, service.Publishers{webhookService, stream}, nil)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, stream.published, 1)
+		assert.Contains(t, stream.published[0], `5 subscription.created {"id":1,"name":"Netflix"`)
+		outboxRepo.AssertExpectations(t)
+		webhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("Relay Deleted Subscription", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		stream := &recordingPublisher{}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       8,
+				AggregateType: "subscription",
+				AggregateID:   3,
+				Event:         "subscription.deleted",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":3,"Name":"Old"}`,
+			}}, nil)
+		outboxRepo.
+			On("MarkPublished", 8).
+			Return(nil)
+
+		outboxService := service.NewOutboxService(outboxRepo, nil, stream, nil)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{`5 subscription.deleted {"id":3}`}, stream.published)
+	})
+
+	t.Run("Relay Failure Is Retried", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		stream := &recordingPublisher{err: errors.New("broker is down")}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       9,
+				AggregateType: "subscription",
+				AggregateID:   1,
+				Event:         "subscription.updated",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":1,"Name":"Netflix"}`,
+				Attempts:      2,
+			}}, nil)
+		outboxRepo.
+			On("MarkFailed", 9, "broker is down", 20*time.Second).
+			Return(nil)
+
+		outboxService := service.NewOutboxService(outboxRepo, nil, stream, nil)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		outboxRepo.AssertExpectations(t)
+		outboxRepo.AssertNotCalled(t, "MarkPublished", mock.Anything)
+	})
+
+	t.Run("Relay Failure Is Dead-Lettered", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		stream := &recordingPublisher{err: errors.New("broker is down")}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       9,
+				AggregateType: "subscription",
+				AggregateID:   1,
+				Event:         "subscription.updated",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":1,"Name":"Netflix"}`,
+				Attempts:      9,
+			}}, nil)
+		outboxRepo.
+			On("MarkDead", 9, "broker is down").
+			Return(nil)
+
+		outboxService := service.NewOutboxService(outboxRepo, nil, stream, nil)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		outboxRepo.AssertExpectations(t)
+		outboxRepo.AssertNotCalled(t, "MarkFailed", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Relay Share Notifies Members", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		channelRepo := repository.NewNotificationChannelRepositoryMock()
+		stream := &recordingPublisher{}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       10,
+				AggregateType: "share",
+				AggregateID:   4,
+				Event:         "share.updated",
+				UserID:        5,
+				Payload: `{"SubscriptionID":4,"Name":"Netflix","Amount":300,"Currency":"THB",
+					"BillingCycle":"monthly","BillingDate":"2025-01-15","Status":"active",
+					"OwnerID":5,"OwnerName":"Ann","Method":"equal","StartedOn":"2025-01-01",
+					"Members":[{"MemberID":1,"UserID":6,"Name":"Bob"},{"MemberID":2,"Name":"Cara"}]}`,
+			}}, nil)
+		channelRepo.
+			On("Notify", repository.Notification{
+				Type:    "share_update",
+				Title:   "Netflix is shared with you",
+				Message: "Ann splits the cost of Netflix with you. Your part is 100.00 THB monthly.",
+			}, "outbox:10", 6).
+			Return(nil)
+		outboxRepo.
+			On("MarkPublished", 10).
+			Return(nil)
+
+		channelService := service.NewNotificationChannelService(channelRepo, nil, nil)
+		outboxService := service.NewOutboxService(outboxRepo, nil, stream, channelService)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, stream.published, 1)
+		assert.Contains(t, stream.published[0], `5 share.updated {"subscription_id":4,"subscription_name":"Netflix"`)
+		channelRepo.AssertExpectations(t)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Relay Reaches Workspace Members", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+		stream := &recordingPublisher{}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       12,
+				AggregateType: "subscription",
+				AggregateID:   1,
+				Event:         "subscription.updated",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":1,"Name":"Netflix","WorkspaceID":2}`,
+			}}, nil)
+		workspaceRepo.
+			On("GetMembers", 2).
+			Return([]repository.WorkspaceMember{{UserID: 5}, {UserID: 6}}, nil)
+		outboxRepo.
+			On("MarkPublished", 12).
+			Return(nil)
+
+		outboxService := service.NewOutboxService(outboxRepo, workspaceRepo, stream, nil)
+
+		// act
+		err := outboxService.Relay(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, stream.published, 2)
+		assert.Contains(t, stream.published[0], `5 subscription.updated {"id":1,"name":"Netflix"`)
+		assert.Contains(t, stream.published[1], `6 subscription.updated {"id":1,"name":"Netflix"`)
+		outboxRepo.AssertExpectations(t)
+	})
+
+	t.Run("Relay Stops Once Cancelled", func(t *testing.T) {
+		// arrange
+		outboxRepo := repository.NewOutboxRepositoryMock()
+		stream := &recordingPublisher{}
+
+		outboxRepo.
+			On("ClaimDue", 100, time.Minute).
+			Return([]repository.OutboxEvent{{
+				EventID:       11,
+				AggregateType: "subscription",
+				AggregateID:   1,
+				Event:         "subscription.updated",
+				UserID:        5,
+				Payload:       `{"SubscriptionID":1,"Name":"Netflix"}`,
+			}}, nil)
+
+		outboxService := service.NewOutboxService(outboxRepo, nil, stream, nil)
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+
+		// act
+		err := outboxService.Relay(ctx)
+
+		// assert
+		assert.ErrorIs(t, err, context.Canceled)
+		assert.Empty(t, stream.published)
+		outboxRepo.AssertNotCalled(t, "MarkPublished", mock.Anything)
+	})
+}
@@ -0,0 +1,50 @@
+package service
+
+import (
+	"context"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+type PaymentMethodResponse struct {
+	PaymentMethodID int    `json:"id"`
+	Label           string `json:"label"`
+	Type            string `json:"type"`
+	Last4           string `json:"last4"`
+	ExpiryMonth     *int   `json:"expiry_month"`
+	ExpiryYear      *int   `json:"expiry_year"`
+	ExpiresOn       string `json:"expires_on,omitempty"` // last day of the expiry month
+	Expired         bool   `json:"expired"`
+	Subscriptions   int    `json:"subscriptions"`
+}
+
+// PaymentMethodRequest creates or updates a payment method. Type defaults
+// to card, which requires an expiry month and year. Last4 holds the last
+// four digits only; full card numbers are rejected.
+type PaymentMethodRequest struct {
+	Label       string `json:"label"`
+	Type        string `json:"type"`
+	Last4       string `json:"last4"`
+	ExpiryMonth *int   `json:"expiry_month"`
+	ExpiryYear  *int   `json:"expiry_year"`
+}
+
+// LinkPaymentMethodRequest bills a subscription to a payment method, or
+// unlinks it when PaymentMethodID is null.
+type LinkPaymentMethodRequest struct {
+	PaymentMethodID *int `json:"payment_method_id"`
+}
+
+type PaymentMethodService interface {
+	GetPaymentMethods(userID int) ([]PaymentMethodResponse, error)
+	CreatePaymentMethod(req PaymentMethodRequest, userID int) (*PaymentMethodResponse, error)
+	UpdatePaymentMethod(id int, req PaymentMethodRequest, userID int) (*PaymentMethodResponse, error)
+	DeletePaymentMethod(id int, userID int) error
+	// GetAffectedSubscriptions lists what is billed to the payment method,
+	// i.e. what needs updating when the card is replaced.
+	GetAffectedSubscriptions(id int, userID int) ([]SubscriptionResponse, error)
+	LinkSubscription(subscriptionID int, req LinkPaymentMethodRequest, actor repository.Actor) error
+	// CheckExpiring notifies users about cards that expire soon or have
+	// expired.
+	CheckExpiring(ctx context.Context) error
+}
@@ -0,0 +1,330 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	paymentMethodCard        = "card"
+	paymentMethodExpiryType  = "payment_method_expiry"
+	maxPaymentMethodLabel    = 50
+	maxExpiryNotificationSub = 3
+)
+
+var (
+	ErrPaymentMethodNotFound = errors.New("payment method not found")
+	ErrInvalidPaymentMethod  = errors.New("invalid payment method")
+)
+
+var paymentMethodTypes = map[string]bool{
+	paymentMethodCard: true,
+	"bank_account":    true,
+	"paypal":          true,
+	"wallet":          true,
+	"other":           true,
+}
+
+// cardExpiryAlertDays are the warnings sent ahead of a card's expiry, the
+// last one on the day it expires.
+var cardExpiryAlertDays = []int{30, 7, 0}
+
+var (
+	last4Pattern = regexp.MustCompile(`^[0-9]{4}$`)
+	// a run of 12 or more digits, allowing spaces and dashes, looks like a
+	// card number
+	panPattern = regexp.MustCompile(`[0-9](?:[ -]?[0-9]){11,}`)
+)
+
+type paymentMethodService struct {
+	paymentMethodRepo repository.PaymentMethodRepository
+}
+
+func NewPaymentMethodService(paymentMethodRepo repository.PaymentMethodRepository) PaymentMethodService {
+	return paymentMethodService{paymentMethodRepo: paymentMethodRepo}
+}
+
+// cardExpiresOn returns the last day of the expiry month, the last day the
+// card can be charged.
+func cardExpiresOn(pm repository.PaymentMethod) (time.Time, bool) {
+	if pm.ExpiryMonth == nil || pm.ExpiryYear == nil {
+		return time.Time{}, false
+	}
+	first := time.Date(*pm.ExpiryYear, time.Month(*pm.ExpiryMonth), 1, 0, 0, 0, 0, time.UTC)
+	return first.AddDate(0, 1, -1), true
+}
+
+func toPaymentMethodResponse(pm repository.PaymentMethod) PaymentMethodResponse {
+	res := PaymentMethodResponse{
+		PaymentMethodID: pm.PaymentMethodID,
+		Label:           pm.Label,
+		Type:            pm.Type,
+		Last4:           pm.Last4,
+		ExpiryMonth:     pm.ExpiryMonth,
+		ExpiryYear:      pm.ExpiryYear,
+		Subscriptions:   pm.Subscriptions,
+	}
+	if expiresOn, ok := cardExpiresOn(pm); ok {
+		res.ExpiresOn = expiresOn.Format(dateLayout)
+		res.Expired = expiresOn.Before(today())
+	}
+	return res
+}
+
+func (s paymentMethodService) GetPaymentMethods(userID int) ([]PaymentMethodResponse, error) {
+	methods, err := s.paymentMethodRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []PaymentMethodResponse{}
+	for _, pm := range methods {
+		res = append(res, toPaymentMethodResponse(pm))
+	}
+
+	return res, nil
+}
+
+func (s paymentMethodService) CreatePaymentMethod(req PaymentMethodRequest, userID int) (*PaymentMethodResponse, error) {
+	pm, err := normalizePaymentMethodRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.paymentMethodRepo.Create(pm, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toPaymentMethodResponse(*created)
+	return &res, nil
+}
+
+func (s paymentMethodService) UpdatePaymentMethod(id int, req PaymentMethodRequest, userID int) (*PaymentMethodResponse, error) {
+	pm, err := normalizePaymentMethodRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	pm.PaymentMethodID = id
+
+	if _, err := s.paymentMethodRepo.Update(pm, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrPaymentMethodNotFound
+		}
+		return nil, err
+	}
+
+	// re-read for the subscription count
+	updated, err := s.paymentMethodRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	res := toPaymentMethodResponse(*updated)
+	return &res, nil
+}
+
+func (s paymentMethodService) DeletePaymentMethod(id int, userID int) error {
+	err := s.paymentMethodRepo.Delete(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrPaymentMethodNotFound
+	}
+	return err
+}
+
+func (s paymentMethodService) GetAffectedSubscriptions(id int, userID int) ([]SubscriptionResponse, error) {
+	pm, err := s.paymentMethodRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if pm == nil {
+		return nil, ErrPaymentMethodNotFound
+	}
+
+	subs, err := s.paymentMethodRepo.GetSubscriptions(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SubscriptionResponse{}
+	for _, sub := range subs {
+		res = append(res, toResponse(sub))
+	}
+
+	return res, nil
+}
+
+func (s paymentMethodService) LinkSubscription(subscriptionID int, req LinkPaymentMethodRequest, actor repository.Actor) error {
+	if req.PaymentMethodID != nil {
+		pm, err := s.paymentMethodRepo.GetById(*req.PaymentMethodID, actor.UserID)
+		if err != nil {
+			return err
+		}
+		if pm == nil {
+			return ErrPaymentMethodNotFound
+		}
+	}
+
+	err := s.paymentMethodRepo.Link(subscriptionID, req.PaymentMethodID, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSubscriptionNotFound
+	}
+	return err
+}
+
+func (s paymentMethodService) CheckExpiring(ctx context.Context) error {
+	now := today()
+	until := now.AddDate(0, 0, cardExpiryAlertDays[0])
+
+	methods, err := s.paymentMethodRepo.GetExpiring(until.Format(dateLayout))
+	if err != nil {
+		return err
+	}
+
+	for _, pm := range methods {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// keep warning the other users when one notification fails
+		if err := s.alertExpiry(pm, now); err != nil {
+			log.Printf("payment method expiry check failed for payment method %d: %v", pm.PaymentMethodID, err)
+		}
+	}
+
+	return nil
+}
+
+// alertExpiry sends the closest warning the card has reached. Earlier
+// warnings that were missed, e.g. because the card was added with little
+// time left, are skipped rather than sent together.
+func (s paymentMethodService) alertExpiry(pm repository.ExpiringPaymentMethod, now time.Time) error {
+	expiresOn, ok := cardExpiresOn(pm.PaymentMethod)
+	if !ok {
+		return nil
+	}
+
+	days := int(expiresOn.Sub(now).Hours() / 24)
+	daysBefore := -1
+	for _, d := range cardExpiryAlertDays {
+		if days <= d {
+			daysBefore = d
+		}
+	}
+	if daysBefore < 0 {
+		return nil
+	}
+
+	subs, err := s.paymentMethodRepo.GetSubscriptions(pm.PaymentMethodID, pm.UserID)
+	if err != nil {
+		return err
+	}
+
+	name := pm.Label
+	if pm.Last4 != "" {
+		name = fmt.Sprintf("%s ending in %s", pm.Label, pm.Last4)
+	}
+
+	var title string
+	switch {
+	case days < 0:
+		title = fmt.Sprintf("%s has expired", name)
+	case days == 0:
+		title = fmt.Sprintf("%s expires today", name)
+	case days == 1:
+		title = fmt.Sprintf("%s expires tomorrow", name)
+	default:
+		title = fmt.Sprintf("%s expires in %d days", name, days)
+	}
+
+	_, err = s.paymentMethodRepo.RecordAlert(repository.PaymentMethodAlert{
+		PaymentMethodID: pm.PaymentMethodID,
+		ExpiresOn:       expiresOn.Format(dateLayout),
+		DaysBefore:      daysBefore,
+	}, repository.Notification{
+		Type:    paymentMethodExpiryType,
+		Title:   title,
+		Message: expiryMessage(subs),
+	}, pm.UserID)
+	return err
+}
+
+// expiryMessage names the subscriptions that need a new card.
+func expiryMessage(subs []repository.Subscription) string {
+	if len(subs) == 0 {
+		return "No subscriptions are billed to it."
+	}
+
+	names := []string{}
+	for i, sub := range subs {
+		if i == maxExpiryNotificationSub {
+			break
+		}
+		names = append(names, sub.Name)
+	}
+
+	list := strings.Join(names, ", ")
+	if more := len(subs) - len(names); more > 0 {
+		list = fmt.Sprintf("%s and %d more", list, more)
+	}
+
+	if len(subs) == 1 {
+		return fmt.Sprintf("Update the payment details of %s before it fails to renew.", list)
+	}
+	return fmt.Sprintf("Update the payment details of %d subscriptions before they fail to renew: %s.", len(subs), list)
+}
+
+func normalizePaymentMethodRequest(req PaymentMethodRequest) (*repository.PaymentMethod, error) {
+	label := strings.TrimSpace(req.Label)
+	last4 := strings.TrimSpace(req.Last4)
+	kind := strings.ToLower(strings.TrimSpace(req.Type))
+	if kind == "" {
+		kind = paymentMethodCard
+	}
+
+	switch {
+	case label == "":
+		return nil, fmt.Errorf("%w: label is required", ErrInvalidPaymentMethod)
+	case len(label) > maxPaymentMethodLabel:
+		return nil, fmt.Errorf("%w: label must be at most %d characters", ErrInvalidPaymentMethod, maxPaymentMethodLabel)
+	case panPattern.MatchString(label) || panPattern.MatchString(last4):
+		return nil, fmt.Errorf("%w: never enter a full card number, only the last 4 digits", ErrInvalidPaymentMethod)
+	case !paymentMethodTypes[kind]:
+		return nil, fmt.Errorf("%w: type must be card, bank_account, paypal, wallet or other", ErrInvalidPaymentMethod)
+	case last4 != "" && !last4Pattern.MatchString(last4):
+		return nil, fmt.Errorf("%w: last4 must be exactly 4 digits", ErrInvalidPaymentMethod)
+	case (req.ExpiryMonth == nil) != (req.ExpiryYear == nil):
+		return nil, fmt.Errorf("%w: expiry_month and expiry_year go together", ErrInvalidPaymentMethod)
+	case kind == paymentMethodCard && req.ExpiryMonth == nil:
+		return nil, fmt.Errorf("%w: a card needs an expiry month and year", ErrInvalidPaymentMethod)
+	}
+
+	pm := &repository.PaymentMethod{Label: label, Type: kind, Last4: last4}
+
+	if req.ExpiryMonth != nil {
+		month, year := *req.ExpiryMonth, *req.ExpiryYear
+		if year < 100 {
+			// cards print the year as two digits
+			year += 2000
+		}
+		if month < 1 || month > 12 {
+			return nil, fmt.Errorf("%w: expiry_month must be between 1 and 12", ErrInvalidPaymentMethod)
+		}
+		if year < 2000 || year > 2100 {
+			return nil, fmt.Errorf("%w: expiry_year is out of range", ErrInvalidPaymentMethod)
+		}
+		pm.ExpiryMonth, pm.ExpiryYear = &month, &year
+	}
+
+	return pm, nil
+}
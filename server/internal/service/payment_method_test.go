@@ -0,0 +1,120 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCreatePaymentMethod(t *testing.T) {
+	t.Run("Create Payment Method Two Digit Year", func(t *testing.T) {
+		// arrange
+		paymentMethodRepo := repository.NewPaymentMethodRepositoryMock()
+		month, year, fullYear := 8, 29, 2029
+
+		paymentMethodRepo.
+			On("Create", mock.MatchedBy(func(pm *repository.PaymentMethod) bool {
+				return pm.Type == "card" && pm.Last4 == "4242" && *pm.ExpiryMonth == 8 && *pm.ExpiryYear == 2029
+			}), 10).
+			Return(&repository.PaymentMethod{PaymentMethodID: 1, Label: "Visa", Type: "card", Last4: "4242", ExpiryMonth: &month, ExpiryYear: &fullYear}, nil)
+
+		paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo)
+
+		// act
+		res, err := paymentMethodService.CreatePaymentMethod(service.PaymentMethodRequest{
+			Label:       "Visa",
+			Last4:       "4242",
+			ExpiryMonth: &month,
+			ExpiryYear:  &year,
+		}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "2029-08-31", res.ExpiresOn)
+		paymentMethodRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Payment Method Invalid", func(t *testing.T) {
+		// arrange
+		paymentMethodService := service.NewPaymentMethodService(repository.NewPaymentMethodRepositoryMock())
+		month, badMonth, year := 8, 13, 2029
+
+		// act & assert
+		for _, req := range []service.PaymentMethodRequest{
+			{Label: "", Type: "paypal"},
+			{Label: "Visa", Last4: "4242424242424242", ExpiryMonth: &month, ExpiryYear: &year},
+			{Label: "Visa 4242 4242 4242 4242", ExpiryMonth: &month, ExpiryYear: &year},
+			{Label: "Visa", Last4: "42a2", ExpiryMonth: &month, ExpiryYear: &year},
+			{Label: "Visa"},
+			{Label: "Visa", ExpiryMonth: &month},
+			{Label: "Visa", ExpiryMonth: &badMonth, ExpiryYear: &year},
+			{Label: "Cash", Type: "cash"},
+		} {
+			_, err := paymentMethodService.CreatePaymentMethod(req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidPaymentMethod)
+		}
+	})
+}
+
+func TestCheckExpiring(t *testing.T) {
+	t.Run("Check Expiring Alerts Closest Warning", func(t *testing.T) {
+		// arrange
+		paymentMethodRepo := repository.NewPaymentMethodRepositoryMock()
+
+		// a card expiring at the end of this month is at most 30 days away
+		now := time.Now().UTC()
+		month, year := int(now.Month()), now.Year()
+		expiresOn := time.Date(year, now.Month()+1, 0, 0, 0, 0, 0, time.UTC)
+		today := time.Date(year, now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+		days := int(expiresOn.Sub(today).Hours() / 24)
+		daysBefore := 30
+		if days <= 7 {
+			daysBefore = 7
+		}
+		if days <= 0 {
+			daysBefore = 0
+		}
+
+		paymentMethodRepo.
+			On("GetExpiring", today.AddDate(0, 0, 30).Format("2006-01-02")).
+			Return([]repository.ExpiringPaymentMethod{
+				{
+					PaymentMethod: repository.PaymentMethod{
+						PaymentMethodID: 3, Label: "Visa", Type: "card", Last4: "4242",
+						ExpiryMonth: &month, ExpiryYear: &year,
+					},
+					UserID: 10,
+				},
+			}, nil)
+		paymentMethodRepo.
+			On("GetSubscriptions", 3, 10).
+			Return([]repository.Subscription{{Name: "Netflix"}, {Name: "Spotify"}}, nil)
+		paymentMethodRepo.
+			On("RecordAlert",
+				repository.PaymentMethodAlert{
+					PaymentMethodID: 3,
+					ExpiresOn:       expiresOn.Format("2006-01-02"),
+					DaysBefore:      daysBefore,
+				},
+				mock.MatchedBy(func(n repository.Notification) bool {
+					return n.Type == "payment_method_expiry" &&
+						n.Message == "Update the payment details of 2 subscriptions before they fail to renew: Netflix, Spotify."
+				}),
+				10).
+			Return(true, nil)
+
+		paymentMethodService := service.NewPaymentMethodService(paymentMethodRepo)
+
+		// act
+		err := paymentMethodService.CheckExpiring(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		paymentMethodRepo.AssertExpectations(t)
+	})
+}
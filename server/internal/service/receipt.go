@@ -0,0 +1,20 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+// ReceiptAddressResponse is the user's personal receipt inbox address.
+type ReceiptAddressResponse struct {
+	Address string `json:"address"`
+}
+
+type ReceiptService interface {
+	// IngestReceipt turns a raw .eml receipt uploaded by the user into a
+	// pending suggestion.
+	IngestReceipt(raw []byte, userID int) (*SuggestionResponse, error)
+	// IngestMail handles a message from the receipt mailbox, working out
+	// the user from the secret token in the recipient address.
+	IngestMail(raw []byte) error
+	// RotateAddress issues a new receipt inbox address for the user,
+	// invalidating the previous one.
+	RotateAddress(actor repository.Actor) (*ReceiptAddressResponse, error)
+}
@@ -0,0 +1,192 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/receipt"
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/statement"
+)
+
+const (
+	suggestionSourceEmail = "email"
+	// a hex encoded token of 16 bytes keeps the address within the 64
+	// characters allowed before the @
+	receiptTokenBytes = 16
+)
+
+var (
+	ErrInvalidReceipt       = errors.New("invalid receipt")
+	ErrNoReceiptData        = receipt.ErrNoReceipt
+	ErrUnknownRecipient     = errors.New("no user matches the receipt address")
+	ErrReceiptInboxDisabled = errors.New("receipt inbox is not configured")
+)
+
+type receiptService struct {
+	parser         *receipt.Parser
+	subRepo        repository.SubscriptionRepository
+	userRepo       repository.UserRepository
+	suggestionRepo repository.SuggestionRepository
+	// inbox is the receipt mailbox, e.g. receipts@example.com; users
+	// forward to receipts+<token>@example.com. Empty disables mail.
+	inbox string
+}
+
+func NewReceiptService(
+	parser *receipt.Parser,
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	suggestionRepo repository.SuggestionRepository,
+	inbox string,
+) ReceiptService {
+	return receiptService{
+		parser:         parser,
+		subRepo:        subRepo,
+		userRepo:       userRepo,
+		suggestionRepo: suggestionRepo,
+		inbox:          strings.ToLower(strings.TrimSpace(inbox)),
+	}
+}
+
+func (s receiptService) IngestReceipt(raw []byte, userID int) (*SuggestionResponse, error) {
+	msg, r, err := s.parser.Parse(raw)
+	if err != nil {
+		if errors.Is(err, receipt.ErrInvalidMessage) {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidReceipt, err)
+		}
+		return nil, err
+	}
+
+	return s.suggest(msg, r, userID)
+}
+
+// IngestMail only trusts the token in the recipient address. The sender
+// is never used to find the user, since anyone can write any From header.
+func (s receiptService) IngestMail(raw []byte) error {
+	msg, r, err := s.parser.Parse(raw)
+	if err != nil {
+		return err
+	}
+
+	for _, addr := range msg.Recipients {
+		token, ok := s.receiptToken(addr)
+		if !ok {
+			continue
+		}
+		user, err := s.userRepo.GetByReceiptTokenHash(hashToken(token))
+		if err != nil {
+			return err
+		}
+		if user != nil {
+			_, err := s.suggest(msg, r, user.ID)
+			return err
+		}
+	}
+
+	return ErrUnknownRecipient
+}
+
+// RotateAddress stores only a hash of the token, so the address is shown
+// to the user exactly once.
+func (s receiptService) RotateAddress(actor repository.Actor) (*ReceiptAddressResponse, error) {
+	at := strings.LastIndex(s.inbox, "@")
+	if at < 0 {
+		return nil, ErrReceiptInboxDisabled
+	}
+
+	raw := make([]byte, receiptTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	if err := s.userRepo.SetReceiptTokenHash(actor.UserID, hashToken(token), actor); err != nil {
+		return nil, err
+	}
+
+	return &ReceiptAddressResponse{
+		Address: s.inbox[:at] + "+" + token + s.inbox[at:],
+	}, nil
+}
+
+// receiptToken returns the token of a recipient such as
+// receipts+<token>@example.com when it is an address of the inbox.
+func (s receiptService) receiptToken(address string) (string, bool) {
+	at := strings.LastIndex(address, "@")
+	plus := strings.Index(address, "+")
+	if s.inbox == "" || at < 0 || plus < 0 || plus > at {
+		return "", false
+	}
+	if address[:plus]+address[at:] != s.inbox {
+		return "", false
+	}
+
+	token := address[plus+1 : at]
+	return token, token != ""
+}
+
+func (s receiptService) suggest(msg *receipt.Message, r *receipt.Receipt, userID int) (*SuggestionResponse, error) {
+	receiptDate := r.Date
+	if receiptDate.IsZero() {
+		receiptDate = today()
+	}
+
+	next := r.NextBillingDate
+	if next.IsZero() {
+		if cycle, ok := parseBillingCycle(r.BillingCycle); ok {
+			next = cycle.occurrence(receiptDate, 1)
+		}
+	}
+
+	evidence, err := json.Marshal([]SuggestionEvidence{{
+		Date:        receiptDate.Format(dateLayout),
+		Description: msg.Subject,
+		Amount:      r.Amount,
+		Currency:    r.Currency,
+	}})
+	if err != nil {
+		return nil, err
+	}
+
+	merchantKey := statement.NormalizeMerchant(r.Merchant)
+	if merchantKey == "" {
+		merchantKey = strings.ToLower(r.Merchant)
+	}
+
+	suggestion := &repository.Suggestion{
+		Source:       suggestionSourceEmail,
+		MerchantKey:  merchantKey,
+		Name:         r.Merchant,
+		Amount:       float32(r.Amount),
+		Currency:     normalizeCurrency(r.Currency),
+		BillingCycle: r.BillingCycle,
+		Confidence:   r.Confidence,
+		Evidence:     string(evidence),
+	}
+	if !next.IsZero() {
+		suggestion.BillingDate = next.Format(dateLayout)
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+	suggestion.MatchedSubscriptionID = matchSubscription(subs, merchantKey)
+
+	saved, err := s.suggestionRepo.Save(suggestion, userID)
+	if err != nil {
+		return nil, err
+	}
+	if saved == nil {
+		// the user rejected this merchant before
+		return nil, ErrSuggestionNotPending
+	}
+
+	res := toSuggestionResponse(*saved)
+	return &res, nil
+}
@@ -0,0 +1,78 @@
+package service_test
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/receipt"
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+const forwardedReceipt = "From: John <john@test.com>\r\n" +
+	"To: Receipts <receipts+0a1b2c@subscout.test>\r\n" +
+	"Subject: Fwd: Your Netflix receipt\r\n" +
+	"Content-Type: text/plain; charset=utf-8\r\n" +
+	"\r\n" +
+	"---------- Forwarded message ---------\r\n" +
+	"From: Netflix <info@account.netflix.com>\r\n" +
+	"Date: Mon, Feb 3, 2025 at 8:00 AM\r\n" +
+	"Subject: Your Netflix receipt\r\n" +
+	"\r\n" +
+	"Total: 419.00 THB\r\n"
+
+func tokenHash(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestIngestMail(t *testing.T) {
+	t.Run("Attributed By Inbox Token", func(t *testing.T) {
+		// arrange
+		subRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+
+		userRepo.On("GetByReceiptTokenHash", tokenHash("0a1b2c")).Return(&repository.User{ID: 10}, nil)
+		subRepo.On("GetAll", 10).Return([]repository.Subscription{}, nil)
+		suggestionRepo.
+			On("Save", mock.MatchedBy(func(s *repository.Suggestion) bool {
+				return s.Name == "Netflix" && s.MerchantKey == "netflix" && s.Amount == 419
+			}), 10).
+			Return(&repository.Suggestion{SuggestionID: 1, Name: "Netflix", Status: "pending"}, nil)
+
+		receiptService := service.NewReceiptService(
+			receipt.NewDefaultParser(), subRepo, userRepo, suggestionRepo, "receipts@subscout.test",
+		)
+
+		// act
+		err := receiptService.IngestMail([]byte(forwardedReceipt))
+
+		// assert
+		assert.NoError(t, err)
+		userRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+		suggestionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Sender Is Not Trusted", func(t *testing.T) {
+		// arrange
+		userRepo := repository.NewUserRepositoryMock()
+		raw := "From: john@test.com\r\nTo: receipts@subscout.test\r\nSubject: Receipt\r\n\r\nTotal: $10.00\r\n"
+
+		receiptService := service.NewReceiptService(
+			receipt.NewDefaultParser(), repository.NewSubscriptionRepositoryMock(), userRepo,
+			repository.NewSuggestionRepositoryMock(), "receipts@subscout.test",
+		)
+
+		// act
+		err := receiptService.IngestMail([]byte(raw))
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrUnknownRecipient)
+		userRepo.AssertNotCalled(t, "GetByEmail", mock.Anything)
+		userRepo.AssertNotCalled(t, "GetByReceiptTokenHash", mock.Anything)
+	})
+}
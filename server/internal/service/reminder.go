@@ -0,0 +1,39 @@
+package service
+
+import "context"
+
+// Dates a reminder rule counts back from.
+const (
+	ReminderAnchorRenewal              = "renewal"
+	ReminderAnchorTrialEnd             = "trial_end"
+	ReminderAnchorCancellationDeadline = "cancellation_deadline"
+)
+
+type ReminderRuleResponse struct {
+	RuleID         int    `json:"id"`
+	SubscriptionID int    `json:"subscription_id"`
+	Anchor         string `json:"anchor"`
+	DaysBefore     int    `json:"days_before"`
+	CreatedAt      string `json:"created_at"`
+}
+
+// ReminderRuleRequest asks for a reminder DaysBefore days ahead of the
+// anchor date. Anchor defaults to the renewal.
+type ReminderRuleRequest struct {
+	Anchor     string `json:"anchor"`
+	DaysBefore *int   `json:"days_before"`
+}
+
+type ReminderService interface {
+	// GetRules lists a subscription's rules. A subscription without rules
+	// is reminded about reminder_days before each renewal.
+	GetRules(subscriptionID int, userID int) ([]ReminderRuleResponse, error)
+	CreateRule(subscriptionID int, req ReminderRuleRequest, userID int) (*ReminderRuleResponse, error)
+	UpdateRule(subscriptionID int, ruleID int, req ReminderRuleRequest, userID int) (*ReminderRuleResponse, error)
+	DeleteRule(subscriptionID int, ruleID int, userID int) error
+
+	// SendReminders notifies every user about the dates their reminder
+	// rules ask for, once per rule and date. Subscriptions the user
+	// snoozed are skipped until the snooze is over.
+	SendReminders(ctx context.Context) error
+}
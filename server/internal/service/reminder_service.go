@@ -0,0 +1,353 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	reminderRenewalType      = "renewal_reminder"
+	reminderTrialType        = "trial_ending"
+	reminderCancellationType = "cancellation_deadline"
+	maxReminderOffset        = 365
+)
+
+var (
+	ErrReminderRuleNotFound = errors.New("reminder rule not found")
+	ErrInvalidReminderRule  = errors.New("invalid reminder rule")
+)
+
+var reminderAnchors = map[string]bool{
+	ReminderAnchorRenewal:              true,
+	ReminderAnchorTrialEnd:             true,
+	ReminderAnchorCancellationDeadline: true,
+}
+
+// reminderKinds is the order a subscription's reminders are raised in.
+var reminderKinds = []string{reminderCancellationType, reminderTrialType, reminderRenewalType}
+
+type reminderService struct {
+	reminderRepo repository.ReminderRepository
+	prefRepo     repository.NotificationPreferenceRepository
+	subRepo      repository.SubscriptionRepository
+	userRepo     repository.UserRepository
+}
+
+func NewReminderService(
+	reminderRepo repository.ReminderRepository,
+	prefRepo repository.NotificationPreferenceRepository,
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+) ReminderService {
+	return reminderService{
+		reminderRepo: reminderRepo,
+		prefRepo:     prefRepo,
+		subRepo:      subRepo,
+		userRepo:     userRepo,
+	}
+}
+
+func toReminderRuleResponse(r repository.ReminderRule) ReminderRuleResponse {
+	return ReminderRuleResponse{
+		RuleID:         r.RuleID,
+		SubscriptionID: r.SubscriptionID,
+		Anchor:         r.Anchor,
+		DaysBefore:     r.DaysBefore,
+		CreatedAt:      r.CreatedAt,
+	}
+}
+
+func (s reminderService) GetRules(subscriptionID int, userID int) ([]ReminderRuleResponse, error) {
+	sub, err := s.subRepo.GetById(subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	rules, err := s.reminderRepo.GetSubscriptionRules(subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []ReminderRuleResponse{}
+	for _, r := range rules {
+		res = append(res, toReminderRuleResponse(r))
+	}
+
+	return res, nil
+}
+
+func (s reminderService) CreateRule(subscriptionID int, req ReminderRuleRequest, userID int) (*ReminderRuleResponse, error) {
+	rule, err := normalizeReminderRuleRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	rule.SubscriptionID = subscriptionID
+
+	created, err := s.reminderRepo.CreateRule(rule, userID)
+	if err != nil {
+		return nil, err
+	}
+	if created == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	res := toReminderRuleResponse(*created)
+	return &res, nil
+}
+
+func (s reminderService) UpdateRule(subscriptionID int, ruleID int, req ReminderRuleRequest, userID int) (*ReminderRuleResponse, error) {
+	rule, err := normalizeReminderRuleRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	rule.RuleID = ruleID
+	rule.SubscriptionID = subscriptionID
+
+	updated, err := s.reminderRepo.UpdateRule(rule, userID)
+	if err != nil {
+		return nil, err
+	}
+	if updated == nil {
+		return nil, ErrReminderRuleNotFound
+	}
+
+	res := toReminderRuleResponse(*updated)
+	return &res, nil
+}
+
+func (s reminderService) DeleteRule(subscriptionID int, ruleID int, userID int) error {
+	err := s.reminderRepo.DeleteRule(ruleID, subscriptionID, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrReminderRuleNotFound
+	}
+	return err
+}
+
+func normalizeReminderRuleRequest(req ReminderRuleRequest) (*repository.ReminderRule, error) {
+	anchor := strings.ToLower(strings.TrimSpace(req.Anchor))
+	if anchor == "" {
+		anchor = ReminderAnchorRenewal
+	}
+	if !reminderAnchors[anchor] {
+		return nil, fmt.Errorf("%w: anchor must be renewal, trial_end or cancellation_deadline", ErrInvalidReminderRule)
+	}
+
+	if req.DaysBefore == nil {
+		return nil, fmt.Errorf("%w: days_before is required", ErrInvalidReminderRule)
+	}
+	if *req.DaysBefore < 0 || *req.DaysBefore > maxReminderOffset {
+		return nil, fmt.Errorf("%w: days_before must be between 0 and %d", ErrInvalidReminderRule, maxReminderOffset)
+	}
+
+	return &repository.ReminderRule{Anchor: anchor, DaysBefore: *req.DaysBefore}, nil
+}
+
+func (s reminderService) SendReminders(ctx context.Context) error {
+	userIDs, err := s.reminderRepo.GetUserIDs()
+	if err != nil {
+		return err
+	}
+
+	now := time.Now()
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// keep reminding the other users when one fails
+		if err := s.remind(userID, now); err != nil {
+			log.Printf("reminders failed for user %d: %v", userID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s reminderService) remind(userID int, now time.Time) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	loc, err := loadTimezone(user.Timezone)
+	if err != nil {
+		loc = time.UTC
+	}
+	day := localDay(now, loc)
+
+	snoozes, err := s.prefRepo.GetSnoozes(userID)
+	if err != nil {
+		return err
+	}
+	snoozedUntil := map[int]string{}
+	for _, z := range snoozes {
+		snoozedUntil[z.SubscriptionID] = z.Until
+	}
+
+	rules, err := s.reminderRepo.GetRules(userID)
+	if err != nil {
+		return err
+	}
+	rulesOf := map[int][]repository.ReminderRule{}
+	for _, r := range rules {
+		rulesOf[r.SubscriptionID] = append(rulesOf[r.SubscriptionID], r)
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, sub := range subs {
+		if !isActive(sub.Status) {
+			continue
+		}
+		if until, ok := snoozedUntil[sub.SubscriptionID]; ok && until >= day.Format(dateLayout) {
+			continue
+		}
+
+		subRules, ok := rulesOf[sub.SubscriptionID]
+		if !ok {
+			subRules = []repository.ReminderRule{defaultReminderRule(sub, user.ReminderDays)}
+		}
+
+		for _, r := range dueReminders(sub, subRules, day) {
+			if _, err := s.reminderRepo.Record(r.reminder, r.notification, userID); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// defaultReminderRule applies to subscriptions without rules of their own.
+// A renewal that must be cancelled ahead of time is reminded before the
+// last day to cancel, since a reminder before the renewal itself would
+// come too late to act on.
+func defaultReminderRule(sub repository.Subscription, days int) repository.ReminderRule {
+	anchor := ReminderAnchorRenewal
+	if sub.AutoRenew && sub.NoticePeriodDays > 0 {
+		anchor = ReminderAnchorCancellationDeadline
+	}
+	return repository.ReminderRule{Anchor: anchor, DaysBefore: days}
+}
+
+type dueReminder struct {
+	reminder     repository.Reminder
+	notification repository.Notification
+}
+
+// dueReminders evaluates the rules of sub on day. Of the offsets counting
+// back from the same date, only the closest one that has been reached is
+// raised, so a scheduler that was down does not catch up with a burst of
+// reminders for one renewal.
+func dueReminders(sub repository.Subscription, rules []repository.ReminderRule, day time.Time) []dueReminder {
+	offsets := map[string][]int{}
+	longest := 0
+	for _, r := range rules {
+		kind, ok := reminderKind(r.Anchor, sub)
+		if !ok {
+			continue
+		}
+		offsets[kind] = append(offsets[kind], r.DaysBefore)
+		longest = max(longest, r.DaysBefore)
+	}
+
+	var due []dueReminder
+	for _, kind := range reminderKinds {
+		if len(offsets[kind]) == 0 {
+			continue
+		}
+
+		var date, renewal time.Time
+		if kind == reminderCancellationType {
+			deadline, next, ok, err := cancellationDeadline(sub, day)
+			if err != nil || !ok {
+				continue
+			}
+			date, renewal = deadline, next
+		} else {
+			dates, err := renewalDates(sub, day, day.AddDate(0, 0, longest))
+			if err != nil || len(dates) == 0 {
+				continue
+			}
+			date, renewal = dates[0], dates[0]
+		}
+
+		daysLeft := int(date.Sub(day).Hours() / 24)
+		sort.Ints(offsets[kind])
+		for _, offset := range offsets[kind] {
+			if offset >= daysLeft {
+				due = append(due, reminderFor(kind, sub, date, renewal, daysLeft, offset))
+				break
+			}
+		}
+	}
+
+	return due
+}
+
+// reminderKind is the notification a rule raises for sub. Trials are
+// billed for the first time when they end, so both renewal and trial end
+// rules announce the end of a trial; subscriptions that are not trials
+// have no trial end.
+func reminderKind(anchor string, sub repository.Subscription) (string, bool) {
+	switch anchor {
+	case ReminderAnchorRenewal, ReminderAnchorTrialEnd:
+		if sub.Trial {
+			return reminderTrialType, true
+		}
+		return reminderRenewalType, anchor == ReminderAnchorRenewal
+	case ReminderAnchorCancellationDeadline:
+		return reminderCancellationType, true
+	}
+	return "", false
+}
+
+// reminderFor announces date, daysLeft days from now, for a rule of offset
+// days. renewal is the renewal that date leads up to.
+func reminderFor(kind string, sub repository.Subscription, date, renewal time.Time, daysLeft, offset int) dueReminder {
+	when := fmt.Sprintf("in %d days", daysLeft)
+	switch daysLeft {
+	case 0:
+		when = "today"
+	case 1:
+		when = "tomorrow"
+	}
+
+	charge := fmt.Sprintf("%.2f %s", sub.Amount, normalizeCurrency(sub.Currency))
+
+	var title, message string
+	switch kind {
+	case reminderTrialType:
+		title = fmt.Sprintf("%s trial ends %s", sub.Name, when)
+		message = fmt.Sprintf("%s will be charged on %s.", charge, renewal.Format(dateLayout))
+	case reminderCancellationType:
+		title = fmt.Sprintf("Last day to cancel %s is %s", sub.Name, when)
+		message = fmt.Sprintf("Cancel by %s to avoid the %s renewal on %s.", date.Format(dateLayout), charge, renewal.Format(dateLayout))
+	default:
+		title = fmt.Sprintf("%s renews %s", sub.Name, when)
+		message = fmt.Sprintf("%s will be charged on %s.", charge, renewal.Format(dateLayout))
+	}
+
+	return dueReminder{
+		reminder: repository.Reminder{
+			SubscriptionID: sub.SubscriptionID,
+			Kind:           kind,
+			DueOn:          date.Format(dateLayout),
+			DaysBefore:     offset,
+		},
+		notification: repository.Notification{Type: kind, Title: title, Message: message},
+	}
+}
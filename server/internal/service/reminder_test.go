@@ -0,0 +1,233 @@
+package service_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSendReminders(t *testing.T) {
+	t.Run("Send Reminders Success", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		day := time.Now().UTC()
+		tomorrow := day.AddDate(0, 0, 1).Format("2006-01-02")
+		later := day.AddDate(0, 0, 2).Format("2006-01-02")
+
+		reminderRepo.
+			On("GetUserIDs").
+			Return([]int{10}, nil)
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, ReminderDays: 3, Timezone: "UTC"}, nil)
+		prefRepo.
+			On("GetSnoozes", 10).
+			Return([]repository.SubscriptionSnooze{{SubscriptionID: 3, Until: later}}, nil)
+		reminderRepo.
+			On("GetRules", 10).
+			Return([]repository.ReminderRule{}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 1, Name: "Netflix", Amount: 419, Currency: "THB", BillingCycle: "monthly", BillingDate: tomorrow, Status: "active"},
+				{SubscriptionID: 2, Name: "Cloud", Amount: 10, Currency: "usd", BillingCycle: "monthly", BillingDate: later, Status: "active", Trial: true},
+				{SubscriptionID: 3, Name: "Snoozed", Amount: 5, Currency: "USD", BillingCycle: "monthly", BillingDate: tomorrow, Status: "active"},
+				{SubscriptionID: 4, Name: "Cancelled", Amount: 5, Currency: "USD", BillingCycle: "monthly", BillingDate: tomorrow, Status: "cancelled"},
+				{SubscriptionID: 5, Name: "Far Away", Amount: 5, Currency: "USD", BillingCycle: "yearly", BillingDate: day.AddDate(0, 0, 30).Format("2006-01-02"), Status: "active"},
+			}, nil)
+		reminderRepo.
+			On("Record", repository.Reminder{SubscriptionID: 1, Kind: "renewal_reminder", DueOn: tomorrow, DaysBefore: 3}, repository.Notification{
+				Type:    "renewal_reminder",
+				Title:   "Netflix renews tomorrow",
+				Message: "419.00 THB will be charged on " + tomorrow + ".",
+			}, 10).
+			Return(true, nil)
+		reminderRepo.
+			On("Record", repository.Reminder{SubscriptionID: 2, Kind: "trial_ending", DueOn: later, DaysBefore: 3}, mock.MatchedBy(func(n repository.Notification) bool {
+				return n.Title == "Cloud trial ends in 2 days"
+			}), 10).
+			Return(true, nil)
+
+		reminderService := service.NewReminderService(reminderRepo, prefRepo, subscriptionRepo, userRepo)
+
+		// act
+		err := reminderService.SendReminders(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		reminderRepo.AssertExpectations(t)
+		reminderRepo.AssertNumberOfCalls(t, "Record", 2)
+	})
+
+	t.Run("Send Reminders By Rules", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		day := time.Now().UTC()
+		renewal := day.AddDate(0, 0, 10).Format("2006-01-02")
+		deadline := day.AddDate(0, 0, 3).Format("2006-01-02")
+
+		reminderRepo.
+			On("GetUserIDs").
+			Return([]int{10}, nil)
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, ReminderDays: 3, Timezone: "UTC"}, nil)
+		prefRepo.
+			On("GetSnoozes", 10).
+			Return([]repository.SubscriptionSnooze{}, nil)
+		reminderRepo.
+			On("GetRules", 10).
+			Return([]repository.ReminderRule{
+				// 10 days left: the 14 day rule applies, the 3 day one not yet
+				{SubscriptionID: 1, Anchor: "renewal", DaysBefore: 14},
+				{SubscriptionID: 1, Anchor: "renewal", DaysBefore: 3},
+				{SubscriptionID: 1, Anchor: "cancellation_deadline", DaysBefore: 7},
+				// not a trial, so there is no trial end
+				{SubscriptionID: 1, Anchor: "trial_end", DaysBefore: 30},
+				// a rule for a subscription that renews later only
+				{SubscriptionID: 2, Anchor: "renewal", DaysBefore: 1},
+			}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 1, Name: "Domain", Amount: 12, Currency: "USD", BillingCycle: "yearly", BillingDate: renewal, Status: "active", AutoRenew: true, NoticePeriodDays: 7},
+				{SubscriptionID: 2, Name: "App", Amount: 35, Currency: "THB", BillingCycle: "monthly", BillingDate: deadline, Status: "active"},
+			}, nil)
+		reminderRepo.
+			On("Record", repository.Reminder{SubscriptionID: 1, Kind: "renewal_reminder", DueOn: renewal, DaysBefore: 14}, mock.MatchedBy(func(n repository.Notification) bool {
+				return n.Title == "Domain renews in 10 days"
+			}), 10).
+			Return(true, nil)
+		reminderRepo.
+			On("Record", repository.Reminder{SubscriptionID: 1, Kind: "cancellation_deadline", DueOn: deadline, DaysBefore: 7}, repository.Notification{
+				Type:    "cancellation_deadline",
+				Title:   "Last day to cancel Domain is in 3 days",
+				Message: "Cancel by " + deadline + " to avoid the 12.00 USD renewal on " + renewal + ".",
+			}, 10).
+			Return(true, nil)
+
+		reminderService := service.NewReminderService(reminderRepo, prefRepo, subscriptionRepo, userRepo)
+
+		// act
+		err := reminderService.SendReminders(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		reminderRepo.AssertExpectations(t)
+		reminderRepo.AssertNumberOfCalls(t, "Record", 2)
+	})
+
+	t.Run("Default Rule Before Last Day To Cancel", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+		prefRepo := repository.NewNotificationPreferenceRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		day := time.Now().UTC()
+		renewal := day.AddDate(0, 0, 33).Format("2006-01-02")
+		deadline := day.AddDate(0, 0, 3).Format("2006-01-02")
+
+		reminderRepo.
+			On("GetUserIDs").
+			Return([]int{10}, nil)
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, ReminderDays: 3, Timezone: "UTC"}, nil)
+		prefRepo.
+			On("GetSnoozes", 10).
+			Return([]repository.SubscriptionSnooze{}, nil)
+		reminderRepo.
+			On("GetRules", 10).
+			Return([]repository.ReminderRule{}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 1, Name: "Gym", Amount: 900, Currency: "THB", BillingCycle: "monthly", BillingDate: renewal, Status: "active", AutoRenew: true, NoticePeriodDays: 30},
+				// renews in 3 days, but the last day to cancel has passed
+				{SubscriptionID: 2, Name: "Phone", Amount: 499, Currency: "THB", BillingCycle: "monthly", BillingDate: deadline, Status: "active", AutoRenew: true, NoticePeriodDays: 30},
+			}, nil)
+		reminderRepo.
+			On("Record", repository.Reminder{SubscriptionID: 1, Kind: "cancellation_deadline", DueOn: deadline, DaysBefore: 3}, mock.Anything, 10).
+			Return(true, nil)
+
+		reminderService := service.NewReminderService(reminderRepo, prefRepo, subscriptionRepo, userRepo)
+
+		// act
+		err := reminderService.SendReminders(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		reminderRepo.AssertExpectations(t)
+		reminderRepo.AssertNumberOfCalls(t, "Record", 1)
+	})
+}
+
+func TestCreateReminderRule(t *testing.T) {
+	t.Run("Create Reminder Rule Success", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+
+		reminderRepo.
+			On("CreateRule", &repository.ReminderRule{SubscriptionID: 5, Anchor: "renewal", DaysBefore: 14}, 10).
+			Return(&repository.ReminderRule{RuleID: 1, SubscriptionID: 5, Anchor: "renewal", DaysBefore: 14}, nil)
+
+		reminderService := service.NewReminderService(reminderRepo, nil, nil, nil)
+		days := 14
+
+		// act
+		res, err := reminderService.CreateRule(5, service.ReminderRuleRequest{DaysBefore: &days}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.RuleID)
+		assert.Equal(t, "renewal", res.Anchor)
+	})
+
+	t.Run("Create Reminder Rule Subscription Not Found", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+
+		reminderRepo.
+			On("CreateRule", mock.Anything, 10).
+			Return((*repository.ReminderRule)(nil), nil)
+
+		reminderService := service.NewReminderService(reminderRepo, nil, nil, nil)
+		days := 1
+
+		// act
+		_, err := reminderService.CreateRule(5, service.ReminderRuleRequest{Anchor: "trial_end", DaysBefore: &days}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrSubscriptionNotFound)
+	})
+
+	t.Run("Create Reminder Rule Invalid", func(t *testing.T) {
+		// arrange
+		reminderRepo := repository.NewReminderRepositoryMock()
+		reminderService := service.NewReminderService(reminderRepo, nil, nil, nil)
+		negative, tooFar, fine := -1, 400, 3
+
+		// act & assert
+		for _, req := range []service.ReminderRuleRequest{
+			{},
+			{DaysBefore: &negative},
+			{DaysBefore: &tooFar},
+			{Anchor: "birthday", DaysBefore: &fine},
+		} {
+			_, err := reminderService.CreateRule(5, req, 10)
+			assert.ErrorIs(t, err, service.ErrInvalidReminderRule)
+		}
+		reminderRepo.AssertNotCalled(t, "CreateRule", mock.Anything, mock.Anything)
+	})
+}
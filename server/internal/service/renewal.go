@@ -0,0 +1,49 @@
+package service
+
+type RenewalOccurrence struct {
+	SubscriptionID  int     `json:"subscription_id"`
+	Name            string  `json:"name"`
+	Category        string  `json:"category"`
+	Date            string  `json:"date"`
+	Amount          float32 `json:"amount"`
+	Currency        string  `json:"currency"`
+	ConvertedAmount float64 `json:"converted_amount"`
+	Trial           bool    `json:"is_trial"`
+}
+
+type RenewalTotal struct {
+	Date  string  `json:"date"` // the day, or the Monday starting the week
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+type RenewalCalendarResponse struct {
+	From         string              `json:"from"`
+	To           string              `json:"to"`
+	BaseCurrency string              `json:"base_currency"`
+	Total        float64             `json:"total"`
+	Occurrences  []RenewalOccurrence `json:"occurrences"`
+	Daily        []RenewalTotal      `json:"daily"`
+	Weekly       []RenewalTotal      `json:"weekly"`
+	Unconverted  []UnconvertedAmount `json:"unconverted"`
+}
+
+// CancellationWindow is an auto-renewing subscription that can still be
+// cancelled before its next renewal, but only until LastDayToCancel.
+type CancellationWindow struct {
+	SubscriptionID   int     `json:"subscription_id"`
+	Name             string  `json:"name"`
+	Amount           float32 `json:"amount"`
+	Currency         string  `json:"currency"`
+	NoticePeriodDays int     `json:"notice_period_days"`
+	NextRenewal      string  `json:"next_renewal"`
+	LastDayToCancel  string  `json:"last_day_to_cancel"`
+	DaysLeft         int     `json:"days_left"`
+}
+
+type RenewalService interface {
+	GetRenewals(userID int, from, to string) (*RenewalCalendarResponse, error)
+	// GetCancellationWindows lists subscriptions whose last day to cancel
+	// falls within the next days days, soonest deadline first.
+	GetCancellationWindows(userID int, days int) ([]CancellationWindow, error)
+}
@@ -0,0 +1,239 @@
+package service
+
+import (
+	"errors"
+	"sort"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	defaultRenewalWindowDays = 30
+	maxRenewalWindowDays     = 366
+)
+
+var ErrInvalidDateRange = errors.New("invalid date range")
+
+type renewalService struct {
+	subRepo      repository.SubscriptionRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewRenewalService(
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	currencyRepo repository.CurrencyRepository,
+) RenewalService {
+	return renewalService{subRepo: subRepo, userRepo: userRepo, currencyRepo: currencyRepo}
+}
+
+// GetRenewals expands every active subscription into the renewals falling
+// inside [from, to]. Both bounds are optional and default to the next 30
+// days starting today.
+func (s renewalService) GetRenewals(userID int, from, to string) (*RenewalCalendarResponse, error) {
+	start, end, err := renewalWindow(from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	rates, err := s.currencyRepo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	converter := newCurrencyConverter(user.BaseCurrency, rates)
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &RenewalCalendarResponse{
+		From:         start.Format(dateLayout),
+		To:           end.Format(dateLayout),
+		BaseCurrency: converter.base,
+		Occurrences:  []RenewalOccurrence{},
+		Daily:        []RenewalTotal{},
+		Weekly:       []RenewalTotal{},
+		Unconverted:  []UnconvertedAmount{},
+	}
+
+	for _, sub := range subs {
+		if !isActive(sub.Status) {
+			continue
+		}
+
+		dates, err := renewalDates(sub, start, end)
+		if err != nil {
+			return nil, err
+		}
+		if len(dates) == 0 {
+			continue
+		}
+
+		converted, err := converter.convert(float64(sub.Amount), sub.Currency)
+		if errors.Is(err, ErrUnknownCurrency) {
+			res.Unconverted = append(res.Unconverted, unconvertedSubscription(sub))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		converted = roundMoney(converted)
+
+		for _, d := range dates {
+			res.Occurrences = append(res.Occurrences, RenewalOccurrence{
+				SubscriptionID:  sub.SubscriptionID,
+				Name:            sub.Name,
+				Category:        sub.Category,
+				Date:            d.Format(dateLayout),
+				Amount:          sub.Amount,
+				Currency:        normalizeCurrency(sub.Currency),
+				ConvertedAmount: converted,
+				Trial:           sub.Trial,
+			})
+		}
+	}
+
+	sort.SliceStable(res.Occurrences, func(i, j int) bool {
+		return res.Occurrences[i].Date < res.Occurrences[j].Date
+	})
+
+	// occurrences are sorted, so days and weeks arrive in order too
+	for _, o := range res.Occurrences {
+		d, _ := time.Parse(dateLayout, o.Date)
+		res.Daily = addToTotal(res.Daily, o.Date, o.ConvertedAmount)
+		res.Weekly = addToTotal(res.Weekly, weekStart(d).Format(dateLayout), o.ConvertedAmount)
+		res.Total += o.ConvertedAmount
+	}
+
+	res.Total = roundMoney(res.Total)
+
+	return res, nil
+}
+
+func (s renewalService) GetCancellationWindows(userID int, days int) ([]CancellationWindow, error) {
+	if days <= 0 || days > maxRenewalWindowDays {
+		return nil, ErrInvalidDateRange
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	day := today()
+	until := day.AddDate(0, 0, days)
+
+	windows := []CancellationWindow{}
+	for _, sub := range subs {
+		deadline, renewal, ok, err := cancellationDeadline(sub, day)
+		if err != nil {
+			return nil, err
+		}
+		if !ok || deadline.After(until) {
+			continue
+		}
+
+		windows = append(windows, CancellationWindow{
+			SubscriptionID:   sub.SubscriptionID,
+			Name:             sub.Name,
+			Amount:           sub.Amount,
+			Currency:         normalizeCurrency(sub.Currency),
+			NoticePeriodDays: sub.NoticePeriodDays,
+			NextRenewal:      renewal.Format(dateLayout),
+			LastDayToCancel:  deadline.Format(dateLayout),
+			DaysLeft:         int(deadline.Sub(day).Hours() / 24),
+		})
+	}
+
+	sort.SliceStable(windows, func(i, j int) bool {
+		return windows[i].LastDayToCancel < windows[j].LastDayToCancel
+	})
+
+	return windows, nil
+}
+
+func renewalWindow(from, to string) (time.Time, time.Time, error) {
+	start := today()
+	if from != "" {
+		t, err := parseDate(from)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		start = t
+	}
+
+	end := start.AddDate(0, 0, defaultRenewalWindowDays)
+	if to != "" {
+		t, err := parseDate(to)
+		if err != nil {
+			return time.Time{}, time.Time{}, err
+		}
+		end = t
+	}
+
+	if end.Before(start) || end.Sub(start) > maxRenewalWindowDays*24*time.Hour {
+		return time.Time{}, time.Time{}, ErrInvalidDateRange
+	}
+
+	return start, end, nil
+}
+
+// renewalDates lists the renewals of sub inside [start, end]. billing_date
+// is the next renewal, so nothing is generated before it. Subscriptions
+// with an unknown cycle only renew once, on billing_date.
+func renewalDates(sub repository.Subscription, start, end time.Time) ([]time.Time, error) {
+	anchor, err := parseDate(sub.BillingDate)
+	if err != nil {
+		return nil, err
+	}
+
+	cycle, ok := parseBillingCycle(sub.BillingCycle)
+	if !ok {
+		if anchor.Before(start) || anchor.After(end) {
+			return nil, nil
+		}
+		return []time.Time{anchor}, nil
+	}
+
+	var dates []time.Time
+	for n := 0; ; n++ {
+		d := cycle.occurrence(anchor, n)
+		if d.After(end) {
+			break
+		}
+		if !d.Before(start) {
+			dates = append(dates, d)
+		}
+	}
+
+	return dates, nil
+}
+
+// weekStart returns the Monday of the ISO week containing t.
+func weekStart(t time.Time) time.Time {
+	offset := (int(t.Weekday()) + 6) % 7
+	return t.AddDate(0, 0, -offset)
+}
+
+func addToTotal(totals []RenewalTotal, date string, amount float64) []RenewalTotal {
+	if len(totals) == 0 || totals[len(totals)-1].Date != date {
+		totals = append(totals, RenewalTotal{Date: date})
+	}
+
+	last := &totals[len(totals)-1]
+	last.Total = roundMoney(last.Total + amount)
+	last.Count++
+
+	return totals
+}
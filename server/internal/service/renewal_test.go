@@ -0,0 +1,182 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetRenewals(t *testing.T) {
+	t.Run("Get Renewals Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{
+					SubscriptionID: 1,
+					Name:           "Netflix",
+					Amount:         100,
+					Currency:       "THB",
+					BillingCycle:   "Monthly",
+					BillingDate:    "2025-01-31T00:00:00Z",
+					Status:         "active",
+				},
+				{
+					SubscriptionID: 2,
+					Name:           "Domain",
+					Amount:         10,
+					Currency:       "USD",
+					BillingCycle:   "yearly",
+					BillingDate:    "2025-02-28",
+					Status:         "active",
+				},
+				{
+					SubscriptionID: 3,
+					Name:           "Gym",
+					Amount:         500,
+					Currency:       "THB",
+					BillingCycle:   "monthly",
+					BillingDate:    "2025-02-01",
+					Status:         "canceled",
+				},
+			}, nil)
+
+		renewalService := service.NewRenewalService(subscriptionRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := renewalService.GetRenewals(10, "2025-01-15", "2025-04-15")
+
+		// assert
+		assert.NoError(t, err)
+
+		dates := []string{}
+		for _, o := range res.Occurrences {
+			dates = append(dates, o.Date)
+		}
+		assert.Equal(t, []string{"2025-01-31", "2025-02-28", "2025-02-28", "2025-03-31"}, dates)
+		assert.Equal(t, float64(350), res.Occurrences[2].ConvertedAmount)
+		assert.Equal(t, float64(650), res.Total)
+
+		assert.Len(t, res.Daily, 3)
+		assert.Equal(t, service.RenewalTotal{Date: "2025-02-28", Total: 450, Count: 2}, res.Daily[1])
+
+		assert.Len(t, res.Weekly, 3)
+		assert.Equal(t, "2025-01-27", res.Weekly[0].Date)
+
+		subscriptionRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+		currencyRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Date Range", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		renewalService := service.NewRenewalService(subscriptionRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := renewalService.GetRenewals(10, "2025-04-15", "2025-01-15")
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrInvalidDateRange)
+	})
+
+	t.Run("Unknown Currency Is Reported", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{Name: "Steam", Amount: 5, Currency: "XYZ", BillingCycle: "monthly", BillingDate: "2025-02-01"},
+			}, nil)
+
+		renewalService := service.NewRenewalService(subscriptionRepo, userRepo, currencyRepo)
+
+		// act
+		res, err := renewalService.GetRenewals(10, "2025-01-15", "2025-04-15")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Empty(t, res.Occurrences)
+		assert.Zero(t, res.Total)
+		if assert.Len(t, res.Unconverted, 1) {
+			assert.Equal(t, "Steam", res.Unconverted[0].Name)
+			assert.Equal(t, "XYZ", res.Unconverted[0].Currency)
+		}
+	})
+}
+
+func TestGetCancellationWindows(t *testing.T) {
+	t.Run("Get Cancellation Windows Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		now := time.Now().UTC()
+		day := func(offset int) string {
+			return now.AddDate(0, 0, offset).Format("2006-01-02")
+		}
+
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 1, Name: "Adobe", BillingCycle: "yearly", BillingDate: day(20), Status: "active", AutoRenew: true, NoticePeriodDays: 10},
+				// this year's deadline has passed, so the next one is a year out
+				{SubscriptionID: 2, Name: "Gym", BillingCycle: "yearly", BillingDate: day(5), Status: "active", AutoRenew: true, NoticePeriodDays: 30},
+				{SubscriptionID: 3, Name: "Domain", BillingCycle: "yearly", BillingDate: day(3), Status: "active", AutoRenew: false},
+				{SubscriptionID: 4, Name: "Netflix", BillingCycle: "monthly", BillingDate: day(2), Status: "active", AutoRenew: true},
+			}, nil)
+
+		renewalService := service.NewRenewalService(subscriptionRepo, nil, nil)
+
+		// act
+		res, err := renewalService.GetCancellationWindows(10, 30)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 2)
+		assert.Equal(t, "Netflix", res[0].Name)
+		assert.Equal(t, 2, res[0].DaysLeft)
+		assert.Equal(t, "Adobe", res[1].Name)
+		assert.Equal(t, day(10), res[1].LastDayToCancel)
+		assert.Equal(t, day(20), res[1].NextRenewal)
+		assert.Equal(t, 10, res[1].DaysLeft)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invalid Days", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		renewalService := service.NewRenewalService(subscriptionRepo, nil, nil)
+
+		// act
+		res, err := renewalService.GetCancellationWindows(10, 0)
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrInvalidDateRange)
+	})
+}
@@ -0,0 +1,98 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+const (
+	SplitEqual      = "equal"
+	SplitPercentage = "percentage"
+	SplitFixed      = "fixed"
+)
+
+// ShareMemberRequest names either a registered user, by email, or someone
+// without an account, by name.
+type ShareMemberRequest struct {
+	Email string   `json:"email"`
+	Name  string   `json:"name"`
+	Value *float64 `json:"value"` // percentage or fixed amount per billing period
+}
+
+// ShareRequest replaces the split of a subscription. Method defaults to
+// equal and StartedOn, the first billing period that is split, to today.
+type ShareRequest struct {
+	Method    string               `json:"method"`
+	StartedOn string               `json:"started_on"`
+	Members   []ShareMemberRequest `json:"members"`
+}
+
+// ShareMemberResponse shows what a member pays per billing period and how
+// much of it is still outstanding, in the subscription's currency.
+type ShareMemberResponse struct {
+	MemberID    int      `json:"id"`
+	UserID      *int     `json:"user_id"` // null for someone without an account
+	Name        string   `json:"name"`
+	Value       *float64 `json:"value"`
+	Share       float64  `json:"share"`
+	Periods     int      `json:"periods"` // billing periods split so far
+	Owed        float64  `json:"owed"`
+	Settled     float64  `json:"settled"`
+	Outstanding float64  `json:"outstanding"`
+}
+
+type ShareResponse struct {
+	SubscriptionID   int                   `json:"subscription_id"`
+	SubscriptionName string                `json:"subscription_name"`
+	OwnerID          int                   `json:"owner_id"`
+	OwnerName        string                `json:"owner_name"`
+	Amount           float32               `json:"amount"`
+	Currency         string                `json:"currency"`
+	BillingCycle     string                `json:"billing_cycle"`
+	Method           string                `json:"method"`
+	StartedOn        string                `json:"started_on"`
+	OwnerShare       float64               `json:"owner_share"` // per billing period
+	Members          []ShareMemberResponse `json:"members"`
+}
+
+// SettlementRequest records a payment from a member to the owner, in the
+// subscription's currency. PaidOn defaults to today.
+type SettlementRequest struct {
+	MemberID int     `json:"member_id"`
+	Amount   float64 `json:"amount"`
+	PaidOn   string  `json:"paid_on"`
+	Note     string  `json:"note"`
+}
+
+type SettlementResponse struct {
+	SettlementID int     `json:"id"`
+	MemberID     int     `json:"member_id"`
+	Amount       float64 `json:"amount"`
+	PaidOn       string  `json:"paid_on"`
+	Note         string  `json:"note"`
+}
+
+// Balance is what one person owes the user (positive) or what the user
+// owes them (negative), netted across every shared subscription.
+type Balance struct {
+	UserID *int    `json:"user_id"` // null for someone without an account
+	Name   string  `json:"name"`
+	Amount float64 `json:"amount"`
+}
+
+type BalancesResponse struct {
+	BaseCurrency string              `json:"base_currency"`
+	OwedToYou    float64             `json:"owed_to_you"`
+	YouOwe       float64             `json:"you_owe"`
+	Balances     []Balance           `json:"balances"`
+	Unconverted  []UnconvertedAmount `json:"unconverted"` // Amount is signed like a Balance
+}
+
+type ShareService interface {
+	// GetShares lists every share the user owns or is a member of.
+	GetShares(userID int) ([]ShareResponse, error)
+	GetShare(subscriptionID int, userID int) (*ShareResponse, error)
+	SetShare(subscriptionID int, req ShareRequest, actor repository.Actor) (*ShareResponse, error)
+	DeleteShare(subscriptionID int, actor repository.Actor) error
+	RecordSettlement(subscriptionID int, req SettlementRequest, actor repository.Actor) (*SettlementResponse, error)
+	// GetBalances nets what is outstanding on every shared subscription
+	// per person, converted to the user's base currency.
+	GetBalances(userID int) (*BalancesResponse, error)
+}
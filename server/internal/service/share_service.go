@@ -0,0 +1,482 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	maxShareMembers       = 20
+	maxShareMemberName    = 100
+	maxSettlementNote     = 200
+	fullSharePercentage   = 100
+	settledBalanceEpsilon = 0.005
+)
+
+var (
+	ErrShareNotFound       = errors.New("subscription is not shared")
+	ErrShareMemberNotFound = errors.New("share member not found")
+	ErrInvalidShare        = errors.New("invalid share")
+	ErrInvalidSettlement   = errors.New("invalid settlement")
+)
+
+type shareService struct {
+	shareRepo    repository.ShareRepository
+	subRepo      repository.SubscriptionRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewShareService(
+	shareRepo repository.ShareRepository,
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	currencyRepo repository.CurrencyRepository,
+) ShareService {
+	return shareService{
+		shareRepo:    shareRepo,
+		subRepo:      subRepo,
+		userRepo:     userRepo,
+		currencyRepo: currencyRepo,
+	}
+}
+
+func (s shareService) GetShares(userID int) ([]ShareResponse, error) {
+	shares, err := s.shareRepo.GetShares(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []ShareResponse{}
+	for _, sh := range shares {
+		r, err := toShareResponse(sh, today())
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, *r)
+	}
+
+	return res, nil
+}
+
+func (s shareService) GetShare(subscriptionID int, userID int) (*ShareResponse, error) {
+	sh, err := s.shareRepo.GetShare(subscriptionID, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sh == nil {
+		return nil, ErrShareNotFound
+	}
+
+	return toShareResponse(*sh, today())
+}
+
+func (s shareService) SetShare(subscriptionID int, req ShareRequest, actor repository.Actor) (*ShareResponse, error) {
+	sub, err := s.subRepo.GetById(subscriptionID, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	share, err := s.normalizeShareRequest(*sub, req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.shareRepo.SaveShare(share, actor); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSubscriptionNotFound
+		}
+		return nil, err
+	}
+
+	return s.GetShare(subscriptionID, actor.UserID)
+}
+
+func (s shareService) DeleteShare(subscriptionID int, actor repository.Actor) error {
+	err := s.shareRepo.DeleteShare(subscriptionID, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrShareNotFound
+	}
+	return err
+}
+
+func (s shareService) RecordSettlement(subscriptionID int, req SettlementRequest, actor repository.Actor) (*SettlementResponse, error) {
+	note := strings.TrimSpace(req.Note)
+	paidOn := today()
+	if req.PaidOn != "" {
+		t, err := parseDate(req.PaidOn)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidSettlement, err)
+		}
+		paidOn = t
+	}
+
+	switch {
+	case req.MemberID <= 0:
+		return nil, fmt.Errorf("%w: member_id is required", ErrInvalidSettlement)
+	case req.Amount <= 0 || math.IsNaN(req.Amount) || math.IsInf(req.Amount, 0):
+		return nil, fmt.Errorf("%w: amount must be greater than zero", ErrInvalidSettlement)
+	case len(note) > maxSettlementNote:
+		return nil, fmt.Errorf("%w: note must be at most %d characters", ErrInvalidSettlement, maxSettlementNote)
+	}
+
+	settlement := &repository.Settlement{
+		MemberID: req.MemberID,
+		Amount:   roundMoney(req.Amount),
+		PaidOn:   paidOn.Format(dateLayout),
+		Note:     note,
+	}
+
+	created, err := s.shareRepo.AddSettlement(subscriptionID, settlement, actor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrShareMemberNotFound
+		}
+		return nil, err
+	}
+
+	return &SettlementResponse{
+		SettlementID: created.SettlementID,
+		MemberID:     created.MemberID,
+		Amount:       created.Amount,
+		PaidOn:       created.PaidOn,
+		Note:         created.Note,
+	}, nil
+}
+
+func (s shareService) GetBalances(userID int) (*BalancesResponse, error) {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	rates, err := s.currencyRepo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	converter := newCurrencyConverter(user.BaseCurrency, rates)
+
+	shares, err := s.shareRepo.GetShares(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	now := today()
+	balances := map[string]*Balance{}
+	add := func(key string, userID *int, name string, amount float64) {
+		b, ok := balances[key]
+		if !ok {
+			b = &Balance{UserID: userID, Name: name}
+			balances[key] = b
+		}
+		b.Amount += amount
+	}
+
+	unconverted := []UnconvertedAmount{}
+	for _, sh := range shares {
+		res, err := toShareResponse(sh, now)
+		if err != nil {
+			return nil, err
+		}
+
+		skipped := unconvertedSubscription(sh.Subscription)
+		skipped.Amount = 0
+		for _, m := range res.Members {
+			if sh.OwnerID != userID && (m.UserID == nil || *m.UserID != userID) {
+				continue
+			}
+
+			sign := 1.0
+			if sh.OwnerID != userID {
+				sign = -1
+			}
+
+			outstanding, err := converter.convert(m.Outstanding, sh.Currency)
+			if errors.Is(err, ErrUnknownCurrency) {
+				skipped.Amount += sign * m.Outstanding
+				continue
+			}
+			if err != nil {
+				return nil, err
+			}
+
+			if sh.OwnerID == userID {
+				add(balanceKey(m.UserID, m.Name), m.UserID, m.Name, outstanding)
+			} else {
+				ownerID := sh.OwnerID
+				add(balanceKey(&ownerID, sh.OwnerName), &ownerID, sh.OwnerName, -outstanding)
+			}
+		}
+
+		if skipped.Amount != 0 {
+			skipped.Amount = roundMoney(skipped.Amount)
+			unconverted = append(unconverted, skipped)
+		}
+	}
+
+	res := &BalancesResponse{BaseCurrency: converter.base, Balances: []Balance{}, Unconverted: unconverted}
+	for _, b := range balances {
+		b.Amount = roundMoney(b.Amount)
+		if math.Abs(b.Amount) < settledBalanceEpsilon {
+			continue
+		}
+		if b.Amount > 0 {
+			res.OwedToYou += b.Amount
+		} else {
+			res.YouOwe -= b.Amount
+		}
+		res.Balances = append(res.Balances, *b)
+	}
+	res.OwedToYou = roundMoney(res.OwedToYou)
+	res.YouOwe = roundMoney(res.YouOwe)
+
+	sort.Slice(res.Balances, func(i, j int) bool {
+		return strings.ToLower(res.Balances[i].Name) < strings.ToLower(res.Balances[j].Name)
+	})
+
+	return res, nil
+}
+
+// balanceKey identifies a person across shares: registered users by id,
+// everyone else by name.
+func balanceKey(userID *int, name string) string {
+	if userID != nil {
+		return "user:" + strconv.Itoa(*userID)
+	}
+	return "name:" + strings.ToLower(name)
+}
+
+func (s shareService) normalizeShareRequest(sub repository.Subscription, req ShareRequest) (*repository.Share, error) {
+	method := strings.ToLower(strings.TrimSpace(req.Method))
+	if method == "" {
+		method = SplitEqual
+	}
+	if method != SplitEqual && method != SplitPercentage && method != SplitFixed {
+		return nil, fmt.Errorf("%w: method must be equal, percentage or fixed", ErrInvalidShare)
+	}
+
+	startedOn := today()
+	if req.StartedOn != "" {
+		t, err := parseDate(req.StartedOn)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidShare, err)
+		}
+		startedOn = t
+	}
+
+	if len(req.Members) == 0 {
+		return nil, fmt.Errorf("%w: at least one member is required", ErrInvalidShare)
+	}
+	if len(req.Members) > maxShareMembers {
+		return nil, fmt.Errorf("%w: at most %d members", ErrInvalidShare, maxShareMembers)
+	}
+
+	share := &repository.Share{
+		Subscription: sub,
+		Method:       method,
+		StartedOn:    startedOn.Format(dateLayout),
+	}
+
+	seen := map[string]bool{}
+	var problems []string
+	var total float64
+
+	for i, m := range req.Members {
+		email := strings.TrimSpace(m.Email)
+		name := strings.TrimSpace(m.Name)
+		member := repository.ShareMember{Name: name}
+
+		switch {
+		case email != "" && name != "":
+			problems = append(problems, fmt.Sprintf("member %d: give either email or name, not both", i+1))
+			continue
+		case email != "":
+			user, err := s.userRepo.GetByEmail(email)
+			if err != nil {
+				return nil, err
+			}
+			if user == nil {
+				problems = append(problems, fmt.Sprintf("member %d: no registered user with email %s", i+1, email))
+				continue
+			}
+			if user.ID == sub.CreatedBy {
+				problems = append(problems, fmt.Sprintf("member %d: the owner is not a member", i+1))
+				continue
+			}
+			id := user.ID
+			member.UserID = &id
+			member.Name = user.Name
+		case name == "":
+			problems = append(problems, fmt.Sprintf("member %d: email or name is required", i+1))
+			continue
+		case len(name) > maxShareMemberName:
+			problems = append(problems, fmt.Sprintf("member %d: name must be at most %d characters", i+1, maxShareMemberName))
+			continue
+		}
+
+		key := balanceKey(member.UserID, member.Name)
+		if seen[key] {
+			problems = append(problems, fmt.Sprintf("member %d: %s is listed twice", i+1, member.Name))
+			continue
+		}
+		seen[key] = true
+
+		if method != SplitEqual {
+			if m.Value == nil || *m.Value <= 0 || math.IsNaN(*m.Value) || math.IsInf(*m.Value, 0) {
+				problems = append(problems, fmt.Sprintf("member %d: value must be greater than zero", i+1))
+				continue
+			}
+			value := roundMoney(*m.Value)
+			member.Value = &value
+			total += value
+		}
+
+		share.Members = append(share.Members, member)
+	}
+
+	switch {
+	case method == SplitPercentage && total > fullSharePercentage:
+		problems = append(problems, fmt.Sprintf("percentages add up to %.2f, more than 100", total))
+	case method == SplitFixed && roundMoney(total) > roundMoney(float64(sub.Amount)):
+		problems = append(problems, fmt.Sprintf("fixed amounts add up to %.2f, more than the subscription's %.2f", total, sub.Amount))
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidShare, strings.Join(problems, "; "))
+	}
+
+	return share, nil
+}
+
+// toShareResponse splits one billing period between the members and the
+// owner, then works out what each member owes for the periods billed
+// between the share's start and asOf.
+func toShareResponse(sh repository.Share, asOf time.Time) (*ShareResponse, error) {
+	periods, err := billedPeriods(sh.Subscription, sh.StartedOn, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	amount := float64(sh.Amount)
+	shares := splitAmount(sh.Method, amount, sh.Members)
+
+	res := &ShareResponse{
+		SubscriptionID:   sh.SubscriptionID,
+		SubscriptionName: sh.Name,
+		OwnerID:          sh.OwnerID,
+		OwnerName:        sh.OwnerName,
+		Amount:           sh.Amount,
+		Currency:         sh.Currency,
+		BillingCycle:     sh.BillingCycle,
+		Method:           sh.Method,
+		StartedOn:        formatDate(sh.StartedOn),
+		OwnerShare:       amount,
+		Members:          []ShareMemberResponse{},
+	}
+
+	for i, m := range sh.Members {
+		owed := roundMoney(shares[i] * float64(periods))
+		res.OwnerShare -= shares[i]
+		res.Members = append(res.Members, ShareMemberResponse{
+			MemberID:    m.MemberID,
+			UserID:      m.UserID,
+			Name:        m.Name,
+			Value:       m.Value,
+			Share:       shares[i],
+			Periods:     periods,
+			Owed:        owed,
+			Settled:     roundMoney(m.Settled),
+			Outstanding: roundMoney(owed - m.Settled),
+		})
+	}
+	res.OwnerShare = roundMoney(res.OwnerShare)
+
+	return res, nil
+}
+
+// splitAmount returns each member's part of one billing period. With an
+// equal split the owner counts as one of the people sharing the cost and
+// absorbs the rounding.
+func splitAmount(method string, amount float64, members []repository.ShareMember) []float64 {
+	shares := make([]float64, len(members))
+	for i, m := range members {
+		switch {
+		case method == SplitEqual:
+			shares[i] = roundMoney(amount / float64(len(members)+1))
+		case m.Value == nil:
+			shares[i] = 0
+		case method == SplitPercentage:
+			shares[i] = roundMoney(amount * *m.Value / fullSharePercentage)
+		case method == SplitFixed:
+			shares[i] = roundMoney(*m.Value)
+		}
+	}
+	return shares
+}
+
+// billedPeriods counts the renewals of sub from startedOn up to asOf.
+// billing_date is only an anchor here: renewals before it are found by
+// stepping back one cycle at a time. A subscription that is no longer
+// active stops accruing at its billing date.
+func billedPeriods(sub repository.Subscription, startedOn string, asOf time.Time) (int, error) {
+	from, err := parseDate(startedOn)
+	if err != nil {
+		return 0, err
+	}
+	anchor, err := parseDate(sub.BillingDate)
+	if err != nil {
+		return 0, err
+	}
+
+	to := asOf
+	if !isActive(sub.Status) && !anchor.After(to) {
+		to = anchor.AddDate(0, 0, -1)
+	}
+	if to.Before(from) {
+		return 0, nil
+	}
+
+	cycle, ok := parseBillingCycle(sub.BillingCycle)
+	if !ok {
+		if anchor.Before(from) || anchor.After(to) {
+			return 0, nil
+		}
+		return 1, nil
+	}
+
+	count := 0
+	for n := 0; ; n-- {
+		d := cycle.occurrence(anchor, n)
+		if d.Before(from) {
+			break
+		}
+		if !d.After(to) {
+			count++
+		}
+	}
+	for n := 1; ; n++ {
+		d := cycle.occurrence(anchor, n)
+		if d.After(to) {
+			break
+		}
+		if !d.Before(from) {
+			count++
+		}
+	}
+
+	return count, nil
+}
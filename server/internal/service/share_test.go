@@ -0,0 +1,183 @@
+package service_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSetShare(t *testing.T) {
+	sub := &repository.Subscription{
+		SubscriptionID: 1,
+		Name:           "Spotify Family",
+		Amount:         300,
+		Currency:       "THB",
+		BillingCycle:   "monthly",
+		BillingDate:    "2025-02-01",
+		Status:         "active",
+		CreatedBy:      10,
+	}
+
+	t.Run("Set Share Equal Split", func(t *testing.T) {
+		// arrange
+		shareRepo := repository.NewShareRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		bob := 11
+
+		subscriptionRepo.On("GetById", 1, 10).Return(sub, nil)
+		userRepo.
+			On("GetByEmail", "bob@example.com").
+			Return(&repository.User{ID: 11, Name: "Bob"}, nil)
+		shareRepo.
+			On("SaveShare", mock.MatchedBy(func(sh *repository.Share) bool {
+				return sh.Method == service.SplitEqual &&
+					sh.StartedOn == "2025-01-01" &&
+					len(sh.Members) == 2 &&
+					*sh.Members[0].UserID == 11 && sh.Members[0].Name == "Bob" &&
+					sh.Members[1].UserID == nil && sh.Members[1].Name == "Mom"
+			}), actor).
+			Return(nil)
+		shareRepo.
+			On("GetShare", 1, 10).
+			Return(&repository.Share{
+				Subscription: *sub,
+				OwnerID:      10,
+				OwnerName:    "Alice",
+				Method:       service.SplitEqual,
+				StartedOn:    "2025-01-01",
+				Members: []repository.ShareMember{
+					{MemberID: 1, UserID: &bob, Name: "Bob"},
+					{MemberID: 2, Name: "Mom"},
+				},
+			}, nil)
+
+		shareService := service.NewShareService(shareRepo, subscriptionRepo, userRepo, nil)
+
+		// act
+		res, err := shareService.SetShare(1, service.ShareRequest{
+			StartedOn: "2025-01-01",
+			Members: []service.ShareMemberRequest{
+				{Email: "bob@example.com"},
+				{Name: "Mom"},
+			},
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 100.0, res.OwnerShare)
+		assert.Equal(t, 100.0, res.Members[0].Share)
+		assert.Equal(t, 100.0, res.Members[1].Share)
+		shareRepo.AssertExpectations(t)
+	})
+
+	t.Run("Set Share Invalid", func(t *testing.T) {
+		// arrange
+		shareRepo := repository.NewShareRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		sixty, fifty, twoHundred := 60.0, 50.0, 200.0
+
+		subscriptionRepo.On("GetById", 1, 10).Return(sub, nil)
+		userRepo.On("GetByEmail", "nobody@example.com").Return((*repository.User)(nil), nil)
+		userRepo.On("GetByEmail", "alice@example.com").Return(&repository.User{ID: 10, Name: "Alice"}, nil)
+
+		shareService := service.NewShareService(shareRepo, subscriptionRepo, userRepo, nil)
+
+		// act & assert
+		for _, req := range []service.ShareRequest{
+			{},
+			{Method: "random", Members: []service.ShareMemberRequest{{Name: "Mom"}}},
+			{Members: []service.ShareMemberRequest{{Email: "nobody@example.com"}}},
+			{Members: []service.ShareMemberRequest{{Email: "alice@example.com"}}},
+			{Members: []service.ShareMemberRequest{{Name: "Mom"}, {Name: "mom"}}},
+			{Method: "percentage", Members: []service.ShareMemberRequest{{Name: "Mom"}}},
+			{Method: "percentage", Members: []service.ShareMemberRequest{
+				{Name: "Mom", Value: &sixty},
+				{Name: "Dad", Value: &fifty},
+			}},
+			{Method: "fixed", Members: []service.ShareMemberRequest{
+				{Name: "Mom", Value: &twoHundred},
+				{Name: "Dad", Value: &twoHundred},
+			}},
+		} {
+			_, err := shareService.SetShare(1, req, actor)
+			assert.ErrorIs(t, err, service.ErrInvalidShare)
+		}
+		shareRepo.AssertNotCalled(t, "SaveShare", mock.Anything, mock.Anything)
+	})
+}
+
+func TestGetBalances(t *testing.T) {
+	t.Run("Get Balances Success", func(t *testing.T) {
+		// arrange
+		shareRepo := repository.NewShareRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		// two monthly renewals fall between the start and today
+		now := time.Now().UTC()
+		billingDate := now.AddDate(0, 0, 10).Format("2006-01-02")
+		startedOn := now.AddDate(0, 0, -65).Format("2006-01-02")
+		bob := 11
+		alice := 10
+		four := 4.0
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+		shareRepo.
+			On("GetShares", 10).
+			Return([]repository.Share{
+				{
+					Subscription: repository.Subscription{
+						SubscriptionID: 1, Name: "Spotify Family", Amount: 300, Currency: "THB",
+						BillingCycle: "monthly", BillingDate: billingDate, Status: "active",
+					},
+					OwnerID:   10,
+					OwnerName: "Alice",
+					Method:    service.SplitEqual,
+					StartedOn: startedOn,
+					Members: []repository.ShareMember{
+						{MemberID: 1, UserID: &bob, Name: "Bob", Settled: 50},
+						{MemberID: 2, Name: "Mom"},
+					},
+				},
+				{
+					Subscription: repository.Subscription{
+						SubscriptionID: 2, Name: "YouTube Premium", Amount: 10, Currency: "USD",
+						BillingCycle: "monthly", BillingDate: billingDate, Status: "active",
+					},
+					OwnerID:   11,
+					OwnerName: "Bob",
+					Method:    service.SplitFixed,
+					StartedOn: startedOn,
+					Members: []repository.ShareMember{
+						{MemberID: 3, UserID: &alice, Name: "Alice", Value: &four},
+					},
+				},
+			}, nil)
+
+		shareService := service.NewShareService(shareRepo, nil, userRepo, currencyRepo)
+
+		// act
+		res, err := shareService.GetBalances(10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "THB", res.BaseCurrency)
+		assert.Equal(t, []service.Balance{
+			{UserID: &bob, Name: "Bob", Amount: -130},
+			{Name: "Mom", Amount: 200},
+		}, res.Balances)
+		assert.Equal(t, 200.0, res.OwedToYou)
+		assert.Equal(t, 130.0, res.YouOwe)
+	})
+}
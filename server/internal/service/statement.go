@@ -0,0 +1,19 @@
+package service
+
+type StatementImportRequest struct {
+	// Format is csv, ofx, qfx or camt053; empty means detect.
+	Format string
+	Data   []byte
+	// Mapping maps date, description, amount, debit, credit and currency
+	// to CSV column headers.
+	Mapping map[string]string
+}
+
+type StatementImportResponse struct {
+	Transactions int                  `json:"transactions"`
+	Suggestions  []SuggestionResponse `json:"suggestions"`
+}
+
+type StatementService interface {
+	ImportStatement(req StatementImportRequest, userID int) (*StatementImportResponse, error)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/statement"
+)
+
+const suggestionSourceStatement = "statement"
+
+var ErrInvalidStatement = statement.ErrInvalidStatement
+
+type statementService struct {
+	subRepo        repository.SubscriptionRepository
+	suggestionRepo repository.SuggestionRepository
+}
+
+func NewStatementService(
+	subRepo repository.SubscriptionRepository,
+	suggestionRepo repository.SuggestionRepository,
+) StatementService {
+	return statementService{subRepo: subRepo, suggestionRepo: suggestionRepo}
+}
+
+// ImportStatement parses a statement, detects recurring charges and stores
+// each one as a pending suggestion. Charges from a merchant the user
+// already tracks are linked to that subscription instead of proposing a
+// new one. The statement itself is not kept.
+func (s statementService) ImportStatement(req StatementImportRequest, userID int) (*StatementImportResponse, error) {
+	txns, err := statement.Parse(req.Format, req.Data, req.Mapping)
+	if err != nil {
+		return nil, err
+	}
+	if len(txns) == 0 {
+		return nil, fmt.Errorf("%w: no transactions found", ErrInvalidStatement)
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &StatementImportResponse{
+		Transactions: len(txns),
+		Suggestions:  []SuggestionResponse{},
+	}
+
+	for _, r := range statement.Detect(txns) {
+		evidence := make([]SuggestionEvidence, 0, len(r.Transactions))
+		for _, t := range r.Transactions {
+			evidence = append(evidence, SuggestionEvidence{
+				Date:        t.Date.Format(dateLayout),
+				Description: t.Description,
+				Amount:      t.Amount,
+				Currency:    t.Currency,
+			})
+		}
+		evidenceJSON, err := json.Marshal(evidence)
+		if err != nil {
+			return nil, err
+		}
+
+		suggestion := &repository.Suggestion{
+			Source:          suggestionSourceStatement,
+			MerchantKey:     r.MerchantKey,
+			Name:            r.Name,
+			Amount:          float32(r.Amount),
+			Currency:        normalizeCurrency(r.Currency),
+			BillingCycle:    r.Cadence,
+			BillingDate:     r.NextDate.Format(dateLayout),
+			Confidence:      r.Confidence,
+			AmountVariation: r.AmountVariation,
+			Evidence:        string(evidenceJSON),
+		}
+		suggestion.MatchedSubscriptionID = matchSubscription(subs, r.MerchantKey)
+
+		saved, err := s.suggestionRepo.Save(suggestion, userID)
+		if err != nil {
+			return nil, err
+		}
+		if saved == nil {
+			// the user rejected this merchant before
+			continue
+		}
+
+		res.Suggestions = append(res.Suggestions, toSuggestionResponse(*saved))
+	}
+
+	return res, nil
+}
+
+func matchSubscription(subs []repository.Subscription, merchantKey string) *int {
+	for _, sub := range subs {
+		if statement.SameMerchant(statement.NormalizeMerchant(sub.Name), merchantKey) {
+			id := sub.SubscriptionID
+			return &id
+		}
+	}
+	return nil
+}
@@ -1,31 +1,93 @@
 package service
 
+import "github.com/NetlutZ/subscout/internal/repository"
+
 type SubscriptionResponse struct {
-	SubscriptionID int     `json:"id"`
-	Name           string  `json:"name"`
-	Category       string  `json:"category"`
-	Amount         float32 `json:"amount"`
-	Currency       string  `json:"currency"`
-	BillingCycle   string  `json:"billing_cycle"`
-	BillingDate    string  `json:"billing_date"`
-	Status         string  `json:"status"`
-	Trial          bool    `json:"is_trial"`
+	SubscriptionID   int            `json:"id"`
+	Name             string         `json:"name"`
+	Category         string         `json:"category"`
+	CategoryID       *int           `json:"category_id"`
+	Amount           float32        `json:"amount"`
+	Currency         string         `json:"currency"`
+	BillingCycle     string         `json:"billing_cycle"`
+	BillingDate      string         `json:"billing_date"`
+	Status           string         `json:"status"`
+	Trial            bool           `json:"is_trial"`
+	Notes            string         `json:"notes"`
+	Tags             []string       `json:"tags"`
+	CustomFields     map[string]any `json:"custom_fields"`
+	WorkspaceID      *int           `json:"workspace_id"`
+	PaymentMethodID  *int           `json:"payment_method_id"`
+	AutoRenew        bool           `json:"auto_renew"`
+	NoticePeriodDays int            `json:"notice_period_days"`
+	// LastDayToCancel is the last day the subscription can be cancelled
+	// before it renews for another term; nil unless it is active and renews
+	// automatically.
+	LastDayToCancel *string `json:"last_day_to_cancel"`
 }
 
+// CreateSubscriptionRequest adds a subscription to WorkspaceID, or to the
+// caller's personal workspace when it is omitted. AutoRenew defaults to true.
 type CreateSubscriptionRequest struct {
-	Name         string  `json:"name"`
-	Category     string  `json:"category"`
-	Amount       float32 `json:"amount"`
-	Currency     string  `json:"currency"`
-	BillingCycle string  `json:"billing_cycle"`
-	BillingDate  string  `json:"billing_date"`
-	Status       string  `json:"status"`
-	Trial        bool    `json:"is_trial"`
+	Name             string         `json:"name"`
+	Category         string         `json:"category"`
+	Amount           float32        `json:"amount"`
+	Currency         string         `json:"currency"`
+	BillingCycle     string         `json:"billing_cycle"`
+	BillingDate      string         `json:"billing_date"`
+	Status           string         `json:"status"`
+	Trial            bool           `json:"is_trial"`
+	Notes            string         `json:"notes"`
+	Tags             []string       `json:"tags"`
+	CustomFields     map[string]any `json:"custom_fields"`
+	WorkspaceID      *int           `json:"workspace_id"`
+	AutoRenew        *bool          `json:"auto_renew"`
+	NoticePeriodDays int            `json:"notice_period_days"`
+}
+
+// UpdateSubscriptionRequest replaces every editable field of a
+// subscription. Custom fields keep their values; they are set through
+// SetCustomFields. A nil AutoRenew or NoticePeriodDays keeps the stored
+// value.
+type UpdateSubscriptionRequest struct {
+	Name             string   `json:"name"`
+	Category         string   `json:"category"`
+	Amount           float32  `json:"amount"`
+	Currency         string   `json:"currency"`
+	BillingCycle     string   `json:"billing_cycle"`
+	BillingDate      string   `json:"billing_date"`
+	Status           string   `json:"status"`
+	Trial            bool     `json:"is_trial"`
+	Notes            string   `json:"notes"`
+	Tags             []string `json:"tags"`
+	AutoRenew        *bool    `json:"auto_renew"`
+	NoticePeriodDays *int     `json:"notice_period_days"`
+}
+
+// SubscriptionFilter narrows GetSubscriptions to subscriptions carrying
+// every one of Tags and whose custom fields equal CustomFields. Without a
+// WorkspaceID every workspace the user belongs to is listed.
+type SubscriptionFilter struct {
+	WorkspaceID  *int
+	Tags         []string
+	CustomFields map[string]string
+}
+
+type SubscriptionSearchResponse struct {
+	SubscriptionResponse
+	Rank       float64           `json:"rank"`
+	Highlights map[string]string `json:"highlights"`
 }
 
 type SubscriptionService interface {
-	GetSubscriptions(userID int) ([]SubscriptionResponse, error)
+	GetSubscriptions(userID int, filter SubscriptionFilter) ([]SubscriptionResponse, error)
 	GetSubscription(id int, userID int) (*SubscriptionResponse, error)
-	CreateSubscription(req CreateSubscriptionRequest, userID int) (*SubscriptionResponse, error)
-	DeleteSubscription(id int, userID int) error
+	CreateSubscription(req CreateSubscriptionRequest, actor repository.Actor) (*SubscriptionResponse, error)
+	UpdateSubscription(id int, req UpdateSubscriptionRequest, actor repository.Actor) (*SubscriptionResponse, error)
+	// DeleteSubscription moves a subscription to the trash, where
+	// TrashService can restore it until it is purged.
+	DeleteSubscription(id int, actor repository.Actor) error
+	SearchSubscriptions(query string, userID int) ([]SubscriptionSearchResponse, error)
+	// SetCustomFields replaces every custom field value of a subscription.
+	SetCustomFields(id int, values map[string]any, actor repository.Actor) (*SubscriptionResponse, error)
 }
@@ -1,6 +1,9 @@
 package service
 
-import "github.com/stretchr/testify/mock"
+import (
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/stretchr/testify/mock"
+)
 
 type SubscriptionServiceMock struct {
 	mock.Mock
@@ -10,8 +13,8 @@ func NewSubscriptionServiceMock() *SubscriptionServiceMock {
 	return &SubscriptionServiceMock{}
 }
 
-func (m *SubscriptionServiceMock) GetSubscriptions(userID int) ([]SubscriptionResponse, error) {
-	args := m.Called(userID)
+func (m *SubscriptionServiceMock) GetSubscriptions(userID int, filter SubscriptionFilter) ([]SubscriptionResponse, error) {
+	args := m.Called(userID, filter)
 	return args.Get(0).([]SubscriptionResponse), args.Error(1)
 }
 
@@ -20,12 +23,27 @@ func (m *SubscriptionServiceMock) GetSubscription(id int, userID int) (*Subscrip
 	return args.Get(0).(*SubscriptionResponse), args.Error(1)
 }
 
-func (m *SubscriptionServiceMock) CreateSubscription(req CreateSubscriptionRequest, userID int) (*SubscriptionResponse, error) {
-	args := m.Called(req, userID)
+func (m *SubscriptionServiceMock) CreateSubscription(req CreateSubscriptionRequest, actor repository.Actor) (*SubscriptionResponse, error) {
+	args := m.Called(req, actor)
 	return args.Get(0).(*SubscriptionResponse), args.Error(1)
 }
 
-func (m *SubscriptionServiceMock) DeleteSubscription(id int, userID int) error {
-	args := m.Called(id, userID)
+func (m *SubscriptionServiceMock) DeleteSubscription(id int, actor repository.Actor) error {
+	args := m.Called(id, actor)
 	return args.Error(0)
 }
+
+func (m *SubscriptionServiceMock) SearchSubscriptions(query string, userID int) ([]SubscriptionSearchResponse, error) {
+	args := m.Called(query, userID)
+	return args.Get(0).([]SubscriptionSearchResponse), args.Error(1)
+}
+
+func (m *SubscriptionServiceMock) UpdateSubscription(id int, req UpdateSubscriptionRequest, actor repository.Actor) (*SubscriptionResponse, error) {
+	args := m.Called(id, req, actor)
+	return args.Get(0).(*SubscriptionResponse), args.Error(1)
+}
+
+func (m *SubscriptionServiceMock) SetCustomFields(id int, values map[string]any, actor repository.Actor) (*SubscriptionResponse, error) {
+	args := m.Called(id, values, actor)
+	return args.Get(0).(*SubscriptionResponse), args.Error(1)
+}
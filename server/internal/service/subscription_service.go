@@ -1,33 +1,93 @@
 package service
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
 	"github.com/NetlutZ/subscout/internal/repository"
 )
 
+const (
+	searchResultLimit = 20
+	maxNotesLength    = 10000
+	maxNoticePeriod   = 365
+)
+
+var (
+	ErrEmptySearchQuery     = errors.New("search query is required")
+	ErrSubscriptionNotFound = errors.New("subscription not found")
+)
+
 type subscriptionService struct {
-	subRepo repository.SubscriptionRepository
+	subRepo   repository.SubscriptionRepository
+	fieldRepo repository.CustomFieldRepository
 }
 
-func NewSubscriptionService(subRepo repository.SubscriptionRepository) SubscriptionService {
-	return subscriptionService{subRepo: subRepo}
+// NewSubscriptionService leaves subscription.* events to subRepo, which
+// stores them in the outbox along with each change.
+func NewSubscriptionService(
+	subRepo repository.SubscriptionRepository,
+	fieldRepo repository.CustomFieldRepository,
+) SubscriptionService {
+	return subscriptionService{subRepo: subRepo, fieldRepo: fieldRepo}
 }
 
 func toResponse(sub repository.Subscription) SubscriptionResponse {
-	return SubscriptionResponse{
-		SubscriptionID: sub.SubscriptionID,
-		Name:           sub.Name,
-		Category:       sub.Category,
-		Amount:         sub.Amount,
-		Currency:       sub.Currency,
-		BillingCycle:   sub.BillingCycle,
-		BillingDate:    sub.BillingDate,
-		Status:         sub.Status,
-		Trial:          sub.Trial,
+	res := SubscriptionResponse{
+		SubscriptionID:   sub.SubscriptionID,
+		Name:             sub.Name,
+		Category:         sub.Category,
+		CategoryID:       sub.CategoryID,
+		Amount:           sub.Amount,
+		Currency:         sub.Currency,
+		BillingCycle:     sub.BillingCycle,
+		BillingDate:      sub.BillingDate,
+		Status:           sub.Status,
+		Trial:            sub.Trial,
+		Notes:            sub.Notes,
+		Tags:             sub.Tags,
+		WorkspaceID:      sub.WorkspaceID,
+		PaymentMethodID:  sub.PaymentMethodID,
+		AutoRenew:        sub.AutoRenew,
+		NoticePeriodDays: sub.NoticePeriodDays,
 	}
+	if sub.CustomFields != "" {
+		res.CustomFields = decodeCustomFields(sub.CustomFields)
+	}
+	if deadline, _, ok, err := cancellationDeadline(sub, today()); ok && err == nil {
+		lastDay := deadline.Format(dateLayout)
+		res.LastDayToCancel = &lastDay
+	}
+	return res
 }
 
-func (s subscriptionService) GetSubscriptions(userID int) ([]SubscriptionResponse, error) {
-	subs, err := s.subRepo.GetAll(userID)
+func (s subscriptionService) GetSubscriptions(userID int, filter SubscriptionFilter) ([]SubscriptionResponse, error) {
+	tags, _ := normalizeTags(filter.Tags)
+
+	if len(filter.CustomFields) > 0 {
+		fields, err := s.fieldRepo.GetShared(userID)
+		if err != nil {
+			return nil, err
+		}
+		known := map[string]bool{}
+		for _, f := range fields {
+			known[f.Key] = true
+		}
+		for key := range filter.CustomFields {
+			if !known[key] {
+				return nil, fmt.Errorf("%w %q", ErrUnknownCustomField, key)
+			}
+		}
+	}
+
+	subs, err := s.subRepo.List(userID, repository.SubscriptionFilter{
+		WorkspaceID:  filter.WorkspaceID,
+		Tags:         tags,
+		CustomFields: filter.CustomFields,
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -52,10 +112,70 @@ func (s subscriptionService) GetSubscription(id int, userID int) (*SubscriptionR
 
 func (s subscriptionService) CreateSubscription(
 	req CreateSubscriptionRequest,
-	userID int,
+	actor repository.Actor,
 ) (*SubscriptionResponse, error) {
+	userID := actor.UserID
+
+	tags, problems := normalizeTags(req.Tags)
+	if utf8.RuneCountInString(req.Notes) > maxNotesLength {
+		problems = append(problems, fmt.Sprintf("notes are longer than %d characters", maxNotesLength))
+	}
+	if req.NoticePeriodDays < 0 || req.NoticePeriodDays > maxNoticePeriod {
+		problems = append(problems, fmt.Sprintf("notice period must be between 0 and %d days", maxNoticePeriod))
+	}
+	autoRenew := req.AutoRenew == nil || *req.AutoRenew
+
+	var customFields string
+	if len(req.CustomFields) > 0 {
+		fields, err := s.fieldRepo.GetAll(userID)
+		if err != nil {
+			return nil, err
+		}
+		var fieldProblems []string
+		customFields, fieldProblems = validateCustomFields(fields, req.CustomFields)
+		problems = append(problems, fieldProblems...)
+	}
+
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, "; "))
+	}
 
 	sub := &repository.Subscription{
+		Name:             req.Name,
+		Category:         req.Category,
+		Amount:           req.Amount,
+		Currency:         req.Currency,
+		BillingCycle:     req.BillingCycle,
+		BillingDate:      req.BillingDate,
+		Status:           req.Status,
+		Trial:            req.Trial,
+		Notes:            strings.TrimSpace(req.Notes),
+		Tags:             tags,
+		CustomFields:     customFields,
+		WorkspaceID:      req.WorkspaceID,
+		AutoRenew:        autoRenew,
+		NoticePeriodDays: req.NoticePeriodDays,
+	}
+
+	created, err := s.subRepo.Create(sub, actor)
+	if err != nil {
+		if errors.Is(err, repository.ErrWorkspaceAccessDenied) {
+			return nil, ErrWorkspaceForbidden
+		}
+		return nil, err
+	}
+
+	res := toResponse(*created)
+	return &res, nil
+}
+
+// UpdateSubscription validates the request like a row of an import.
+func (s subscriptionService) UpdateSubscription(
+	id int,
+	req UpdateSubscriptionRequest,
+	actor repository.Actor,
+) (*SubscriptionResponse, error) {
+	normalized, problems := normalizeSubscriptionRequest(CreateSubscriptionRequest{
 		Name:         req.Name,
 		Category:     req.Category,
 		Amount:       req.Amount,
@@ -64,21 +184,144 @@ func (s subscriptionService) CreateSubscription(
 		BillingDate:  req.BillingDate,
 		Status:       req.Status,
 		Trial:        req.Trial,
+		Notes:        req.Notes,
+		Tags:         req.Tags,
+	})
+	if req.NoticePeriodDays != nil && (*req.NoticePeriodDays < 0 || *req.NoticePeriodDays > maxNoticePeriod) {
+		problems = append(problems, fmt.Sprintf("notice period must be between 0 and %d days", maxNoticePeriod))
+	}
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, "; "))
 	}
 
-	created, err := s.subRepo.Create(sub, userID)
+	current, err := s.subRepo.GetById(id, actor.UserID)
 	if err != nil {
 		return nil, err
 	}
+	if current == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+	autoRenew := current.AutoRenew
+	if req.AutoRenew != nil {
+		autoRenew = *req.AutoRenew
+	}
+	noticePeriod := current.NoticePeriodDays
+	if req.NoticePeriodDays != nil {
+		noticePeriod = *req.NoticePeriodDays
+	}
 
-	res := toResponse(*created)
+	updated, err := s.subRepo.Update(&repository.Subscription{
+		SubscriptionID:   id,
+		Name:             normalized.Name,
+		Category:         normalized.Category,
+		Amount:           normalized.Amount,
+		Currency:         normalized.Currency,
+		BillingCycle:     normalized.BillingCycle,
+		BillingDate:      normalized.BillingDate,
+		Status:           normalized.Status,
+		Trial:            normalized.Trial,
+		Notes:            normalized.Notes,
+		Tags:             normalized.Tags,
+		AutoRenew:        autoRenew,
+		NoticePeriodDays: noticePeriod,
+	}, actor)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.notChanged(id, actor.UserID)
+		}
+		return nil, err
+	}
+
+	res := toResponse(*updated)
 	return &res, nil
 }
 
-func (s subscriptionService) DeleteSubscription(id int, userID int) error {
-	err := s.subRepo.Delete(id, userID)
+// SetCustomFields validates values against the definitions of the
+// subscription's creator, like categories and tags, so workspace members
+// fill in the same fields.
+func (s subscriptionService) SetCustomFields(id int, values map[string]any, actor repository.Actor) (*SubscriptionResponse, error) {
+	userID := actor.UserID
+	current, err := s.subRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if current == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	fields, err := s.fieldRepo.GetAll(current.CreatedBy)
+	if err != nil {
+		return nil, err
+	}
+
+	customFields, problems := validateCustomFields(fields, values)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, "; "))
+	}
+
+	if err := s.subRepo.SetCustomFields(id, actor, customFields); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, s.notChanged(id, userID)
+		}
+		return nil, err
+	}
+
+	sub, err := s.subRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if sub == nil {
+		return nil, ErrSubscriptionNotFound
+	}
+
+	res := toResponse(*sub)
+	return &res, nil
+}
+
+func (s subscriptionService) DeleteSubscription(id int, actor repository.Actor) error {
+	err := s.subRepo.Delete(id, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return s.notChanged(id, actor.UserID)
+	}
+	return err
+}
+
+// notChanged explains why a write matched no subscription: either the user
+// cannot see it at all, or their workspace role only lets them view it.
+func (s subscriptionService) notChanged(id int, userID int) error {
+	sub, err := s.subRepo.GetById(id, userID)
 	if err != nil {
 		return err
 	}
-	return nil
+	if sub == nil {
+		return ErrSubscriptionNotFound
+	}
+	return ErrWorkspaceForbidden
+}
+
+func (s subscriptionService) SearchSubscriptions(query string, userID int) ([]SubscriptionSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, ErrEmptySearchQuery
+	}
+
+	results, err := s.subRepo.Search(query, userID, searchResultLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SubscriptionSearchResponse{}
+	for _, r := range results {
+		res = append(res, SubscriptionSearchResponse{
+			SubscriptionResponse: toResponse(r.Subscription),
+			Rank:                 r.Rank,
+			Highlights: map[string]string{
+				"name":     r.NameHighlight,
+				"category": r.CategoryHighlight,
+				"notes":    r.NotesHighlight,
+			},
+		})
+	}
+
+	return res, nil
 }
@@ -1,7 +1,9 @@
 package service_test
 
 import (
+	"database/sql"
 	"errors"
+	"strings"
 	"testing"
 
 	"github.com/NetlutZ/subscout/internal/repository"
@@ -10,13 +12,16 @@ import (
 	"github.com/stretchr/testify/mock"
 )
 
+// actor is user 10 making changes from a browser.
+var actor = repository.Actor{UserID: 10, IP: "203.0.113.7", UserAgent: "Mozilla/5.0"}
+
 func TestGetSubscriptions(t *testing.T) {
 
 	t.Run("Get Subscriptions Success", func(t *testing.T) {
 		// arrange
 		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
 		subscriptionRepo.
-			On("GetAll", 1).
+			On("List", 1, repository.SubscriptionFilter{}).
 			Return([]repository.Subscription{
 				{
 					SubscriptionID: 1,
@@ -31,10 +36,10 @@ func TestGetSubscriptions(t *testing.T) {
 				},
 			}, nil)
 
-		subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+		subscriptionService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		subs, err := subscriptionService.GetSubscriptions(1)
+		subs, err := subscriptionService.GetSubscriptions(1, service.SubscriptionFilter{})
 
 		// assert
 		assert.NoError(t, err)
@@ -63,13 +68,13 @@ func TestGetSubscriptions(t *testing.T) {
 		expectedErr := errors.New("database error")
 
 		subscriptionRepo.
-			On("GetAll", 1).
+			On("List", 1, repository.SubscriptionFilter{}).
 			Return([]repository.Subscription(nil), expectedErr)
 
-		subscriptionService := service.NewSubscriptionService(subscriptionRepo)
+		subscriptionService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		subs, err := subscriptionService.GetSubscriptions(1)
+		subs, err := subscriptionService.GetSubscriptions(1, service.SubscriptionFilter{})
 
 		// assert
 		assert.Nil(t, subs)
@@ -97,7 +102,7 @@ func TestGetSubscription(t *testing.T) {
 				Trial:          false,
 			}, nil)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
 		res, err := subService.GetSubscription(1, 10)
@@ -131,7 +136,7 @@ func TestGetSubscription(t *testing.T) {
 			On("GetById", 1, 10).
 			Return((*repository.Subscription)(nil), expectedErr)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
 		res, err := subService.GetSubscription(1, 10)
@@ -161,7 +166,7 @@ func TestCreateSubscription(t *testing.T) {
 		}
 
 		subscriptionRepo.
-			On("Create", mock.AnythingOfType("*repository.Subscription"), 10).
+			On("Create", mock.AnythingOfType("*repository.Subscription"), actor).
 			Return(&repository.Subscription{
 				SubscriptionID: 1,
 				Name:           "Netflix",
@@ -174,10 +179,10 @@ func TestCreateSubscription(t *testing.T) {
 				Trial:          false,
 			}, nil)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		res, err := subService.CreateSubscription(req, 10)
+		res, err := subService.CreateSubscription(req, actor)
 
 		// assert
 		assert.NoError(t, err)
@@ -200,13 +205,13 @@ func TestCreateSubscription(t *testing.T) {
 		}
 
 		subscriptionRepo.
-			On("Create", mock.Anything, 10).
+			On("Create", mock.Anything, actor).
 			Return((*repository.Subscription)(nil), expectedErr)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		res, err := subService.CreateSubscription(req, 10)
+		res, err := subService.CreateSubscription(req, actor)
 
 		// assert
 		assert.Nil(t, res)
@@ -222,13 +227,13 @@ func TestDeleteSubscription(t *testing.T) {
 		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
 
 		subscriptionRepo.
-			On("Delete", 1, 10).
+			On("Delete", 1, actor).
 			Return(nil)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		err := subService.DeleteSubscription(1, 10)
+		err := subService.DeleteSubscription(1, actor)
 
 		// assert
 		assert.NoError(t, err)
@@ -241,17 +246,259 @@ func TestDeleteSubscription(t *testing.T) {
 		expectedErr := errors.New("delete failed")
 
 		subscriptionRepo.
-			On("Delete", 1, 10).
+			On("Delete", 1, actor).
 			Return(expectedErr)
 
-		subService := service.NewSubscriptionService(subscriptionRepo)
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
 
 		// act
-		err := subService.DeleteSubscription(1, 10)
+		err := subService.DeleteSubscription(1, actor)
 
 		// assert
 		assert.EqualError(t, err, "delete failed")
 		subscriptionRepo.AssertExpectations(t)
 	})
 
+	t.Run("Delete Subscription Viewer", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("Delete", 1, actor).
+			Return(sql.ErrNoRows)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix"}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		err := subService.DeleteSubscription(1, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Delete Subscription Not Found", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("Delete", 1, actor).
+			Return(sql.ErrNoRows)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return((*repository.Subscription)(nil), nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		err := subService.DeleteSubscription(1, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrSubscriptionNotFound)
+	})
+
+}
+
+func TestUpdateSubscription(t *testing.T) {
+	noticePeriod := 30
+	req := service.UpdateSubscriptionRequest{
+		Name:         " Netflix ",
+		Amount:       449,
+		Currency:     "thb",
+		BillingCycle: "monthly",
+		BillingDate:  "2025-05-02",
+		Notes:        "Family plan",
+		Tags:         []string{"shared", "Shared"},
+		// AutoRenew is omitted, so the stored flag is kept
+		NoticePeriodDays: &noticePeriod,
+	}
+
+	t.Run("Update Subscription Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix", AutoRenew: true}, nil)
+		subscriptionRepo.
+			On("Update", mock.MatchedBy(func(sub *repository.Subscription) bool {
+				return sub.SubscriptionID == 1 && sub.Name == "Netflix" && sub.Currency == "THB" &&
+					sub.Status == "active" && sub.Notes == "Family plan" && len(sub.Tags) == 1 &&
+					sub.AutoRenew && sub.NoticePeriodDays == 30
+			}), actor).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix", Tags: []string{"shared"}}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		res, err := subService.UpdateSubscription(1, req, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"shared"}, res.Tags)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update Subscription Invalid", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		tooLong := 400
+
+		// act
+		res, err := subService.UpdateSubscription(1, service.UpdateSubscriptionRequest{
+			Name:             "Netflix",
+			NoticePeriodDays: &tooLong,
+		}, actor)
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrInvalidSubscription)
+		assert.ErrorContains(t, err, "notice period must be between 0 and 365 days")
+		subscriptionRepo.AssertNotCalled(t, "Update", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Omitted Notice Period Is Kept", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix", AutoRenew: true, NoticePeriodDays: 14}, nil)
+		subscriptionRepo.
+			On("Update", mock.MatchedBy(func(sub *repository.Subscription) bool {
+				return sub.SubscriptionID == 1 && sub.NoticePeriodDays == 14
+			}), actor).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix", NoticePeriodDays: 14}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+		omitted := req
+		omitted.NoticePeriodDays = nil
+
+		// act
+		res, err := subService.UpdateSubscription(1, omitted, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 14, res.NoticePeriodDays)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Update Subscription Viewer", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("Update", mock.Anything, actor).
+			Return((*repository.Subscription)(nil), sql.ErrNoRows)
+		subscriptionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix"}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		res, err := subService.UpdateSubscription(1, req, actor)
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		subscriptionRepo.AssertExpectations(t)
+	})
+}
+
+func TestSearchSubscriptions(t *testing.T) {
+	t.Run("Search Subscriptions Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("Search", "spot", 10, 20).
+			Return([]repository.SubscriptionSearchResult{
+				{
+					Subscription: repository.Subscription{
+						SubscriptionID: 1,
+						Name:           "Spotify",
+						Category:       "Music",
+					},
+					Rank:              0.9,
+					NameHighlight:     "<mark>Spotify</mark>",
+					CategoryHighlight: "Music",
+				},
+			}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		res, err := subService.SearchSubscriptions("  spot ", 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "Spotify", res[0].Name)
+		assert.Equal(t, "<mark>Spotify</mark>", res[0].Highlights["name"])
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Empty Query", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		res, err := subService.SearchSubscriptions("   ", 10)
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrEmptySearchQuery)
+		subscriptionRepo.AssertNotCalled(t, "Search", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+}
+
+func TestCreateSubscriptionTags(t *testing.T) {
+	t.Run("Tags Are Deduplicated", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+
+		subscriptionRepo.
+			On("Create", mock.MatchedBy(func(sub *repository.Subscription) bool {
+				return assert.ObjectsAreEqual([]string{"work", "tax-deductible"}, sub.Tags) && sub.Notes == "Billed to **company** card"
+			}), actor).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "GitHub", Tags: []string{"work", "tax-deductible"}}, nil)
+
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		res, err := subService.CreateSubscription(service.CreateSubscriptionRequest{
+			Name:  "GitHub",
+			Notes: " Billed to **company** card\n",
+			Tags:  []string{"work", " Work ", "", "tax-deductible"},
+		}, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, []string{"work", "tax-deductible"}, res.Tags)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Tag Too Long", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subService := service.NewSubscriptionService(subscriptionRepo, nil)
+
+		// act
+		_, err := subService.CreateSubscription(service.CreateSubscriptionRequest{
+			Name: "GitHub",
+			Tags: []string{strings.Repeat("x", 51)},
+		}, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidSubscription)
+		subscriptionRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
 }
@@ -0,0 +1,53 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+const (
+	SuggestionStatusPending  = "pending"
+	SuggestionStatusAccepted = "accepted"
+	SuggestionStatusRejected = "rejected"
+)
+
+// SuggestionEvidence is one observation a suggestion is based on, such as
+// a statement line.
+type SuggestionEvidence struct {
+	Date        string  `json:"date"`
+	Description string  `json:"description"`
+	Amount      float64 `json:"amount"`
+	Currency    string  `json:"currency"`
+}
+
+type SuggestionResponse struct {
+	SuggestionID          int                  `json:"id"`
+	Source                string               `json:"source"`
+	MerchantKey           string               `json:"merchant_key"`
+	Name                  string               `json:"name"`
+	Amount                float32              `json:"amount"`
+	Currency              string               `json:"currency"`
+	BillingCycle          string               `json:"billing_cycle"`
+	BillingDate           string               `json:"billing_date"`
+	Confidence            float64              `json:"confidence"`
+	AmountVariation       float64              `json:"amount_variation"`
+	MatchedSubscriptionID *int                 `json:"matched_subscription_id"`
+	Status                string               `json:"status"`
+	Evidence              []SuggestionEvidence `json:"evidence"`
+}
+
+// AcceptSuggestionRequest lets the user adjust a suggestion while
+// accepting it. Empty fields keep the suggested value.
+type AcceptSuggestionRequest struct {
+	Name         string `json:"name"`
+	Category     string `json:"category"`
+	BillingCycle string `json:"billing_cycle"`
+	BillingDate  string `json:"billing_date"`
+	// UpdateMatched is required when the suggestion matched a subscription
+	// the user already tracks: true overwrites its amount and billing date,
+	// false adds a new subscription next to it.
+	UpdateMatched *bool `json:"update_matched"`
+}
+
+type SuggestionService interface {
+	GetSuggestions(userID int, status string) ([]SuggestionResponse, error)
+	AcceptSuggestion(id int, actor repository.Actor, req AcceptSuggestionRequest) (*SubscriptionResponse, error)
+	RejectSuggestion(id int, userID int) error
+}
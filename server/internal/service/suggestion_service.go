@@ -0,0 +1,161 @@
+package service
+
+import (
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+var (
+	ErrSuggestionNotFound   = errors.New("suggestion not found")
+	ErrSuggestionNotPending = errors.New("suggestion was already accepted or rejected")
+	ErrSuggestionMatched    = errors.New("suggestion matches an existing subscription, set update_matched to update it or to false to add a new one")
+	ErrInvalidSubscription  = errors.New("invalid subscription")
+	ErrInvalidStatus        = errors.New("status must be pending, accepted or rejected")
+)
+
+type suggestionService struct {
+	suggestionRepo repository.SuggestionRepository
+}
+
+func NewSuggestionService(suggestionRepo repository.SuggestionRepository) SuggestionService {
+	return suggestionService{suggestionRepo: suggestionRepo}
+}
+
+func toSuggestionResponse(s repository.Suggestion) SuggestionResponse {
+	res := SuggestionResponse{
+		SuggestionID:          s.SuggestionID,
+		Source:                s.Source,
+		MerchantKey:           s.MerchantKey,
+		Name:                  s.Name,
+		Amount:                s.Amount,
+		Currency:              s.Currency,
+		BillingCycle:          s.BillingCycle,
+		BillingDate:           s.BillingDate,
+		Confidence:            s.Confidence,
+		AmountVariation:       s.AmountVariation,
+		MatchedSubscriptionID: s.MatchedSubscriptionID,
+		Status:                s.Status,
+		Evidence:              []SuggestionEvidence{},
+	}
+	// evidence is only informative, a malformed value is shown as empty
+	_ = json.Unmarshal([]byte(s.Evidence), &res.Evidence)
+	return res
+}
+
+func (s suggestionService) GetSuggestions(userID int, status string) ([]SuggestionResponse, error) {
+	switch status {
+	case "", SuggestionStatusPending, SuggestionStatusAccepted, SuggestionStatusRejected:
+	default:
+		return nil, ErrInvalidStatus
+	}
+
+	suggestions, err := s.suggestionRepo.GetAll(userID, status)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []SuggestionResponse{}
+	for _, suggestion := range suggestions {
+		res = append(res, toSuggestionResponse(suggestion))
+	}
+
+	return res, nil
+}
+
+// AcceptSuggestion turns a suggestion into a subscription. When the
+// suggestion matched an existing subscription the user has to say whether
+// that subscription gets the observed amount and next billing date or a
+// new one is added, so nothing is overwritten without asking. The
+// evidence is recorded as charges either way.
+func (s suggestionService) AcceptSuggestion(id int, actor repository.Actor, req AcceptSuggestionRequest) (*SubscriptionResponse, error) {
+	suggestion, err := s.suggestionRepo.GetById(id, actor.UserID)
+	if err != nil {
+		return nil, err
+	}
+	if suggestion == nil {
+		return nil, ErrSuggestionNotFound
+	}
+	if suggestion.Status != SuggestionStatusPending {
+		return nil, ErrSuggestionNotPending
+	}
+	if suggestion.MatchedSubscriptionID != nil && req.UpdateMatched == nil {
+		return nil, ErrSuggestionMatched
+	}
+
+	create := CreateSubscriptionRequest{
+		Name:         firstNonEmpty(req.Name, suggestion.Name),
+		Category:     req.Category,
+		Amount:       suggestion.Amount,
+		Currency:     suggestion.Currency,
+		BillingCycle: firstNonEmpty(req.BillingCycle, suggestion.BillingCycle),
+		BillingDate:  firstNonEmpty(req.BillingDate, suggestion.BillingDate),
+		Status:       "active",
+	}
+	create, problems := normalizeSubscriptionRequest(create)
+	if len(problems) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrInvalidSubscription, strings.Join(problems, "; "))
+	}
+
+	sub := &repository.Subscription{
+		Name:         create.Name,
+		Category:     create.Category,
+		Amount:       create.Amount,
+		Currency:     create.Currency,
+		BillingCycle: create.BillingCycle,
+		BillingDate:  create.BillingDate,
+		Status:       create.Status,
+		AutoRenew:    true, // only applies to a new subscription
+	}
+	if suggestion.MatchedSubscriptionID != nil && *req.UpdateMatched {
+		sub.SubscriptionID = *suggestion.MatchedSubscriptionID
+	}
+
+	var evidence []SuggestionEvidence
+	_ = json.Unmarshal([]byte(suggestion.Evidence), &evidence)
+
+	var charges []repository.Charge
+	for _, e := range evidence {
+		if e.Date == "" || e.Amount <= 0 {
+			continue
+		}
+		charges = append(charges, repository.Charge{
+			ChargedOn: e.Date,
+			Amount:    float32(e.Amount),
+			Currency:  normalizeCurrency(firstNonEmpty(e.Currency, suggestion.Currency)),
+			Source:    suggestion.Source,
+		})
+	}
+
+	saved, err := s.suggestionRepo.Accept(id, actor, sub, charges)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrSuggestionNotPending
+		}
+		return nil, err
+	}
+
+	res := toResponse(*saved)
+	return &res, nil
+}
+
+func (s suggestionService) RejectSuggestion(id int, userID int) error {
+	err := s.suggestionRepo.Reject(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrSuggestionNotFound
+	}
+	return err
+}
+
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if strings.TrimSpace(v) != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,167 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestAcceptSuggestion(t *testing.T) {
+	t.Run("Accept Matched Suggestion", func(t *testing.T) {
+		// arrange
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+		matched := 4
+
+		suggestionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Suggestion{
+				SuggestionID:          1,
+				Source:                "statement",
+				Name:                  "Netflix",
+				Amount:                449,
+				Currency:              "THB",
+				BillingCycle:          "monthly",
+				BillingDate:           "2025-05-02",
+				MatchedSubscriptionID: &matched,
+				Evidence:              `[{"date":"2025-04-02","description":"NETFLIX.COM","amount":449,"currency":"THB"}]`,
+				Status:                "pending",
+			}, nil)
+		suggestionRepo.
+			On("Accept", 1, actor,
+				mock.MatchedBy(func(sub *repository.Subscription) bool {
+					return sub.SubscriptionID == 4 && sub.Amount == 449 && sub.BillingDate == "2025-05-02"
+				}),
+				[]repository.Charge{{ChargedOn: "2025-04-02", Amount: 449, Currency: "THB", Source: "statement"}},
+			).
+			Return(&repository.Subscription{SubscriptionID: 4, Name: "Netflix", Amount: 449}, nil)
+
+		suggestionService := service.NewSuggestionService(suggestionRepo)
+
+		// act
+		update := true
+		res, err := suggestionService.AcceptSuggestion(1, actor, service.AcceptSuggestionRequest{UpdateMatched: &update})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 4, res.SubscriptionID)
+		suggestionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Matched Suggestion Needs Confirmation", func(t *testing.T) {
+		// arrange
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+		matched := 4
+
+		suggestionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Suggestion{
+				SuggestionID:          1,
+				Name:                  "Apple",
+				Amount:                35,
+				Currency:              "THB",
+				BillingCycle:          "monthly",
+				BillingDate:           "2025-05-02",
+				MatchedSubscriptionID: &matched,
+				Status:                "pending",
+			}, nil)
+
+		suggestionService := service.NewSuggestionService(suggestionRepo)
+
+		// act
+		res, err := suggestionService.AcceptSuggestion(1, actor, service.AcceptSuggestionRequest{})
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrSuggestionMatched)
+		suggestionRepo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Matched Suggestion Added As New", func(t *testing.T) {
+		// arrange
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+		matched := 4
+
+		suggestionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Suggestion{
+				SuggestionID:          1,
+				Name:                  "Apple",
+				Amount:                35,
+				Currency:              "THB",
+				BillingCycle:          "monthly",
+				BillingDate:           "2025-05-02",
+				MatchedSubscriptionID: &matched,
+				Status:                "pending",
+			}, nil)
+		suggestionRepo.
+			On("Accept", 1, actor,
+				mock.MatchedBy(func(sub *repository.Subscription) bool {
+					return sub.SubscriptionID == 0 && sub.Name == "iCloud+"
+				}),
+				[]repository.Charge(nil),
+			).
+			Return(&repository.Subscription{SubscriptionID: 7, Name: "iCloud+", Amount: 35}, nil)
+
+		suggestionService := service.NewSuggestionService(suggestionRepo)
+
+		// act
+		update := false
+		res, err := suggestionService.AcceptSuggestion(1, actor, service.AcceptSuggestionRequest{
+			Name:          "iCloud+",
+			UpdateMatched: &update,
+		})
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 7, res.SubscriptionID)
+		suggestionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Already Rejected", func(t *testing.T) {
+		// arrange
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+
+		suggestionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Suggestion{SuggestionID: 1, Status: "rejected"}, nil)
+
+		suggestionService := service.NewSuggestionService(suggestionRepo)
+
+		// act
+		res, err := suggestionService.AcceptSuggestion(1, actor, service.AcceptSuggestionRequest{})
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrSuggestionNotPending)
+		suggestionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Missing Billing Cycle", func(t *testing.T) {
+		// arrange
+		suggestionRepo := repository.NewSuggestionRepositoryMock()
+
+		suggestionRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Suggestion{
+				SuggestionID: 1,
+				Name:         "Notion",
+				Amount:       10,
+				Currency:     "USD",
+				BillingDate:  "2025-05-02",
+				Status:       "pending",
+			}, nil)
+
+		suggestionService := service.NewSuggestionService(suggestionRepo)
+
+		// act
+		res, err := suggestionService.AcceptSuggestion(1, actor, service.AcceptSuggestionRequest{})
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrInvalidSubscription)
+		suggestionRepo.AssertNotCalled(t, "Accept", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
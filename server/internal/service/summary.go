@@ -0,0 +1,31 @@
+package service
+
+const (
+	SummaryGroupByCategory = "category"
+	SummaryGroupByTag      = "tag"
+)
+
+type SummaryGroup struct {
+	Name          string  `json:"name"`
+	Subscriptions int     `json:"subscriptions"`
+	Monthly       float64 `json:"monthly"`
+	Yearly        float64 `json:"yearly"`
+	Share         float64 `json:"share"` // percent of the monthly total
+}
+
+// SummaryResponse totals the normalized cost of active subscriptions in the
+// user's base currency. When grouping by tag a subscription counts towards
+// each of its tags, so shares can add up to more than 100.
+type SummaryResponse struct {
+	BaseCurrency  string              `json:"base_currency"`
+	GroupBy       string              `json:"group_by"`
+	Subscriptions int                 `json:"subscriptions"`
+	Monthly       float64             `json:"monthly"`
+	Yearly        float64             `json:"yearly"`
+	Groups        []SummaryGroup      `json:"groups"`
+	Unconverted   []UnconvertedAmount `json:"unconverted"`
+}
+
+type SummaryService interface {
+	GetSummary(userID int, groupBy string) (*SummaryResponse, error)
+}
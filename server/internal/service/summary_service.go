@@ -0,0 +1,126 @@
+package service
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const untaggedName = "Untagged"
+
+var ErrInvalidGroupBy = errors.New("group_by must be category or tag")
+
+type summaryService struct {
+	subRepo      repository.SubscriptionRepository
+	userRepo     repository.UserRepository
+	currencyRepo repository.CurrencyRepository
+}
+
+func NewSummaryService(
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+	currencyRepo repository.CurrencyRepository,
+) SummaryService {
+	return summaryService{subRepo: subRepo, userRepo: userRepo, currencyRepo: currencyRepo}
+}
+
+// GetSummary groups the monthly cost of active subscriptions by category
+// (the default) or by tag, most expensive group first.
+func (s summaryService) GetSummary(userID int, groupBy string) (*SummaryResponse, error) {
+	if groupBy == "" {
+		groupBy = SummaryGroupByCategory
+	}
+	if groupBy != SummaryGroupByCategory && groupBy != SummaryGroupByTag {
+		return nil, ErrInvalidGroupBy
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+
+	rates, err := s.currencyRepo.GetRates()
+	if err != nil {
+		return nil, err
+	}
+	converter := newCurrencyConverter(user.BaseCurrency, rates)
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &SummaryResponse{
+		BaseCurrency: converter.base,
+		GroupBy:      groupBy,
+		Groups:       []SummaryGroup{},
+		Unconverted:  []UnconvertedAmount{},
+	}
+	groups := map[string]*SummaryGroup{}
+
+	for _, sub := range subs {
+		monthly, ok, err := monthlyAmount(sub, converter)
+		if errors.Is(err, ErrUnknownCurrency) {
+			res.Unconverted = append(res.Unconverted, unconvertedSubscription(sub))
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !ok {
+			continue
+		}
+
+		res.Subscriptions++
+		res.Monthly += monthly
+
+		for _, name := range summaryKeys(sub, groupBy) {
+			g, ok := groups[name]
+			if !ok {
+				g = &SummaryGroup{Name: name}
+				groups[name] = g
+			}
+			g.Subscriptions++
+			g.Monthly += monthly
+		}
+	}
+
+	for _, g := range groups {
+		if res.Monthly > 0 {
+			g.Share = roundMoney(g.Monthly / res.Monthly * 100)
+		}
+		g.Yearly = roundMoney(g.Monthly * 12)
+		g.Monthly = roundMoney(g.Monthly)
+		res.Groups = append(res.Groups, *g)
+	}
+
+	sort.Slice(res.Groups, func(i, j int) bool {
+		if res.Groups[i].Monthly != res.Groups[j].Monthly {
+			return res.Groups[i].Monthly > res.Groups[j].Monthly
+		}
+		return res.Groups[i].Name < res.Groups[j].Name
+	})
+
+	res.Yearly = roundMoney(res.Monthly * 12)
+	res.Monthly = roundMoney(res.Monthly)
+
+	return res, nil
+}
+
+func summaryKeys(sub repository.Subscription, groupBy string) []string {
+	if groupBy == SummaryGroupByTag {
+		if len(sub.Tags) == 0 {
+			return []string{untaggedName}
+		}
+		return sub.Tags
+	}
+
+	if sub.Category == "" {
+		return []string{uncategorizedName}
+	}
+	return []string{sub.Category}
+}
@@ -0,0 +1,73 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSummary(t *testing.T) {
+	setup := func() service.SummaryService {
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		currencyRepo := repository.NewCurrencyRepositoryMock()
+
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, BaseCurrency: "THB"}, nil)
+		currencyRepo.
+			On("GetRates").
+			Return(map[string]float64{"THB": 1, "USD": 35}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{Name: "Netflix", Category: "Streaming", Amount: 400, Currency: "THB", BillingCycle: "monthly", Status: "active", Tags: []string{"family", "shared"}},
+				{Name: "GitHub", Category: "Tools", Amount: 120, Currency: "USD", BillingCycle: "yearly", Status: "active", Tags: []string{"work"}},
+				{Name: "Gym", Amount: 100, Currency: "THB", BillingCycle: "monthly", Status: "active"},
+				{Name: "Disney+", Category: "Streaming", Amount: 300, Currency: "THB", BillingCycle: "monthly", Status: "canceled", Tags: []string{"family"}},
+			}, nil)
+
+		return service.NewSummaryService(subscriptionRepo, userRepo, currencyRepo)
+	}
+
+	t.Run("Group By Category", func(t *testing.T) {
+		// act
+		res, err := setup().GetSummary(10, "")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "category", res.GroupBy)
+		assert.Equal(t, 3, res.Subscriptions)
+		assert.Equal(t, 850.0, res.Monthly)
+		assert.Equal(t, 10200.0, res.Yearly)
+		assert.Equal(t, []service.SummaryGroup{
+			{Name: "Streaming", Subscriptions: 1, Monthly: 400, Yearly: 4800, Share: 47.06},
+			{Name: "Tools", Subscriptions: 1, Monthly: 350, Yearly: 4200, Share: 41.18},
+			{Name: "Uncategorized", Subscriptions: 1, Monthly: 100, Yearly: 1200, Share: 11.76},
+		}, res.Groups)
+	})
+
+	t.Run("Group By Tag", func(t *testing.T) {
+		// act
+		res, err := setup().GetSummary(10, "tag")
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res.Groups, 4)
+		assert.Equal(t, "family", res.Groups[0].Name)
+		assert.Equal(t, "shared", res.Groups[1].Name)
+		assert.Equal(t, 400.0, res.Groups[1].Monthly)
+		assert.Equal(t, "work", res.Groups[2].Name)
+		assert.Equal(t, "Untagged", res.Groups[3].Name)
+	})
+
+	t.Run("Invalid Group By", func(t *testing.T) {
+		// act
+		_, err := service.NewSummaryService(nil, nil, nil).GetSummary(10, "status")
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidGroupBy)
+	})
+}
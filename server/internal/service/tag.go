@@ -0,0 +1,20 @@
+package service
+
+type TagResponse struct {
+	TagID         int    `json:"id"`
+	Name          string `json:"name"`
+	Color         string `json:"color"`
+	Subscriptions int    `json:"subscriptions"`
+}
+
+type TagRequest struct {
+	Name  string `json:"name"`
+	Color string `json:"color"`
+}
+
+type TagService interface {
+	GetTags(userID int) ([]TagResponse, error)
+	CreateTag(req TagRequest, userID int) (*TagResponse, error)
+	UpdateTag(id int, req TagRequest, userID int) (*TagResponse, error)
+	DeleteTag(id int, userID int) error
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	maxTagLength           = 50
+	maxTagsPerSubscription = 20
+)
+
+var (
+	ErrTagNotFound = errors.New("tag not found")
+	ErrInvalidTag  = errors.New("invalid tag")
+)
+
+type tagService struct {
+	tagRepo repository.TagRepository
+}
+
+func NewTagService(tagRepo repository.TagRepository) TagService {
+	return tagService{tagRepo: tagRepo}
+}
+
+func toTagResponse(t repository.Tag) TagResponse {
+	return TagResponse{
+		TagID:         t.TagID,
+		Name:          t.Name,
+		Color:         t.Color,
+		Subscriptions: t.Subscriptions,
+	}
+}
+
+func (s tagService) GetTags(userID int) ([]TagResponse, error) {
+	tags, err := s.tagRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []TagResponse{}
+	for _, t := range tags {
+		res = append(res, toTagResponse(t))
+	}
+
+	return res, nil
+}
+
+func (s tagService) CreateTag(req TagRequest, userID int) (*TagResponse, error) {
+	tag, err := normalizeTagRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.tagRepo.Create(tag, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toTagResponse(*created)
+	return &res, nil
+}
+
+func (s tagService) UpdateTag(id int, req TagRequest, userID int) (*TagResponse, error) {
+	tag, err := normalizeTagRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	tag.TagID = id
+
+	updated, err := s.tagRepo.Update(tag, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrTagNotFound
+		}
+		return nil, err
+	}
+
+	res := toTagResponse(*updated)
+	return &res, nil
+}
+
+func (s tagService) DeleteTag(id int, userID int) error {
+	err := s.tagRepo.Delete(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrTagNotFound
+	}
+	return err
+}
+
+func normalizeTagRequest(req TagRequest) (*repository.Tag, error) {
+	name := strings.TrimSpace(req.Name)
+	color := strings.TrimSpace(req.Color)
+
+	switch {
+	case name == "":
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidTag)
+	case len(name) > maxTagLength:
+		return nil, fmt.Errorf("%w: name must be at most %d characters", ErrInvalidTag, maxTagLength)
+	case color != "" && !colorPattern.MatchString(color):
+		return nil, fmt.Errorf("%w: color must look like #RRGGBB", ErrInvalidTag)
+	}
+
+	return &repository.Tag{Name: name, Color: strings.ToUpper(color)}, nil
+}
+
+// normalizeTags trims tags and drops empty and case-insensitive duplicates,
+// keeping the first spelling. A nil input stays nil.
+func normalizeTags(tags []string) ([]string, []string) {
+	if tags == nil {
+		return nil, nil
+	}
+
+	var problems []string
+	seen := map[string]bool{}
+	res := []string{}
+	for _, tag := range tags {
+		tag = strings.TrimSpace(tag)
+		key := strings.ToLower(tag)
+		if tag == "" || seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if len(tag) > maxTagLength {
+			problems = append(problems, fmt.Sprintf("tag %q is longer than %d characters", tag, maxTagLength))
+			continue
+		}
+		res = append(res, tag)
+	}
+
+	if len(res) > maxTagsPerSubscription {
+		problems = append(problems, fmt.Sprintf("at most %d tags are allowed", maxTagsPerSubscription))
+	}
+
+	return res, problems
+}
+
+// splitTags reads a list of tags from one text field, separated by commas
+// or semicolons.
+func splitTags(value string) []string {
+	if strings.TrimSpace(value) == "" {
+		return nil
+	}
+	return strings.FieldsFunc(value, func(r rune) bool {
+		return r == ',' || r == ';'
+	})
+}
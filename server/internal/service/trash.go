@@ -0,0 +1,23 @@
+package service
+
+import (
+	"context"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+// TrashedSubscriptionResponse is a deleted subscription that can be
+// restored until PurgeOn, when it is deleted for good.
+type TrashedSubscriptionResponse struct {
+	SubscriptionResponse
+	DeletedAt string `json:"deleted_at"`
+	PurgeOn   string `json:"purge_on"`
+}
+
+type TrashService interface {
+	GetTrash(userID int) ([]TrashedSubscriptionResponse, error)
+	RestoreSubscription(id int, actor repository.Actor) (*SubscriptionResponse, error)
+	// PurgeExpired permanently deletes the subscriptions that have been in
+	// the trash for longer than the retention period.
+	PurgeExpired(ctx context.Context) error
+}
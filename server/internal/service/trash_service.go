@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"log"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+// DefaultTrashRetentionDays is how long deleted subscriptions can be
+// restored unless configured otherwise.
+const DefaultTrashRetentionDays = 30
+
+// trashPurgeBatch bounds each purge transaction.
+const trashPurgeBatch = 500
+
+type trashService struct {
+	subRepo       repository.SubscriptionRepository
+	retentionDays int
+}
+
+func NewTrashService(subRepo repository.SubscriptionRepository, retentionDays int) TrashService {
+	if retentionDays <= 0 {
+		retentionDays = DefaultTrashRetentionDays
+	}
+	return trashService{subRepo: subRepo, retentionDays: retentionDays}
+}
+
+func (s trashService) GetTrash(userID int) ([]TrashedSubscriptionResponse, error) {
+	trash, err := s.subRepo.GetTrash(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []TrashedSubscriptionResponse{}
+	for _, t := range trash {
+		item := TrashedSubscriptionResponse{
+			SubscriptionResponse: toResponse(t.Subscription),
+			DeletedAt:            t.DeletedAt,
+		}
+		if deletedAt, err := time.Parse(time.RFC3339, t.DeletedAt); err == nil {
+			item.PurgeOn = deletedAt.AddDate(0, 0, s.retentionDays).Format(dateLayout)
+		}
+		res = append(res, item)
+	}
+
+	return res, nil
+}
+
+func (s trashService) RestoreSubscription(id int, actor repository.Actor) (*SubscriptionResponse, error) {
+	sub, err := s.subRepo.Restore(id, actor)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrSubscriptionNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	res := toResponse(*sub)
+	return &res, nil
+}
+
+func (s trashService) PurgeExpired(ctx context.Context) error {
+	total := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		n, err := s.subRepo.Purge(s.retentionDays, trashPurgeBatch)
+		if err != nil {
+			return err
+		}
+		total += n
+		if n < trashPurgeBatch {
+			break
+		}
+	}
+
+	if total > 0 {
+		log.Printf("Purged %d subscriptions deleted more than %d days ago", total, s.retentionDays)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package service_test
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetTrash(t *testing.T) {
+	t.Run("Purge Date Follows Retention", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subscriptionRepo.
+			On("GetTrash", 10).
+			Return([]repository.TrashedSubscription{{
+				Subscription: repository.Subscription{SubscriptionID: 1, Name: "Netflix"},
+				DeletedAt:    "2026-10-19T08:30:00.123456Z",
+			}}, nil)
+
+		svc := service.NewTrashService(subscriptionRepo, 14)
+
+		// act
+		res, err := svc.GetTrash(10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res, 1)
+		assert.Equal(t, "Netflix", res[0].Name)
+		assert.Equal(t, "2026-11-02", res[0].PurgeOn)
+		subscriptionRepo.AssertExpectations(t)
+	})
+}
+
+func TestRestoreSubscription(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subscriptionRepo.
+			On("Restore", 1, actor).
+			Return(&repository.Subscription{SubscriptionID: 1, Name: "Netflix"}, nil)
+
+		svc := service.NewTrashService(subscriptionRepo, 30)
+
+		// act
+		res, err := svc.RestoreSubscription(1, actor)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, 1, res.SubscriptionID)
+		subscriptionRepo.AssertExpectations(t)
+	})
+
+	t.Run("Not In Trash", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subscriptionRepo.
+			On("Restore", 1, actor).
+			Return((*repository.Subscription)(nil), sql.ErrNoRows)
+
+		svc := service.NewTrashService(subscriptionRepo, 30)
+
+		// act
+		res, err := svc.RestoreSubscription(1, actor)
+
+		// assert
+		assert.Nil(t, res)
+		assert.ErrorIs(t, err, service.ErrSubscriptionNotFound)
+	})
+
+	t.Run("Name Taken Again", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subscriptionRepo.
+			On("Restore", 1, actor).
+			Return((*repository.Subscription)(nil), repository.ErrDuplicateSubscription)
+
+		svc := service.NewTrashService(subscriptionRepo, 30)
+
+		// act
+		_, err := svc.RestoreSubscription(1, actor)
+
+		// assert
+		assert.ErrorIs(t, err, repository.ErrDuplicateSubscription)
+	})
+}
+
+func TestPurgeExpired(t *testing.T) {
+	t.Run("Purges In Batches", func(t *testing.T) {
+		// arrange
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		subscriptionRepo.On("Purge", 30, 500).Return(500, nil).Once()
+		subscriptionRepo.On("Purge", 30, 500).Return(3, nil).Once()
+
+		svc := service.NewTrashService(subscriptionRepo, 0)
+
+		// act
+		err := svc.PurgeExpired(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		subscriptionRepo.AssertNumberOfCalls(t, "Purge", 2)
+	})
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+// Events sent to webhooks. Subscription, share and snooze events come from
+// the outbox.
+const (
+	EventSubscriptionCreated   = repository.EventSubscriptionCreated
+	EventSubscriptionUpdated   = repository.EventSubscriptionUpdated
+	EventSubscriptionDeleted   = repository.EventSubscriptionDeleted
+	EventSubscriptionRestored  = repository.EventSubscriptionRestored
+	EventSubscriptionSnoozed   = repository.EventSubscriptionSnoozed
+	EventSubscriptionUnsnoozed = repository.EventSubscriptionUnsnoozed
+	EventShareUpdated          = repository.EventShareUpdated
+	EventShareDeleted          = repository.EventShareDeleted
+	EventShareSettled          = repository.EventShareSettled
+	EventRenewalUpcoming       = "renewal.upcoming"
+	EventTrialEnding           = "trial.ending"
+	// EventPing is only sent by PingWebhook to test an endpoint.
+	EventPing = "ping"
+)
+
+// WebhookEvent is the JSON body POSTed to a webhook. ID stays the same
+// across retries and redeliveries so receivers can drop duplicates.
+type WebhookEvent struct {
+	ID        string `json:"id"`
+	Type      string `json:"type"`
+	CreatedAt string `json:"created_at"`
+	Data      any    `json:"data"`
+}
+
+// UpcomingEvent is the data of renewal.upcoming and trial.ending events.
+type UpcomingEvent struct {
+	Subscription SubscriptionResponse `json:"subscription"`
+	Date         string               `json:"date"`
+	DaysLeft     int                  `json:"days_left"`
+}
+
+type WebhookResponse struct {
+	WebhookID int      `json:"id"`
+	URL       string   `json:"url"`
+	Events    []string `json:"events"`
+	Active    bool     `json:"active"`
+	Secret    string   `json:"secret,omitempty"` // only returned when the webhook is created
+	CreatedAt string   `json:"created_at"`
+}
+
+// WebhookRequest registers or changes a webhook. An empty Events list
+// subscribes to every event; Active defaults to true.
+type WebhookRequest struct {
+	URL    string   `json:"url"`
+	Events []string `json:"events"`
+	Active *bool    `json:"active"`
+}
+
+type WebhookDeliveryResponse struct {
+	DeliveryID     int     `json:"id"`
+	Event          string  `json:"event"`
+	Payload        string  `json:"payload"`
+	Status         string  `json:"status"`
+	Attempts       int     `json:"attempts"`
+	NextAttemptAt  *string `json:"next_attempt_at"`
+	ResponseStatus *int    `json:"response_status"`
+	LastError      string  `json:"last_error,omitempty"`
+	DeliveredAt    *string `json:"delivered_at"`
+	CreatedAt      string  `json:"created_at"`
+}
+
+type WebhookService interface {
+	KeyedPublisher
+	EventPublisher
+	GetWebhooks(userID int) ([]WebhookResponse, error)
+	CreateWebhook(req WebhookRequest, userID int) (*WebhookResponse, error)
+	UpdateWebhook(id int, req WebhookRequest, userID int) (*WebhookResponse, error)
+	DeleteWebhook(id int, userID int) error
+	// PingWebhook queues a ping event to check the endpoint is reachable.
+	PingWebhook(id int, userID int) (*WebhookDeliveryResponse, error)
+	GetDeliveries(id int, userID int) ([]WebhookDeliveryResponse, error)
+	// Redeliver queues an earlier delivery again, with the same payload.
+	Redeliver(id int, deliveryID int, userID int) (*WebhookDeliveryResponse, error)
+	// DeliverDue sends the queued deliveries that are due.
+	DeliverDue(ctx context.Context) error
+	// QueueUpcoming raises renewal.upcoming and trial.ending events for the
+	// renewals inside each user's reminder window, once per renewal.
+	QueueUpcoming(ctx context.Context) error
+}
@@ -0,0 +1,479 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	webhookTimeout       = 10 * time.Second
+	webhookBatchSize     = 50
+	webhookRetryBase     = 30 * time.Second
+	webhookRetryMax      = 6 * time.Hour
+	maxWebhookAttempts   = 8
+	maxWebhookURLLength  = 2000
+	webhookDeliveryLimit = 100
+)
+
+const (
+	deliveryPending   = "pending"
+	deliveryDelivered = "delivered"
+	deliveryFailed    = "failed"
+)
+
+// Headers sent with every delivery. The signature is the hex HMAC-SHA256,
+// keyed with the webhook secret, of the timestamp, a dot and the body.
+const (
+	WebhookEventHeader     = "X-Subscout-Event"
+	WebhookDeliveryHeader  = "X-Subscout-Delivery"
+	WebhookTimestampHeader = "X-Subscout-Timestamp"
+	WebhookSignatureHeader = "X-Subscout-Signature"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrInvalidWebhook          = errors.New("invalid webhook")
+)
+
+var webhookEvents = map[string]bool{
+	EventSubscriptionCreated:   true,
+	EventSubscriptionUpdated:   true,
+	EventSubscriptionDeleted:   true,
+	EventSubscriptionRestored:  true,
+	EventSubscriptionSnoozed:   true,
+	EventSubscriptionUnsnoozed: true,
+	EventShareUpdated:          true,
+	EventShareDeleted:          true,
+	EventShareSettled:          true,
+	EventRenewalUpcoming:       true,
+	EventTrialEnding:           true,
+}
+
+type webhookService struct {
+	webhookRepo repository.WebhookRepository
+	subRepo     repository.SubscriptionRepository
+	userRepo    repository.UserRepository
+	client      *http.Client
+}
+
+func NewWebhookService(
+	webhookRepo repository.WebhookRepository,
+	subRepo repository.SubscriptionRepository,
+	userRepo repository.UserRepository,
+) WebhookService {
+	return webhookService{
+		webhookRepo: webhookRepo,
+		subRepo:     subRepo,
+		userRepo:    userRepo,
+		client:      &http.Client{Timeout: webhookTimeout},
+	}
+}
+
+func randomHex(n int) (string, error) {
+	raw := make([]byte, n)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(raw), nil
+}
+
+// signWebhook returns the signature header value for body sent at
+// timestamp.
+func signWebhook(secret string, timestamp int64, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	fmt.Fprintf(mac, "%d.", timestamp)
+	mac.Write(body)
+	return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// retryBackoff is the wait before retrying something that failed for the
+// attempts-th time: base, then doubling after every failure up to limit.
+func retryBackoff(attempts int, base, limit time.Duration) time.Duration {
+	wait := base
+	for i := 1; i < attempts && wait < limit; i++ {
+		wait *= 2
+	}
+	return min(wait, limit)
+}
+
+func newWebhookPayload(event string, data any) (string, error) {
+	id, err := randomHex(16)
+	if err != nil {
+		return "", err
+	}
+
+	body, err := json.Marshal(WebhookEvent{
+		ID:        "evt_" + id,
+		Type:      event,
+		CreatedAt: time.Now().UTC().Format(time.RFC3339),
+		Data:      data,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return string(body), nil
+}
+
+func toWebhookResponse(w repository.Webhook) WebhookResponse {
+	events := w.Events
+	if events == nil {
+		events = []string{}
+	}
+	return WebhookResponse{
+		WebhookID: w.WebhookID,
+		URL:       w.URL,
+		Events:    events,
+		Active:    w.Active,
+		CreatedAt: w.CreatedAt,
+	}
+}
+
+func toWebhookDeliveryResponse(d repository.WebhookDelivery) WebhookDeliveryResponse {
+	return WebhookDeliveryResponse{
+		DeliveryID:     d.DeliveryID,
+		Event:          d.Event,
+		Payload:        d.Payload,
+		Status:         d.Status,
+		Attempts:       d.Attempts,
+		NextAttemptAt:  d.NextAttemptAt,
+		ResponseStatus: d.ResponseStatus,
+		LastError:      d.LastError,
+		DeliveredAt:    d.DeliveredAt,
+		CreatedAt:      d.CreatedAt,
+	}
+}
+
+func (s webhookService) Publish(userID int, event string, data any) error {
+	return s.PublishOnce(userID, event, "", data)
+}
+
+// PublishOnce queues the event for each webhook at most once per non-empty
+// key.
+func (s webhookService) PublishOnce(userID int, event string, key string, data any) error {
+	payload, err := newWebhookPayload(event, data)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.webhookRepo.Enqueue(userID, event, key, payload)
+	return err
+}
+
+func (s webhookService) GetWebhooks(userID int) ([]WebhookResponse, error) {
+	webhooks, err := s.webhookRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []WebhookResponse{}
+	for _, w := range webhooks {
+		res = append(res, toWebhookResponse(w))
+	}
+
+	return res, nil
+}
+
+func (s webhookService) CreateWebhook(req WebhookRequest, userID int) (*WebhookResponse, error) {
+	w, err := normalizeWebhookRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	secret, err := randomHex(32)
+	if err != nil {
+		return nil, err
+	}
+	w.Secret = "whsec_" + secret
+
+	created, err := s.webhookRepo.Create(w, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	// the secret is shown once; receivers need it to verify signatures
+	res := toWebhookResponse(*created)
+	res.Secret = created.Secret
+	return &res, nil
+}
+
+func (s webhookService) UpdateWebhook(id int, req WebhookRequest, userID int) (*WebhookResponse, error) {
+	w, err := normalizeWebhookRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	w.WebhookID = id
+
+	updated, err := s.webhookRepo.Update(w, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWebhookNotFound
+		}
+		return nil, err
+	}
+
+	res := toWebhookResponse(*updated)
+	return &res, nil
+}
+
+func (s webhookService) DeleteWebhook(id int, userID int) error {
+	err := s.webhookRepo.Delete(id, userID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWebhookNotFound
+	}
+	return err
+}
+
+func (s webhookService) PingWebhook(id int, userID int) (*WebhookDeliveryResponse, error) {
+	payload, err := newWebhookPayload(EventPing, map[string]int{"webhook_id": id})
+	if err != nil {
+		return nil, err
+	}
+
+	d, err := s.webhookRepo.EnqueueTo(id, EventPing, payload, userID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	res := toWebhookDeliveryResponse(*d)
+	return &res, nil
+}
+
+func (s webhookService) GetDeliveries(id int, userID int) ([]WebhookDeliveryResponse, error) {
+	w, err := s.webhookRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, ErrWebhookNotFound
+	}
+
+	deliveries, err := s.webhookRepo.GetDeliveries(id, userID, webhookDeliveryLimit)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []WebhookDeliveryResponse{}
+	for _, d := range deliveries {
+		res = append(res, toWebhookDeliveryResponse(d))
+	}
+
+	return res, nil
+}
+
+func (s webhookService) Redeliver(id int, deliveryID int, userID int) (*WebhookDeliveryResponse, error) {
+	d, err := s.webhookRepo.Redeliver(deliveryID, id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, ErrWebhookDeliveryNotFound
+	}
+
+	res := toWebhookDeliveryResponse(*d)
+	return &res, nil
+}
+
+func (s webhookService) DeliverDue(ctx context.Context) error {
+	// claimed deliveries are skipped by other senders until the lease ends
+	due, err := s.webhookRepo.ClaimDue(webhookBatchSize, 2*webhookTimeout)
+	if err != nil {
+		return err
+	}
+
+	for _, d := range due {
+		// the rest are sent by the next run once their lease is over
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		attempt := s.send(ctx, d)
+
+		var retryIn time.Duration
+		if attempt.Status == deliveryPending {
+			retryIn = retryBackoff(attempt.Attempts, webhookRetryBase, webhookRetryMax)
+		}
+		if err := s.webhookRepo.RecordAttempt(attempt, retryIn); err != nil {
+			log.Printf("webhook delivery %d could not be recorded: %v", d.DeliveryID, err)
+		}
+	}
+
+	return nil
+}
+
+// send POSTs the delivery once and returns it updated with the outcome.
+func (s webhookService) send(ctx context.Context, d repository.DueDelivery) repository.WebhookDelivery {
+	attempt := d.WebhookDelivery
+	attempt.Attempts++
+	attempt.ResponseStatus = nil
+	attempt.LastError = ""
+
+	status, err := s.post(ctx, d)
+	if status != 0 {
+		attempt.ResponseStatus = &status
+	}
+
+	switch {
+	case err == nil:
+		attempt.Status = deliveryDelivered
+	case attempt.Attempts >= maxWebhookAttempts:
+		attempt.Status = deliveryFailed
+		attempt.LastError = err.Error()
+	default:
+		attempt.Status = deliveryPending
+		attempt.LastError = err.Error()
+	}
+
+	return attempt
+}
+
+func (s webhookService) post(ctx context.Context, d repository.DueDelivery) (int, error) {
+	body := []byte(d.Payload)
+	timestamp := time.Now().Unix()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, d.URL, bytes.NewReader(body))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Subscout-Webhook/1.0")
+	req.Header.Set(WebhookEventHeader, d.Event)
+	req.Header.Set(WebhookDeliveryHeader, strconv.Itoa(d.DeliveryID))
+	req.Header.Set(WebhookTimestampHeader, strconv.FormatInt(timestamp, 10))
+	req.Header.Set(WebhookSignatureHeader, signWebhook(d.Secret, timestamp, body))
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	// drain a little so the connection can be reused
+	io.Copy(io.Discard, io.LimitReader(resp.Body, 4096))
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("endpoint responded %s", resp.Status)
+	}
+	return resp.StatusCode, nil
+}
+
+func (s webhookService) QueueUpcoming(ctx context.Context) error {
+	userIDs, err := s.webhookRepo.GetUserIDs()
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		// keep queueing for the other users when one fails
+		if err := s.queueUpcoming(userID); err != nil {
+			log.Printf("upcoming webhook events failed for user %d: %v", userID, err)
+		}
+	}
+
+	return nil
+}
+
+func (s webhookService) queueUpcoming(userID int) error {
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil || user == nil {
+		return err
+	}
+
+	subs, err := s.subRepo.GetAll(userID)
+	if err != nil {
+		return err
+	}
+
+	start := today()
+	end := start.AddDate(0, 0, user.ReminderDays)
+
+	for _, sub := range subs {
+		if !isActive(sub.Status) {
+			continue
+		}
+
+		dates, err := renewalDates(sub, start, end)
+		if err != nil || len(dates) == 0 {
+			continue
+		}
+
+		// a trial ends when it is first billed
+		event := EventRenewalUpcoming
+		if sub.Trial {
+			event = EventTrialEnding
+		}
+
+		date := dates[0].Format(dateLayout)
+		payload, err := newWebhookPayload(event, UpcomingEvent{
+			Subscription: toResponse(sub),
+			Date:         date,
+			DaysLeft:     int(dates[0].Sub(start).Hours() / 24),
+		})
+		if err != nil {
+			return err
+		}
+
+		key := fmt.Sprintf("%s:%d:%s", event, sub.SubscriptionID, date)
+		if _, err := s.webhookRepo.Enqueue(userID, event, key, payload); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func normalizeWebhookRequest(req WebhookRequest) (*repository.Webhook, error) {
+	rawURL := strings.TrimSpace(req.URL)
+	if rawURL == "" {
+		return nil, fmt.Errorf("%w: url is required", ErrInvalidWebhook)
+	}
+	if len(rawURL) > maxWebhookURLLength {
+		return nil, fmt.Errorf("%w: url must be at most %d characters", ErrInvalidWebhook, maxWebhookURLLength)
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%w: url must be an absolute http or https URL", ErrInvalidWebhook)
+	}
+
+	events := []string{}
+	seen := map[string]bool{}
+	for _, e := range req.Events {
+		e = strings.ToLower(strings.TrimSpace(e))
+		if !webhookEvents[e] {
+			return nil, fmt.Errorf("%w: unknown event %q", ErrInvalidWebhook, e)
+		}
+		if !seen[e] {
+			seen[e] = true
+			events = append(events, e)
+		}
+	}
+
+	return &repository.Webhook{
+		URL:    rawURL,
+		Events: events,
+		Active: req.Active == nil || *req.Active,
+	}, nil
+}
@@ -0,0 +1,221 @@
+package service_test
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeliverDue(t *testing.T) {
+	t.Run("Deliver Due Success", func(t *testing.T) {
+		// arrange
+		var received *http.Request
+		var body []byte
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			received = r
+			body, _ = io.ReadAll(r.Body)
+		}))
+		defer receiver.Close()
+
+		webhookRepo := repository.NewWebhookRepositoryMock()
+		payload := `{"id":"evt_1","type":"subscription.created","data":{"id":1}}`
+
+		webhookRepo.
+			On("ClaimDue", 50, 20*time.Second).
+			Return([]repository.DueDelivery{
+				{
+					WebhookDelivery: repository.WebhookDelivery{DeliveryID: 7, Event: service.EventSubscriptionCreated, Payload: payload, Status: "pending"},
+					URL:             receiver.URL,
+					Secret:          "whsec_test",
+				},
+			}, nil)
+		webhookRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.WebhookDelivery) bool {
+				return d.DeliveryID == 7 && d.Status == "delivered" && d.Attempts == 1 && *d.ResponseStatus == 200
+			}), time.Duration(0)).
+			Return(nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+
+		// act
+		err := webhookService.DeliverDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, payload, string(body))
+		assert.Equal(t, service.EventSubscriptionCreated, received.Header.Get(service.WebhookEventHeader))
+		assert.Equal(t, "7", received.Header.Get(service.WebhookDeliveryHeader))
+
+		mac := hmac.New(sha256.New, []byte("whsec_test"))
+		mac.Write([]byte(received.Header.Get(service.WebhookTimestampHeader) + "." + payload))
+		assert.Equal(t, "sha256="+hex.EncodeToString(mac.Sum(nil)), received.Header.Get(service.WebhookSignatureHeader))
+
+		webhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("Failed Delivery Is Retried With Backoff", func(t *testing.T) {
+		// arrange
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusInternalServerError)
+		}))
+		defer receiver.Close()
+
+		webhookRepo := repository.NewWebhookRepositoryMock()
+
+		webhookRepo.
+			On("ClaimDue", 50, 20*time.Second).
+			Return([]repository.DueDelivery{
+				{
+					WebhookDelivery: repository.WebhookDelivery{DeliveryID: 7, Payload: "{}", Status: "pending", Attempts: 2},
+					URL:             receiver.URL,
+					Secret:          "whsec_test",
+				},
+			}, nil)
+		webhookRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.WebhookDelivery) bool {
+				return d.Status == "pending" && d.Attempts == 3 && *d.ResponseStatus == 500 && d.LastError != ""
+			}), 2*time.Minute).
+			Return(nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+
+		// act
+		err := webhookService.DeliverDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		webhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("Last Attempt Fails The Delivery", func(t *testing.T) {
+		// arrange
+		receiver := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusGone)
+		}))
+		defer receiver.Close()
+
+		webhookRepo := repository.NewWebhookRepositoryMock()
+
+		webhookRepo.
+			On("ClaimDue", 50, 20*time.Second).
+			Return([]repository.DueDelivery{
+				{
+					WebhookDelivery: repository.WebhookDelivery{DeliveryID: 7, Payload: "{}", Status: "pending", Attempts: 7},
+					URL:             receiver.URL,
+				},
+			}, nil)
+		webhookRepo.
+			On("RecordAttempt", mock.MatchedBy(func(d repository.WebhookDelivery) bool {
+				return d.Status == "failed" && d.Attempts == 8
+			}), time.Duration(0)).
+			Return(nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+
+		// act
+		err := webhookService.DeliverDue(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		webhookRepo.AssertExpectations(t)
+	})
+}
+
+func TestCreateWebhook(t *testing.T) {
+	t.Run("Create Webhook Success", func(t *testing.T) {
+		// arrange
+		webhookRepo := repository.NewWebhookRepositoryMock()
+
+		webhookRepo.
+			On("Create", mock.MatchedBy(func(w *repository.Webhook) bool {
+				return w.URL == "http://localhost:9000/hooks" &&
+					assert.ObjectsAreEqual([]string{"subscription.created", "trial.ending"}, w.Events) &&
+					w.Active && strings.HasPrefix(w.Secret, "whsec_")
+			}), 10).
+			Return(&repository.Webhook{WebhookID: 1, URL: "http://localhost:9000/hooks", Secret: "whsec_abc", Active: true}, nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+
+		// act
+		res, err := webhookService.CreateWebhook(service.WebhookRequest{
+			URL:    " http://localhost:9000/hooks ",
+			Events: []string{"subscription.created", "Trial.Ending", "subscription.created"},
+		}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Equal(t, "whsec_abc", res.Secret)
+		webhookRepo.AssertExpectations(t)
+	})
+
+	t.Run("Create Webhook Invalid", func(t *testing.T) {
+		// arrange
+		webhookRepo := repository.NewWebhookRepositoryMock()
+		webhookService := service.NewWebhookService(webhookRepo, nil, nil)
+
+		// act
+		_, urlErr := webhookService.CreateWebhook(service.WebhookRequest{URL: "ftp://example.com"}, 10)
+		_, eventErr := webhookService.CreateWebhook(service.WebhookRequest{URL: "https://example.com", Events: []string{"renewal.paid"}}, 10)
+
+		// assert
+		assert.ErrorIs(t, urlErr, service.ErrInvalidWebhook)
+		assert.ErrorIs(t, eventErr, service.ErrInvalidWebhook)
+		webhookRepo.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+}
+
+func TestQueueUpcoming(t *testing.T) {
+	t.Run("Queue Upcoming Success", func(t *testing.T) {
+		// arrange
+		webhookRepo := repository.NewWebhookRepositoryMock()
+		subscriptionRepo := repository.NewSubscriptionRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+		inTwoDays := time.Now().UTC().AddDate(0, 0, 2).Format("2006-01-02")
+
+		webhookRepo.
+			On("GetUserIDs").
+			Return([]int{10}, nil)
+		userRepo.
+			On("GetByID", 10).
+			Return(&repository.User{ID: 10, ReminderDays: 3}, nil)
+		subscriptionRepo.
+			On("GetAll", 10).
+			Return([]repository.Subscription{
+				{SubscriptionID: 1, Name: "Netflix", BillingCycle: "monthly", BillingDate: inTwoDays, Status: "active"},
+				{SubscriptionID: 2, Name: "Notion", BillingCycle: "monthly", BillingDate: inTwoDays, Status: "active", Trial: true},
+				{SubscriptionID: 3, Name: "Gym", BillingCycle: "monthly", BillingDate: inTwoDays, Status: "canceled"},
+			}, nil)
+		webhookRepo.
+			On("Enqueue", 10, service.EventRenewalUpcoming, "renewal.upcoming:1:"+inTwoDays, mock.MatchedBy(func(payload string) bool {
+				var event service.WebhookEvent
+				return json.Unmarshal([]byte(payload), &event) == nil && event.Type == service.EventRenewalUpcoming
+			})).
+			Return(1, nil)
+		webhookRepo.
+			On("Enqueue", 10, service.EventTrialEnding, "trial.ending:2:"+inTwoDays, mock.AnythingOfType("string")).
+			Return(1, nil)
+
+		webhookService := service.NewWebhookService(webhookRepo, subscriptionRepo, userRepo)
+
+		// act
+		err := webhookService.QueueUpcoming(context.Background())
+
+		// assert
+		assert.NoError(t, err)
+		webhookRepo.AssertExpectations(t)
+		webhookRepo.AssertNumberOfCalls(t, "Enqueue", 2)
+	})
+}
@@ -0,0 +1,71 @@
+package service
+
+import "github.com/NetlutZ/subscout/internal/repository"
+
+const (
+	RoleOwner  = "owner"
+	RoleAdmin  = "admin"
+	RoleEditor = "editor"
+	RoleViewer = "viewer"
+)
+
+type WorkspaceResponse struct {
+	WorkspaceID int    `json:"id"`
+	Name        string `json:"name"`
+	Personal    bool   `json:"personal"`
+	Role        string `json:"role"` // the caller's role
+	Members     int    `json:"members"`
+}
+
+type WorkspaceRequest struct {
+	Name string `json:"name"`
+}
+
+type WorkspaceMemberResponse struct {
+	UserID int    `json:"user_id"`
+	Name   string `json:"name"`
+	Email  string `json:"email"`
+	Role   string `json:"role"`
+}
+
+type WorkspaceRoleRequest struct {
+	Role string `json:"role"`
+}
+
+// InvitationRequest invites someone by email; Role defaults to viewer.
+type InvitationRequest struct {
+	Email string `json:"email"`
+	Role  string `json:"role"`
+}
+
+// InvitationResponse carries the invitation token. Only its hash is
+// stored, so this is the one chance to send it to the invitee.
+type InvitationResponse struct {
+	InvitationID int    `json:"id"`
+	WorkspaceID  int    `json:"workspace_id"`
+	Email        string `json:"email"`
+	Role         string `json:"role"`
+	ExpiresAt    string `json:"expires_at"`
+	Token        string `json:"token"`
+}
+
+type AcceptInvitationRequest struct {
+	Token string `json:"token"`
+}
+
+type WorkspaceService interface {
+	GetWorkspaces(userID int) ([]WorkspaceResponse, error)
+	GetWorkspace(id int, userID int) (*WorkspaceResponse, error)
+	CreateWorkspace(req WorkspaceRequest, userID int) (*WorkspaceResponse, error)
+	RenameWorkspace(id int, req WorkspaceRequest, userID int) (*WorkspaceResponse, error)
+	DeleteWorkspace(id int, actor repository.Actor) error
+	GetMembers(id int, userID int) ([]WorkspaceMemberResponse, error)
+	SetMemberRole(id int, memberID int, req WorkspaceRoleRequest, userID int) ([]WorkspaceMemberResponse, error)
+	// RemoveMember removes someone from the workspace; members may also
+	// remove themselves to leave it.
+	RemoveMember(id int, memberID int, userID int) error
+	Invite(id int, req InvitationRequest, userID int) (*InvitationResponse, error)
+	// AcceptInvitation adds the user to the workspace the token was issued
+	// for, provided the invitation was sent to the user's email.
+	AcceptInvitation(token string, userID int) (*WorkspaceResponse, error)
+}
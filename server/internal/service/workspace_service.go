@@ -0,0 +1,375 @@
+package service
+
+import (
+	"crypto/rand"
+	"database/sql"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"net/mail"
+	"strings"
+	"time"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+)
+
+const (
+	maxWorkspaceName     = 100
+	invitationTokenBytes = 32
+	invitationLifetime   = 7 * 24 * time.Hour
+)
+
+var (
+	ErrWorkspaceNotFound       = errors.New("workspace not found")
+	ErrWorkspaceMemberNotFound = errors.New("workspace member not found")
+	ErrWorkspaceForbidden      = errors.New("your role in this workspace does not allow this")
+	ErrInvalidWorkspace        = errors.New("invalid workspace")
+	ErrInvitationNotFound      = errors.New("invitation not found or expired")
+)
+
+// roleRanks orders roles from least to most privileged.
+var roleRanks = map[string]int{
+	RoleViewer: 1,
+	RoleEditor: 2,
+	RoleAdmin:  3,
+	RoleOwner:  4,
+}
+
+type workspaceService struct {
+	workspaceRepo repository.WorkspaceRepository
+	userRepo      repository.UserRepository
+}
+
+func NewWorkspaceService(
+	workspaceRepo repository.WorkspaceRepository,
+	userRepo repository.UserRepository,
+) WorkspaceService {
+	return workspaceService{workspaceRepo: workspaceRepo, userRepo: userRepo}
+}
+
+func toWorkspaceResponse(w repository.Workspace) WorkspaceResponse {
+	return WorkspaceResponse{
+		WorkspaceID: w.WorkspaceID,
+		Name:        w.Name,
+		Personal:    w.Personal,
+		Role:        w.Role,
+		Members:     w.Members,
+	}
+}
+
+// canManage reports whether a member with role actor may assign, change or
+// remove the role target. Owners may do anything; everyone else only
+// manages roles below their own, and only admins manage at all.
+func canManage(actor, target string) bool {
+	if actor == RoleOwner {
+		return true
+	}
+	return roleRanks[actor] >= roleRanks[RoleAdmin] && roleRanks[actor] > roleRanks[target]
+}
+
+// membership returns the workspace with the caller's role, hiding
+// workspaces the caller does not belong to.
+func (s workspaceService) membership(id int, userID int) (*repository.Workspace, error) {
+	w, err := s.workspaceRepo.GetById(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w == nil {
+		return nil, ErrWorkspaceNotFound
+	}
+	return w, nil
+}
+
+func (s workspaceService) GetWorkspaces(userID int) ([]WorkspaceResponse, error) {
+	workspaces, err := s.workspaceRepo.GetAll(userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []WorkspaceResponse{}
+	for _, w := range workspaces {
+		res = append(res, toWorkspaceResponse(w))
+	}
+
+	return res, nil
+}
+
+func (s workspaceService) GetWorkspace(id int, userID int) (*WorkspaceResponse, error) {
+	w, err := s.membership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toWorkspaceResponse(*w)
+	return &res, nil
+}
+
+func (s workspaceService) CreateWorkspace(req WorkspaceRequest, userID int) (*WorkspaceResponse, error) {
+	name, err := normalizeWorkspaceName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	created, err := s.workspaceRepo.Create(&repository.Workspace{Name: name}, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	res := toWorkspaceResponse(*created)
+	return &res, nil
+}
+
+func (s workspaceService) RenameWorkspace(id int, req WorkspaceRequest, userID int) (*WorkspaceResponse, error) {
+	name, err := normalizeWorkspaceName(req.Name)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.membership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if roleRanks[w.Role] < roleRanks[RoleAdmin] {
+		return nil, ErrWorkspaceForbidden
+	}
+
+	if err := s.workspaceRepo.Rename(id, name); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceNotFound
+		}
+		return nil, err
+	}
+
+	w.Name = name
+	res := toWorkspaceResponse(*w)
+	return &res, nil
+}
+
+// DeleteWorkspace leaves moving or deleting the subscriptions of the
+// workspace to its members; it is refused while any remain.
+func (s workspaceService) DeleteWorkspace(id int, actor repository.Actor) error {
+	w, err := s.membership(id, actor.UserID)
+	if err != nil {
+		return err
+	}
+	if w.Role != RoleOwner {
+		return ErrWorkspaceForbidden
+	}
+	if w.Personal {
+		return fmt.Errorf("%w: a personal workspace cannot be deleted", ErrInvalidWorkspace)
+	}
+
+	err = s.workspaceRepo.Delete(id, actor)
+	switch {
+	case errors.Is(err, sql.ErrNoRows):
+		return ErrWorkspaceNotFound
+	case errors.Is(err, repository.ErrWorkspaceNotEmpty):
+		return fmt.Errorf("%w: move or delete its subscriptions first", ErrInvalidWorkspace)
+	}
+	return err
+}
+
+func (s workspaceService) GetMembers(id int, userID int) ([]WorkspaceMemberResponse, error) {
+	if _, err := s.membership(id, userID); err != nil {
+		return nil, err
+	}
+
+	members, err := s.workspaceRepo.GetMembers(id)
+	if err != nil {
+		return nil, err
+	}
+
+	res := []WorkspaceMemberResponse{}
+	for _, m := range members {
+		res = append(res, WorkspaceMemberResponse{
+			UserID: m.UserID,
+			Name:   m.Name,
+			Email:  m.Email,
+			Role:   m.Role,
+		})
+	}
+
+	return res, nil
+}
+
+func (s workspaceService) SetMemberRole(id int, memberID int, req WorkspaceRoleRequest, userID int) ([]WorkspaceMemberResponse, error) {
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if _, ok := roleRanks[role]; !ok {
+		return nil, fmt.Errorf("%w: role must be owner, admin, editor or viewer", ErrInvalidWorkspace)
+	}
+
+	w, err := s.membership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w.Personal {
+		return nil, fmt.Errorf("%w: a personal workspace has a single owner", ErrInvalidWorkspace)
+	}
+
+	target, owners, err := s.findMember(id, memberID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManage(w.Role, target.Role) || !canManage(w.Role, role) {
+		return nil, ErrWorkspaceForbidden
+	}
+	if target.Role == RoleOwner && role != RoleOwner && owners == 1 {
+		return nil, fmt.Errorf("%w: the workspace needs at least one owner", ErrInvalidWorkspace)
+	}
+
+	if err := s.workspaceRepo.SetMemberRole(id, memberID, role); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrWorkspaceMemberNotFound
+		}
+		return nil, err
+	}
+
+	return s.GetMembers(id, userID)
+}
+
+func (s workspaceService) RemoveMember(id int, memberID int, userID int) error {
+	w, err := s.membership(id, userID)
+	if err != nil {
+		return err
+	}
+
+	target, owners, err := s.findMember(id, memberID)
+	if err != nil {
+		return err
+	}
+	if memberID != userID && !canManage(w.Role, target.Role) {
+		return ErrWorkspaceForbidden
+	}
+	if target.Role == RoleOwner && owners == 1 {
+		return fmt.Errorf("%w: the workspace needs at least one owner", ErrInvalidWorkspace)
+	}
+
+	err = s.workspaceRepo.RemoveMember(id, memberID)
+	if errors.Is(err, sql.ErrNoRows) {
+		return ErrWorkspaceMemberNotFound
+	}
+	return err
+}
+
+// findMember returns the member with memberID and how many owners the
+// workspace has.
+func (s workspaceService) findMember(id int, memberID int) (*repository.WorkspaceMember, int, error) {
+	members, err := s.workspaceRepo.GetMembers(id)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	var target *repository.WorkspaceMember
+	owners := 0
+	for i, m := range members {
+		if m.Role == RoleOwner {
+			owners++
+		}
+		if m.UserID == memberID {
+			target = &members[i]
+		}
+	}
+	if target == nil {
+		return nil, 0, ErrWorkspaceMemberNotFound
+	}
+
+	return target, owners, nil
+}
+
+func (s workspaceService) Invite(id int, req InvitationRequest, userID int) (*InvitationResponse, error) {
+	role := strings.ToLower(strings.TrimSpace(req.Role))
+	if role == "" {
+		role = RoleViewer
+	}
+	if _, ok := roleRanks[role]; !ok {
+		return nil, fmt.Errorf("%w: role must be owner, admin, editor or viewer", ErrInvalidWorkspace)
+	}
+
+	addr, err := mail.ParseAddress(strings.TrimSpace(req.Email))
+	if err != nil {
+		return nil, fmt.Errorf("%w: a valid email is required", ErrInvalidWorkspace)
+	}
+
+	w, err := s.membership(id, userID)
+	if err != nil {
+		return nil, err
+	}
+	if w.Personal {
+		return nil, fmt.Errorf("%w: a personal workspace cannot be shared", ErrInvalidWorkspace)
+	}
+	if !canManage(w.Role, role) {
+		return nil, ErrWorkspaceForbidden
+	}
+
+	raw := make([]byte, invitationTokenBytes)
+	if _, err := rand.Read(raw); err != nil {
+		return nil, err
+	}
+	token := hex.EncodeToString(raw)
+
+	inv, err := s.workspaceRepo.CreateInvitation(&repository.WorkspaceInvitation{
+		WorkspaceID: id,
+		Email:       strings.ToLower(addr.Address),
+		Role:        role,
+		InvitedBy:   userID,
+		ExpiresAt:   time.Now().UTC().Add(invitationLifetime).Format(time.RFC3339),
+	}, hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+
+	return &InvitationResponse{
+		InvitationID: inv.InvitationID,
+		WorkspaceID:  inv.WorkspaceID,
+		Email:        inv.Email,
+		Role:         inv.Role,
+		ExpiresAt:    inv.ExpiresAt,
+		Token:        token,
+	}, nil
+}
+
+func (s workspaceService) AcceptInvitation(token string, userID int) (*WorkspaceResponse, error) {
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return nil, ErrInvitationNotFound
+	}
+
+	inv, err := s.workspaceRepo.GetInvitationByTokenHash(hashToken(token))
+	if err != nil {
+		return nil, err
+	}
+	if inv == nil {
+		return nil, ErrInvitationNotFound
+	}
+
+	user, err := s.userRepo.GetByID(userID)
+	if err != nil {
+		return nil, err
+	}
+	if user == nil {
+		return nil, ErrUserNotFound
+	}
+	if !strings.EqualFold(strings.TrimSpace(user.Email), inv.Email) {
+		return nil, ErrWorkspaceForbidden
+	}
+
+	if err := s.workspaceRepo.AcceptInvitation(inv.InvitationID, userID); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvitationNotFound
+		}
+		return nil, err
+	}
+
+	return s.GetWorkspace(inv.WorkspaceID, userID)
+}
+
+func normalizeWorkspaceName(name string) (string, error) {
+	name = strings.TrimSpace(name)
+	switch {
+	case name == "":
+		return "", fmt.Errorf("%w: name is required", ErrInvalidWorkspace)
+	case len(name) > maxWorkspaceName:
+		return "", fmt.Errorf("%w: name must be at most %d characters", ErrInvalidWorkspace, maxWorkspaceName)
+	}
+	return name, nil
+}
@@ -0,0 +1,198 @@
+package service_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/repository"
+	"github.com/NetlutZ/subscout/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestInvite(t *testing.T) {
+	t.Run("Invite Success", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 10).
+			Return(&repository.Workspace{WorkspaceID: 2, Name: "Family", Role: service.RoleAdmin}, nil)
+		workspaceRepo.
+			On("CreateInvitation", mock.MatchedBy(func(inv *repository.WorkspaceInvitation) bool {
+				return inv.WorkspaceID == 2 && inv.Email == "bob@example.com" &&
+					inv.Role == service.RoleEditor && inv.InvitedBy == 10
+			}), mock.AnythingOfType("string")).
+			Return(&repository.WorkspaceInvitation{InvitationID: 5, WorkspaceID: 2, Email: "bob@example.com", Role: service.RoleEditor}, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		res, err := workspaceService.Invite(2, service.InvitationRequest{Email: " Bob@Example.com ", Role: "editor"}, 10)
+
+		// assert
+		assert.NoError(t, err)
+		assert.Len(t, res.Token, 64)
+		workspaceRepo.AssertExpectations(t)
+	})
+
+	t.Run("Invite Forbidden", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 10).
+			Return(&repository.Workspace{WorkspaceID: 2, Role: service.RoleAdmin}, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		_, err := workspaceService.Invite(2, service.InvitationRequest{Email: "bob@example.com", Role: "admin"}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		workspaceRepo.AssertNotCalled(t, "CreateInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Invite Personal Workspace", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 1, 10).
+			Return(&repository.Workspace{WorkspaceID: 1, Personal: true, Role: service.RoleOwner}, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		_, err := workspaceService.Invite(1, service.InvitationRequest{Email: "bob@example.com"}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidWorkspace)
+	})
+}
+
+func TestSetMemberRole(t *testing.T) {
+	members := []repository.WorkspaceMember{
+		{UserID: 10, Name: "Alice", Role: service.RoleOwner},
+		{UserID: 11, Name: "Bob", Role: service.RoleEditor},
+	}
+
+	t.Run("Set Member Role Last Owner", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 10).
+			Return(&repository.Workspace{WorkspaceID: 2, Role: service.RoleOwner}, nil)
+		workspaceRepo.On("GetMembers", 2).Return(members, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		_, err := workspaceService.SetMemberRole(2, 10, service.WorkspaceRoleRequest{Role: "viewer"}, 10)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidWorkspace)
+		workspaceRepo.AssertNotCalled(t, "SetMemberRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("Set Member Role Editor Forbidden", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 11).
+			Return(&repository.Workspace{WorkspaceID: 2, Role: service.RoleEditor}, nil)
+		workspaceRepo.On("GetMembers", 2).Return(members, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		_, err := workspaceService.SetMemberRole(2, 11, service.WorkspaceRoleRequest{Role: "admin"}, 11)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+	})
+}
+
+func TestDeleteWorkspace(t *testing.T) {
+	t.Run("Delete Workspace With Subscriptions", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 10).
+			Return(&repository.Workspace{WorkspaceID: 2, Name: "Family", Role: service.RoleOwner}, nil)
+		workspaceRepo.
+			On("Delete", 2, actor).
+			Return(repository.ErrWorkspaceNotEmpty)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		err := workspaceService.DeleteWorkspace(2, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvalidWorkspace)
+		workspaceRepo.AssertExpectations(t)
+	})
+
+	t.Run("Delete Workspace Forbidden", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetById", 2, 10).
+			Return(&repository.Workspace{WorkspaceID: 2, Name: "Family", Role: service.RoleAdmin}, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		err := workspaceService.DeleteWorkspace(2, actor)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		workspaceRepo.AssertNotCalled(t, "Delete", mock.Anything, mock.Anything)
+	})
+}
+
+func TestAcceptInvitation(t *testing.T) {
+	t.Run("Accept Invitation Wrong Email", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+		userRepo := repository.NewUserRepositoryMock()
+
+		workspaceRepo.
+			On("GetInvitationByTokenHash", mock.AnythingOfType("string")).
+			Return(&repository.WorkspaceInvitation{InvitationID: 5, WorkspaceID: 2, Email: "bob@example.com"}, nil)
+		userRepo.
+			On("GetByID", 12).
+			Return(&repository.User{ID: 12, Email: "eve@example.com"}, nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, userRepo)
+
+		// act
+		_, err := workspaceService.AcceptInvitation("token", 12)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrWorkspaceForbidden)
+		workspaceRepo.AssertNotCalled(t, "AcceptInvitation", mock.Anything, mock.Anything)
+	})
+
+	t.Run("Accept Invitation Unknown Token", func(t *testing.T) {
+		// arrange
+		workspaceRepo := repository.NewWorkspaceRepositoryMock()
+
+		workspaceRepo.
+			On("GetInvitationByTokenHash", mock.AnythingOfType("string")).
+			Return((*repository.WorkspaceInvitation)(nil), nil)
+
+		workspaceService := service.NewWorkspaceService(workspaceRepo, nil)
+
+		// act
+		_, err := workspaceService.AcceptInvitation("token", 12)
+
+		// assert
+		assert.ErrorIs(t, err, service.ErrInvitationNotFound)
+	})
+}
@@ -0,0 +1,119 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// camtDocument maps the parts of an ISO 20022 camt.053 statement that are
+// needed here. Tags carry no namespace so every camt.053 version matches.
+type camtDocument struct {
+	Statements []struct {
+		Entries []camtEntry `xml:"Ntry"`
+	} `xml:"BkToCstmrStmt>Stmt"`
+}
+
+type camtEntry struct {
+	Reference string `xml:"NtryRef"`
+	Amount    struct {
+		Value    string `xml:",chardata"`
+		Currency string `xml:"Ccy,attr"`
+	} `xml:"Amt"`
+	CreditDebit string `xml:"CdtDbtInd"`
+	// camt.053.001.02 has <Sts>BOOK</Sts>, later versions <Sts><Cd>BOOK</Cd></Sts>
+	Status struct {
+		Value string `xml:",chardata"`
+		Code  string `xml:"Cd"`
+	} `xml:"Sts"`
+	BookingDate struct {
+		Date     string `xml:"Dt"`
+		DateTime string `xml:"DtTm"`
+	} `xml:"BookgDt"`
+	Details []struct {
+		Creditor   string   `xml:"RltdPties>Cdtr>Nm"`
+		CreditorV9 string   `xml:"RltdPties>Cdtr>Pty>Nm"`
+		Debtor     string   `xml:"RltdPties>Dbtr>Nm"`
+		Remittance []string `xml:"RmtInf>Ustrd"`
+	} `xml:"NtryDtls>TxDtls"`
+	AdditionalInfo string `xml:"AddtlNtryInf"`
+}
+
+// ParseCAMT053 reads an ISO 20022 camt.053 bank-to-customer statement.
+// Only booked entries are returned.
+func ParseCAMT053(data []byte) ([]Transaction, error) {
+	var doc camtDocument
+	if err := xml.NewDecoder(bytes.NewReader(data)).Decode(&doc); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+	}
+
+	var txns []Transaction
+	for _, stmt := range doc.Statements {
+		for _, entry := range stmt.Entries {
+			status := strings.TrimSpace(entry.Status.Code)
+			if status == "" {
+				status = strings.TrimSpace(entry.Status.Value)
+			}
+			if status != "" && !strings.EqualFold(status, "BOOK") {
+				continue
+			}
+
+			amount, err := ParseAmount(entry.Amount.Value)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid amount %q", ErrInvalidStatement, entry.Amount.Value)
+			}
+
+			date, err := parseCAMTDate(entry.BookingDate.Date, entry.BookingDate.DateTime)
+			if err != nil {
+				return nil, fmt.Errorf("%w: invalid booking date", ErrInvalidStatement)
+			}
+
+			debit := strings.EqualFold(entry.CreditDebit, "DBIT")
+			txns = append(txns, Transaction{
+				ID:          entry.Reference,
+				Date:        date,
+				Description: camtDescription(entry, debit),
+				Amount:      abs(amount),
+				Currency:    strings.ToUpper(entry.Amount.Currency),
+				Debit:       debit,
+			})
+		}
+	}
+
+	return txns, nil
+}
+
+// camtDescription prefers the counterparty name, falling back to the
+// remittance text and the free form entry information.
+func camtDescription(entry camtEntry, debit bool) string {
+	for _, d := range entry.Details {
+		if debit {
+			if d.Creditor != "" {
+				return d.Creditor
+			}
+			if d.CreditorV9 != "" {
+				return d.CreditorV9
+			}
+		} else if d.Debtor != "" {
+			return d.Debtor
+		}
+	}
+	for _, d := range entry.Details {
+		if len(d.Remittance) > 0 {
+			return strings.Join(d.Remittance, " ")
+		}
+	}
+	return strings.TrimSpace(entry.AdditionalInfo)
+}
+
+func parseCAMTDate(date, dateTime string) (time.Time, error) {
+	if date != "" {
+		return time.Parse("2006-01-02", strings.TrimSpace(date))
+	}
+	if len(dateTime) >= 10 {
+		return time.Parse("2006-01-02", dateTime[:10])
+	}
+	return time.Time{}, fmt.Errorf("missing date")
+}
@@ -0,0 +1,244 @@
+package statement
+
+import (
+	"bytes"
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Header names recognised when no mapping is given, in order of preference.
+var csvColumnAliases = map[string][]string{
+	"date":        {"date", "transaction date", "posted date", "posting date", "booking date", "value date"},
+	"description": {"description", "merchant", "payee", "name", "details", "narrative", "memo"},
+	"amount":      {"amount", "value", "transaction amount"},
+	"debit":       {"debit", "withdrawal", "withdrawals", "money out"},
+	"credit":      {"credit", "deposit", "deposits", "money in"},
+	"currency":    {"currency", "ccy"},
+}
+
+// Date layouts in order of preference. One layout is used for the whole
+// file, so day-first wins only where every date allows it.
+var csvDateLayouts = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"2/1/2006",
+	"02-01-2006",
+	"02.01.2006",
+	"2006/01/02",
+	"01/02/2006",
+	"1/2/2006",
+	"02 Jan 2006",
+	"Jan 2, 2006",
+}
+
+// ParseCSV reads a CSV export. mapping maps the fields date, description,
+// amount, debit, credit and currency to column headers; unmapped fields
+// are looked up among common header names. With a single amount column,
+// negative values are debits, unless the file has no negative values at
+// all, in which case every row is treated as a debit (credit card style).
+func ParseCSV(data []byte, mapping map[string]string) ([]Transaction, error) {
+	reader := csv.NewReader(bytes.NewReader(bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))))
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("%w: missing header row", ErrInvalidStatement)
+	}
+
+	columns := map[string]int{}
+	for i, h := range header {
+		columns[strings.ToLower(strings.TrimSpace(h))] = i
+	}
+
+	index := map[string]int{}
+	for field, aliases := range csvColumnAliases {
+		if mapped, ok := mapping[field]; ok {
+			i, found := columns[strings.ToLower(strings.TrimSpace(mapped))]
+			if !found {
+				return nil, fmt.Errorf("%w: column %q not found", ErrInvalidStatement, mapped)
+			}
+			index[field] = i
+			continue
+		}
+		for _, alias := range aliases {
+			if i, ok := columns[alias]; ok {
+				index[field] = i
+				break
+			}
+		}
+	}
+
+	_, hasAmount := index["amount"]
+	_, hasDebit := index["debit"]
+	if _, ok := index["date"]; !ok {
+		return nil, fmt.Errorf("%w: no date column", ErrInvalidStatement)
+	}
+	if _, ok := index["description"]; !ok {
+		return nil, fmt.Errorf("%w: no description column", ErrInvalidStatement)
+	}
+	if !hasAmount && !hasDebit {
+		return nil, fmt.Errorf("%w: no amount column", ErrInvalidStatement)
+	}
+
+	var records [][]string
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidStatement, err)
+		}
+		records = append(records, record)
+	}
+
+	var dates []string
+	for _, record := range records {
+		if i := index["date"]; i < len(record) && strings.TrimSpace(record[i]) != "" {
+			dates = append(dates, strings.TrimSpace(record[i]))
+		}
+	}
+	layout := csvDateLayout(dates)
+
+	var txns []Transaction
+	var signed []float64
+	for n, record := range records {
+		line := n + 2
+		value := func(field string) string {
+			i, ok := index[field]
+			if !ok || i >= len(record) {
+				return ""
+			}
+			return strings.TrimSpace(record[i])
+		}
+
+		if value("date") == "" && value("description") == "" {
+			continue
+		}
+
+		date, err := time.Parse(layout, value("date"))
+		if err != nil {
+			return nil, fmt.Errorf("%w: line %d: invalid date %q", ErrInvalidStatement, line, value("date"))
+		}
+
+		var amount float64
+		if value("debit") != "" {
+			debit, err := ParseAmount(value("debit"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid debit %q", ErrInvalidStatement, line, value("debit"))
+			}
+			amount = -abs(debit)
+		} else if value("credit") != "" {
+			credit, err := ParseAmount(value("credit"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid credit %q", ErrInvalidStatement, line, value("credit"))
+			}
+			amount = abs(credit)
+		} else if hasAmount {
+			amount, err = ParseAmount(value("amount"))
+			if err != nil {
+				return nil, fmt.Errorf("%w: line %d: invalid amount %q", ErrInvalidStatement, line, value("amount"))
+			}
+		} else {
+			continue
+		}
+
+		txns = append(txns, Transaction{
+			Date:        date,
+			Description: value("description"),
+			Amount:      abs(amount),
+			Currency:    strings.ToUpper(value("currency")),
+		})
+		signed = append(signed, amount)
+	}
+
+	anyNegative := false
+	for _, a := range signed {
+		if a < 0 {
+			anyNegative = true
+			break
+		}
+	}
+	for i := range txns {
+		txns[i].Debit = signed[i] < 0 || (!anyNegative && !hasDebit)
+	}
+
+	return txns, nil
+}
+
+// csvDateLayout picks the layout that reads every date in the file, so
+// "01/12/2025" and "01/13/2025" are read the same way. When none does, the
+// layout reading the most dates is used and the rest are reported.
+func csvDateLayout(dates []string) string {
+	best, bestCount := csvDateLayouts[0], -1
+	for _, layout := range csvDateLayouts {
+		count := 0
+		for _, d := range dates {
+			if _, err := time.Parse(layout, d); err == nil {
+				count++
+			}
+		}
+		if count > bestCount {
+			best, bestCount = layout, count
+		}
+		if count == len(dates) {
+			break
+		}
+	}
+	return best
+}
+
+// ParseAmount understands "1,234.50", "1.234,50", "9,99", "-12.00",
+// "(12.00)" and a trailing minus sign as well as leading currency symbols.
+// The last "," or "." is the decimal separator when one or two digits
+// follow it; every other "," and "." separates thousands.
+func ParseAmount(value string) (float64, error) {
+	value = strings.TrimSpace(value)
+	negative := false
+
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+	if strings.HasSuffix(value, "-") {
+		negative = true
+		value = strings.TrimSuffix(value, "-")
+	}
+
+	value = strings.Map(func(r rune) rune {
+		if (r >= '0' && r <= '9') || r == '.' || r == ',' || r == '-' {
+			return r
+		}
+		return -1
+	}, value)
+
+	decimals := ""
+	if i := strings.LastIndexAny(value, ".,"); i >= 0 {
+		if digits := value[i+1:]; len(digits) >= 1 && len(digits) <= 2 && strings.Trim(digits, "0123456789") == "" {
+			decimals = "." + digits
+			value = value[:i]
+		}
+	}
+	value = strings.NewReplacer(".", "", ",", "").Replace(value) + decimals
+
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
+
+func abs(v float64) float64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
@@ -0,0 +1,242 @@
+package statement
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Cadence is a billing interval recognised by Detect. Names match the
+// billing_cycle values used for subscriptions.
+type Cadence struct {
+	Name    string
+	Days    float64
+	MinDays int
+	MaxDays int
+	// MinCount is the number of charges needed before a cadence is
+	// trusted; long cadences rarely repeat often within one statement.
+	MinCount int
+}
+
+var cadences = []Cadence{
+	{Name: "weekly", Days: 7, MinDays: 6, MaxDays: 8, MinCount: 3},
+	{Name: "biweekly", Days: 14, MinDays: 13, MaxDays: 16, MinCount: 3},
+	{Name: "monthly", Days: 30.44, MinDays: 27, MaxDays: 33, MinCount: 3},
+	{Name: "quarterly", Days: 91.31, MinDays: 85, MaxDays: 97, MinCount: 2},
+	{Name: "yearly", Days: 365.25, MinDays: 355, MaxDays: 376, MinCount: 2},
+}
+
+const (
+	// minRegularity is the share of intervals that must fit the cadence.
+	minRegularity = 0.75
+	// maxAmountVariation is the largest coefficient of variation of the
+	// amounts that still looks like a subscription rather than shopping.
+	maxAmountVariation = 0.25
+)
+
+// Recurring is a group of debits that repeat on a regular cadence.
+type Recurring struct {
+	MerchantKey string
+	Name        string
+	Cadence     string
+	// Amount is the most recent charge, which reflects price changes.
+	Amount   float64
+	Currency string
+	// AmountVariation is the coefficient of variation of the amounts.
+	AmountVariation float64
+	LastDate        time.Time
+	NextDate        time.Time
+	Confidence      float64
+	Transactions    []Transaction
+}
+
+// Detect groups debits by normalized merchant, folding "name city" keys
+// into a plain "name" key from the same statement when the charges of both
+// recur together, and returns every group whose charges follow a known
+// cadence with a stable amount, most confident first.
+func Detect(txns []Transaction) []Recurring {
+	groups := map[string][]Transaction{}
+	for _, t := range txns {
+		if !t.Debit || t.Amount <= 0 {
+			continue
+		}
+		key := NormalizeMerchant(t.Description)
+		if key == "" {
+			continue
+		}
+		groups[key] = append(groups[key], t)
+	}
+
+	// a merchant that sometimes adds its city without a reference in
+	// between ("spotify stockholm") belongs with its bare charges, but
+	// only if the charges still recur together: "apple music" is another
+	// product than the bare "apple" and keeps its own group
+	keys := make([]string, 0, len(groups))
+	for key := range groups {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		words := strings.Fields(key)
+		if len(words) < 2 {
+			continue
+		}
+		bare, ok := groups[words[0]]
+		if !ok {
+			continue
+		}
+		merged := append(append([]Transaction(nil), bare...), groups[key]...)
+		if _, recurring := detectGroup(words[0], merged); recurring {
+			groups[words[0]] = merged
+			delete(groups, key)
+		}
+	}
+
+	var found []Recurring
+	for key, group := range groups {
+		if r, ok := detectGroup(key, group); ok {
+			found = append(found, r)
+		}
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Confidence != found[j].Confidence {
+			return found[i].Confidence > found[j].Confidence
+		}
+		return found[i].MerchantKey < found[j].MerchantKey
+	})
+
+	return found
+}
+
+func detectGroup(key string, txns []Transaction) (Recurring, bool) {
+	if len(txns) < 2 {
+		return Recurring{}, false
+	}
+
+	sort.Slice(txns, func(i, j int) bool { return txns[i].Date.Before(txns[j].Date) })
+
+	intervals := make([]int, 0, len(txns)-1)
+	for i := 1; i < len(txns); i++ {
+		days := int(txns[i].Date.Sub(txns[i-1].Date).Hours() / 24)
+		// two charges on the same day are one purchase split in two or a
+		// different product, either way not the cadence
+		if days == 0 {
+			continue
+		}
+		intervals = append(intervals, days)
+	}
+	if len(intervals) == 0 {
+		return Recurring{}, false
+	}
+
+	cadence, ok := matchCadence(median(intervals))
+	if !ok || len(txns) < cadence.MinCount {
+		return Recurring{}, false
+	}
+
+	fitting := 0
+	for _, d := range intervals {
+		if d >= cadence.MinDays && d <= cadence.MaxDays {
+			fitting++
+		}
+	}
+	regularity := float64(fitting) / float64(len(intervals))
+	if regularity < minRegularity {
+		return Recurring{}, false
+	}
+
+	amounts := make([]float64, len(txns))
+	for i, t := range txns {
+		amounts[i] = t.Amount
+	}
+	variation := coefficientOfVariation(amounts)
+	if variation > maxAmountVariation {
+		return Recurring{}, false
+	}
+
+	last := txns[len(txns)-1]
+	confidence := regularity * (1 - variation/maxAmountVariation*0.5)
+	// more evidence than the minimum makes the guess safer
+	confidence *= math.Min(1, 0.6+0.2*float64(len(txns)-cadence.MinCount+1))
+
+	return Recurring{
+		MerchantKey:     key,
+		Name:            MerchantName(key),
+		Cadence:         cadence.Name,
+		Amount:          last.Amount,
+		Currency:        mostCommonCurrency(txns),
+		AmountVariation: round(variation, 4),
+		LastDate:        last.Date,
+		NextDate:        nextDate(last.Date, cadence),
+		Confidence:      round(confidence, 2),
+		Transactions:    txns,
+	}, true
+}
+
+func matchCadence(days int) (Cadence, bool) {
+	for _, c := range cadences {
+		if days >= c.MinDays && days <= c.MaxDays {
+			return c, true
+		}
+	}
+	return Cadence{}, false
+}
+
+func nextDate(last time.Time, c Cadence) time.Time {
+	switch c.Name {
+	case "monthly":
+		return last.AddDate(0, 1, 0)
+	case "quarterly":
+		return last.AddDate(0, 3, 0)
+	case "yearly":
+		return last.AddDate(1, 0, 0)
+	}
+	return last.AddDate(0, 0, int(c.Days))
+}
+
+func median(values []int) int {
+	sorted := append([]int(nil), values...)
+	sort.Ints(sorted)
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+func coefficientOfVariation(values []float64) float64 {
+	var sum float64
+	for _, v := range values {
+		sum += v
+	}
+	mean := sum / float64(len(values))
+	if mean == 0 {
+		return 0
+	}
+
+	var squares float64
+	for _, v := range values {
+		squares += (v - mean) * (v - mean)
+	}
+	return math.Sqrt(squares/float64(len(values))) / mean
+}
+
+func mostCommonCurrency(txns []Transaction) string {
+	counts := map[string]int{}
+	best := ""
+	for _, t := range txns {
+		c := strings.ToUpper(t.Currency)
+		counts[c]++
+		if counts[c] > counts[best] || (counts[c] == counts[best] && c < best) {
+			best = c
+		}
+	}
+	return best
+}
+
+func round(v float64, places int) float64 {
+	p := math.Pow(10, float64(places))
+	return math.Round(v*p) / p
+}
@@ -0,0 +1,78 @@
+package statement
+
+import (
+	"strings"
+	"unicode"
+)
+
+// Words that banks add around merchant names and that say nothing about
+// who was paid.
+var merchantNoise = map[string]bool{
+	"pos": true, "purchase": true, "debit": true, "credit": true, "card": true,
+	"payment": true, "recurring": true, "direct": true, "dd": true, "sepa": true,
+	"visa": true, "mastercard": true, "mc": true, "www": true, "inc": true,
+	"ltd": true, "llc": true, "bv": true, "gmbh": true, "online": true,
+	"subscription": true, "bill": true, "billing": true, "autopay": true,
+	"ach": true, "trx": true, "ref": true, "the": true,
+}
+
+// Domain endings. A merchant that bills as "netflix.com" is named by what
+// comes before the ending, whatever follows it.
+var merchantDomains = map[string]bool{
+	"com": true, "net": true, "org": true, "co": true, "io": true, "th": true,
+}
+
+const merchantKeyWords = 2
+
+// NormalizeMerchant reduces a statement description to a short key that
+// is stable across charges from the same merchant, e.g.
+// "NETFLIX.COM 866-579-7172 CA" and "Netflix.com*Subscription" both become
+// "netflix" and "SPOTIFY P0123ABC STOCKHOLM" becomes "spotify". The name
+// ends at a domain ending or at the first token containing digits (dates,
+// references, phone numbers), since what follows is usually a location.
+// Two-letter tokens after the name are state or country codes and are
+// skipped.
+func NormalizeMerchant(description string) string {
+	tokens := strings.FieldsFunc(strings.ToLower(description), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	var words []string
+	for _, t := range tokens {
+		reference := strings.IndexFunc(t, unicode.IsDigit) >= 0
+		if len(words) > 0 && (reference || merchantDomains[t]) {
+			break
+		}
+		if reference || merchantNoise[t] || merchantDomains[t] || len(t) < 2 {
+			continue
+		}
+		if len(words) > 0 && len([]rune(t)) < 3 {
+			continue
+		}
+		words = append(words, t)
+		if len(words) == merchantKeyWords {
+			break
+		}
+	}
+
+	return strings.Join(words, " ")
+}
+
+// MerchantName turns a merchant key into a display name.
+func MerchantName(key string) string {
+	words := strings.Fields(key)
+	for i, w := range words {
+		r := []rune(w)
+		r[0] = unicode.ToUpper(r[0])
+		words[i] = string(r)
+	}
+	return strings.Join(words, " ")
+}
+
+// SameMerchant reports whether two merchant keys refer to the same
+// merchant. Only whole keys match: "apple" (APPLE.COM/BILL) is not
+// "apple music", since a shared brand word is not enough to tell which
+// product a charge pays for.
+func SameMerchant(a, b string) bool {
+	return a != "" && a == b
+}
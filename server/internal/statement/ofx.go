@@ -0,0 +1,104 @@
+package statement
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+)
+
+var (
+	ofxTransactionPattern = regexp.MustCompile(`(?is)<STMTTRN>(.*?)</STMTTRN>`)
+	ofxCurrencyPattern    = regexp.MustCompile(`(?i)<CURDEF>\s*([A-Za-z]{3})`)
+)
+
+// ParseOFX reads OFX and QFX files. Both the SGML flavour (OFX 1.x, where
+// leaf elements are not closed) and the XML flavour (OFX 2.x) are handled
+// by reading each <STMTTRN> block tag by tag.
+func ParseOFX(data []byte) ([]Transaction, error) {
+	content := string(data)
+	if !strings.Contains(strings.ToUpper(content), "<OFX>") {
+		return nil, fmt.Errorf("%w: missing <OFX> element", ErrInvalidStatement)
+	}
+
+	currency := ""
+	if m := ofxCurrencyPattern.FindStringSubmatch(content); m != nil {
+		currency = strings.ToUpper(m[1])
+	}
+
+	var txns []Transaction
+	for _, block := range ofxTransactionPattern.FindAllStringSubmatch(content, -1) {
+		fields := ofxFields(block[1])
+
+		date, err := parseOFXDate(fields["DTPOSTED"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid DTPOSTED %q", ErrInvalidStatement, fields["DTPOSTED"])
+		}
+
+		amount, err := ParseAmount(fields["TRNAMT"])
+		if err != nil {
+			return nil, fmt.Errorf("%w: invalid TRNAMT %q", ErrInvalidStatement, fields["TRNAMT"])
+		}
+
+		description := fields["NAME"]
+		if description == "" {
+			description = fields["PAYEE"]
+		}
+		if memo := fields["MEMO"]; memo != "" && description == "" {
+			description = memo
+		}
+
+		txnCurrency := currency
+		if c := fields["CURSYM"]; c != "" {
+			txnCurrency = strings.ToUpper(c)
+		}
+
+		txns = append(txns, Transaction{
+			ID:          fields["FITID"],
+			Date:        date,
+			Description: description,
+			Amount:      abs(amount),
+			Currency:    txnCurrency,
+			Debit:       amount < 0,
+		})
+	}
+
+	return txns, nil
+}
+
+// ofxFields collects the text value of every leaf element in a block.
+// Nested aggregates such as <CURRENCY> are flattened.
+func ofxFields(block string) map[string]string {
+	fields := map[string]string{}
+
+	for _, part := range strings.Split(block, "<")[1:] {
+		end := strings.Index(part, ">")
+		if end < 0 || strings.HasPrefix(part, "/") {
+			continue
+		}
+
+		tag := strings.ToUpper(strings.TrimSpace(part[:end]))
+		value := strings.TrimSpace(part[end+1:])
+		if value == "" {
+			continue
+		}
+		if _, ok := fields[tag]; !ok {
+			fields[tag] = unescapeOFX(value)
+		}
+	}
+
+	return fields
+}
+
+func unescapeOFX(value string) string {
+	return strings.NewReplacer("&amp;", "&", "&lt;", "<", "&gt;", ">").Replace(value)
+}
+
+// parseOFXDate reads dates like 20250131, 20250131120000 or
+// 20250131120000.000[-5:EST]; only the date part matters here.
+func parseOFXDate(value string) (time.Time, error) {
+	if len(value) < 8 {
+		return time.Time{}, fmt.Errorf("date too short")
+	}
+	return time.Parse("20060102", value[:8])
+}
@@ -0,0 +1,67 @@
+// Package statement reads bank and credit card statements and finds the
+// recurring charges in them.
+package statement
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	FormatCSV  = "csv"
+	FormatOFX  = "ofx"
+	FormatQFX  = "qfx"
+	FormatCAMT = "camt053"
+)
+
+var ErrInvalidStatement = errors.New("invalid statement")
+
+// Transaction is a single booked statement line. Amount is always positive,
+// Debit tells whether money left the account.
+type Transaction struct {
+	ID          string    `json:"id,omitempty"`
+	Date        time.Time `json:"date"`
+	Description string    `json:"description"`
+	Amount      float64   `json:"amount"`
+	Currency    string    `json:"currency"`
+	Debit       bool      `json:"debit"`
+}
+
+// Parse reads a statement in the given format. When format is empty it is
+// guessed from the content. mapping is only used for CSV files, see
+// ParseCSV.
+func Parse(format string, data []byte, mapping map[string]string) ([]Transaction, error) {
+	if format == "" {
+		format = DetectFormat(data)
+	}
+
+	switch strings.ToLower(format) {
+	case FormatCSV:
+		return ParseCSV(data, mapping)
+	case FormatOFX, FormatQFX:
+		return ParseOFX(data)
+	case FormatCAMT, "camt", "xml":
+		return ParseCAMT053(data)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported format %q", ErrInvalidStatement, format)
+}
+
+// DetectFormat guesses the statement format from its first bytes.
+func DetectFormat(data []byte) string {
+	head := bytes.ToUpper(bytes.TrimSpace(data))
+	if len(head) > 512 {
+		head = head[:512]
+	}
+
+	switch {
+	case bytes.Contains(head, []byte("OFXHEADER")), bytes.Contains(head, []byte("<OFX>")):
+		return FormatOFX
+	case bytes.Contains(head, []byte("CAMT.053")), bytes.Contains(head, []byte("BKTOCSTMRSTMT")):
+		return FormatCAMT
+	}
+	return FormatCSV
+}
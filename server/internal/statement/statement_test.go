@@ -0,0 +1,235 @@
+package statement_test
+
+import (
+	"testing"
+
+	"github.com/NetlutZ/subscout/internal/statement"
+	"github.com/stretchr/testify/assert"
+)
+
+const statementCSV = `Date,Description,Amount,Currency
+2025-01-03,NETFLIX.COM 866-579-7172,-419.00,THB
+2025-01-05,SALARY ACME,50000.00,THB
+2025-01-10,7-ELEVEN 1234,-85.00,THB
+2025-02-03,NETFLIX.COM 866-579-7172,-419.00,THB
+2025-02-11,SPOTIFY P3C8D1 STOCKHOLM,-149.00,THB
+2025-02-14,7-ELEVEN 1234,-240.00,THB
+2025-03-03,NETFLIX.COM 866-579-7172,-419.00,THB
+2025-03-11,SPOTIFY P9Z2K4 STOCKHOLM,-149.00,THB
+2025-04-02,NETFLIX.COM 866-579-7172,-449.00,THB
+2025-04-11,SPOTIFY P1Q7W3 STOCKHOLM,-149.00,THB
+`
+
+const statementOFX = `OFXHEADER:100
+DATA:OFXSGML
+VERSION:102
+
+<OFX>
+<BANKMSGSRSV1><STMTTRNRS><STMTRS>
+<CURDEF>USD
+<BANKTRANLIST>
+<STMTTRN>
+<TRNTYPE>DEBIT
+<DTPOSTED>20250115120000.000[-5:EST]
+<TRNAMT>-9.99
+<FITID>1001
+<NAME>GITHUB INC
+</STMTTRN>
+<STMTTRN>
+<TRNTYPE>CREDIT
+<DTPOSTED>20250120
+<TRNAMT>100.00
+<FITID>1002
+<NAME>REFUND
+</STMTTRN>
+</BANKTRANLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`
+
+const statementCAMT = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:camt.053.001.02">
+  <BkToCstmrStmt>
+    <Stmt>
+      <Ntry>
+        <NtryRef>A1</NtryRef>
+        <Amt Ccy="EUR">12.99</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>BOOK</Sts>
+        <BookgDt><Dt>2025-01-07</Dt></BookgDt>
+        <NtryDtls><TxDtls>
+          <RltdPties><Cdtr><Nm>Disney Plus</Nm></Cdtr></RltdPties>
+          <RmtInf><Ustrd>Subscription January</Ustrd></RmtInf>
+        </TxDtls></NtryDtls>
+      </Ntry>
+      <Ntry>
+        <Amt Ccy="EUR">5.00</Amt>
+        <CdtDbtInd>DBIT</CdtDbtInd>
+        <Sts>PDNG</Sts>
+        <BookgDt><Dt>2025-01-08</Dt></BookgDt>
+      </Ntry>
+    </Stmt>
+  </BkToCstmrStmt>
+</Document>
+`
+
+func TestParse(t *testing.T) {
+	t.Run("CSV", func(t *testing.T) {
+		txns, err := statement.Parse("", []byte(statementCSV), nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, txns, 10)
+		assert.True(t, txns[0].Debit)
+		assert.Equal(t, 419.0, txns[0].Amount)
+		assert.False(t, txns[1].Debit)
+	})
+
+	t.Run("OFX", func(t *testing.T) {
+		txns, err := statement.Parse("", []byte(statementOFX), nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, txns, 2)
+		assert.Equal(t, "GITHUB INC", txns[0].Description)
+		assert.Equal(t, "2025-01-15", txns[0].Date.Format("2006-01-02"))
+		assert.Equal(t, "USD", txns[0].Currency)
+		assert.True(t, txns[0].Debit)
+		assert.False(t, txns[1].Debit)
+	})
+
+	t.Run("CAMT.053", func(t *testing.T) {
+		txns, err := statement.Parse("", []byte(statementCAMT), nil)
+
+		assert.NoError(t, err)
+		assert.Len(t, txns, 1)
+		assert.Equal(t, "Disney Plus", txns[0].Description)
+		assert.Equal(t, "EUR", txns[0].Currency)
+		assert.Equal(t, 12.99, txns[0].Amount)
+	})
+
+	t.Run("CSV Without Amount", func(t *testing.T) {
+		txns, err := statement.Parse(statement.FormatCSV, []byte("Date,Description\n2025-01-01,Shop\n"), nil)
+
+		assert.Nil(t, txns)
+		assert.ErrorIs(t, err, statement.ErrInvalidStatement)
+	})
+}
+
+func TestNormalizeMerchant(t *testing.T) {
+	assert.Equal(t, "netflix", statement.NormalizeMerchant("NETFLIX.COM 866-579-7172"))
+	assert.Equal(t, "netflix", statement.NormalizeMerchant("NETFLIX.COM 866-579-7172 CA"))
+	assert.Equal(t, "netflix", statement.NormalizeMerchant("Netflix.com*Subscription"))
+	assert.Equal(t, "netflix", statement.NormalizeMerchant("NETFLIX.COM LOS GATOS CA"))
+	assert.Equal(t, "spotify", statement.NormalizeMerchant("SPOTIFY P3C8D1 STOCKHOLM"))
+	assert.Equal(t, "spotify", statement.NormalizeMerchant("Spotify SE 0123456"))
+	assert.Equal(t, "disney plus", statement.NormalizeMerchant("POS 12/03 DISNEY PLUS"))
+	assert.Equal(t, "apple", statement.NormalizeMerchant("APPLE.COM/BILL"))
+	assert.True(t, statement.SameMerchant("netflix", "netflix"))
+	assert.False(t, statement.SameMerchant("apple", "apple music"))
+	assert.False(t, statement.SameMerchant("", ""))
+}
+
+func TestDetect(t *testing.T) {
+	txns, err := statement.ParseCSV([]byte(statementCSV), nil)
+	assert.NoError(t, err)
+
+	found := statement.Detect(txns)
+
+	assert.Len(t, found, 2)
+
+	netflix := found[0]
+	if netflix.MerchantKey != "netflix" {
+		netflix = found[1]
+	}
+	assert.Equal(t, "Netflix", netflix.Name)
+	assert.Equal(t, "monthly", netflix.Cadence)
+	assert.Equal(t, 449.0, netflix.Amount)
+	assert.Equal(t, "2025-05-02", netflix.NextDate.Format("2006-01-02"))
+	assert.Len(t, netflix.Transactions, 4)
+	assert.Greater(t, netflix.AmountVariation, 0.0)
+}
+
+func TestDetectVaryingSuffixes(t *testing.T) {
+	const csv = `Date,Description,Amount,Currency
+2025-01-03,NETFLIX.COM 866-579-7172 CA,-15.49,USD
+2025-02-03,Netflix.com*Subscription,-15.49,USD
+2025-03-03,NETFLIX.COM LOS GATOS CA,-15.49,USD
+2025-01-11,SPOTIFY P3C8D1 STOCKHOLM,-10.99,USD
+2025-02-11,SPOTIFY,-10.99,USD
+2025-03-11,Spotify Stockholm SE,-10.99,USD
+2025-04-11,SPOTIFY*Subscription,-10.99,USD
+`
+	txns, err := statement.ParseCSV([]byte(csv), nil)
+	assert.NoError(t, err)
+
+	found := statement.Detect(txns)
+
+	keys := map[string]int{}
+	for _, r := range found {
+		keys[r.MerchantKey] = len(r.Transactions)
+	}
+	assert.Equal(t, map[string]int{"netflix": 3, "spotify": 4}, keys)
+}
+
+func TestParseCSVFormats(t *testing.T) {
+	t.Run("Month First Dates", func(t *testing.T) {
+		const csv = `Date,Description,Amount
+01/12/2025,NETFLIX,-15.49
+01/13/2025,SPOTIFY,-10.99
+`
+		txns, err := statement.ParseCSV([]byte(csv), nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, "2025-01-12", txns[0].Date.Format("2006-01-02"))
+		assert.Equal(t, "2025-01-13", txns[1].Date.Format("2006-01-02"))
+	})
+
+	t.Run("Decimal Comma", func(t *testing.T) {
+		const csv = `Date,Description,Amount
+03.01.2025,NETFLIX,"-9,99"
+04.01.2025,RENT,"-1.250,00"
+`
+		txns, err := statement.ParseCSV([]byte(csv), nil)
+
+		assert.NoError(t, err)
+		assert.Equal(t, 9.99, txns[0].Amount)
+		assert.Equal(t, 1250.0, txns[1].Amount)
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	cases := map[string]float64{
+		"1,234.50": 1234.5,
+		"1.234,50": 1234.5,
+		"9,99":     9.99,
+		"1,290":    1290,
+		"(12.00)":  -12,
+		"12.00-":   -12,
+		"€ 4,5":    4.5,
+	}
+	for in, want := range cases {
+		got, err := statement.ParseAmount(in)
+		assert.NoError(t, err, in)
+		assert.Equal(t, want, got, in)
+	}
+}
+
+func TestDetectKeepsProductsApart(t *testing.T) {
+	const csv = `Date,Description,Amount,Currency
+2025-01-05,APPLE.COM/BILL,-0.99,USD
+2025-02-05,APPLE.COM/BILL,-0.99,USD
+2025-03-05,APPLE.COM/BILL,-0.99,USD
+2025-01-20,APPLE MUSIC,-10.99,USD
+2025-02-20,APPLE MUSIC,-10.99,USD
+2025-03-20,APPLE MUSIC,-10.99,USD
+`
+	txns, err := statement.ParseCSV([]byte(csv), nil)
+	assert.NoError(t, err)
+
+	found := statement.Detect(txns)
+
+	amounts := map[string]float64{}
+	for _, r := range found {
+		amounts[r.MerchantKey] = r.Amount
+	}
+	assert.Equal(t, map[string]float64{"apple": 0.99, "apple music": 10.99}, amounts)
+}